@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/urfave/cli"
+)
+
+// maxAliasDepth stops aliases expanding to each other forever
+const maxAliasDepth = 10
+
+var aliasDepth int
+
+// splitArgs splits the command line of an alias like a shell does for words and quotes, without any expansion
+func splitArgs(line string) ([]string, error) {
+	args := []string{}
+	var word strings.Builder
+	inWord := false
+	quote := rune(0)
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote in alias '" + line + "'")
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+// runAlias runs the command line an alias of the configuration stands for, with the arguments given after the alias.
+// It returns false when command is not an alias.
+func runAlias(c *cli.Context, paths adrPaths, command string) (bool, error) {
+	repo, err := adr.Open(paths.ConfigDir)
+	if err != nil {
+		return false, nil
+	}
+	line, ok := repo.Settings().Aliases[command]
+	if !ok {
+		return false, nil
+	}
+	aliasDepth++
+	if aliasDepth > maxAliasDepth {
+		return true, errors.New("the alias '" + command + "' expands to itself")
+	}
+	expanded, err := splitArgs(line)
+	if err != nil {
+		return true, err
+	}
+	args := c.Args()
+	// the global flags given before the alias are kept
+	commandAt := len(os.Args) - len(args)
+	if commandAt < 1 {
+		commandAt = 1
+	}
+	run := append([]string{}, os.Args[:commandAt]...)
+	run = append(append(run, expanded...), args.Tail()...)
+	return true, c.App.Run(run)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// approveAdr logs the approval of the ADR numbered number by approver, recording the operation in the journal,
+// and tells who the ADR still waits for
+func approveAdr(ctx context.Context, repo *adr.Repository, out *reporter, number int, approver string) (adr.Record, error) {
+	record, err := repo.Find(ctx, number)
+	if err != nil {
+		return record, err
+	}
+	op := startOperation(repo.ConfigDir, "approve", []string{strconv.Itoa(number), approver})
+	op.track(record.Path)
+	record, q, err := repo.Approve(ctx, number, approver)
+	op.done()
+	if err != nil {
+		return record, err
+	}
+	out.Success("ADR " + record.ID() + " approved by " + record.Approvals[len(record.Approvals)-1].By + ", " + q.String())
+	if q.Met() {
+		out.Info("The approval quorum is met, ADR " + record.ID() + " can be accepted")
+	} else {
+		out.Info("Waiting for " + strings.Join(q.Missing(), ", "))
+	}
+	return record, nil
+}
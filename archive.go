@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// archiveAdr moves record into the archive folder of its folder, see adr.ArchiveDir, with its signature. Its links
+// and the links of the other ADRs to it are rewritten to keep pointing to the same files. It returns the archived
+// record, the operation recording the paths written.
+func archiveAdr(ctx context.Context, repo *adr.Repository, record adr.Record, op *operation) (adr.Record, error) {
+	archived := record
+	archived.Path = adr.ArchivePath(record)
+	archived.Archived = true
+	if _, err := repo.FS.Stat(archived.Path); err == nil {
+		return record, errors.New(archived.Path + " already exists, the ADR was archived before")
+	}
+	content, err := repo.FS.ReadFile(record.Path)
+	if err != nil {
+		return record, err
+	}
+	name := filepath.Base(record.Path)
+	if err := replaceLinks(repo.FS, repo.Dir, name, adr.ArchiveDir+"/"+name, op); err != nil {
+		return record, err
+	}
+	if err := repo.FS.MkdirAll(filepath.Dir(archived.Path), 0755); err != nil {
+		return record, err
+	}
+	moved := []string{record.Path}
+	if signature, err := repo.FS.ReadFile(record.SignaturePath()); err == nil {
+		op.created(archived.SignaturePath())
+		if err := repo.FS.WriteFile(archived.SignaturePath(), signature, 0644); err != nil {
+			return record, err
+		}
+		moved = append(moved, record.SignaturePath())
+	}
+	op.created(archived.Path)
+	if err := repo.FS.WriteFile(archived.Path, adr.ArchiveLinks(content), 0644); err != nil {
+		return record, err
+	}
+	for _, path := range moved {
+		op.track(path)
+		if err := repo.FS.Remove(path); err != nil {
+			return record, err
+		}
+	}
+	return archived, ctx.Err()
+}
+
+// deleteAdr removes the file of record and its signature, returning the paths removed
+func deleteAdr(repo *adr.Repository, record adr.Record, op *operation) ([]string, error) {
+	removed := []string{}
+	for _, path := range []string{record.Path, record.SignaturePath()} {
+		if _, err := repo.FS.Stat(path); err != nil {
+			continue
+		}
+		op.track(path)
+		if err := repo.FS.Remove(path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// linkingAdrs the ADRs, archived or not, whose links point to record
+func linkingAdrs(ctx context.Context, repo *adr.Repository, record adr.Record) ([]adr.Record, error) {
+	records, err := repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := repo.ListArchived(ctx)
+	if err != nil {
+		return nil, err
+	}
+	linking := []adr.Record{}
+	seen := map[string]bool{}
+	for _, edge := range adr.Edges(append(records, archived...)) {
+		if edge.To.Path == record.Path && edge.From.Path != record.Path && !seen[edge.From.Path] {
+			seen[edge.From.Path] = true
+			linking = append(linking, edge.From)
+		}
+	}
+	return linking, nil
+}
+
+// findArchivedAdr finds the archived ADR referred to by arg, a number or category/number like findAdr takes
+func findArchivedAdr(ctx context.Context, repo *adr.Repository, arg string) (adr.Record, error) {
+	category, ref := splitAdrRef(arg)
+	number, err := parseAdrNumber(ref)
+	if err != nil {
+		return adr.Record{}, err
+	}
+	records, err := repo.ListArchived(ctx)
+	if err != nil {
+		return adr.Record{}, err
+	}
+	for _, record := range records {
+		if record.Number == number && (category == "" || record.Category == category) {
+			return record, nil
+		}
+	}
+	return adr.Record{}, fmt.Errorf("%w: no archived ADR %s", adr.ErrAdrNotFound, arg)
+}
+
+// withArchived adds the archived ADRs of the repository matching q to records, of every scope with the all scope
+func withArchived(ctx context.Context, repo *adr.Repository, records []adr.Record, q adr.Query) ([]adr.Record, error) {
+	archived := []adr.Record{}
+	if repo.Scope != adr.AllScopes {
+		read, err := repo.ListArchived(ctx)
+		if err != nil {
+			return nil, err
+		}
+		archived = read
+	} else {
+		dirs := map[string]string{defaultScope: repo.BaseDir()}
+		for name, scope := range repo.Settings().Scopes {
+			dirs[name] = scopeDir(ctx, repo.BaseDir(), scope)
+		}
+		for name, dir := range dirs {
+			read, err := adr.ReadArchive(ctx, repo.FS, dir)
+			if err != nil {
+				return nil, err
+			}
+			for i := range read {
+				read[i].Scope = name
+			}
+			archived = append(archived, read...)
+		}
+		sort.SliceStable(archived, func(i, j int) bool { return archived[i].Scope < archived[j].Scope })
+	}
+	archived, err := q.Filter(repo.FS, archived)
+	if err != nil {
+		return nil, err
+	}
+	return append(records, archived...), nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// atlassianClient calls the REST API of a Jira or Confluence site with an API token
+type atlassianClient struct {
+	product   string
+	baseURL   string
+	client    *http.Client
+	authorize func(*http.Request)
+}
+
+// newAtlassianClient a client of the site of product at baseURL. The token is sent with email on Atlassian Cloud,
+// and as a personal access token to Data Center sites when email is empty.
+func newAtlassianClient(product string, baseURL string, email string, token string) *atlassianClient {
+	client := &atlassianClient{product: product, baseURL: strings.TrimSuffix(baseURL, "/"), client: &http.Client{Timeout: 30 * time.Second}}
+	if email != "" {
+		client.authorize = func(r *http.Request) { r.SetBasicAuth(email, token) }
+	} else {
+		client.authorize = func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+	}
+	return client
+}
+
+// do sends a request with body encoded to JSON and decodes the JSON answer into result, unless it is nil
+func (a *atlassianClient) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	request.Header.Set("Accept", "application/json")
+	a.authorize(request)
+	response, err := a.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode >= 300 {
+		return &atlassianError{product: a.product, StatusCode: response.StatusCode, request: method + " " + path, message: atlassianErrors(content)}
+	}
+	if result == nil || len(content) == 0 {
+		return nil
+	}
+	return json.Unmarshal(content, result)
+}
+
+// atlassianError an error answer of a Jira or Confluence site
+type atlassianError struct {
+	product    string
+	StatusCode int
+	request    string
+	message    string
+}
+
+func (e *atlassianError) Error() string {
+	return fmt.Sprintf("%s answered %d %s to %s: %s", e.product, e.StatusCode, http.StatusText(e.StatusCode), e.request, e.message)
+}
+
+// atlassianErrors the error messages of an error answer, its raw content when it is not JSON
+func atlassianErrors(content []byte) string {
+	answer := struct {
+		// Jira
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+		// Confluence
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(content, &answer) != nil {
+		return strings.TrimSpace(string(content))
+	}
+	messages := answer.ErrorMessages
+	for field, message := range answer.Errors {
+		messages = append(messages, field+": "+message)
+	}
+	if answer.Message != "" {
+		messages = append(messages, answer.Message)
+	}
+	return strings.Join(messages, ", ")
+}
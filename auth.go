@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// authRealm the realm of the basic authentication challenge, shown by browsers
+const authRealm = "adr"
+
+// credential a client of adr serve, with its secrets read from the configuration or the environment
+type credential struct {
+	token    string
+	username string
+	password string
+	write    bool
+}
+
+// serveCredentials the credentials of the configuration, checked to fail at startup rather than on each request
+func serveCredentials(config []adr.Credential) ([]credential, error) {
+	credentials := []credential{}
+	for i, c := range config {
+		token, username, password := c.Secrets(os.Getenv)
+		if c.Access != adr.ReadAccess && c.Access != adr.WriteAccess {
+			return nil, errors.New("serve_credentials: the access of credential " + strconv.Itoa(i+1) + " must be " + adr.ReadAccess + " or " + adr.WriteAccess)
+		}
+		if token == "" && (username == "" || password == "") {
+			return nil, errors.New("serve_credentials: credential " + strconv.Itoa(i+1) + " needs a token, or a user name and a password, check its environment variables")
+		}
+		credentials = append(credentials, credential{token: token, username: username, password: password, write: c.Access == adr.WriteAccess})
+	}
+	return credentials, nil
+}
+
+// sameSecret compares secrets in constant time, hashing them first so their length does not leak either
+func sameSecret(given string, expected string) bool {
+	a, b := sha256.Sum256([]byte(given)), sha256.Sum256([]byte(expected))
+	return expected != "" && subtle.ConstantTimeCompare(a[:], b[:]) == 1
+}
+
+// authenticate finds the credential of a request, false when the request has none that matches
+func authenticate(credentials []credential, r *http.Request) (credential, bool) {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		token := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+		for _, c := range credentials {
+			if sameSecret(token, c.token) {
+				return c, true
+			}
+		}
+		return credential{}, false
+	}
+	if username, password, ok := r.BasicAuth(); ok {
+		for _, c := range credentials {
+			if c.username != "" && sameSecret(username, c.username) && sameSecret(password, c.password) {
+				return c, true
+			}
+		}
+	}
+	return credential{}, false
+}
+
+// writes tells whether a request changes ADRs, GraphQL queries only read them
+func writes(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return r.URL.Path != apiPrefix+"graphql"
+}
+
+// requireCredentials lets in the requests with a credential allowing them, reading needs read or write access
+// and changing ADRs needs write access. Without credentials, everyone is let in.
+func requireCredentials(credentials []credential, next http.Handler) http.Handler {
+	if len(credentials) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, ok := authenticate(credentials, r)
+		if !ok {
+			w.Header().Add("WWW-Authenticate", `Basic realm="`+authRealm+`", charset="UTF-8"`)
+			w.Header().Add("WWW-Authenticate", `Bearer realm="`+authRealm+`"`)
+			writeJSON(w, http.StatusUnauthorized, apiError{Error: "authentication required, with a bearer token or basic authentication"})
+			return
+		}
+		if writes(r) && !c.write {
+			writeJSON(w, http.StatusForbidden, apiError{Error: "these credentials are read-only"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// exposed tells whether a listen address accepts connections from other machines
+func exposed(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return true
+	}
+	if host == "localhost" {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip == nil || !ip.IsLoopback()
+}
+
+// sameOrigin refuses the requests sent by the pages of other sites, whose Origin is not the server's, so that the
+// pages the user visits cannot change the ADRs. A server listening on a loopback address on port 8080 is only reached
+// as localhost:8080 or a loopback address: a page of another site resolving its own name to 127.0.0.1, DNS rebinding,
+// is refused as well.
+func sameOrigin(addr net.Addr, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !listenedHost(addr, r.Host) {
+			writeJSON(w, http.StatusMisdirectedRequest, apiError{Error: "this server is not reached as " + r.Host + ", use localhost"})
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || !strings.EqualFold(u.Host, r.Host) {
+				writeJSON(w, http.StatusForbidden, apiError{Error: "requests from the pages of " + origin + " are not allowed"})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listenedHost tells whether host, the Host of a request, names the server listening on addr. Servers listening on
+// other addresses than loopback ones are reached with names of their own, which are all accepted.
+func listenedHost(addr net.Addr, host string) bool {
+	listenHost, port, err := net.SplitHostPort(addr.String())
+	if ip := net.ParseIP(listenHost); err != nil || ip == nil || !ip.IsLoopback() {
+		return true
+	}
+	name, requestPort, err := net.SplitHostPort(host)
+	if err != nil {
+		name, requestPort = strings.Trim(host, "[]"), "80"
+	}
+	if requestPort != port {
+		return false
+	}
+	if strings.EqualFold(name, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(name)
+	return ip != nil && ip.IsLoopback()
+}
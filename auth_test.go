@@ -0,0 +1,192 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSameSecret(t *testing.T) {
+	tests := []struct {
+		given    string
+		expected string
+		want     bool
+	}{
+		{"s3cret", "s3cret", true},
+		{"s3cret", "s3cret!", false},
+		{"S3cret", "s3cret", false},
+		{"", "s3cret", false},
+		{"", "", false},
+	}
+	for _, test := range tests {
+		if got := sameSecret(test.given, test.expected); got != test.want {
+			t.Errorf("sameSecret(%q, %q) = %v, want %v", test.given, test.expected, got, test.want)
+		}
+	}
+}
+
+func TestRequireCredentials(t *testing.T) {
+	credentials := []credential{
+		{token: "reader-token"},
+		{token: "writer-token", write: true},
+		{username: "ann", password: "pass", write: true},
+	}
+	handler := requireCredentials(credentials, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		auth   func(r *http.Request)
+		want   int
+	}{
+		{
+			name:   "no credentials",
+			method: http.MethodGet,
+			path:   "/api/adrs",
+			auth:   func(r *http.Request) {},
+			want:   http.StatusUnauthorized,
+		},
+		{
+			name:   "unknown token",
+			method: http.MethodGet,
+			path:   "/api/adrs",
+			auth:   func(r *http.Request) { r.Header.Set("Authorization", "Bearer other-token") },
+			want:   http.StatusUnauthorized,
+		},
+		{
+			name:   "read-only token reading",
+			method: http.MethodGet,
+			path:   "/api/adrs",
+			auth:   func(r *http.Request) { r.Header.Set("Authorization", "Bearer reader-token") },
+			want:   http.StatusNoContent,
+		},
+		{
+			name:   "read-only token writing",
+			method: http.MethodPost,
+			path:   "/api/adrs",
+			auth:   func(r *http.Request) { r.Header.Set("Authorization", "Bearer reader-token") },
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   "read-only token querying GraphQL",
+			method: http.MethodPost,
+			path:   "/api/graphql",
+			auth:   func(r *http.Request) { r.Header.Set("Authorization", "Bearer reader-token") },
+			want:   http.StatusNoContent,
+		},
+		{
+			name:   "write token writing",
+			method: http.MethodPut,
+			path:   "/api/adrs/1",
+			auth:   func(r *http.Request) { r.Header.Set("Authorization", "Bearer writer-token") },
+			want:   http.StatusNoContent,
+		},
+		{
+			name:   "basic authentication",
+			method: http.MethodPost,
+			path:   "/api/adrs",
+			auth:   func(r *http.Request) { r.SetBasicAuth("ann", "pass") },
+			want:   http.StatusNoContent,
+		},
+		{
+			name:   "basic authentication with a wrong password",
+			method: http.MethodGet,
+			path:   "/api/adrs",
+			auth:   func(r *http.Request) { r.SetBasicAuth("ann", "guess") },
+			want:   http.StatusUnauthorized,
+		},
+		{
+			name:   "basic authentication with a token as password",
+			method: http.MethodGet,
+			path:   "/api/adrs",
+			auth:   func(r *http.Request) { r.SetBasicAuth("", "reader-token") },
+			want:   http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, test.path, nil)
+			test.auth(r)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != test.want {
+				t.Errorf("%s %s answered %d, want %d", test.method, test.path, w.Code, test.want)
+			}
+			if w.Code == http.StatusUnauthorized && len(w.Header().Values("WWW-Authenticate")) != 2 {
+				t.Errorf("the challenges are %v, want a basic and a bearer one", w.Header().Values("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRequireNoCredentials(t *testing.T) {
+	handler := requireCredentials(nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/adrs", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("a request to a server without credentials answered %d, want %d", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestListenedHost(t *testing.T) {
+	loopback := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8080}
+	tests := []struct {
+		addr net.Addr
+		host string
+		want bool
+	}{
+		{loopback, "localhost:8080", true},
+		{loopback, "LOCALHOST:8080", true},
+		{loopback, "127.0.0.1:8080", true},
+		{loopback, "[::1]:8080", true},
+		{loopback, "localhost:9090", false},
+		{loopback, "localhost", false},
+		{loopback, "attacker.example:8080", false},
+		{&net.TCPAddr{IP: net.ParseIP("::1"), Port: 80}, "localhost", true},
+		{&net.TCPAddr{IP: net.IPv4zero, Port: 8080}, "adr.example.com", true},
+		{&net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 8080}, "10.0.0.2:8080", true},
+	}
+	for _, test := range tests {
+		if got := listenedHost(test.addr, test.host); got != test.want {
+			t.Errorf("listenedHost(%s, %q) = %v, want %v", test.addr, test.host, got, test.want)
+		}
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	handler := sameOrigin(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8080}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	tests := []struct {
+		name   string
+		host   string
+		origin string
+		want   int
+	}{
+		{name: "no origin", host: "localhost:8080", want: http.StatusNoContent},
+		{name: "own origin", host: "localhost:8080", origin: "http://localhost:8080", want: http.StatusNoContent},
+		{name: "other site", host: "localhost:8080", origin: "https://attacker.example", want: http.StatusForbidden},
+		{name: "other port", host: "localhost:8080", origin: "http://localhost:3000", want: http.StatusForbidden},
+		{name: "null origin", host: "localhost:8080", origin: "null", want: http.StatusForbidden},
+		{name: "DNS rebinding", host: "attacker.example:8080", origin: "http://attacker.example:8080", want: http.StatusMisdirectedRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/adrs", nil)
+			r.Host = test.host
+			if test.origin != "" {
+				r.Header.Set("Origin", test.origin)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != test.want {
+				t.Errorf("a request to %s from %q answered %d, want %d", test.host, test.origin, w.Code, test.want)
+			}
+		})
+	}
+}
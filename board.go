@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+	"golang.org/x/term"
+)
+
+// Keys of the status board
+const (
+	keyUp    = "up"
+	keyDown  = "down"
+	keyLeft  = "left"
+	keyRight = "right"
+	keyBack  = "back"
+	keyNext  = "next"
+	keyQuit  = "quit"
+)
+
+// boardKeys maps the bytes read from the terminal to board keys: arrows and hjkl move the cursor,
+// < > and shifted arrows or HL move the selected ADR to the previous or next status
+var boardKeys = map[string]string{
+	"\x1b[A": keyUp, "k": keyUp,
+	"\x1b[B": keyDown, "j": keyDown,
+	"\x1b[D": keyLeft, "h": keyLeft,
+	"\x1b[C": keyRight, "l": keyRight,
+	"<": keyBack, "H": keyBack, "\x1b[1;2D": keyBack,
+	">": keyNext, "L": keyNext, "\x1b[1;2C": keyNext,
+	"q": keyQuit, "\x1b": keyQuit, "\x03": keyQuit,
+}
+
+// board the ADRs in one column per status, with a cursor on one of them
+type board struct {
+	columns [][]adr.Record
+	column  int
+	row     int
+}
+
+// newBoard sorts the numbered ADRs into columns, ADRs with an unknown status and drafts are left out
+func newBoard(records []adr.Record) *board {
+	b := &board{columns: make([][]adr.Record, len(adr.Statuses))}
+	for _, record := range records {
+		for i, status := range adr.Statuses {
+			if record.Status == status && record.Draft == "" {
+				b.columns[i] = append(b.columns[i], record)
+			}
+		}
+	}
+	return b
+}
+
+// selected the ADR under the cursor
+func (b *board) selected() (adr.Record, bool) {
+	if b.row >= len(b.columns[b.column]) {
+		return adr.Record{}, false
+	}
+	return b.columns[b.column][b.row], true
+}
+
+// moveCursor moves the cursor by columns and rows, keeping it on the board
+func (b *board) moveCursor(columns int, rows int) {
+	b.column = clamp(b.column+columns, 0, len(b.columns)-1)
+	b.row = clamp(b.row+rows, 0, len(b.columns[b.column])-1)
+}
+
+// replace moves the selected ADR to the column of its new status, the cursor following it
+func (b *board) replace(record adr.Record) {
+	column := b.columns[b.column]
+	b.columns[b.column] = append(column[:b.row:b.row], column[b.row+1:]...)
+	for i, status := range adr.Statuses {
+		if record.Status == status {
+			b.columns[i] = append(b.columns[i], record)
+			b.column, b.row = i, len(b.columns[i])-1
+			return
+		}
+	}
+	b.moveCursor(0, 0)
+}
+
+func clamp(value int, min int, max int) int {
+	if value > max {
+		value = max
+	}
+	if value < min {
+		value = min
+	}
+	return value
+}
+
+// render draws the board for a terminal of the given width, in raw mode lines end with \r\n
+func (b *board) render(w io.Writer, width int, message string) {
+	columnWidth := width/len(b.columns) - 1
+	fmt.Fprint(w, "\x1b[H\x1b[2J")
+	cells := []string{}
+	for i, status := range adr.Statuses {
+		cells = append(cells, fit(fmt.Sprintf("%s (%d)", status, len(b.columns[i])), columnWidth))
+	}
+	fmt.Fprint(w, "\x1b[1m"+strings.Join(cells, " ")+"\x1b[0m\r\n")
+	fmt.Fprint(w, strings.Repeat("─", width-1)+"\r\n")
+	for row := 0; ; row++ {
+		cells, more := []string{}, false
+		for i, column := range b.columns {
+			cell := fit("", columnWidth)
+			if row < len(column) {
+				more = true
+				cell = fit(column[row].ID()+" "+column[row].Title, columnWidth)
+				if i == b.column && row == b.row {
+					cell = "\x1b[7m" + cell + "\x1b[0m"
+				}
+			}
+			cells = append(cells, cell)
+		}
+		if !more {
+			break
+		}
+		fmt.Fprint(w, strings.Join(cells, " ")+"\r\n")
+	}
+	fmt.Fprint(w, "\r\n←↓↑→ or hjkl select, < > or H L change the status, q quit\r\n")
+	if message != "" {
+		fmt.Fprint(w, message+"\r\n")
+	}
+}
+
+// fit pads or truncates text to exactly width runes
+func fit(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) > width {
+		if width < 1 {
+			return ""
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return text + strings.Repeat(" ", width-len(runes))
+}
+
+// rawTerminal puts the terminal in raw mode, its keys being read one by one, returning the function restoring it and
+// clearing the screen
+func rawTerminal() (func(), error) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		term.Restore(fd, state)
+		fmt.Print("\x1b[H\x1b[2J")
+	}, nil
+}
+
+// terminalWidth the number of columns of the terminal, 80 when unknown
+func terminalWidth() int {
+	width, _ := terminalSize()
+	return width
+}
+
+// terminalSize the number of columns and rows of the terminal, 80 by 24 when unknown
+func terminalSize() (int, int) {
+	if columns, rows, err := term.GetSize(int(os.Stdout.Fd())); err == nil && columns > 0 && rows > 0 {
+		return columns, rows
+	}
+	return 80, 24
+}
+
+// runBoard shows the status board until the user quits, each move of an ADR transitions its status
+func runBoard(ctx context.Context, repo *adr.Repository) error {
+	if !interactive() {
+		return errors.New("adr board needs a terminal")
+	}
+	records, err := repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	restore, err := rawTerminal()
+	if err != nil {
+		return errors.New("adr board cannot read keys: " + err.Error())
+	}
+	defer restore()
+
+	b := newBoard(records)
+	message := ""
+	buffer := make([]byte, 8)
+	for ctx.Err() == nil {
+		b.render(os.Stdout, terminalWidth(), message)
+		message = ""
+		n, err := os.Stdin.Read(buffer)
+		if err != nil {
+			return err
+		}
+		switch boardKeys[string(buffer[:n])] {
+		case keyUp:
+			b.moveCursor(0, -1)
+		case keyDown:
+			b.moveCursor(0, 1)
+		case keyLeft:
+			b.moveCursor(-1, 0)
+		case keyRight:
+			b.moveCursor(1, 0)
+		case keyBack, keyNext:
+			record, ok := b.selected()
+			if !ok {
+				continue
+			}
+			to := b.column + 1
+			if boardKeys[string(buffer[:n])] == keyBack {
+				to = b.column - 1
+			}
+			if to < 0 || to >= len(adr.Statuses) {
+				continue
+			}
+			updated, err := transitionAdr(ctx, repo, "board", record, adr.Statuses[to])
+			if err != nil {
+				message = err.Error()
+				continue
+			}
+			b.replace(updated)
+			message = fmt.Sprintf("%s. %s is now %s", updated.ID(), updated.Title, updated.Status)
+		case keyQuit:
+			return nil
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// changesPollInterval how often the ADR directories are read again to find the changes made by any tool
+var changesPollInterval = time.Second
+
+// changesKeepAlive how often an idle stream gets a comment, so proxies do not close it
+var changesKeepAlive = 30 * time.Second
+
+// changeBacklog how many changes a slow client can fall behind before it is disconnected
+const changeBacklog = 64
+
+// adrFile what is known of an ADR file, to tell the changes made to it
+type adrFile struct {
+	record  adr.Record
+	modTime time.Time
+	size    int64
+}
+
+// changeStream finds the changes of the ADRs served, whether made through the API, by adr or by an editor,
+// and pushes them to the clients of /api/events as server-sent events
+type changeStream struct {
+	ctx      context.Context
+	registry *registry
+
+	mu      sync.Mutex
+	clients map[chan adr.ChangeJSON]struct{}
+}
+
+// newChangeStream streams the changes of the ADRs of registry until ctx is done
+func newChangeStream(ctx context.Context, registry *registry) *changeStream {
+	return &changeStream{ctx: ctx, registry: registry, clients: map[chan adr.ChangeJSON]struct{}{}}
+}
+
+// snapshot the ADR files served, by path
+func (s *changeStream) snapshot() (map[string]adrFile, error) {
+	records, err := s.registry.Query(s.ctx, adr.Query{})
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]adrFile{}
+	for _, record := range records {
+		info, err := s.registry.repo.FS.Stat(record.Path)
+		if err != nil {
+			continue
+		}
+		files[record.Path] = adrFile{record: record, modTime: info.ModTime(), size: info.Size()}
+	}
+	return files, nil
+}
+
+// changes the changes turning before into after, a rewritten status being a status change rather than an update
+func changes(before map[string]adrFile, after map[string]adrFile) []adr.ChangeJSON {
+	found := []adr.ChangeJSON{}
+	for path, file := range after {
+		previous, known := before[path]
+		switch {
+		case !known:
+			found = append(found, adr.ChangeJSON{Event: adr.ChangeCreated, Record: file.record.JSON()})
+		case previous.record.Status != file.record.Status:
+			found = append(found, adr.ChangeJSON{Event: adr.ChangeStatusChanged, Record: file.record.JSON(),
+				From: previous.record.Status, To: file.record.Status})
+		case !previous.modTime.Equal(file.modTime) || previous.size != file.size:
+			found = append(found, adr.ChangeJSON{Event: adr.ChangeUpdated, Record: file.record.JSON()})
+		}
+	}
+	for path, file := range before {
+		if _, kept := after[path]; !kept {
+			found = append(found, adr.ChangeJSON{Event: adr.ChangeRemoved, Record: file.record.JSON()})
+		}
+	}
+	for i := range found {
+		found[i].SchemaVersion = adr.SchemaVersion
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Record.Path < found[j].Record.Path })
+	return found
+}
+
+// watch reads the ADR directories every changesPollInterval and publishes what changed, until the server stops
+func (s *changeStream) watch() {
+	before, err := s.snapshot()
+	if err != nil {
+		slog.Warn("ADRs not read, changes are not streamed", "error", err)
+		return
+	}
+	ticker := time.NewTicker(changesPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.ctx.Done():
+			return
+		}
+		after, err := s.snapshot()
+		if err != nil {
+			slog.Debug("ADRs not read", "error", err)
+			continue
+		}
+		for _, change := range changes(before, after) {
+			s.publish(change)
+		}
+		before = after
+	}
+}
+
+func (s *changeStream) publish(change adr.ChangeJSON) {
+	slog.Debug("ADR changed", "event", change.Event, "path", change.Record.Path)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for client := range s.clients {
+		select {
+		case client <- change:
+		default:
+			// the client does not keep up, closing its channel ends its stream
+			delete(s.clients, client)
+			close(client)
+		}
+	}
+}
+
+func (s *changeStream) subscribe() chan adr.ChangeJSON {
+	client := make(chan adr.ChangeJSON, changeBacklog)
+	s.mu.Lock()
+	s.clients[client] = struct{}{}
+	s.mu.Unlock()
+	return client
+}
+
+func (s *changeStream) unsubscribe(client chan adr.ChangeJSON) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[client]; ok {
+		delete(s.clients, client)
+		close(client)
+	}
+}
+
+// ServeHTTP streams the changes as server-sent events named after their event, with the JSON of the change as data
+func (s *changeStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	client := s.subscribe()
+	defer s.unsubscribe(client)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	controller := http.NewResponseController(w)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if err := controller.Flush(); err != nil {
+		return
+	}
+	keepAlive := time.NewTicker(changesKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case change, ok := <-client:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(change)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", change.Event, data)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		case <-s.ctx.Done():
+			return
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/marouni/adr/pkg/lint"
+	"github.com/urfave/cli"
+)
+
+// ruleFlag and disableFlag select the lint rules of adr check and adr lint
+var ruleFlag = cli.StringSliceFlag{
+	Name:  "rule",
+	Usage: "Only run this lint rule, can be repeated, one of " + strings.Join(lintRuleNames(), ", "),
+}
+var disableFlag = cli.StringSliceFlag{
+	Name:  "disable",
+	Usage: "Do not run this lint rule, can be repeated",
+}
+
+// lintRuleNames the names of the built-in lint rules
+func lintRuleNames() []string {
+	names := []string{}
+	for _, rule := range lint.Rules() {
+		names = append(names, rule.Name)
+	}
+	return names
+}
+
+// lintOptions what the lint rules know of repo besides its ADRs: the taxonomy of its tags, the numbering of its
+// categories, the sections its ADRs require and, when it has one, the table of contents adr toc would write
+func lintOptions(ctx context.Context, repo *adr.Repository) []lint.Option {
+	settings := repo.Settings()
+	options := []lint.Option{lint.WithTaxonomy(settings.Tags), lint.WithCategoryNumbering(settings)}
+	if settings.Lint != nil {
+		options = append(options, lint.WithRequiredSections(settings.Lint.RequiredSections))
+	}
+	if path := indexPath(repo); path != "" {
+		if toc, err := repo.RenderToc(ctx); err == nil {
+			options = append(options, lint.WithIndex(path, toc))
+		}
+	}
+	return options
+}
+
+// indexPath the table of contents of the ADRs of repo that must be kept up to date: the one of the toc configuration,
+// or one adr toc wrote, empty when there is none or when the ADRs of all the scopes are checked together
+func indexPath(repo *adr.Repository) string {
+	if repo.Scope == adr.AllScopes {
+		return ""
+	}
+	settings := repo.Settings()
+	path := filepath.Join(repo.Dir, settings.TocFile())
+	if _, err := repo.FS.Stat(path); err != nil && settings.Toc == nil {
+		return ""
+	}
+	return path
+}
+
+// writeCheckReport writes findings in a machine-readable format, json or sarif
+func writeCheckReport(w io.Writer, format string, findings []adr.Finding) error {
+	var report interface{}
+	switch format {
+	case "json":
+		report = adr.NewLintReportJSON(findings)
+	case "sarif":
+		report = sarifReport(findings)
+	default:
+		return errors.New("unknown report format " + format + ", expected json or sarif")
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// sarifReport converts findings to a SARIF 2.1.0 log, understood by most code scanning tools
+func sarifReport(findings []adr.Finding) map[string]interface{} {
+	rules := []map[string]interface{}{}
+	for _, rule := range lint.Rules() {
+		rules = append(rules, map[string]interface{}{
+			"id":               rule.Name,
+			"shortDescription": map[string]string{"text": rule.Description},
+		})
+	}
+
+	results := []map[string]interface{}{}
+	for _, finding := range findings {
+		location := map[string]interface{}{
+			"artifactLocation": map[string]string{"uri": filepath.ToSlash(finding.File)},
+		}
+		if finding.Line > 0 {
+			location["region"] = map[string]int{"startLine": finding.Line}
+		}
+		results = append(results, map[string]interface{}{
+			"ruleId":    finding.Rule,
+			"level":     string(finding.Severity),
+			"message":   map[string]string{"text": finding.Message},
+			"locations": []map[string]interface{}{{"physicalLocation": location}},
+		})
+	}
+
+	return map[string]interface{}{
+		"version": "2.1.0",
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"runs": []map[string]interface{}{{
+			"tool": map[string]interface{}{
+				"driver": map[string]interface{}{
+					"name":           "adr",
+					"informationUri": "https://github.com/marouni/adr",
+					"rules":          rules,
+				},
+			},
+			"results": results,
+		}},
+	}
+}
+
+// reportFindings prints findings, warnings as warnings, and returns the number of errors among them
+func reportFindings(out *reporter, findings []adr.Finding) int {
+	for _, finding := range findings {
+		if finding.IsWarning() {
+			out.Warning(finding.String())
+		} else {
+			out.Error(finding.String())
+		}
+	}
+	return adr.CountErrors(findings)
+}
+
+// filterFindings keeps the findings reported on one of the given files
+func filterFindings(findings []adr.Finding, files map[string]bool) []adr.Finding {
+	filtered := []adr.Finding{}
+	for _, finding := range findings {
+		if files[absPath(finding.File)] {
+			filtered = append(filtered, finding)
+		}
+	}
+	return filtered
+}
+
+// pathSet indexes paths by their cleaned absolute form, so differently spelled paths of a file match
+func pathSet(paths []string) map[string]bool {
+	set := map[string]bool{}
+	for _, path := range paths {
+		set[absPath(path)] = true
+	}
+	return set
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
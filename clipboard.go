@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// What adr new can copy to the clipboard
+const (
+	copyPath      = "path"
+	copyReference = "reference"
+)
+
+// clipboardCommands the commands writing their input to the clipboard, by operating system, the first one installed is used
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbcopy"}},
+	"windows": {{"clip"}},
+	"linux":   {{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}},
+}
+
+// copyToClipboard places text on the system clipboard
+func copyToClipboard(ctx context.Context, text string) error {
+	for _, command := range clipboardCommands[runtime.GOOS] {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			continue
+		}
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return errors.New("no clipboard command found, install one of pbcopy, clip, wl-copy, xclip or xsel")
+}
+
+// adrReference how an ADR is cited in pull requests and chats, e.g. "ADR-0042: Use Postgres"
+func adrReference(record adr.Record) string {
+	return "ADR-" + record.ID() + ": " + record.Title
+}
+
+// copyNewAdr copies the path or the reference of a new ADR, as selected by what, nothing when what is empty.
+// Failing to copy is only a warning, the ADR is written anyway.
+func copyNewAdr(ctx context.Context, out *reporter, record adr.Record, what string) {
+	text := record.Path
+	switch what {
+	case "":
+		return
+	case copyReference:
+		text = adrReference(record)
+	}
+	if err := copyToClipboard(ctx, text); err != nil {
+		out.Warning("Could not copy to the clipboard: " + err.Error())
+		return
+	}
+	out.Info("Copied " + text + " to the clipboard")
+}
@@ -1,22 +1,201 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/fatih/color"
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/marouni/adr/pkg/lint"
 	"github.com/urfave/cli"
 )
 
-func setCommands(app *cli.App) {
+// setCommands registers the sub-commands, they stop their work once ctx is cancelled
+func setCommands(ctx context.Context, app *cli.App, paths adrPaths, out *reporter) {
 	app.Commands = []cli.Command{
 		{
-			Name:    "new",
-			Aliases: []string{"c"},
-			Usage:   "Create a new ADR",
-			Flags:   []cli.Flag{},
+			Name:      "new",
+			Aliases:   []string{"c"},
+			Usage:     "Create a new ADR",
+			UsageText: "adr new [options] <title>\n   Without a title, adr asks for the title, status, tags and summary of the ADR",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit the new ADR to git, defaults to the auto_commit configuration",
+				},
+				cli.StringFlag{
+					Name:  "author",
+					Usage: "Author of the ADR, defaults to the author configuration then to the git user",
+				},
+				cli.BoolFlag{
+					Name:  "branch",
+					Usage: "Create and switch to a git branch named after the ADR, e.g. adr/0042-use-postgres",
+				},
+				cli.BoolFlag{
+					Name:  "draft",
+					Usage: "Create a draft ADR, numbered by 'adr finalize' once merged, defaults to the draft_on_branches configuration",
+				},
+				cli.StringFlag{
+					Name:  "template",
+					Usage: "Name of the template of the ADR, see 'adr template list'",
+				},
+				cli.StringFlag{
+					Name:  "copy",
+					Usage: "Copy the path or the reference (ADR-0042: Title) of the new ADR to the clipboard, defaults to the copy_on_new configuration",
+				},
+				cli.BoolFlag{
+					Name:  "fix-title",
+					Usage: "Apply the suggested fixes of the title: capitals, trailing punctuation and common misspellings",
+				},
+				cli.StringFlag{
+					Name:  "review-by",
+					Usage: "Date to review the decision by, YYYY-MM-DD or an interval such as 6m or 1y, defaults to the review_interval configuration",
+				},
+				cli.StringSliceFlag{
+					Name:  "approver",
+					Usage: "Approver of the decision, repeatable, defaults to the approvers of the approvals configuration",
+				},
+				cli.StringSliceFlag{
+					Name:  "tag",
+					Usage: "Tag of the ADR, repeatable, one of the tags of the configuration when it lists them",
+				},
+				cli.BoolFlag{
+					Name:  "allow-new-tag",
+					Usage: "Allow tags that are not in the tags of the configuration",
+				},
+				cli.StringFlag{
+					Name:  "category",
+					Usage: "Category of the ADR, the folder of the ADR directory it is written in, created when missing",
+				},
+			},
 			Action: func(c *cli.Context) error {
-				currentConfig := getConfig()
-				currentConfig.CurrentAdr++
-				updateConfig(currentConfig)
-				newAdr(currentConfig, c.Args())
+				repo := openRepository(ctx, paths, out)
+				if category := c.String("category"); category != "" {
+					if err := adr.ValidateCategory(category); err != nil {
+						return err
+					}
+					repo.Category = category
+				}
+				draft := draftByDefault(ctx, repo)
+				if c.IsSet("draft") {
+					draft = c.Bool("draft")
+				}
+				options := adr.CreateOptions{
+					Author:       adrAuthor(ctx, c, repo),
+					Draft:        draft,
+					Template:     c.String("template"),
+					ReviewBy:     c.String("review-by"),
+					Approvers:    c.StringSlice("approver"),
+					Tags:         c.StringSlice("tag"),
+					AllowNewTags: c.Bool("allow-new-tag"),
+				}
+				args := []string(c.Args())
+				if len(args) == 0 {
+					if !interactive() {
+						return errors.New("the title of the ADR is missing, e.g. adr new Use PostgreSQL for storage")
+					}
+					var err error
+					if options, err = askNewAdr(newPrompter(), options, repo.Settings().Tags); err != nil {
+						return err
+					}
+					args = []string{options.Title}
+				}
+				title, err := suggestTitle(c, out, strings.Join(args, " "))
+				if err != nil {
+					return err
+				}
+				if title != strings.Join(args, " ") {
+					args = []string{title}
+				}
+				clipboard := repo.Settings().CopyOnNew
+				if c.IsSet("copy") {
+					clipboard = c.String("copy")
+				}
+				if clipboard != "" && clipboard != copyPath && clipboard != copyReference {
+					return errors.New("invalid --copy value '" + clipboard + "', expected " + copyPath + " or " + copyReference)
+				}
+				record, err := createAdr(ctx, repo, out, "new", args, options)
+				if errors.Is(err, adr.ErrUnknownTag) {
+					out.Hint("'adr tags' lists the tags of the taxonomy, --allow-new-tag allows others")
+				}
+				if err != nil {
+					return err
+				}
+				copyNewAdr(ctx, out, record, clipboard)
+				if c.Bool("branch") {
+					branch := adrBranchName(record)
+					if err := createBranch(ctx, repo.Dir, branch); err != nil {
+						return err
+					}
+					out.Success("Switched to new branch " + branch)
+				}
+				if shouldCommit(c, repo) {
+					return commitAdr(ctx, repo, "add", record, withToc(repo, record.Path)...)
+				}
+				return nil
+			},
+		},
+
+		{
+			Name:        "from-issue",
+			Usage:       "Creates an ADR from a GitHub issue or discussion",
+			UsageText:   "adr from-issue [options] <owner/repo#123|URL>",
+			Description: "Creates an ADR titled like the issue, pull request or discussion, with its description quoted as the context and an Issue link back to it\n   Set GITHUB_TOKEN or GH_TOKEN to read private repositories and discussions, and GITHUB_API_URL for GitHub Enterprise",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit the new ADR to git, defaults to the auto_commit configuration",
+				},
+				cli.StringFlag{
+					Name:  "author",
+					Usage: "Author of the ADR, defaults to the author configuration then to the git user",
+				},
+				cli.BoolFlag{
+					Name:  "draft",
+					Usage: "Create a draft ADR, numbered by 'adr finalize' once merged, defaults to the draft_on_branches configuration",
+				},
+				cli.StringFlag{
+					Name:  "template",
+					Usage: "Name of the template of the ADR, see 'adr template list'",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if len(c.Args()) != 1 {
+					return errors.New("the issue is missing, e.g. adr from-issue octo-org/platform#42")
+				}
+				ref, err := parseGitHubRef(c.Args().First())
+				if err != nil {
+					return err
+				}
+				thread, err := newGitHubClient().Thread(ctx, ref)
+				if err != nil {
+					return err
+				}
+				repo := openRepository(ctx, paths, out)
+				draft := draftByDefault(ctx, repo)
+				if c.IsSet("draft") {
+					draft = c.Bool("draft")
+				}
+				record, err := createAdr(ctx, repo, out, "from-issue", []string{strings.TrimSpace(thread.Title)}, adr.CreateOptions{
+					Author:   adrAuthor(ctx, c, repo),
+					Draft:    draft,
+					Template: c.String("template"),
+				})
+				if err != nil {
+					return err
+				}
+				if record, err = scaffoldFromThread(ctx, repo, record, ref, thread); err != nil {
+					return err
+				}
+				if shouldCommit(c, repo) {
+					return commitAdr(ctx, repo, "add", record, withToc(repo, record.Path)...)
+				}
 				return nil
 			},
 		},
@@ -25,19 +204,1826 @@ func setCommands(app *cli.App) {
 			Name:        "init",
 			Aliases:     []string{"i"},
 			Usage:       "Initializes the ADR configurations",
-			UsageText:   "adr init /home/user/adrs",
-			Description: "Initializes the ADR configuration with an optional ADR base directory\n This is a a prerequisite to running any other adr sub-command",
+			UsageText:   "adr init [--portable] /home/user/adrs",
+			Description: "Initializes the ADR configuration with an optional ADR base directory\n The base directory defaults to <repo-root>/docs/adr inside a git repository and to ~/adr otherwise\n With --portable, the configuration is kept in the .adr folder of the repository, shared by every checkout, and adr run in the repository reads nothing from the home directory\n This is a a prerequisite to running any other adr sub-command",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "portable",
+					Usage: "Keep the configuration, templates and hooks in the .adr folder of the repository",
+				},
+				yesFlag,
+			},
 			Action: func(c *cli.Context) error {
+				paths := paths
+				if c.Bool("portable") && !paths.Portable {
+					var err error
+					if paths, err = portablePaths(ctx, paths); err != nil {
+						return err
+					}
+				}
 				initDir := c.Args().First()
 				if initDir == "" {
-					initDir = adrDefaultBaseFolder
+					initDir = defaultBaseDir(ctx, paths)
+				}
+				if _, err := os.Stat(paths.ConfigFile()); err == nil {
+					err := confirm(c, out, "Replace the ADR configuration, its numbering restarting at 1", []string{paths.ConfigFile(), paths.TemplateFile()})
+					if err != nil {
+						return err
+					}
+				}
+				out.Success("Initializing ADR base at " + initDir)
+				if _, err := os.Stat(initDir); err == nil {
+					out.Warning(initDir + " already exists, skipping folder creation")
+				}
+				op := startOperation(paths.ConfigDir, "init", c.Args())
+				defer op.done()
+				op.track(paths.ConfigFile())
+				op.track(paths.TemplateFile())
+				if !paths.Portable {
+					_, err := adr.Init(paths.ConfigDir, initDir)
+					return err
+				}
+				if _, err := adr.Init(paths.ConfigDir, paths.portableBaseDir(initDir)); err != nil {
+					return err
+				}
+				gitignore := filepath.Join(paths.ConfigDir, ".gitignore")
+				op.track(gitignore)
+				if err := os.WriteFile(gitignore, []byte(portableGitignore), 0644); err != nil {
+					return err
+				}
+				out.Info("The configuration is kept in " + paths.ConfigDir + ", commit it for every checkout to share it")
+				return nil
+			},
+		},
+
+		{
+			Name:        "list",
+			Aliases:     []string{"l"},
+			Usage:       "Lists the ADRs of the base directory",
+			UsageText:   "adr list [--status accepted] [--tag security] [--since 2024-01-01] [--until 2024-12-31] [--category name] [--include-archived] [--last-edit]",
+			Description: "Lists the ADRs of the base directory, including ADRs written with adr-tools, MADR or log4brains\n   The filters combine: an ADR is listed when it has one of the statuses, all the tags and a date in the range given",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "last-edit",
+					Usage: "Show the author and date of the last git commit of each ADR",
+				},
+				statusFilterFlag,
+				tagFilterFlag,
+				sinceFilterFlag,
+				untilFilterFlag,
+				categoryFilterFlag,
+				includeArchivedFlag,
+			},
+			Action: func(c *cli.Context) error {
+				q, err := parseQuery(c)
+				if err != nil {
+					return err
+				}
+				repo := openRepository(ctx, paths, out)
+				records, err := readScopedAdrs(ctx, repo)
+				if err != nil {
+					return err
+				}
+				if records, err = q.Filter(repo.FS, records); err != nil {
+					return err
+				}
+				if c.Bool("include-archived") {
+					if records, err = withArchived(ctx, repo, records, q); err != nil {
+						return err
+					}
+				}
+				if out.JSON {
+					return out.Document(adr.NewListingJSON(records))
+				}
+				printRecords(ctx, repo, out, records, c.Bool("last-edit"))
+				return nil
+			},
+		},
+
+		{
+			Name:        "query",
+			Aliases:     []string{"q"},
+			Usage:       "Lists the ADRs matching filters",
+			Description: "Lists the ADRs of the base directory matching every filter given, e.g. adr query --status accepted --tag security --since 2024-01-01",
+			Flags:       queryFlags,
+			Action: func(c *cli.Context) error {
+				q, err := parseQuery(c)
+				if err != nil {
+					return err
+				}
+				repo := openRepository(ctx, paths, out)
+				records, err := queryAdrs(ctx, repo, q)
+				if err != nil {
+					return err
+				}
+				if out.JSON {
+					return out.Document(adr.NewListingJSON(records))
+				}
+				printRecords(ctx, repo, out, records, false)
+				return nil
+			},
+		},
+
+		{
+			Name:        "search",
+			Usage:       "Searches the titles and contents of the ADRs",
+			UsageText:   "adr search [--status accepted] [--since 2024-01-01] <terms...>",
+			Description: "Lists the ADRs whose title or content holds every term, ignoring case, with the first line holding one highlighted\n   The ADRs with terms in their title come first, then those with the most matching lines; quote a term to search for a phrase",
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "status",
+					Usage: "Keep the ADRs with this status, can be repeated to keep any of several statuses",
+				},
+				cli.StringFlag{
+					Name:  "since",
+					Usage: "Keep the ADRs dated on or after this day, as YYYY-MM-DD",
+				},
+				includeArchivedFlag,
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() == 0 {
+					return cli.NewExitError("the terms to search for are missing, e.g. adr search kafka", 1)
+				}
+				q, err := newQuery(c.StringSlice("status"), nil, c.String("since"), "", "", "--")
+				if err != nil {
+					return err
+				}
+				return runSearch(ctx, openRepository(ctx, paths, out), out, q, c.Args(), c.Bool("include-archived"))
+			},
+		},
+
+		{
+			Name:  "hooks",
+			Usage: "Manages the git hooks checking ADRs",
+			Subcommands: []cli.Command{
+				{
+					Name:      "install",
+					Usage:     "Installs git hooks checking the ADRs before commits and pushes",
+					UsageText: "adr hooks install [--force] [pre-commit|pre-push...]",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "Replace existing hooks that were not installed by adr",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						repo := openRepository(ctx, paths, out)
+						hooks := []string(c.Args())
+						if len(hooks) == 0 {
+							hooks = supportedGitHooks
+						}
+						for _, hook := range hooks {
+							hookPath, err := installGitHook(ctx, repo.Dir, hook, c.Bool("force"))
+							if err != nil {
+								return err
+							}
+							out.Success("Installed " + hook + " hook at " + hookPath)
+						}
+						return nil
+					},
+				},
+				{
+					Name:      "run",
+					Usage:     "Runs the checks of a git hook, this is what installed hooks call",
+					UsageText: "adr hooks run pre-commit",
+					Action: func(c *cli.Context) error {
+						return runGitHook(ctx, openRepository(ctx, paths, out), out, c.Args().First())
+					},
+				},
+			},
+		},
+
+		{
+			Name:        "check",
+			Usage:       "Checks the ADRs numbering and links",
+			UsageText:   "adr check [--ci] [--format json|sarif]",
+			Description: "Checks every ADR of the base directory and exits with a non-zero status when a problem is found\n With --ci, a machine-readable report is written to the standard output",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "ci",
+					Usage: "Write a machine-readable report instead of colored messages",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Value: "json",
+					Usage: "Format of the --ci report, json or sarif",
+				},
+				ruleFlag,
+				disableFlag,
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				records, err := readScopedAdrs(ctx, repo)
+				if err != nil {
+					return err
+				}
+				rules, err := lint.Select(c.StringSlice("rule"), c.StringSlice("disable"))
+				if err != nil {
+					return err
+				}
+				findings := lint.Run(repo.FS, records, rules, lintOptions(ctx, repo)...)
+				failures := adr.CountErrors(findings)
+				if c.Bool("ci") {
+					if err := writeCheckReport(os.Stdout, c.String("format"), findings); err != nil {
+						return err
+					}
+				} else {
+					reportFindings(out, findings)
+				}
+				if failures > 0 {
+					return cli.NewExitError("adr check failed with "+pluralize(failures, "problem"), 1)
+				}
+				if !c.Bool("ci") {
+					out.Success("All " + pluralize(len(records), "ADR") + " passed the checks")
+				}
+				return nil
+			},
+		},
+
+		{
+			Name:        "show",
+			Usage:       "Shows an ADR, rendered for the terminal",
+			UsageText:   "adr show [--raw] [number]",
+			Description: "Shows an ADR with styled headings, code blocks and tables, --raw writes its markdown as is\n   Without a number, the ADR is picked on the terminal",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "raw",
+					Usage: "Write the markdown of the ADR as is",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				record, err := targetAdr(ctx, c, repo)
+				if err != nil {
+					return err
+				}
+				content, err := repo.FS.ReadFile(record.Path)
+				if err != nil {
+					return err
+				}
+				if out.JSON {
+					return out.Document(record.JSON())
+				}
+				if c.Bool("raw") {
+					_, err := out.Out.Write(content)
+					return err
+				}
+				fmt.Fprintln(consoleWriter(out.Out), markdownRenderer{out}.Render(strings.TrimSpace(string(content))))
+				return nil
+			},
+		},
+
+		{
+			Name:      "preview",
+			Usage:     "Renders an ADR to HTML, live in the browser with --serve",
+			UsageText: "adr preview [--serve] [--listen address] [number]",
+			Description: "Writes the ADR rendered to a standalone HTML page, or with --serve serves it and refreshes the browser\n" +
+				"   each time the file is saved, to draft with a live preview. Without a number, the ADR is picked on the terminal",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "serve",
+					Usage: "Serve the rendered ADR, refreshed as the file is edited",
+				},
+				cli.StringFlag{
+					Name:  "listen, l",
+					Usage: "Address the preview listens on with --serve",
+					Value: defaultPreviewAddress,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				record, err := targetAdr(ctx, c, repo)
+				if err != nil {
+					return err
+				}
+				preview := newPreviewServer(ctx, repo, record)
+				if !c.Bool("serve") {
+					return preview.writePage(out.Out, false)
+				}
+				go preview.watch()
+				return serve(ctx, out, c.String("listen"), "the preview of "+record.Path, preview.Handler(), false)
+			},
+		},
+
+		{
+			Name:        "prompt-info",
+			Usage:       "Prints a short summary of the ADRs for shell prompts, e.g. 3 proposed",
+			Description: "Prints the number of proposed ADRs, or with --all the number of ADRs of each status, when the current folder\n   is in the project of the ADRs. It prints nothing otherwise, and reads the index cache to be fast enough for every prompt.",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "all",
+					Usage: "Count the ADRs of every status",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				repo, err := adr.Open(paths.ConfigDir)
+				if err != nil || applyScope(ctx, repo, selectedScope) != nil || repo.Scope == adr.AllScopes {
+					return nil
+				}
+				if cwd, err := os.Getwd(); err != nil || !within(absPath(cwd), projectRoot(repo.Dir)) {
+					return nil
+				}
+				records, err := repo.CachedList(ctx)
+				if err != nil {
+					return err
+				}
+				if summary := promptSummary(records, c.Bool("all")); summary != "" {
+					fmt.Fprintln(out.Out, summary)
+				}
+				return nil
+			},
+		},
+
+		{
+			Name:        "board",
+			Usage:       "Shows the ADRs on a board with one column per status",
+			Description: "Shows the ADRs on a board with one column per status, moving an ADR to another column changes its status\n   Arrows or hjkl select an ADR, < and > or H and L move it to the previous or next status, q quits",
+			Action: func(c *cli.Context) error {
+				return runBoard(ctx, openRepository(ctx, paths, out))
+			},
+		},
+
+		{
+			Name:        "tui",
+			Usage:       "Browses and manages the ADRs in a terminal UI",
+			Description: "Lists the ADRs next to a preview of the selected one, searched as they are typed, and changes their status\n   Arrows or jk select an ADR, page up and down or b and space scroll its preview, / searches, p, a and d propose, accept and deprecate the selected ADR, r reads the ADRs again, q quits",
+			Action: func(c *cli.Context) error {
+				return runBrowser(ctx, openRepository(ctx, paths, out))
+			},
+		},
+
+		{
+			Name:        "status",
+			Usage:       "Changes the status of an ADR",
+			UsageText:   "adr status [--commit] <number> <status>",
+			Description: "Rewrites the status of an ADR in place, where its format keeps it, and reports the change\n   The status is one of Proposed, Accepted, Deprecated or Superseded, in any case; an ADR with approvers is only accepted once they approved it",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit the ADR to git, defaults to the auto_commit configuration",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 2 {
+					return cli.NewExitError("adr status takes the number of an ADR and its new status, e.g. adr status 3 accepted", 1)
+				}
+				repo := openRepository(ctx, paths, out)
+				record, err := findAdr(ctx, repo, c.Args().Get(0))
+				if err != nil {
+					return err
+				}
+				status, err := adr.ParseStatus(c.Args().Get(1))
+				if err != nil {
+					return err
+				}
+				if record.Status == status {
+					out.Info("ADR " + record.Ref() + " is already " + string(status))
+					return nil
+				}
+				updated, err := transitionAdr(ctx, repo, "status", record, status)
+				if err != nil {
+					return err
+				}
+				out.Success("ADR " + record.Ref() + " " + record.Title + ": " + string(record.Status) + " -> " + string(updated.Status))
+				if status == adr.Superseded {
+					out.Hint("'adr supersede " + record.Ref() + " <number>' also links it to the ADR replacing it")
+				}
+				if !shouldCommit(c, repo) {
+					return nil
+				}
+				return commitAdr(ctx, repo, "status", updated, withToc(repo, updated.Path)...)
+			},
+		},
+
+		{
+			Name:        "accept",
+			Usage:       "Accepts a proposed ADR",
+			UsageText:   "adr accept [--force] [--commit] <number>",
+			Description: "Changes the status of a proposed ADR to Accepted and stamps today's date in its Accepted on field\n   A deprecated or superseded ADR is only accepted again with --force; an ADR with approvers is only accepted once they approved it",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Accept a deprecated or superseded ADR again",
+				},
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit the ADR to git, defaults to the auto_commit configuration",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				record, err := findAdr(ctx, repo, c.Args().First())
+				if err != nil {
+					return err
+				}
+				if record.Status == adr.Accepted {
+					out.Info("ADR " + record.Ref() + " is already accepted")
+					return nil
+				}
+				if err := runHook(ctx, repo.ConfigDir, preStatusChangeHook, statusChangePayload(repo, record, adr.Accepted)); err != nil {
+					return err
+				}
+				op := startOperation(repo.ConfigDir, "accept", c.Args())
+				op.track(record.Path)
+				updated, err := repo.Accept(ctx, record.Number, c.Bool("force"))
+				if err == nil {
+					refreshToc(ctx, repo, out, updated, op)
+				}
+				op.done()
+				if errors.Is(err, adr.ErrNotInForce) {
+					out.Hint("'adr accept --force " + record.Ref() + "' accepts it again")
+				}
+				if err != nil {
+					return err
+				}
+				out.Success("ADR " + record.Ref() + " " + record.Title + " accepted on " + updated.AcceptedOn)
+				payload := hookPayload(repo, updated)
+				payload.PreviousStatus = record.Status
+				if err := runHook(ctx, repo.ConfigDir, postStatusChangeHook, payload); err != nil {
+					return err
+				}
+				if !shouldCommit(c, repo) {
+					return nil
+				}
+				return commitAdr(ctx, repo, "accept", updated, withToc(repo, updated.Path)...)
+			},
+		},
+
+		{
+			Name:        "archive",
+			Usage:       "Archives an ADR",
+			UsageText:   "adr archive [--commit] <number>",
+			Description: "Moves the ADR into the " + adr.ArchiveDir + " folder of its folder, keeping its number, and rewrites the links to and from it so that they still resolve\n   The archived ADRs are left out of the listings, 'adr list --include-archived' and 'adr search --include-archived' show them",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit the move and the ADRs whose links were updated to git, defaults to the auto_commit configuration",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				record, err := findAdr(ctx, repo, c.Args().First())
+				if err != nil {
+					return err
+				}
+				if record.Status == adr.Proposed || record.Status == adr.Accepted {
+					out.Warning("ADR " + record.Ref() + " is still " + string(record.Status) + ", consider deprecating or superseding it first")
+				}
+				op := startOperation(repo.ConfigDir, "archive", c.Args())
+				archived, err := archiveAdr(ctx, repo, record, op)
+				if err == nil {
+					refreshToc(ctx, repo, out, archived, op)
+				}
+				op.done()
+				if err != nil {
+					return err
+				}
+				out.Success("ADR " + record.Ref() + " " + record.Title + " archived: " + archived.Path)
+				if !shouldCommit(c, repo) {
+					return nil
+				}
+				return commitAdr(ctx, repo, "archive", archived, op.files()...)
+			},
+		},
+
+		{
+			Name:        "delete",
+			Usage:       "Deletes an ADR",
+			UsageText:   "adr delete [--yes] [--commit] <number>",
+			Description: "Removes the file of the ADR, archived or not, and its signature after confirmation\n   The links of other ADRs to it are left broken and reported, prefer 'adr archive' to keep the record of the decision",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit the removal to git, defaults to the auto_commit configuration",
+				},
+				yesFlag,
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				record, err := findAdr(ctx, repo, c.Args().First())
+				if errors.Is(err, adr.ErrAdrNotFound) {
+					if archived, archivedErr := findArchivedAdr(ctx, repo, c.Args().First()); archivedErr == nil {
+						record, err = archived, nil
+					}
+				}
+				if err != nil {
+					return err
+				}
+				linking, err := linkingAdrs(ctx, repo, record)
+				if err != nil {
+					return err
+				}
+				for _, from := range linking {
+					out.Warning("ADR " + from.Ref() + " " + from.Title + " links to it, the link will be broken")
+				}
+				files := []string{record.Path}
+				if _, err := repo.FS.Stat(record.SignaturePath()); err == nil {
+					files = append(files, record.SignaturePath())
+				}
+				if err := confirm(c, out, "Delete ADR "+record.Ref()+" "+record.Title, files); err != nil {
+					return err
+				}
+				op := startOperation(repo.ConfigDir, "delete", c.Args())
+				removed, err := deleteAdr(repo, record, op)
+				if err == nil {
+					refreshToc(ctx, repo, out, record, op)
+				}
+				op.done()
+				if err != nil {
+					return err
+				}
+				out.Success("ADR " + record.Ref() + " " + record.Title + " deleted")
+				if !shouldCommit(c, repo) {
+					return nil
+				}
+				return commitAdr(ctx, repo, "delete", record, withToc(repo, removed...)...)
+			},
+		},
+
+		{
+			Name:        "renumber",
+			Usage:       "Numbers the ADRs without gaps nor duplicates",
+			UsageText:   "adr renumber [--dry-run] [--yes] [--commit]",
+			Description: "Numbers the ADRs from 1 in the order of their numbers, then of their dates for the duplicates, each category on its own with per-category numbering\n   The files of the renumbered ADRs are renamed, their headings renumbered and the links of every ADR to them, archived ones included, updated",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Print the new numbers without changing anything",
+				},
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit the renamed ADRs and the ADRs whose links were updated to git, defaults to the auto_commit configuration",
+				},
+				yesFlag,
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				return runRenumber(ctx, c, repo, out)
+			},
+		},
+
+		{
+			Name:        "toc",
+			Usage:       "Writes the table of contents of the ADRs",
+			UsageText:   "adr toc [--file index.md|README.md] [--force] [--commit]",
+			Description: "Writes a table of the ADRs, with their numbers, titles, dates and statuses linking to them, into the index.md file of the ADR directory\n   The categories and the archived ADRs get their own tables. The toc configuration names another file and regenerates it on every adr new:\n   \"toc\": {\"file\": \"README.md\", \"on_new\": true}",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file",
+					Usage: "Name of the table of contents, index.md or README.md, defaults to the toc configuration",
+				},
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Overwrite a table of contents that adr toc did not write",
+				},
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit the table of contents to git, defaults to the auto_commit configuration",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				op := startOperation(repo.ConfigDir, "toc", c.Args())
+				name := c.String("file")
+				if name == "" {
+					name = repo.Settings().TocFile()
+				}
+				op.track(filepath.Join(repo.Dir, name))
+				path, err := repo.WriteToc(ctx, name, c.Bool("force"))
+				op.done()
+				if errors.Is(err, adr.ErrNotGenerated) {
+					out.Hint("'adr toc --force' overwrites it")
+				}
+				if err != nil {
+					return err
+				}
+				out.Success("Table of contents written to " + path)
+				if !shouldCommit(c, repo) {
+					return nil
+				}
+				return gitCommit(ctx, repo.Dir, []string{path}, "docs(adr): update the table of contents")
+			},
+		},
+
+		{
+			Name:        "diff",
+			Usage:       "Compares two ADRs, or two revisions of an ADR, section by section",
+			UsageText:   "adr diff <number> <other number>\n   adr diff <number>@<revision> <number>[@<revision>]\n   adr diff <number>",
+			Description: "Shows how the sections of an ADR changed from the first ADR given to the second, e.g. how a superseding decision changed the Context, Decision and Consequences\n   A number followed by @ and a git revision reads the ADR at that revision, e.g. 3@HEAD~2; a single number compares its last commit to the ADR as it is",
+			Action: func(c *cli.Context) error {
+				args := c.Args()
+				switch c.NArg() {
+				case 1:
+					args = cli.Args{args.First() + "@HEAD", args.First()}
+				case 2:
+				default:
+					return cli.NewExitError("adr diff takes the two ADRs to compare, e.g. adr diff 1 3 or adr diff 3@HEAD~1 3", 1)
+				}
+				repo := openRepository(ctx, paths, out)
+				before, err := readComparedAdr(ctx, repo, args.Get(0))
+				if err != nil {
+					return err
+				}
+				after, err := readComparedAdr(ctx, repo, args.Get(1))
+				if err != nil {
+					return err
+				}
+				changes := adr.CompareSections(before.Content, after.Content)
+				if out.JSON {
+					return out.Document(adr.NewDiffJSON(before.Record, before.Revision, after.Record, after.Revision, changes))
+				}
+				printSectionDiff(out, before, after, changes)
+				return nil
+			},
+		},
+
+		{
+			Name:        "history",
+			Usage:       "Shows the git history of an ADR",
+			UsageText:   "adr history [number]",
+			Description: "Lists the commits that touched an ADR file, newest first, with the status transitions they made\n   Without a number, the ADR is picked on the terminal, with fzf when it is installed",
+			Action: func(c *cli.Context) error {
+				record, err := targetAdr(ctx, c, openRepository(ctx, paths, out))
+				if errors.Is(err, adr.ErrAdrNotFound) {
+					out.Hint("'adr list' shows the numbers of the ADRs")
+				}
+				if err != nil {
+					return err
+				}
+				revisions, err := adrHistory(ctx, record)
+				if err != nil {
+					return err
+				}
+				if len(revisions) == 0 {
+					out.Warning(record.Path + " has not been committed yet")
+				}
+				for _, revision := range revisions {
+					line := fmt.Sprintf("%s %s %s  %s", out.Highlight(color.FgYellow, "%s", revision.Hash[:7]), revision.Date, revision.Author, revision.Subject)
+					if revision.StatusChanged() {
+						line += out.Highlight(color.FgCyan, "  [%s -> %s]", revision.FromStatus, revision.Status)
+					}
+					out.Info(line)
 				}
-				color.Green("Initializing ADR base at " + initDir)
-				initBaseDir(initDir)
-				initConfig(initDir)
-				initTemplate()
 				return nil
 			},
 		},
+
+		{
+			Name:        "stats",
+			Usage:       "Reports figures about the ADRs",
+			UsageText:   "adr stats [--output text|json] [--category name] [--include-archived]",
+			Description: "Counts the ADRs by status and the ADRs created per month, averages the time from proposed to accepted and ranks the most active authors\n   The time to accept an ADR goes from its date to its Accepted on date, or to the commit that accepted it; authors are counted in commits when git knows the ADRs, in ADRs otherwise",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "output, o",
+					Value: "text",
+					Usage: "Format of the report: text, or json for dashboards",
+				},
+				categoryFilterFlag,
+				includeArchivedFlag,
+			},
+			Action: func(c *cli.Context) error {
+				format := c.String("output")
+				if format != "text" && format != "json" {
+					return cli.NewExitError("adr stats writes text or json, not "+format, 1)
+				}
+				repo := openRepository(ctx, paths, out)
+				records, err := readScopedAdrs(ctx, repo)
+				if err != nil {
+					return err
+				}
+				q := adr.Query{Categories: c.StringSlice("category")}
+				if records, err = q.Filter(repo.FS, records); err != nil {
+					return err
+				}
+				if c.Bool("include-archived") {
+					if records, err = withArchived(ctx, repo, records, q); err != nil {
+						return err
+					}
+				}
+				stats := computeStats(ctx, repo, records)
+				if format == "json" || out.JSON {
+					return out.Document(adr.NewStatsJSON(stats))
+				}
+				printStats(out, stats)
+				return nil
+			},
+		},
+
+		{
+			Name:      "supersede",
+			Usage:     "Marks an ADR as superseded by another one, linking both ways",
+			UsageText: "adr supersede [options] <number> <replacing number>\n   adr supersede [options] <number> <title of a new replacing ADR>\n   adr supersede --interactive [number] [replacing number or title]",
+			Description: "Marks an ADR as superseded by another ADR, which links back to it, creating the replacing ADR when given a title\n" +
+				"   With --interactive, or when numbers are missing on a terminal, adr picks the ADR to replace, asks for the replacing ADR,\n" +
+				"   existing or new, the wording of the relationship and the sections to copy forward, then previews both files before writing them",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "interactive, i",
+					Usage: "Guide through the supersede on the terminal, with a preview of the changes",
+				},
+				cli.StringFlag{
+					Name:  "wording",
+					Usage: "Wording of the relationship in the replaced ADR, e.g. 'Superseded in part by'",
+					Value: adr.DefaultSupersededBy,
+				},
+				cli.StringFlag{
+					Name:  "reverse-wording",
+					Usage: "Wording of the relationship in the replacing ADR",
+					Value: adr.DefaultSupersedes,
+				},
+				cli.StringSliceFlag{
+					Name:  "copy-section",
+					Usage: "Copy a section of the replaced ADR forward into the replacing one, e.g. Context, can be repeated",
+				},
+				cli.StringFlag{
+					Name:  "template",
+					Usage: "Name of the template of a new replacing ADR, see 'adr template list'",
+				},
+				cli.StringFlag{
+					Name:  "author",
+					Usage: "Author of a new replacing ADR, defaults to the author configuration then to the git user",
+				},
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit both ADRs to git, defaults to the auto_commit configuration",
+				},
+				yesFlag,
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				if c.Bool("interactive") || (c.NArg() < 2 && interactive()) {
+					if !interactive() {
+						return errors.New("--interactive needs a terminal")
+					}
+					return guidedSupersede(ctx, c, repo, out)
+				}
+				if c.NArg() < 2 {
+					return errors.New("the replaced and the replacing ADR are missing, e.g. adr supersede 3 7 or adr supersede 3 \"Use Kafka\"")
+				}
+				old, err := targetAdr(ctx, c, repo)
+				if err != nil {
+					return err
+				}
+				if err := runHook(ctx, repo.ConfigDir, preStatusChangeHook, statusChangePayload(repo, old, adr.Superseded)); err != nil {
+					return err
+				}
+				by, created, err := replacingAdr(ctx, c, repo, out, old)
+				if err != nil {
+					return err
+				}
+				return finishSupersede(ctx, c, repo, out, old, by, created, supersedeOptions(c))
+			},
+		},
+
+		{
+			Name:        "link",
+			Usage:       "Relates two ADRs, linking both ways",
+			UsageText:   "adr link [--type relates-to|amends|clarifies] [--commit] <number> <related number>",
+			Description: "Writes a link of the type of the relation under the status of the first ADR, e.g. Amends [3. ...](...),\n   and the reverse link under the status of the second one, e.g. Amended by [7. ...](...)\n   The links are read back as the links of the ADRs, by adr lint, the JSON outputs and the graph of adr serve",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "type, t",
+					Usage: "Type of the relation: relates-to, amends or clarifies",
+					Value: adr.Relations[0].Name,
+				},
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit both ADRs to git, defaults to the auto_commit configuration",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 2 {
+					return cli.NewExitError("adr link takes the numbers of the two ADRs to relate, e.g. adr link --type amends 7 3", 1)
+				}
+				relation, err := adr.ParseRelation(c.String("type"))
+				if err != nil {
+					return err
+				}
+				repo := openRepository(ctx, paths, out)
+				from, err := findAdr(ctx, repo, c.Args().Get(0))
+				if err != nil {
+					return err
+				}
+				repo.Category = ""
+				to, err := findAdr(ctx, repo, c.Args().Get(1))
+				if err != nil {
+					return err
+				}
+				op := startOperation(repo.ConfigDir, "link", []string{from.Ref(), relation.Name, to.Ref()})
+				op.track(from.Path)
+				op.track(to.Path)
+				from, to, err = repo.Relate(ctx, from, to, relation)
+				if err == nil {
+					refreshToc(ctx, repo, out, from, op)
+				}
+				op.done()
+				if err != nil {
+					return err
+				}
+				out.Success("ADR " + from.Ref() + " " + strings.ToLower(relation.Forward) + " ADR " + to.Ref())
+				if !shouldCommit(c, repo) {
+					return nil
+				}
+				return commitAdr(ctx, repo, "link", from, withToc(repo, from.Path, to.Path)...)
+			},
+		},
+
+		{
+			Name:        "graph",
+			Usage:       "Writes the graph of the relations between the ADRs",
+			UsageText:   "adr graph [--format dot|svg] [--output file] [--category name] [--include-archived]",
+			Description: "Writes the ADRs and the supersedes, amends, clarifies and relates-to relations between them as a Graphviz DOT graph,\n   a box per ADR filled after its status, an arrow per relation, e.g. from the superseding ADR to the superseded one\n   --format svg renders the graph with the dot program of Graphviz, which must be installed; the format defaults to the extension of --output",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "Graph format, one of " + strings.Join(graphFormats, ", "),
+				},
+				cli.StringFlag{
+					Name:  "output",
+					Usage: "File to write the graph to, defaults to the standard output",
+				},
+				categoryFilterFlag,
+				includeArchivedFlag,
+			},
+			Action: func(c *cli.Context) error {
+				format, err := graphFormat(c.String("format"), c.String("output"))
+				if err != nil {
+					return err
+				}
+				repo := openRepository(ctx, paths, out)
+				records, err := readScopedAdrs(ctx, repo)
+				if err != nil {
+					return err
+				}
+				q := adr.Query{Categories: c.StringSlice("category")}
+				if records, err = q.Filter(repo.FS, records); err != nil {
+					return err
+				}
+				if c.Bool("include-archived") {
+					if records, err = withArchived(ctx, repo, records, q); err != nil {
+						return err
+					}
+				}
+				var graph bytes.Buffer
+				if err := adr.Export("dot", &graph, records); err != nil {
+					return err
+				}
+				written := graph.Bytes()
+				if format == "svg" {
+					if written, err = renderSVG(ctx, written); err != nil {
+						return err
+					}
+				}
+				if c.String("output") == "" {
+					_, err := out.Out.Write(written)
+					return err
+				}
+				if err := os.WriteFile(c.String("output"), written, 0644); err != nil {
+					return err
+				}
+				out.Success("Graph of " + pluralize(len(records), "ADR") + " written to " + c.String("output"))
+				return nil
+			},
+		},
+
+		{
+			Name:  "serve",
+			Usage: "Serves a REST API over the ADRs",
+			Description: "Serves a JSON API to list, read, create, change the status of and search ADRs, for editors, bots and dashboards:\n" +
+				"   GET /api/adrs (status, tag, since, until and text parameters), POST /api/adrs, GET /api/adrs/{number},\n" +
+				"   PUT /api/adrs/{number}/status, GET /api/search?q=text and GET /api/calendar.ics, and Prometheus metrics at GET /metrics",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "listen, l",
+					Usage: "Address to listen on, e.g. :8080 to accept connections from other machines",
+					Value: defaultListenAddress,
+				},
+				cli.StringSliceFlag{
+					Name:  "root",
+					Usage: "Also serve, read-only, the ADRs of another repository, as name=directory, can be repeated",
+				},
+				cli.BoolFlag{
+					Name:  "open",
+					Usage: "Open the web UI in the default browser",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				stopWebhooks := sendWebhooks(ctx, repo)
+				defer stopWebhooks()
+				credentials, err := serveCredentials(repo.Settings().Credentials)
+				if err != nil {
+					return err
+				}
+				if len(credentials) == 0 && exposed(c.String("listen")) {
+					out.Warning("Anyone reaching " + c.String("listen") + " can read and change the ADRs, add serve_credentials to the configuration to require authentication")
+				}
+				registry, err := newRegistry(ctx, repo, repo.Settings().Roots, c.StringSlice("root"))
+				if err != nil {
+					return err
+				}
+				metrics, stopMetrics := newServeMetrics(ctx, registry)
+				defer stopMetrics()
+				changes := newChangeStream(ctx, registry)
+				go changes.watch()
+				server := &apiServer{repo: repo, registry: registry, out: out, credentials: credentials, metrics: metrics, changes: changes}
+				return serve(ctx, out, c.String("listen"), "the ADRs", server.Handler(), c.Bool("open"))
+			},
+		},
+
+		{
+			Name:        "propose",
+			Usage:       "Creates a new ADR on its own branch and opens a pull request for it",
+			UsageText:   "adr propose [--remote origin] my awesome proposition",
+			Description: "Creates a new ADR on a branch named after it, commits and pushes it, then opens a GitHub pull request with gh\n or a GitLab merge request with glab, using the ADR as description",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "author",
+					Usage: "Author of the ADR, defaults to the author configuration then to the git user",
+				},
+				cli.StringFlag{
+					Name:  "remote",
+					Value: "origin",
+					Usage: "Git remote to push the branch to",
+				},
+				cli.StringFlag{
+					Name:  "template",
+					Usage: "Name of the template of the ADR, see 'adr template list'",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				record, err := createAdr(ctx, repo, out, "propose", c.Args(), adr.CreateOptions{
+					Author:   adrAuthor(ctx, c, repo),
+					Draft:    draftByDefault(ctx, repo),
+					Template: c.String("template"),
+				})
+				if err != nil {
+					return err
+				}
+				branch := adrBranchName(record)
+				if err := createBranch(ctx, repo.Dir, branch); err != nil {
+					return err
+				}
+				if err := commitAdr(ctx, repo, "add", record, withToc(repo, record.Path)...); err != nil {
+					return err
+				}
+				return proposeAdr(ctx, repo, record, c.String("remote"), branch)
+			},
+		},
+
+		{
+			Name:        "lint",
+			Usage:       "Validates the ADRs",
+			UsageText:   "adr lint [--changed origin/main...HEAD]",
+			Description: "Validates the ADRs of the base directory and prints the problems found with their location\n With --changed, only the ADRs added or modified in the git diff range are reported",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "changed",
+					Usage: "Only lint the ADRs changed in this git diff range, e.g. origin/main...HEAD",
+				},
+				ruleFlag,
+				disableFlag,
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				records, err := readScopedAdrs(ctx, repo)
+				if err != nil {
+					return err
+				}
+				rules, err := lint.Select(c.StringSlice("rule"), c.StringSlice("disable"))
+				if err != nil {
+					return err
+				}
+				findings := lint.Run(repo.FS, records, rules, lintOptions(ctx, repo)...)
+				linted := len(records)
+				if diffRange := c.String("changed"); diffRange != "" {
+					files, err := changedFiles(ctx, repo.Dir, diffRange)
+					if err != nil {
+						return err
+					}
+					changed := pathSet(files)
+					findings = filterFindings(findings, changed)
+					linted = 0
+					for _, record := range records {
+						if changed[absPath(record.Path)] {
+							linted++
+						}
+					}
+				}
+				if out.JSON {
+					if err := out.Document(adr.NewLintReportJSON(findings)); err != nil {
+						return err
+					}
+					if adr.CountErrors(findings) > 0 {
+						return cli.NewExitError("", 1)
+					}
+					return nil
+				}
+				if failures := reportFindings(out, findings); failures > 0 {
+					return cli.NewExitError("adr lint found "+pluralize(failures, "problem"), 1)
+				}
+				out.Success(pluralize(linted, "ADR") + " linted, no problem found")
+				return nil
+			},
+		},
+
+		{
+			Name:        "tags",
+			Usage:       "Lists the tags of the ADRs",
+			Description: "Lists the tags of the taxonomy of the configuration, refined tags under their parent, with their description and the number of ADRs using them,\n   then the tags used outside of the taxonomy, which adr new refuses without --allow-new-tag and the unknown-tag rule of adr lint reports",
+			Action: func(c *cli.Context) error {
+				return runTags(ctx, openRepository(ctx, paths, out), out)
+			},
+		},
+
+		{
+			Name:        "approve",
+			Usage:       "Approves a proposed ADR",
+			UsageText:   "adr approve [--as <name>] [--commit] <number>",
+			Description: "Logs the approval of a proposed ADR by one of its approvers in its Approvals section\n   The approvers of an ADR are named by its Approvers field, set by 'adr new --approver' or the approvals configuration;\n   an ADR with approvers is only accepted once the quorum of the configuration, all of them by default, approved it",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "as",
+					Usage: "Name of the approver, defaults to the author configuration then to the git user",
+				},
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit the approval to git, defaults to the auto_commit configuration",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				target, err := findAdr(ctx, repo, c.Args().First())
+				if err != nil {
+					return err
+				}
+				approver := c.String("as")
+				if approver == "" {
+					approver = adrAuthor(ctx, c, repo)
+				}
+				if approver == "" {
+					return cli.NewExitError("the approver is missing, use --as <name>", 1)
+				}
+				record, err := approveAdr(ctx, repo, out, target.Number, approver)
+				if err != nil || !shouldCommit(c, repo) {
+					return err
+				}
+				return commitAdr(ctx, repo, "approve", record, record.Path)
+			},
+		},
+
+		{
+			Name:        "sign",
+			Usage:       "Signs an ADR with GPG",
+			UsageText:   "adr sign [--key <id>] [--commit] <number>",
+			Description: "Writes the detached, ASCII-armored, GPG signature of an ADR next to it, e.g. 0003-use-kubernetes.md.asc\n   The key defaults to the key of the signing configuration, then to the default key of gpg; check the signatures with 'adr verify'",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "key",
+					Usage: "Key id, fingerprint or email of the signing key",
+				},
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit the signature to git, defaults to the auto_commit configuration",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				record, err := findAdr(ctx, repo, c.Args().First())
+				if err != nil {
+					return err
+				}
+				path, err := signAdr(ctx, repo, record, c.String("key"))
+				if err != nil {
+					return err
+				}
+				out.Success("ADR " + record.Ref() + " signed in " + path)
+				if !shouldCommit(c, repo) {
+					return nil
+				}
+				return commitAdr(ctx, repo, "sign", record, path)
+			},
+		},
+
+		{
+			Name:        "verify",
+			Usage:       "Verifies the GPG signatures of ADRs",
+			UsageText:   "adr verify [numbers...]",
+			Description: "Checks that the given ADRs, or all the signed ones, did not change since they were signed with 'adr sign',\n   and that their signers are among the signers of the signing configuration when it names some; fails otherwise",
+			Action: func(c *cli.Context) error {
+				return runVerify(ctx, openRepository(ctx, paths, out), out, c.Args())
+			},
+		},
+
+		{
+			Name:        "review",
+			Usage:       "Lists the decisions due for review",
+			UsageText:   "adr review [--within 30d]",
+			Description: "Lists the decisions in force whose review-by date has passed, then those to review soon, soonest first\n   ADRs get a review-by date from 'adr new --review-by' or the review_interval configuration, e.g. 1y; the review-overdue rule of adr lint reports the overdue ones",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "within",
+					Usage: "How far ahead to list the upcoming reviews, e.g. 2w or 3m",
+					Value: defaultReviewWindow,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return runReview(ctx, openRepository(ctx, paths, out), out, c.String("within"))
+			},
+		},
+
+		{
+			Name:        "sync",
+			Usage:       "Copies ADRs to the ADR directory of another repository",
+			UsageText:   "adr sync [--force] /path/to/architecture-repo/docs/adr [numbers...]",
+			Description: "Copies the given ADRs, or all of them, to another ADR directory, typically a checkout of a central architecture repository\n Copies are numbered after the ADRs already there and their origin is tracked in " + syncManifestFileName + ", so running sync again\n updates the ADRs that changed and reports the ones that were edited on both sides as diverged",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Overwrite diverged copies with the origin ADR, after confirmation",
+				},
+				yesFlag,
+			},
+			Action: func(c *cli.Context) (err error) {
+				repo := openRepository(ctx, paths, out)
+				defer func() { notifyOutcome(ctx, repo, "sync", err) }()
+				targetDir := c.Args().First()
+				if targetDir == "" {
+					return cli.NewExitError("the target ADR directory is missing, check 'adr sync --help'", 1)
+				}
+				records, err := repo.List(ctx)
+				if err != nil {
+					return err
+				}
+				records, err = selectAdrs(records, c.Args().Tail())
+				if err != nil {
+					return err
+				}
+				if c.Bool("force") {
+					if err := confirm(c, out, "Overwrite the copies edited in "+targetDir+" since the last sync", []string{filepath.Join(targetDir, "*.md")}); err != nil {
+						return err
+					}
+				}
+				op := startOperation(repo.ConfigDir, "sync", c.Args())
+				results, err := syncAdrs(ctx, repo, records, targetDir, c.Bool("force"), op)
+				op.done()
+				for _, result := range results {
+					message := fmt.Sprintf("%d. %s %s %s", result.Record.Number, result.Record.Title, result.Outcome, result.Target)
+					switch result.Outcome {
+					case syncDiverged:
+						out.Error(message + ", use --force to overwrite it")
+					case syncUpToDate:
+						out.Info(message)
+					default:
+						out.Success(message)
+					}
+				}
+				return err
+			},
+		},
+
+		{
+			Name:        "publish",
+			Usage:       "Publishes the ADRs to a documentation tool",
+			UsageText:   "adr publish --target confluence|github-discussions|notion [--force] [numbers...]",
+			Description: "Writes the given ADRs, or all of them, to a documentation tool, one page each, drafts excepted; github-discussions only mirrors the accepted ADRs\n The pages are remembered in " + publishManifestFileName + " of the ADR directory, so publishing again updates them\n rather than creating new ones; commit it to share the pages with your team",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "target, t",
+					Usage: "Where to publish the ADRs: " + strings.Join(publishTargetNames(), ", "),
+				},
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Publish the ADRs again even when they did not change since they were last published",
+				},
+			},
+			Action: func(c *cli.Context) (err error) {
+				repo := openRepository(ctx, paths, out)
+				defer func() { notifyOutcome(ctx, repo, "publish", err) }()
+				if c.String("target") == "" {
+					return cli.NewExitError("the target is missing, use --target "+strings.Join(publishTargetNames(), "|"), 1)
+				}
+				records, err := repo.List(ctx)
+				if err != nil {
+					return err
+				}
+				published := []adr.Record{}
+				for _, record := range records {
+					if record.Draft == "" {
+						published = append(published, record)
+					}
+				}
+				if published, err = selectAdrs(published, c.Args()); err != nil {
+					return err
+				}
+				op := startOperation(repo.ConfigDir, "publish", append([]string{c.String("target")}, c.Args()...))
+				results, err := publishAdrs(ctx, repo, c.String("target"), published, c.Bool("force"), op)
+				op.done()
+				for _, result := range results {
+					message := fmt.Sprintf("%d. %s %s %s", result.Record.Number, result.Record.Title, result.Outcome, result.URL)
+					if result.Outcome == publishUpToDate {
+						out.Info(message)
+					} else {
+						out.Success(message)
+					}
+				}
+				return err
+			},
+		},
+
+		{
+			Name:        "digest",
+			Usage:       "Emails a digest of the decisions made during a period",
+			UsageText:   "adr digest [--since 7d] [--smtp host:port] [--from address] [--to address...] [--dry-run]",
+			Description: "Sends an HTML email listing the ADRs created, accepted, and deprecated or superseded during the period, for the stakeholders who follow decisions by email\n   The git history of the ADR files tells when their status changed; the flags override the \"digest\" object of the configuration",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "since",
+					Value: "7d",
+					Usage: "Start of the period: a number of days or weeks such as 7d or 2w, a duration such as 36h, or a date written YYYY-MM-DD",
+				},
+				cli.StringFlag{
+					Name:  "smtp",
+					Usage: "host:port of the SMTP server, port 465 meaning TLS from the start",
+				},
+				cli.StringFlag{
+					Name:  "from",
+					Usage: "Sender of the email",
+				},
+				cli.StringSliceFlag{
+					Name:  "to",
+					Usage: "Recipient of the email, repeat it for several recipients",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Print the email rather than sending it",
+				},
+			},
+			Action: func(c *cli.Context) (err error) {
+				repo := openRepository(ctx, paths, out)
+				defer func() { notifyOutcome(ctx, repo, "digest", err) }()
+				config := adr.DigestConfig{}
+				if settings := repo.Settings().Digest; settings != nil {
+					config = *settings
+				}
+				if c.String("smtp") != "" {
+					config.SMTP = c.String("smtp")
+				}
+				if c.String("from") != "" {
+					config.From = c.String("from")
+				}
+				if len(c.StringSlice("to")) > 0 {
+					config.To = c.StringSlice("to")
+				}
+				return sendDigest(ctx, repo, out, config, c.String("since"), c.Bool("dry-run"))
+			},
+		},
+
+		{
+			Name:        "import",
+			Usage:       "Imports the ADRs of another tool",
+			UsageText:   "adr import [--format <format>] [--keep-numbers] [--rewrite] [--template name] [--commit] <path>",
+			Description: "Imports the ADRs of adr-tools, MADR, log4brains, a JSON export such as adr-manager's, or a folder of markdown files, rewritten in adr's format\n   The format is detected from the path, a project or its ADR folder, such as the folder named by the .adr-dir of adr-tools, or a JSON file; --format forces it: " + strings.Join(adr.Importers(), ", ") + "\n   They are numbered after the existing ADRs, in their order, unless --keep-numbers keeps their numbers, the log4brains drafts staying drafts, and their links follow them; the imported files are left as they are\n   --rewrite, or --template, rewrites them after the template of the configuration, their sections filling its sections",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "from",
+					Usage: "The project, ADR folder or JSON file to import, when not given as the argument",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "The format of the ADRs to import, detected by default",
+				},
+				cli.BoolFlag{
+					Name:  "keep-numbers",
+					Usage: "Keep the numbers of the imported ADRs, the counter following them, instead of numbering them after the existing ADRs",
+				},
+				cli.BoolFlag{
+					Name:  "rewrite",
+					Usage: "Rewrite the imported ADRs after the default template",
+				},
+				cli.StringFlag{
+					Name:  "template",
+					Usage: "Rewrite the imported ADRs after the template of this name",
+				},
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit the imported ADRs together, defaults to the auto_commit configuration",
+				},
+			},
+			Action: func(c *cli.Context) (err error) {
+				source := c.String("from")
+				if source == "" {
+					source = c.Args().First()
+				}
+				if source == "" || c.NArg() > 1 || (c.NArg() == 1 && c.String("from") != "") {
+					return cli.NewExitError("adr import takes the project, ADR folder or JSON file to import, e.g. adr import ../my-project", 1)
+				}
+				repo := openRepository(ctx, paths, out)
+				defer func() { notifyOutcome(ctx, repo, "import", err) }()
+				return runImport(ctx, repo, out, source, importOptions{
+					Format:      c.String("format"),
+					KeepNumbers: c.Bool("keep-numbers"),
+					Rewrite:     c.Bool("rewrite") || c.String("template") != "",
+					Template:    c.String("template"),
+					Commit:      shouldCommit(c, repo),
+				})
+			},
+		},
+
+		{
+			Name:        "finalize",
+			Usage:       "Numbers the draft ADRs",
+			Description: "Gives the draft ADRs their final sequential number, oldest first, renaming their files and updating the links to them\n Run it once drafts are merged into the main branch, the post-merge git hook installed by 'adr hooks install' does it for you",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "commit",
+					Usage: "Commit the numbered ADRs and the ADRs linking to them together, defaults to the auto_commit configuration",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				repo := openRepository(ctx, paths, out)
+				return runFinalize(ctx, repo, out, "finalize", shouldCommit(c, repo))
+			},
+		},
+
+		{
+			Name:  "config",
+			Usage: "Reads and changes the configuration",
+			Subcommands: []cli.Command{
+				{
+					Name:        "list",
+					Usage:       "Lists the settings of the configuration",
+					UsageText:   "adr config list [--all]",
+					Description: "Prints each setting that is set as name = value, the settings of objects named by the path of their keys, e.g. toc.on_new",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "all",
+							Usage: "List the settings that are not set too",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						return listSettings(openRepository(ctx, paths, out), out, c.Bool("all"))
+					},
+				},
+				{
+					Name:        "get",
+					Usage:       "Prints a setting of the configuration",
+					UsageText:   "adr config get <name>",
+					Description: "Prints the value of the setting, the lists and objects in JSON, nothing when it is not set",
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 1 {
+							return cli.NewExitError("adr config get takes the name of a setting, e.g. adr config get base_directory", 1)
+						}
+						repo := openRepository(ctx, paths, out)
+						value, err := repo.Settings().Get(c.Args().First())
+						if err != nil {
+							return err
+						}
+						if out.JSON {
+							return out.Document(value)
+						}
+						if value != nil {
+							out.Info(settingText(value))
+						}
+						return nil
+					},
+				},
+				{
+					Name:        "set",
+					Usage:       "Changes a setting of the configuration",
+					UsageText:   "adr config set <name> <value>",
+					Description: "Sets the setting to the value, text, a number, true or false after the setting, or JSON for the lists and objects,\n   e.g. adr config set auto_commit true or adr config set toc '{\"file\": \"README.md\"}'\n   The values a setting does not allow are refused, leaving the configuration as it was",
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 2 {
+							return cli.NewExitError("adr config set takes the name of a setting and its value, e.g. adr config set date_format 2006-01-02", 1)
+						}
+						repo := openRepository(ctx, paths, out)
+						if err := configure(ctx, repo, c.Args().Get(0), c.Args().Get(1)); err != nil {
+							return err
+						}
+						out.Success(c.Args().Get(0) + " set in " + repo.ConfigPath())
+						return nil
+					},
+				},
+			},
+		},
+
+		{
+			Name:  "template",
+			Usage: "Manages the templates of new ADRs",
+			Subcommands: []cli.Command{
+				{
+					Name:        "list",
+					Usage:       "Lists the templates 'adr new --template' can use",
+					Description: "Lists the built-in templates and the templates of the " + adr.TemplatesDirName + " folder of the ADR configuration, one <name>.md file each",
+					Action: func(c *cli.Context) error {
+						repo := openRepository(ctx, paths, out)
+						templates, err := repo.Templates()
+						if err != nil {
+							return err
+						}
+						for _, t := range templates {
+							source := "built-in"
+							if t.Path != "" {
+								source = t.Path
+							}
+							marker := ""
+							if t.Name == repo.DefaultTemplate() {
+								marker = out.Highlight(color.FgGreen, " %s", "in use")
+							}
+							out.Info(t.Name + out.Highlight(color.FgYellow, " (%s)", source) + marker)
+						}
+						return nil
+					},
+				},
+				{
+					Name:        "show",
+					Usage:       "Prints a template",
+					UsageText:   "adr template show [name]",
+					Description: "Prints the content of the template, the template of new ADRs when no name is given",
+					Action: func(c *cli.Context) error {
+						if c.NArg() > 1 {
+							return cli.NewExitError("adr template show takes the name of a template, e.g. adr template show madr", 1)
+						}
+						repo := openRepository(ctx, paths, out)
+						t, err := repo.Template(c.Args().First())
+						if err != nil {
+							return err
+						}
+						_, err = io.WriteString(out.Out, t.Content)
+						return err
+					},
+				},
+				{
+					Name:        "add",
+					Usage:       "Adds a template",
+					UsageText:   "adr template add [--from file] [--force] <name>",
+					Description: "Writes the template as <name>.md in the " + adr.TemplatesDirName + " folder of the ADR configuration, template.md for the default template\n   Its content is read from --from, or copied from the template of new ADRs to be edited",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "from",
+							Usage: "File to read the template from, - for the standard input",
+						},
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "Overwrite the template if it exists",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 1 {
+							return cli.NewExitError("adr template add takes the name of the template, e.g. adr template add --from madr.md madr", 1)
+						}
+						repo := openRepository(ctx, paths, out)
+						var content []byte
+						var err error
+						switch c.String("from") {
+						case "":
+							t, err := repo.Template("")
+							if err != nil {
+								return err
+							}
+							content = []byte(t.Content)
+						case "-":
+							content, err = io.ReadAll(os.Stdin)
+						default:
+							content, err = os.ReadFile(c.String("from"))
+						}
+						if err != nil {
+							return err
+						}
+						t, err := repo.AddTemplate(c.Args().First(), string(content), c.Bool("force"))
+						if err != nil {
+							return err
+						}
+						out.Success("Template " + t.Name + " written to " + t.Path)
+						if c.String("from") == "" {
+							out.Hint("Edit it, then create ADRs from it with 'adr new --template " + t.Name + "', or by default after 'adr template use " + t.Name + "'")
+						}
+						return nil
+					},
+				},
+				{
+					Name:        "use",
+					Usage:       "Makes a template the template of new ADRs",
+					UsageText:   "adr template use <name>",
+					Description: "Saves the template as the default_template of the configuration, used by adr new and the other commands creating ADRs when --template is not given\n   adr template use default goes back to the default template",
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 1 {
+							return cli.NewExitError("adr template use takes the name of a template, e.g. adr template use madr", 1)
+						}
+						repo := openRepository(ctx, paths, out)
+						release, err := repo.Lock(ctx)
+						if err != nil {
+							return err
+						}
+						defer release()
+						if err := repo.Reload(); err != nil {
+							return err
+						}
+						if err := repo.UseTemplate(c.Args().First()); err != nil {
+							if errors.Is(err, adr.ErrTemplateNotFound) {
+								out.Hint("'adr template list' lists the templates")
+							}
+							return err
+						}
+						out.Success("New ADRs are written from the template " + c.Args().First())
+						return nil
+					},
+				},
+			},
+		},
+
+		{
+			Name:  "diagram",
+			Usage: "Adds diagrams to the ADRs",
+			Subcommands: []cli.Command{
+				{
+					Name:        "add",
+					Usage:       "Adds a diagram to an ADR",
+					UsageText:   "adr diagram add [--type mermaid|plantuml] [--section Decision] [--file] [--commit] <number>",
+					Description: "Adds a fenced diagram block to complete to a section of the ADR, or with --file a link to a new diagram file of the assets folder next to the ADR\n   'adr export --render-diagrams' renders the diagrams to images",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "type",
+							Value: adr.Mermaid,
+							Usage: "Language of the diagram, one of " + strings.Join(adr.DiagramTypes, ", "),
+						},
+						cli.StringFlag{
+							Name:  "section",
+							Value: "Decision",
+							Usage: "Section of the ADR the diagram is added to",
+						},
+						cli.BoolFlag{
+							Name:  "file",
+							Usage: "Write the diagram in its own file of the " + adr.DiagramAssetsDir + " folder, linked from the ADR",
+						},
+						cli.BoolFlag{
+							Name:  "commit",
+							Usage: "Commit the diagram to git, defaults to the auto_commit configuration",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						kind, err := adr.ParseDiagramType(c.String("type"))
+						if err != nil {
+							return err
+						}
+						repo := openRepository(ctx, paths, out)
+						record, err := targetAdr(ctx, c, repo)
+						if err != nil {
+							return err
+						}
+						record, written, err := addDiagram(ctx, repo, record, kind, c.String("section"), c.Bool("file"))
+						if err != nil {
+							return err
+						}
+						out.Success(fmt.Sprintf("%s added to the %s section of ADR %s", diagramTitle(kind), c.String("section"), record.Ref()))
+						if len(written) > 1 {
+							out.Info("Draw it in " + written[1])
+						}
+						if shouldCommit(c, repo) {
+							return commitAdr(ctx, repo, "diagram", record, written...)
+						}
+						return nil
+					},
+				},
+			},
+		},
+
+		{
+			Name:  "jira",
+			Usage: "Links the ADRs to Jira issues and syncs their status",
+			Subcommands: []cli.Command{
+				{
+					Name:        "link",
+					Usage:       "Links an ADR to the Jira issue tracking it",
+					UsageText:   "adr jira link <number> <issue key>",
+					Description: "Writes an Issue link to the Jira issue under the status of the ADR, e.g. adr jira link 12 ARCH-34",
+					Action: func(c *cli.Context) error {
+						repo := openRepository(ctx, paths, out)
+						if len(c.Args()) != 2 {
+							return errors.New("the ADR number and the Jira issue key are expected, e.g. adr jira link 12 ARCH-34")
+						}
+						target, err := findAdr(ctx, repo, c.Args().Get(0))
+						if err != nil {
+							return err
+						}
+						jira, err := newJiraClient(repo)
+						if err != nil {
+							return err
+						}
+						record, err := linkJiraIssue(ctx, repo, jira, target.Number, c.Args().Get(1))
+						if err != nil {
+							return err
+						}
+						out.Success(fmt.Sprintf("ADR %d is tracked in %s", record.Number, jira.browseURL(c.Args().Get(1))))
+						return nil
+					},
+				},
+				{
+					Name:        "create",
+					Usage:       "Creates the Jira issue tracking an ADR",
+					UsageText:   "adr jira create [number]",
+					Description: "Creates an issue in the Jira project of the configuration and links the ADR to it",
+					Action: func(c *cli.Context) error {
+						repo := openRepository(ctx, paths, out)
+						record, err := targetAdr(ctx, c, repo)
+						if err != nil {
+							return err
+						}
+						if keys := issueKeys(record); len(keys) > 0 {
+							return fmt.Errorf("ADR %d is tracked in %s already", record.Number, strings.Join(keys, ", "))
+						}
+						jira, err := newJiraClient(repo)
+						if err != nil {
+							return err
+						}
+						key, err := createJiraIssue(ctx, repo, jira, record)
+						if err != nil {
+							return err
+						}
+						out.Success("Jira issue " + key + " tracks the ADR: " + jira.browseURL(key))
+						return nil
+					},
+				},
+				{
+					Name:        "sync",
+					Usage:       "Syncs the status of the ADRs with the status of their Jira issues",
+					UsageText:   "adr jira sync [--dry-run]",
+					Description: "Compares the status of each ADR linked to Jira issues with the status of its issues, through the statuses of the configuration\n   The status further along the lifecycle wins: an accepted ADR moves its issue, a closed issue accepts its ADR",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "dry-run",
+							Usage: "Print the changes without making them",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						return syncJira(ctx, openRepository(ctx, paths, out), out, c.Bool("dry-run"))
+					},
+				},
+			},
+		},
+
+		{
+			Name:        "export",
+			Usage:       "Exports the ADRs in another format",
+			UsageText:   "adr export [--format json] [--output file] [--lang fr]",
+			Description: "Writes the ADRs of the base directory in one of the export formats, adr plugins and extensions can add formats\n   --format backstage writes the TechDocs site and the ADR pages Backstage reads into the --output directory, typically the root of the repository\n   --format html writes a static site, an index with a search box and a page per ADR, into the --output directory\n   --format pdf prints the ADRs, after their table of contents, to a single PDF, or to a PDF each with --split, with wkhtmltopdf or the program of the pdf configuration\n   --lang translates the ADRs first, with the translation provider of the configuration; unchanged ADRs are not translated again",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format",
+					Value: "json",
+					Usage: "Export format, one of " + strings.Join(adr.Exporters(), ", "),
+				},
+				cli.StringFlag{
+					Name:  "output",
+					Usage: "File to write the export to, defaults to the standard output, or directory for the formats writing one",
+				},
+				cli.StringFlag{
+					Name:  "lang",
+					Usage: "Language to translate the ADRs to, e.g. fr, with the translation provider of the configuration",
+				},
+				categoryFilterFlag,
+				cli.BoolFlag{
+					Name:  "render-diagrams",
+					Usage: "Render the mermaid and PlantUML diagrams of the ADRs to images, with the Kroki server of the diagrams configuration",
+				},
+				cli.BoolFlag{
+					Name:  "split",
+					Usage: "With --format pdf, write a PDF per ADR into the --output directory instead of a single PDF",
+				},
+			},
+			Action: func(c *cli.Context) (err error) {
+				repo := openRepository(ctx, paths, out)
+				defer func() { notifyOutcome(ctx, repo, "export", err) }()
+				configureExporters(ctx, repo)
+				if c.Bool("split") && (c.String("format") != "pdf" || c.String("output") == "") {
+					return errors.New("--split writes a PDF per ADR into a directory, give --format pdf and the directory with --output")
+				}
+				records, err := readScopedAdrs(ctx, repo)
+				if err != nil {
+					return err
+				}
+				if records, err = (adr.Query{Categories: c.StringSlice("category")}).Filter(repo.FS, records); err != nil {
+					return err
+				}
+				if c.Bool("render-diagrams") {
+					rendered, cleanup, err := renderDiagramRecords(ctx, repo, records)
+					if err != nil {
+						return err
+					}
+					defer cleanup()
+					records = rendered
+				}
+				if c.String("lang") != "" {
+					translated, cleanup, err := translateRecords(ctx, repo, records, c.String("lang"))
+					if err != nil {
+						return err
+					}
+					defer cleanup()
+					records = translated
+				}
+				if c.Bool("split") {
+					if err := newPDFExporter(ctx, repo).Split(adr.OS, c.String("output"), records); err != nil {
+						return err
+					}
+					out.Success(pluralize(len(records), "ADR") + " exported to " + c.String("output"))
+					return nil
+				}
+				if adr.ExportsDirectory(c.String("format")) {
+					if c.String("output") == "" {
+						return errors.New("the " + c.String("format") + " format writes a directory, give it with --output, e.g. --output .")
+					}
+					if err := adr.ExportDir(c.String("format"), adr.OS, c.String("output"), records); err != nil {
+						return err
+					}
+					out.Success(pluralize(len(records), "ADR") + " exported to " + c.String("output"))
+					return nil
+				}
+				if c.String("output") == "" {
+					return adr.Export(c.String("format"), out.Out, records)
+				}
+				f, err := os.Create(c.String("output"))
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				if err := adr.Export(c.String("format"), f, records); err != nil {
+					return err
+				}
+				out.Success(pluralize(len(records), "ADR") + " exported to " + c.String("output"))
+				return nil
+			},
+		},
+
+		{
+			Name:        "completion",
+			Usage:       "Writes the completion script of a shell",
+			UsageText:   "adr completion bash|zsh|fish|powershell",
+			Description: "Writes a script completing the commands, flags, ADR numbers, statuses, settings and templates of adr\n   The numbers of the ADRs are completed with their titles, the script tells how to load it, e.g. source <(adr completion bash)",
+			Action: func(c *cli.Context) error {
+				script, ok := completionShells[c.Args().First()]
+				if c.NArg() != 1 || !ok {
+					return cli.NewExitError("adr completion takes a shell: "+strings.Join(shellNames(), ", "), 1)
+				}
+				_, err := io.WriteString(out.Out, script)
+				return err
+			},
+		},
+
+		{
+			Name:            completeCommand,
+			Usage:           "Prints the completions of the words typed after adr, for the completion scripts",
+			Hidden:          true,
+			SkipFlagParsing: true,
+			Action: func(c *cli.Context) error {
+				writeCandidates(out.Out, completeWords(ctx, c.App, paths, c.Args()))
+				return nil
+			},
+		},
+
+		{
+			Name:  "plugin",
+			Usage: "Manages the adr plugins",
+			Subcommands: []cli.Command{
+				{
+					Name:        "list",
+					Usage:       "Lists the plugins found on the PATH",
+					Description: "Any executable named " + pluginPrefix + "<name> on the PATH is run by 'adr <name>', with ADR_CONFIG_DIR and ADR_SCOPE set",
+					Action: func(c *cli.Context) error {
+						plugins := findPlugins()
+						for _, name := range pluginNames(plugins) {
+							out.Info(name + out.Highlight(color.FgYellow, " (%s)", plugins[name]))
+						}
+						return nil
+					},
+				},
+			},
+		},
+	}
+
+	for _, e := range extensions {
+		app.Commands = append(app.Commands, e(ctx, paths, out))
+	}
+
+	// unknown sub-commands run the adr-<name> plugin of the PATH
+	app.CommandNotFound = func(c *cli.Context, command string) {
+		if ok, err := runAlias(c, paths, command); ok {
+			if err != nil {
+				cli.HandleExitCoder(err)
+				out.Error(err.Error())
+				os.Exit(1)
+			}
+			return
+		}
+		if err := runPlugin(ctx, paths, command, c.Args().Tail()); err != nil {
+			cli.HandleExitCoder(err)
+			out.Error(err.Error())
+			if suggestions := suggestCommands(c, paths, command); len(suggestions) > 0 {
+				out.Hint("Did you mean " + strings.Join(suggestions, " or ") + "?")
+			}
+			os.Exit(1)
+		}
 	}
 }
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/urfave/cli"
+)
+
+// completeCommand the hidden command the completion scripts run with the words typed after adr, the last one being
+// completed. It prints the candidates one per line, a tab separating them from their description.
+const completeCommand = "__complete"
+
+// Kinds of the arguments adr completes
+const (
+	argAdr      = "adr"
+	argAdrs     = "adrs"
+	argStatus   = "status"
+	argSetting  = "setting"
+	argTemplate = "template"
+	// argFile leaves the completion to the shell, completing files
+	argFile = ""
+)
+
+// argCompletions the kinds of the arguments of the commands, by command path; argAdrs goes on for every argument
+// after it. The arguments of the other commands are files.
+var argCompletions = map[string][]string{
+	"show":          {argAdr},
+	"preview":       {argAdr},
+	"status":        {argAdr, argStatus},
+	"accept":        {argAdr},
+	"archive":       {argAdr},
+	"delete":        {argAdr},
+	"history":       {argAdr},
+	"diff":          {argAdr, argAdr},
+	"supersede":     {argAdr, argAdr},
+	"link":          {argAdr, argAdr},
+	"approve":       {argAdr},
+	"sign":          {argAdr},
+	"verify":        {argAdrs},
+	"sync":          {argFile, argAdrs},
+	"publish":       {argAdrs},
+	"config get":    {argSetting},
+	"config set":    {argSetting},
+	"template show": {argTemplate},
+	"template use":  {argTemplate},
+	"diagram add":   {argAdr},
+	"jira link":     {argAdr},
+	"jira create":   {argAdr},
+}
+
+// flagCompletions the kinds of the values of the flags, by flag name
+var flagCompletions = map[string]string{
+	"status":   argStatus,
+	"template": argTemplate,
+}
+
+// candidate a completion of the word typed and what it stands for, e.g. an ADR number and its title
+type candidate struct {
+	value       string
+	description string
+}
+
+// completionShells the shells adr completion writes scripts for
+var completionShells = map[string]string{
+	"bash": `# bash completion of adr, source it from ~/.bashrc: source <(adr completion bash)
+_adr() {
+    local IFS=$'\n'
+    local candidates=($(adr ` + completeCommand + ` "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+    COMPREPLY=()
+    if [ ${#candidates[@]} -eq 1 ]; then
+        COMPREPLY=("${candidates[0]%%$'\t'*}")
+        return
+    fi
+    local candidate
+    for candidate in "${candidates[@]}"; do
+        # bash has no descriptions, they are shown next to the candidates when there is a choice
+        if [[ $candidate == *$'\t'* ]]; then
+            COMPREPLY+=("${candidate%%$'\t'*}  (${candidate#*$'\t'})")
+        else
+            COMPREPLY+=("$candidate")
+        fi
+    done
+}
+complete -o default -F _adr adr
+`,
+	"zsh": `#compdef adr
+# zsh completion of adr, source it from ~/.zshrc after compinit: source <(adr completion zsh)
+_adr() {
+    local -a candidates
+    local line
+    for line in "${(@f)$(adr ` + completeCommand + ` "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -n $line ]] || continue
+        if [[ $line == *$'\t'* ]]; then
+            candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+        else
+            candidates+=("${line//:/\\:}")
+        fi
+    done
+    if (( ${#candidates} )); then
+        _describe adr candidates
+    else
+        _files
+    fi
+}
+compdef _adr adr
+`,
+	"fish": `# fish completion of adr, save it as ~/.config/fish/completions/adr.fish: adr completion fish > ~/.config/fish/completions/adr.fish
+function __adr_complete
+    set -l candidates (adr ` + completeCommand + ` (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)
+    if test (count $candidates) -eq 0
+        __fish_complete_path (commandline -ct)
+        return
+    end
+    printf '%s\n' $candidates
+end
+complete -c adr -f -a '(__adr_complete)'
+`,
+	"powershell": `# PowerShell completion of adr, add it to your profile: adr completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName adr -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 |
+        Where-Object { $_.Extent.EndOffset -le $cursorPosition } | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -eq '') {
+        # PowerShell drops the empty arguments of native commands, a space stands for the empty word
+        $words += ' '
+    }
+    adr ` + completeCommand + ` @words 2>$null | ForEach-Object {
+        $value, $description = $_ -split "` + "`" + `t", 2
+        if (-not $description) { $description = $value }
+        [System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $description)
+    }
+}
+`,
+}
+
+// shellNames the shells adr completion writes scripts for, sorted
+func shellNames() []string {
+	names := []string{}
+	for name := range completionShells {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// flagNames the names of flags, long ones first with their dashes, and whether each takes a value
+func flagNames(flags []cli.Flag) ([]string, map[string]bool) {
+	names := []string{}
+	takesValue := map[string]bool{}
+	for _, flag := range flags {
+		valued := false
+		if f, ok := flag.(cli.DocGenerationFlag); ok {
+			valued = f.TakesValue()
+		}
+		for _, name := range strings.Split(flag.GetName(), ",") {
+			name = strings.TrimSpace(name)
+			dashes := "--"
+			if len(name) == 1 {
+				dashes = "-"
+			}
+			names = append(names, dashes+name)
+			takesValue[dashes+name] = valued
+		}
+	}
+	return names, takesValue
+}
+
+// subcommand the command of commands named name, nil when none is
+func subcommand(commands []cli.Command, name string) *cli.Command {
+	for i := range commands {
+		if commands[i].HasName(name) && !commands[i].Hidden {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// commandCandidates the commands, their aliases and with top the aliases of the configuration and the plugins
+func commandCandidates(repo *adr.Repository, commands []cli.Command, top bool) []candidate {
+	candidates := []candidate{}
+	for _, command := range commands {
+		if command.Hidden {
+			continue
+		}
+		for _, name := range command.Names() {
+			candidates = append(candidates, candidate{name, command.Usage})
+		}
+	}
+	if !top {
+		return candidates
+	}
+	if repo != nil {
+		for alias, line := range repo.Settings().Aliases {
+			candidates = append(candidates, candidate{alias, line})
+		}
+	}
+	for _, name := range pluginNames(findPlugins()) {
+		candidates = append(candidates, candidate{name, "plugin"})
+	}
+	return candidates
+}
+
+// argCandidates the candidates for a word of the given kind
+func argCandidates(ctx context.Context, repo *adr.Repository, kind string) []candidate {
+	candidates := []candidate{}
+	switch kind {
+	case argStatus:
+		for _, status := range adr.Statuses {
+			candidates = append(candidates, candidate{strings.ToLower(string(status)), ""})
+		}
+	case argSetting:
+		for _, setting := range adr.ConfigSettings() {
+			candidates = append(candidates, candidate{setting.Name, ""})
+		}
+	case argTemplate:
+		if repo == nil {
+			return nil
+		}
+		templates, err := repo.Templates()
+		if err != nil {
+			return nil
+		}
+		for _, t := range templates {
+			candidates = append(candidates, candidate{t.Name, ""})
+		}
+	case argAdr, argAdrs:
+		if repo == nil {
+			return nil
+		}
+		records, err := repo.List(ctx)
+		if err != nil {
+			return nil
+		}
+		for _, record := range records {
+			candidates = append(candidates, candidate{record.Ref(), record.Title + " [" + string(record.Status) + "]"})
+		}
+	}
+	return candidates
+}
+
+// completeWords the candidates for the last of the words typed after adr, none leaving it to the shell to complete a file
+func completeWords(ctx context.Context, app *cli.App, paths adrPaths, words []string) []candidate {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	// PowerShell gives a space for the empty word, see its completion script
+	current := strings.TrimLeft(words[len(words)-1], " ")
+	typed := words[:len(words)-1]
+
+	scope := selectedScope
+	globals, globalValued := flagNames(app.Flags)
+	i := 0
+	for ; i < len(typed) && strings.HasPrefix(typed[i], "-"); i++ {
+		if globalValued[typed[i]] && i+1 < len(typed) {
+			if typed[i] == "--scope" {
+				scope = typed[i+1]
+			}
+			i++
+		}
+	}
+	repo, err := adr.Open(paths.ConfigDir)
+	if err != nil || applyScope(ctx, repo, scope) != nil {
+		repo = nil
+	}
+
+	matching := func(candidates []candidate) []candidate {
+		matches := []candidate{}
+		for _, c := range candidates {
+			if strings.HasPrefix(c.value, current) {
+				matches = append(matches, c)
+			}
+		}
+		return matches
+	}
+	flagCandidates := func(names []string) []candidate {
+		candidates := []candidate{}
+		for _, name := range names {
+			candidates = append(candidates, candidate{name, ""})
+		}
+		return matching(candidates)
+	}
+
+	if i == len(typed) {
+		if strings.HasPrefix(current, "-") {
+			return flagCandidates(globals)
+		}
+		return matching(commandCandidates(repo, app.Commands, true))
+	}
+	command := subcommand(app.Commands, typed[i])
+	if command == nil {
+		return nil
+	}
+	path := command.Name
+	for i++; len(command.Subcommands) > 0; i++ {
+		if i == len(typed) {
+			if strings.HasPrefix(current, "-") {
+				return nil
+			}
+			return matching(commandCandidates(repo, command.Subcommands, false))
+		}
+		if command = subcommand(command.Subcommands, typed[i]); command == nil {
+			return nil
+		}
+		path += " " + command.Name
+	}
+
+	names, valued := flagNames(command.Flags)
+	if last := len(typed) - 1; last >= i && valued[typed[last]] {
+		return matching(argCandidates(ctx, repo, flagCompletions[strings.TrimLeft(typed[last], "-")]))
+	}
+	if strings.HasPrefix(current, "-") {
+		return flagCandidates(names)
+	}
+	position := 0
+	for ; i < len(typed); i++ {
+		if !strings.HasPrefix(typed[i], "-") {
+			position++
+		} else if valued[typed[i]] && !strings.Contains(typed[i], "=") {
+			i++
+		}
+	}
+	kinds := argCompletions[path]
+	if len(kinds) == 0 {
+		return nil
+	}
+	kind := kinds[len(kinds)-1]
+	if position < len(kinds) {
+		kind = kinds[position]
+	} else if kind != argAdrs {
+		return nil
+	}
+	return matching(argCandidates(ctx, repo, kind))
+}
+
+// writeCandidates prints the candidates as the completion scripts read them
+func writeCandidates(w io.Writer, candidates []candidate) {
+	for _, c := range candidates {
+		if c.description == "" {
+			fmt.Fprintln(w, c.value)
+			continue
+		}
+		fmt.Fprintln(w, c.value+"\t"+strings.ReplaceAll(c.description, "\n", " "))
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/fatih/color"
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// settingText value as adr config prints it: strings as they are, the other values in JSON, nothing when unset
+func settingText(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if text, ok := value.(string); ok {
+		return text
+	}
+	content, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// leafSettings the settings that are no objects, the fields of the objects standing for them
+func leafSettings() []adr.Setting {
+	leaves := []adr.Setting{}
+	for _, setting := range adr.ConfigSettings() {
+		t := setting.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			leaves = append(leaves, setting)
+		}
+	}
+	return leaves
+}
+
+// listSettings prints the settings of the configuration of repo, those that are unset too with all
+func listSettings(repo *adr.Repository, out *reporter, all bool) error {
+	settings := repo.Settings()
+	values := map[string]interface{}{}
+	for _, setting := range leafSettings() {
+		value, err := settings.Get(setting.Name)
+		if err != nil {
+			return err
+		}
+		if value == nil && !all {
+			continue
+		}
+		values[setting.Name] = value
+		if !out.JSON {
+			out.Info(setting.Name + " = " + out.Highlight(color.FgYellow, "%s", settingText(value)))
+		}
+	}
+	if out.JSON {
+		return out.Document(values)
+	}
+	out.Hint("Read from " + repo.ConfigPath())
+	return nil
+}
+
+// configure sets a setting of the configuration of repo under the lock, see adr.Repository.Configure
+func configure(ctx context.Context, repo *adr.Repository, name string, value string) error {
+	release, err := repo.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if err := repo.Reload(); err != nil {
+		return err
+	}
+	op := startOperation(repo.ConfigDir, "config", []string{"set", name, value})
+	defer op.done()
+	op.track(repo.ConfigPath())
+	return repo.Configure(name, value)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/russross/blackfriday/v2"
+)
+
+var (
+	// adrTitleRegexp the title of an ADR, followed by the rule of adr's own format, replaced by the title of its page
+	adrTitleRegexp         = regexp.MustCompile(`^\s*#\s+[^\n]*\n(=+[ \t]*\n)?`)
+	confluenceAnchorRegexp = regexp.MustCompile(`<a href="([^"]*)">(.*?)</a>`)
+	confluenceTagRegexp    = regexp.MustCompile(`<[^>]*>`)
+)
+
+// confluencePublisher publishes ADRs as pages of a Confluence space, through its REST API
+type confluencePublisher struct {
+	*atlassianClient
+	repo   *adr.Repository
+	config adr.ConfluenceConfig
+}
+
+func newConfluencePublisher(repo *adr.Repository) (publisher, error) {
+	config := repo.Settings().Confluence
+	if config == nil || config.URL == "" || config.Space == "" {
+		return nil, errors.New("Confluence is not configured, set the url and space of the \"confluence\" object of " + repo.ConfigPath())
+	}
+	token := config.APIToken(os.Getenv)
+	if token == "" {
+		return nil, errors.New("the Confluence API token is missing, set token or token_env in " + repo.ConfigPath())
+	}
+	return &confluencePublisher{atlassianClient: newAtlassianClient("Confluence", config.URL, config.Email, token), repo: repo, config: *config}, nil
+}
+
+// confluencePage the parts of a page the REST API is given and returns
+type confluencePage struct {
+	ID        string               `json:"id,omitempty"`
+	Type      string               `json:"type"`
+	Title     string               `json:"title"`
+	Space     *confluenceSpace     `json:"space,omitempty"`
+	Ancestors []confluenceAncestor `json:"ancestors,omitempty"`
+	Version   *confluenceVersion   `json:"version,omitempty"`
+	Body      *confluenceBody      `json:"body,omitempty"`
+	Links     struct {
+		Base  string `json:"base"`
+		WebUI string `json:"webui"`
+	} `json:"_links"`
+}
+
+type confluenceSpace struct {
+	Key string `json:"key"`
+}
+
+type confluenceAncestor struct {
+	ID string `json:"id"`
+}
+
+type confluenceVersion struct {
+	Number int `json:"number"`
+}
+
+type confluenceBody struct {
+	Storage struct {
+		Value          string `json:"value"`
+		Representation string `json:"representation"`
+	} `json:"storage"`
+}
+
+// Wants publishes every ADR
+func (p *confluencePublisher) Wants(record adr.Record) bool {
+	return true
+}
+
+// Publish creates or updates the page of record, a page deleted from Confluence is created again
+func (p *confluencePublisher) Publish(ctx context.Context, record adr.Record, content []byte, id string) (string, string, error) {
+	page := confluencePage{Type: "page", Title: pageTitle(record), Space: &confluenceSpace{Key: p.config.Space}, Body: &confluenceBody{}}
+	page.Body.Storage.Value = p.storage(record, content)
+	page.Body.Storage.Representation = "storage"
+	if p.config.ParentID != "" {
+		page.Ancestors = []confluenceAncestor{{ID: p.config.ParentID}}
+	}
+
+	published := confluencePage{}
+	if id != "" {
+		current := confluencePage{}
+		err := p.do(ctx, http.MethodGet, "/rest/api/content/"+url.PathEscape(id)+"?expand=version", nil, &current)
+		var apiErr *atlassianError
+		switch {
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+			id = ""
+		case err != nil:
+			return "", "", err
+		case current.Version != nil:
+			page.ID, page.Version = id, &confluenceVersion{Number: current.Version.Number + 1}
+		}
+	}
+	var err error
+	if id == "" {
+		err = p.do(ctx, http.MethodPost, "/rest/api/content", page, &published)
+	} else {
+		err = p.do(ctx, http.MethodPut, "/rest/api/content/"+url.PathEscape(id), page, &published)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	base := published.Links.Base
+	if base == "" {
+		base = p.baseURL
+	}
+	return published.ID, base + published.Links.WebUI, nil
+}
+
+// storage converts the markdown of an ADR to the storage format of Confluence, XHTML where the links to
+// other ADRs are links to their pages
+func (p *confluencePublisher) storage(record adr.Record, content []byte) string {
+	content = adrTitleRegexp.ReplaceAll(content, nil)
+	content = htmlRule.ReplaceAll(content, []byte("$1"))
+	renderer := blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+		Flags: blackfriday.UseXHTML | blackfriday.SkipHTML,
+	})
+	rendered := blackfriday.Run(content, blackfriday.WithRenderer(renderer), blackfriday.WithExtensions(blackfriday.CommonExtensions))
+	return confluenceAnchorRegexp.ReplaceAllStringFunc(string(rendered), func(anchor string) string {
+		m := confluenceAnchorRegexp.FindStringSubmatch(anchor)
+		target := html.UnescapeString(m[1])
+		if strings.Contains(target, "://") || !strings.HasSuffix(strings.ToLower(target), ".md") {
+			return anchor
+		}
+		linked, err := adr.ParseFile(p.repo.FS, filepath.Join(filepath.Dir(record.Path), filepath.FromSlash(target)))
+		if err != nil {
+			return anchor
+		}
+		text := html.UnescapeString(confluenceTagRegexp.ReplaceAllString(m[2], ""))
+		return `<ac:link><ri:page ri:content-title="` + html.EscapeString(pageTitle(linked)) + `" />` +
+			`<ac:plain-text-link-body><![CDATA[` + strings.Replace(text, "]]>", "]]]]><![CDATA[>", -1) + `]]></ac:plain-text-link-body></ac:link>`
+	})
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// defaultDiagramRenderer the Kroki server rendering the diagrams when the configuration names none
+const defaultDiagramRenderer = "https://kroki.io"
+
+// diagramImageTypes the media types of the image formats of the diagrams, by format
+var diagramImageTypes = map[string]string{"svg": "image/svg+xml", "png": "image/png"}
+
+// diagramRenderer renders diagrams to images with a Kroki server, see https://kroki.io
+type diagramRenderer struct {
+	url    string
+	format string
+	client *http.Client
+}
+
+func newDiagramRenderer(repo *adr.Repository) (*diagramRenderer, error) {
+	r := &diagramRenderer{url: defaultDiagramRenderer, format: "svg", client: &http.Client{Timeout: time.Minute}}
+	if config := repo.Settings().Diagrams; config != nil {
+		if config.RendererURL != "" {
+			r.url = strings.TrimSuffix(config.RendererURL, "/")
+		}
+		if config.Format != "" {
+			r.format = strings.ToLower(config.Format)
+		}
+	}
+	if _, ok := diagramImageTypes[r.format]; !ok {
+		return nil, errors.New("unknown diagram format '" + r.format + "' in " + repo.ConfigPath() + ", expected svg or png")
+	}
+	return r, nil
+}
+
+// Render the image of the diagram of type kind written source
+func (r *diagramRenderer) Render(ctx context.Context, kind string, source string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url+"/"+kind+"/"+r.format, strings.NewReader(source))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "text/plain")
+	response, err := r.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s answered %s: %s", r.url, response.Status, strings.TrimSpace(string(content)))
+	}
+	return content, nil
+}
+
+// Image the markdown image of the diagram, its picture inlined as a data URL so that it goes wherever the ADR goes
+func (r *diagramRenderer) Image(ctx context.Context, title string, kind string, source string) (string, error) {
+	image, err := r.Render(ctx, kind, source)
+	if err != nil {
+		return "", err
+	}
+	return "![" + title + "](data:" + diagramImageTypes[r.format] + ";base64," + base64.StdEncoding.EncodeToString(image) + ")", nil
+}
+
+// addDiagram adds a diagram of type kind to the section of record: a fenced block to complete, or with file a link to
+// a new diagram file of the assets folder next to the ADR. It records the operation in the journal and returns the
+// updated record with the paths written.
+func addDiagram(ctx context.Context, repo *adr.Repository, record adr.Record, kind string, section string, file bool) (adr.Record, []string, error) {
+	op := startOperation(repo.ConfigDir, "diagram add", []string{strconv.Itoa(record.Number), kind, section})
+	defer op.done()
+	markdown := adr.DiagramBlock(kind, adr.DiagramScaffold(kind))
+	written := []string{record.Path}
+	if file {
+		dir := filepath.Join(filepath.Dir(record.Path), adr.DiagramAssetsDir)
+		if err := repo.FS.MkdirAll(dir, 0755); err != nil {
+			return record, nil, err
+		}
+		name := adr.DiagramFileName(record, kind)
+		for n := 2; ; n++ {
+			if _, err := repo.FS.Stat(filepath.Join(dir, name)); err != nil {
+				break
+			}
+			extension := filepath.Ext(name)
+			name = strings.TrimSuffix(adr.DiagramFileName(record, kind), extension) + "-" + strconv.Itoa(n) + extension
+		}
+		path := filepath.Join(dir, name)
+		op.created(path)
+		if err := repo.FS.WriteFile(path, []byte(adr.DiagramScaffold(kind)+"\n"), 0644); err != nil {
+			return record, nil, err
+		}
+		markdown = "[" + diagramTitle(kind) + "](" + adr.DiagramAssetsDir + "/" + name + ")"
+		written = append(written, path)
+	}
+	op.track(record.Path)
+	updated, err := repo.Rewrite(ctx, record, func(content []byte) ([]byte, error) {
+		return adr.AddDiagram(content, section, markdown)
+	})
+	return updated, written, err
+}
+
+// diagramTitle the text of the links and images of the diagrams of type kind, e.g. "PlantUML diagram"
+func diagramTitle(kind string) string {
+	if kind == adr.PlantUML {
+		return "PlantUML diagram"
+	}
+	return "Mermaid diagram"
+}
+
+// renderDiagramRecords renders the diagrams of records to images, in copies of their files, see rewriteCopies.
+// The diagram files the ADRs link to are read next to them.
+func renderDiagramRecords(ctx context.Context, repo *adr.Repository, records []adr.Record) ([]adr.Record, func(), error) {
+	renderer, err := newDiagramRenderer(repo)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rewriteCopies(records, "adr-diagrams-", func(record adr.Record, content []byte) ([]byte, error) {
+		rendered, err := adr.ReplaceDiagrams(content, func(d adr.Diagram) (string, error) {
+			source, title := d.Source, diagramTitle(d.Type)
+			if d.File != "" {
+				path, _ := record.LinkedPath(adr.Link{Target: d.File})
+				text, err := repo.FS.ReadFile(path)
+				if err != nil {
+					return "", err
+				}
+				source = string(text)
+				if d.Title != "" {
+					title = d.Title
+				}
+			}
+			return renderer.Image(ctx, title, d.Type, source)
+		})
+		if err != nil {
+			return nil, errors.New(record.Path + ": " + err.Error())
+		}
+		return rendered, nil
+	})
+}
+
+// rewriteCopies writes what rewrite makes of the file of each record into a temporary directory named after prefix,
+// keeping the names of the files, and one folder per directory, so that the links between ADRs stay valid. The records
+// returned point to the copies, with their titles as rewritten. The returned function removes the copies.
+func rewriteCopies(records []adr.Record, prefix string, rewrite func(record adr.Record, content []byte) ([]byte, error)) ([]adr.Record, func(), error) {
+	tmp, err := os.MkdirTemp("", prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmp) }
+	dirs := map[string]string{}
+	copies := []adr.Record{}
+	for _, record := range records {
+		content, err := os.ReadFile(record.Path)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		if content, err = rewrite(record, content); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		dir, ok := dirs[filepath.Dir(record.Path)]
+		if !ok {
+			dir = filepath.Join(tmp, strconv.Itoa(len(dirs)))
+			dirs[filepath.Dir(record.Path)] = dir
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				cleanup()
+				return nil, nil, err
+			}
+		}
+		record.Path = filepath.Join(dir, filepath.Base(record.Path))
+		if err := os.WriteFile(record.Path, content, 0644); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		if title := adr.Parse(filepath.Base(record.Path), content).Title; title != "" {
+			record.Title = title
+		}
+		copies = append(copies, record)
+	}
+	return copies, cleanup, nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// diffContext the number of unchanged lines shown around changes
+const diffContext = 2
+
+// diffLine a line of a line diff: ' ' unchanged, '-' removed or '+' added
+type diffLine struct {
+	Op   byte
+	Text string
+}
+
+// lineDiff the shortest edit turning the lines of before into the lines of after, from their longest common subsequence
+func lineDiff(before []string, after []string) []diffLine {
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	diff := []diffLine{}
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			diff = append(diff, diffLine{' ', before[i]})
+			i++
+			j++
+		case j < len(after) && (i == len(before) || lcs[i][j+1] > lcs[i+1][j]):
+			diff = append(diff, diffLine{'+', after[j]})
+			j++
+		default:
+			diff = append(diff, diffLine{'-', before[i]})
+			i++
+		}
+	}
+	return diff
+}
+
+// printDiff writes the changes from before to after of the file at path, with a few unchanged lines around them
+func printDiff(out *reporter, path string, before string, after string) {
+	out.Info(out.Highlight(color.Bold, "--- %s", path))
+	printHunks(out, lineDiff(strings.Split(before, "\n"), strings.Split(after, "\n")))
+}
+
+// printHunks writes the changed lines of diff with a few unchanged lines around them
+func printHunks(out *reporter, diff []diffLine) {
+	skipped := false
+	for i, line := range diff {
+		near := false
+		for j := i - diffContext; j <= i+diffContext; j++ {
+			if j >= 0 && j < len(diff) && diff[j].Op != ' ' {
+				near = true
+				break
+			}
+		}
+		if !near {
+			skipped = true
+			continue
+		}
+		if skipped {
+			out.Info(out.Highlight(color.FgCyan, "..."))
+			skipped = false
+		}
+		switch line.Op {
+		case '+':
+			out.Info(out.Highlight(color.FgGreen, "+ %s", line.Text))
+		case '-':
+			out.Info(out.Highlight(color.FgRed, "- %s", line.Text))
+		default:
+			out.Info("  " + line.Text)
+		}
+	}
+}
+
+// comparedAdr an ADR compared by adr diff, as it is on disk or at a git revision
+type comparedAdr struct {
+	Record   adr.Record
+	Revision string
+	Content  []byte
+}
+
+// label names the ADR in the output of adr diff, e.g. "0003. Use MySQL [Accepted] at HEAD~1"
+func (a comparedAdr) label() string {
+	label := fmt.Sprintf("%s. %s [%s]", a.Record.Ref(), a.Record.Title, a.Record.Status)
+	if a.Revision != "" {
+		label += " at " + a.Revision
+	}
+	return label
+}
+
+// readComparedAdr reads the ADR of arg, its reference followed by @ and a git revision to read it at one, e.g. 3@HEAD~1
+func readComparedAdr(ctx context.Context, repo *adr.Repository, arg string) (comparedAdr, error) {
+	ref, revision := arg, ""
+	if at := strings.LastIndex(arg, "@"); at >= 0 {
+		ref, revision = arg[:at], arg[at+1:]
+		if revision == "" {
+			return comparedAdr{}, fmt.Errorf("the git revision after @ is missing in %q, e.g. %s@HEAD~1", arg, ref)
+		}
+	}
+	record, err := findAdr(ctx, repo, ref)
+	if err != nil {
+		return comparedAdr{}, err
+	}
+	if revision == "" {
+		content, err := repo.FS.ReadFile(record.Path)
+		return comparedAdr{Record: record, Content: content}, err
+	}
+	// git reads paths starting with ./ relative to the folder it runs in
+	content, err := runGit(ctx, filepath.Dir(record.Path), "show", revision+":./"+filepath.Base(record.Path))
+	if err != nil {
+		return comparedAdr{}, fmt.Errorf("cannot read ADR %s at %s: %v", record.Ref(), revision, err)
+	}
+	parsed := adr.Parse(filepath.Base(record.Path), []byte(content))
+	record.Title, record.Status, record.Links = parsed.Title, parsed.Status, parsed.Links
+	return comparedAdr{Record: record, Revision: revision, Content: []byte(content)}, nil
+}
+
+// bodyLines the lines of the body of a section, none when it is empty
+func bodyLines(body string) []string {
+	if body == "" {
+		return nil
+	}
+	return strings.Split(body, "\n")
+}
+
+// printSectionDiff writes the changes of each section from before to after, then the relation between the two ADRs
+func printSectionDiff(out *reporter, before comparedAdr, after comparedAdr, changes []adr.SectionChange) {
+	out.Info(out.Highlight(color.Bold, "--- %s", before.label()))
+	out.Info(out.Highlight(color.Bold, "+++ %s", after.label()))
+	if before.Revision == "" && after.Revision == "" {
+		for _, edge := range adr.RelationEdges([]adr.Record{before.Record, after.Record}) {
+			out.Info(fmt.Sprintf("ADR %s %s ADR %s", edge.From.Ref(), edge.Kind, edge.To.Ref()))
+		}
+	}
+	for _, change := range changes {
+		out.Info("")
+		if change.Change == adr.SectionUnchanged {
+			out.Info(out.Highlight(color.Faint, "## %s (unchanged)", change.Title))
+			continue
+		}
+		out.Info(out.Highlight(color.FgCyan, "## %s (%s)", change.Title, change.Change))
+		printHunks(out, lineDiff(bodyLines(change.Before), bodyLines(change.After)))
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// digestEntry an ADR in a section of the digest, with what happened to it and when
+type digestEntry struct {
+	Record adr.Record
+	Link   string
+	Detail string
+}
+
+// digestSection a section of the digest, left out of the email when it has no entries
+type digestSection struct {
+	Title   string
+	Entries []digestEntry
+}
+
+// digest the activity of the ADRs during a period
+type digest struct {
+	Since    time.Time
+	Sections []digestSection
+}
+
+// Sections of the digest
+const (
+	digestNew = iota
+	digestAccepted
+	digestDeprecated
+)
+
+// Count the number of entries of the section i
+func (d digest) Count(i int) int {
+	return len(d.Sections[i].Entries)
+}
+
+// Empty tells whether nothing happened during the period
+func (d digest) Empty() bool {
+	return d.Count(digestNew)+d.Count(digestAccepted)+d.Count(digestDeprecated) == 0
+}
+
+// Subject the subject of the email of the digest
+func (d digest) Subject() string {
+	return fmt.Sprintf("ADR digest: %d new, %d accepted, %d deprecated since %s",
+		d.Count(digestNew), d.Count(digestAccepted), d.Count(digestDeprecated), d.Since.Format("2 Jan 2006"))
+}
+
+// parseDigestSince the start of the period of a digest: a number of days or weeks such as 7d or 2w,
+// a duration such as 36h, or a date written YYYY-MM-DD
+func parseDigestSince(since string, now time.Time) (time.Time, error) {
+	if strings.HasSuffix(since, "d") || strings.HasSuffix(since, "w") {
+		if n, err := strconv.Atoi(since[:len(since)-1]); err == nil && n >= 0 {
+			if strings.HasSuffix(since, "w") {
+				n *= 7
+			}
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if duration, err := time.ParseDuration(since); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	if date, err := time.ParseInLocation(queryDateFormat, since, now.Location()); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q, expected a number of days or weeks such as 7d or 2w, a duration such as 36h, or a date written YYYY-MM-DD", since)
+}
+
+// collectDigest finds what happened to records since since: the git history of their files tells when they were
+// created and when their status changed, the ADRs outside of git being new when they are dated in the period
+func collectDigest(ctx context.Context, records []adr.Record, since time.Time, link string) digest {
+	d := digest{Since: since, Sections: []digestSection{
+		digestNew:        {Title: "New decisions"},
+		digestAccepted:   {Title: "Accepted"},
+		digestDeprecated: {Title: "Deprecated and superseded"},
+	}}
+	// git dates the commits by day
+	day := since.Format(queryDateFormat)
+	for _, record := range records {
+		if record.Draft != "" {
+			continue
+		}
+		entry := digestEntry{Record: record}
+		if link != "" {
+			entry.Link = adrLink(ctx, link, record)
+		}
+		revisions, err := adrHistory(ctx, record)
+		if err != nil || len(revisions) == 0 {
+			slog.Debug("ADR history not read, the ADR is new when dated in the period", "path", record.Path, "error", err)
+			if date, err := adr.ParseDate(record.Date); err == nil && !date.Before(since) {
+				entry.Detail = string(record.Status) + digestAuthor(record) + ", " + date.Format(queryDateFormat)
+				d.Sections[digestNew].Entries = append(d.Sections[digestNew].Entries, entry)
+			}
+			continue
+		}
+		if created := revisions[len(revisions)-1]; created.Date >= day {
+			entry.Detail = string(record.Status) + digestAuthor(record) + ", " + created.Date
+			d.Sections[digestNew].Entries = append(d.Sections[digestNew].Entries, entry)
+		}
+		// the latest transition of the period to each status is told, revisions are newest first
+		told := map[int]bool{}
+		for _, revision := range revisions {
+			if revision.Date < day {
+				break
+			}
+			section := -1
+			switch revision.Status {
+			case adr.Accepted:
+				section = digestAccepted
+			case adr.Deprecated, adr.Superseded:
+				section = digestDeprecated
+			}
+			if !revision.StatusChanged() || section < 0 || told[section] {
+				continue
+			}
+			told[section] = true
+			transition := entry
+			transition.Detail = string(revision.FromStatus) + " → " + string(revision.Status) + ", " + revision.Date
+			d.Sections[section].Entries = append(d.Sections[section].Entries, transition)
+		}
+	}
+	return d
+}
+
+func digestAuthor(record adr.Record) string {
+	if record.Author == "" {
+		return ""
+	}
+	return " by " + record.Author
+}
+
+// digestTemplate the HTML of the email, styled inline as mail clients ignore style sheets
+var digestTemplate = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Subject}}</title></head>
+<body style="font-family: -apple-system, 'Segoe UI', Helvetica, Arial, sans-serif; color: #24292f; max-width: 640px">
+<h1 style="font-size: 20px">Architecture decisions since {{.Since.Format "2 January 2006"}}</h1>
+{{- range .Sections}}{{if .Entries}}
+<h2 style="font-size: 16px; border-bottom: 1px solid #d0d7de; padding-bottom: 4px">{{.Title}}</h2>
+<ul style="padding-left: 20px">
+{{- range .Entries}}
+<li style="margin-bottom: 6px">{{if .Link}}<a href="{{.Link}}" style="color: #0969da">{{.Record.ID}}. {{.Record.Title}}</a>{{else}}<strong>{{.Record.ID}}. {{.Record.Title}}</strong>{{end}}<br><span style="color: #57606a">{{.Detail}}</span></li>
+{{- end}}
+</ul>
+{{- end}}{{end}}
+<p style="color: #57606a; font-size: 12px">Sent by adr digest.</p>
+</body>
+</html>
+`))
+
+// digestEmail the MIME message of the digest, its HTML quoted-printable encoded
+func digestEmail(d digest, from string, to []string, now time.Time) ([]byte, error) {
+	html := bytes.Buffer{}
+	if err := digestTemplate.Execute(&html, d); err != nil {
+		return nil, err
+	}
+	message := bytes.Buffer{}
+	// the sender and the recipients may be missing from the email printed by --dry-run
+	if from != "" {
+		fmt.Fprintf(&message, "From: %s\r\n", from)
+	}
+	if len(to) > 0 {
+		fmt.Fprintf(&message, "To: %s\r\n", strings.Join(to, ", "))
+	}
+	fmt.Fprintf(&message, "Subject: %s\r\nDate: %s\r\n", mime.QEncoding.Encode("utf-8", d.Subject()), now.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\nContent-Type: text/html; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n")
+	body := quotedprintable.NewWriter(&message)
+	if _, err := body.Write(html.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
+
+// sendEmail sends message through the SMTP server of config, over TLS from the start on port 465 and with
+// STARTTLS when the server offers it otherwise
+func sendEmail(ctx context.Context, config adr.DigestConfig, message []byte) error {
+	host, port, err := net.SplitHostPort(config.SMTP)
+	if err != nil {
+		return errors.New("invalid SMTP server '" + config.SMTP + "', expected host:port")
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	var conn net.Conn
+	if port == "465" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", config.SMTP)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", config.SMTP)
+	}
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok && port != "465" {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if config.Username != "" {
+		// PlainAuth refuses to send the password unencrypted but to localhost
+		if err := client.Auth(smtp.PlainAuth("", config.Username, config.SMTPPassword(os.Getenv), host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(config.From); err != nil {
+		return err
+	}
+	for _, recipient := range config.To {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("%s: %w", recipient, err)
+		}
+	}
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := data.Write(message); err != nil {
+		return err
+	}
+	if err := data.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// sendDigest emails the digest of the activity of the ADRs of repo since the start of period, see
+// parseDigestSince, or prints it when dryRun is set. Nothing is sent when nothing happened.
+func sendDigest(ctx context.Context, repo *adr.Repository, out *reporter, config adr.DigestConfig, period string, dryRun bool) error {
+	now := time.Now()
+	since, err := parseDigestSince(period, now)
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		switch {
+		case config.SMTP == "":
+			return errors.New("the SMTP server is missing, use --smtp host:port or set the smtp of the \"digest\" object of " + repo.ConfigPath())
+		case config.From == "":
+			return errors.New("the sender is missing, use --from or set the from of the \"digest\" object of " + repo.ConfigPath())
+		case len(config.To) == 0:
+			return errors.New("the recipients are missing, use --to or set the to of the \"digest\" object of " + repo.ConfigPath())
+		}
+	}
+	records, err := readScopedAdrs(ctx, repo)
+	if err != nil {
+		return err
+	}
+	d := collectDigest(ctx, records, since, config.Link)
+	if d.Empty() {
+		out.Info("No ADR activity since " + since.Format(queryDateFormat) + ", no digest sent")
+		return nil
+	}
+	message, err := digestEmail(d, config.From, config.To, now)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		_, err := out.Out.Write(message)
+		return err
+	}
+	if err := sendEmail(ctx, config, message); err != nil {
+		return errors.New("digest not sent: " + err.Error())
+	}
+	out.Success(d.Subject() + ", sent to " + strings.Join(config.To, ", "))
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// discussionsPublisher mirrors decisions into a category of GitHub Discussions, through the GraphQL API
+type discussionsPublisher struct {
+	github   *githubClient
+	config   adr.DiscussionsConfig
+	statuses []adr.Status
+
+	// the node IDs of the repository and of the category, looked up with the first discussion created
+	repositoryID string
+	categoryID   string
+}
+
+func newDiscussionsPublisher(repo *adr.Repository) (publisher, error) {
+	config := repo.Settings().Discussions
+	if config == nil || config.Repository == "" || config.Category == "" {
+		return nil, errors.New("GitHub Discussions is not configured, set the repository and category of the \"github_discussions\" object of " + repo.ConfigPath())
+	}
+	if owner, name, ok := strings.Cut(config.Repository, "/"); !ok || owner == "" || name == "" {
+		return nil, errors.New("invalid GitHub repository '" + config.Repository + "', expected owner/repo")
+	}
+	statuses := []adr.Status{adr.Accepted}
+	if len(config.Statuses) > 0 {
+		statuses = []adr.Status{}
+		for _, status := range config.Statuses {
+			statuses = append(statuses, adr.NormalizeStatus(status))
+		}
+	}
+	return &discussionsPublisher{github: newGitHubClient(), config: *config, statuses: statuses}, nil
+}
+
+// Wants mirrors the ADRs of the statuses of the configuration, the accepted ones by default
+func (p *discussionsPublisher) Wants(record adr.Record) bool {
+	for _, status := range p.statuses {
+		if record.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// discussion the fields of a discussion the mutations return
+type discussion struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// Publish creates or edits the discussion of record, a discussion deleted from GitHub is created again
+func (p *discussionsPublisher) Publish(ctx context.Context, record adr.Record, content []byte, id string) (string, string, error) {
+	title, body := pageTitle(record), discussionBody(ctx, record, content)
+	if id != "" {
+		data := struct {
+			Node *discussion `json:"node"`
+		}{}
+		query := `query($id: ID!) { node(id: $id) { ... on Discussion { id url } } }`
+		if err := p.github.graphQL(ctx, query, map[string]interface{}{"id": id}, &data); err != nil && !strings.Contains(err.Error(), "Could not resolve") {
+			return "", "", err
+		}
+		if data.Node != nil && data.Node.ID != "" {
+			updated := struct {
+				UpdateDiscussion struct {
+					Discussion discussion `json:"discussion"`
+				} `json:"updateDiscussion"`
+			}{}
+			mutation := `mutation($id: ID!, $title: String!, $body: String!) {
+  updateDiscussion(input: {discussionId: $id, title: $title, body: $body}) { discussion { id url } }
+}`
+			err := p.github.graphQL(ctx, mutation, map[string]interface{}{"id": id, "title": title, "body": body}, &updated)
+			return updated.UpdateDiscussion.Discussion.ID, updated.UpdateDiscussion.Discussion.URL, err
+		}
+	}
+	if err := p.lookUpCategory(ctx); err != nil {
+		return "", "", err
+	}
+	created := struct {
+		CreateDiscussion struct {
+			Discussion discussion `json:"discussion"`
+		} `json:"createDiscussion"`
+	}{}
+	mutation := `mutation($repository: ID!, $category: ID!, $title: String!, $body: String!) {
+  createDiscussion(input: {repositoryId: $repository, categoryId: $category, title: $title, body: $body}) { discussion { id url } }
+}`
+	variables := map[string]interface{}{"repository": p.repositoryID, "category": p.categoryID, "title": title, "body": body}
+	err := p.github.graphQL(ctx, mutation, variables, &created)
+	return created.CreateDiscussion.Discussion.ID, created.CreateDiscussion.Discussion.URL, err
+}
+
+// lookUpCategory finds the node IDs of the repository and of the category of the configuration
+func (p *discussionsPublisher) lookUpCategory(ctx context.Context) error {
+	if p.categoryID != "" {
+		return nil
+	}
+	owner, name, _ := strings.Cut(p.config.Repository, "/")
+	data := struct {
+		Repository *struct {
+			ID                   string `json:"id"`
+			DiscussionCategories struct {
+				Nodes []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+					Slug string `json:"slug"`
+				} `json:"nodes"`
+			} `json:"discussionCategories"`
+		} `json:"repository"`
+	}{}
+	query := `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) { id discussionCategories(first: 100) { nodes { id name slug } } }
+}`
+	if err := p.github.graphQL(ctx, query, map[string]interface{}{"owner": owner, "name": name}, &data); err != nil {
+		return err
+	}
+	if data.Repository == nil {
+		return errors.New("the GitHub repository " + p.config.Repository + " is not found")
+	}
+	names := []string{}
+	for _, category := range data.Repository.DiscussionCategories.Nodes {
+		if strings.EqualFold(category.Name, p.config.Category) || strings.EqualFold(category.Slug, p.config.Category) {
+			p.repositoryID, p.categoryID = data.Repository.ID, category.ID
+			return nil
+		}
+		names = append(names, category.Name)
+	}
+	return errors.New("no discussion category " + p.config.Category + " in " + p.config.Repository + ", its categories are " + strings.Join(names, ", "))
+}
+
+// discussionBody the markdown of a discussion: the ADR without its title, which the discussion has, nor the rules
+// under the headings of adr's own format, and a note telling where it comes from
+func discussionBody(ctx context.Context, record adr.Record, content []byte) string {
+	content = htmlRule.ReplaceAll(adrTitleRegexp.ReplaceAll(content, nil), []byte("$1"))
+	body := strings.TrimSpace(string(content))
+	return body + "\n\n---\n_Mirrored from `" + repositoryPath(ctx, record.Path) + "` by adr publish, edit the ADR rather than this discussion._\n"
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// isMainBranch tells whether the repository holding dir is on its main branch
+func isMainBranch(ctx context.Context, dir string) bool {
+	branch, err := runGit(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	return err == nil && (branch == "main" || branch == "master")
+}
+
+// draftByDefault tells whether adr new creates drafts, which the draft_on_branches configuration
+// enables on every branch but the main one
+func draftByDefault(ctx context.Context, repo *adr.Repository) bool {
+	if !repo.Settings().DraftBranches {
+		return false
+	}
+	if _, err := gitRepositoryRoot(ctx, repo.Dir); err != nil {
+		return false
+	}
+	return !isMainBranch(ctx, repo.Dir)
+}
+
+// finalizedDraft a draft ADR that received its final number
+type finalizedDraft struct {
+	Draft  string
+	Record adr.Record
+}
+
+// finalizeDrafts gives the draft ADRs their sequential number, oldest first, renaming their files
+// and updating the links of the other ADRs. It must be called while holding the lock.
+func finalizeDrafts(ctx context.Context, repo *adr.Repository, op *operation) ([]finalizedDraft, error) {
+	records, err := adr.ReadDir(ctx, repo.FS, repo.Dir)
+	if err != nil {
+		return nil, err
+	}
+	drafts := []adr.Record{}
+	for _, record := range records {
+		if record.Draft != "" {
+			drafts = append(drafts, record)
+		}
+	}
+	sort.SliceStable(drafts, func(i, j int) bool {
+		return adrTime(drafts[i]).Before(adrTime(drafts[j]))
+	})
+
+	// drafts are numbered in their category
+	defer func(category string) { repo.Category = category }(repo.Category)
+	finalized := []finalizedDraft{}
+	for _, draft := range drafts {
+		if err := ctx.Err(); err != nil {
+			return finalized, err
+		}
+		repo.Category = draft.Category
+		op.track(repo.ConfigPath())
+		number, err := repo.ClaimNumber(ctx)
+		if err != nil {
+			return finalized, err
+		}
+		content, err := repo.FS.ReadFile(draft.Path)
+		if err != nil {
+			return finalized, err
+		}
+		record := draft
+		record.Number = number
+		record.Draft = ""
+		record.Path = filepath.Join(filepath.Dir(draft.Path), repo.FileName(record.Number, record.Title))
+		op.created(record.Path)
+		op.track(draft.Path)
+		if err := repo.FS.WriteFile(record.Path, adr.SetHeadingNumber(content, strconv.Itoa(record.Number)), 0644); err != nil {
+			return finalized, err
+		}
+		if err := repo.FS.Remove(draft.Path); err != nil {
+			return finalized, err
+		}
+		if err := replaceLinks(repo.FS, repo.Dir, filepath.Base(draft.Path), filepath.Base(record.Path), op); err != nil {
+			return finalized, err
+		}
+		finalized = append(finalized, finalizedDraft{Draft: draft.Draft, Record: record})
+	}
+	return finalized, nil
+}
+
+// replaceLinks points the links of every ADR of baseDir, of its categories and of their archives from one file name
+// to another, the links from other folders ending with the file name
+func replaceLinks(fsys adr.FileSystem, baseDir string, from string, to string, op *operation) error {
+	paths, err := fsys.Glob(filepath.Join(baseDir, "*.md"))
+	if err != nil {
+		return err
+	}
+	categorized, err := fsys.Glob(filepath.Join(baseDir, "*", "*.md"))
+	if err != nil {
+		return err
+	}
+	archived, err := fsys.Glob(filepath.Join(baseDir, "*", adr.ArchiveDir, "*.md"))
+	if err != nil {
+		return err
+	}
+	for _, path := range append(append(paths, categorized...), archived...) {
+		content, err := fsys.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		// the links from other folders first, to may end with the file name
+		replaced := bytes.Replace(content, []byte("/"+from+")"), []byte("/"+to+")"), -1)
+		replaced = bytes.Replace(replaced, []byte("("+from+")"), []byte("("+to+")"), -1)
+		if !bytes.Equal(content, replaced) {
+			op.track(path)
+			if err := fsys.WriteFile(path, replaced, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// adrTime the creation date of an ADR, falling back to its file modification time
+func adrTime(record adr.Record) time.Time {
+	if t, err := adr.ParseDate(record.Date); err == nil {
+		return t
+	}
+	if info, err := os.Stat(record.Path); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+// runFinalize finalizes the draft ADRs under the lock and reports their new numbers.
+// With commit, the renamed drafts and the ADRs whose links were updated are committed together.
+func runFinalize(ctx context.Context, repo *adr.Repository, out *reporter, command string, commit bool) error {
+	release, err := repo.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if err := repo.Reload(); err != nil {
+		return err
+	}
+	op := startOperation(repo.ConfigDir, command, []string{})
+	finalized, err := finalizeDrafts(ctx, repo, op)
+	if len(finalized) > 0 {
+		refreshToc(ctx, repo, out, finalized[0].Record, op)
+	}
+	op.done()
+	for _, draft := range finalized {
+		out.Success(draft.Draft + " is now ADR number " + strconv.Itoa(draft.Record.Number) + " : " + draft.Record.Path)
+	}
+	if err != nil || !commit || len(finalized) == 0 {
+		return err
+	}
+	return commitAdr(ctx, repo, "finalize", finalized[0].Record, op.files()...)
+}
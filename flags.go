@@ -4,6 +4,47 @@ import (
 	"github.com/urfave/cli"
 )
 
-func setFlags(app *cli.App) {
-	app.Flags = []cli.Flag{}
+func setFlags(app *cli.App, out *reporter, logs *logOptions) {
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:        "scope",
+			EnvVar:      "ADR_SCOPE",
+			Usage:       "ADR directory to work on, one of the scopes configuration or 'all' to read every scope",
+			Destination: &selectedScope,
+		},
+		cli.BoolFlag{
+			Name:        "json",
+			Usage:       "Write messages as JSON lines, with a level and a message",
+			Destination: &out.JSON,
+		},
+		cli.BoolFlag{
+			Name:        "no-color",
+			Usage:       "Disable colored messages",
+			Destination: &out.NoColor,
+		},
+		cli.BoolFlag{
+			Name:        "yes, y",
+			Usage:       "Do not ask for confirmation before overwriting or deleting files",
+			Destination: &assumeYes,
+		},
+		cli.BoolFlag{
+			Name:        "notify",
+			Usage:       "Send a desktop notification when long-running commands, like export or sync, succeed or fail",
+			Destination: &notifyWhenDone,
+		},
+		cli.StringFlag{
+			Name:        "log-level",
+			EnvVar:      "ADR_LOG_LEVEL",
+			Value:       "warn",
+			Usage:       "Level of the logs written to the standard error: debug, info, warn or error",
+			Destination: &logs.Level,
+		},
+		cli.StringFlag{
+			Name:        "log-format",
+			EnvVar:      "ADR_LOG_FORMAT",
+			Value:       "text",
+			Usage:       "Format of the logs: text or json",
+			Destination: &logs.Format,
+		},
+	}
 }
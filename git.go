@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/urfave/cli"
+)
+
+// runGit runs a git command in dir and returns its trimmed standard output
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	slog.Debug("running git", "dir", dir, "args", args)
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", errors.New("git " + args[0] + ": " + message)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// gitCommit stages the given files and commits them, and only them, with message.
+// Deleted files are committed as deletions when git was tracking them.
+func gitCommit(ctx context.Context, dir string, files []string, message string) error {
+	staged := []string{}
+	for _, file := range files {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			if tracked, _ := runGit(ctx, dir, "ls-files", "--", file); tracked == "" {
+				continue
+			}
+		}
+		staged = append(staged, file)
+	}
+	if len(staged) == 0 {
+		return nil
+	}
+	if _, err := runGit(ctx, dir, append([]string{"add", "--"}, staged...)...); err != nil {
+		return err
+	}
+	_, err := runGit(ctx, dir, append([]string{"commit", "-m", message, "--"}, staged...)...)
+	return err
+}
+
+// defaultCommitMessage renders conventional commit messages, e.g. "docs(adr): add 0042 use-postgres"
+const defaultCommitMessage = `docs(adr): {{.Operation}} {{.ID}} {{.Slug}}`
+
+// commitMessageData the fields available to commit message templates
+type commitMessageData struct {
+	Operation string
+	ID        string
+	Number    int
+	Title     string
+	Slug      string
+	Status    adr.Status
+}
+
+// commitMessage renders the commit message of an ADR operation with the commit_message configuration template
+func commitMessage(repo *adr.Repository, operation string, record adr.Record) (string, error) {
+	text := repo.Settings().CommitMessage
+	if text == "" {
+		text = defaultCommitMessage
+	}
+	tmpl, err := template.New("commit_message").Parse(text)
+	if err != nil {
+		return "", errors.New("invalid commit_message template: " + err.Error())
+	}
+	var message bytes.Buffer
+	err = tmpl.Execute(&message, commitMessageData{
+		Operation: operation,
+		ID:        record.ID(),
+		Number:    record.Number,
+		Title:     record.Title,
+		Slug:      adr.Slugify(record.Title),
+		Status:    record.Status,
+	})
+	return strings.TrimSpace(message.String()), err
+}
+
+// commitAdr commits files with the configured commit message of an ADR operation.
+// Operations touching several ADRs pass all their files so they land in a single commit.
+func commitAdr(ctx context.Context, repo *adr.Repository, operation string, record adr.Record, files ...string) error {
+	message, err := commitMessage(repo, operation, record)
+	if err != nil {
+		return err
+	}
+	return gitCommit(ctx, repo.Dir, files, message)
+}
+
+// shouldCommit tells whether an operation must be committed, the --commit flag defaults to the configuration
+func shouldCommit(c *cli.Context, repo *adr.Repository) bool {
+	if c.IsSet("commit") {
+		return c.Bool("commit")
+	}
+	return repo.Settings().AutoCommit
+}
+
+// gitAuthor reads "user.name <user.email>" from the git configuration of dir, empty when unavailable
+func gitAuthor(ctx context.Context, dir string) string {
+	name, err := runGit(ctx, dir, "config", "user.name")
+	if err != nil || name == "" {
+		return ""
+	}
+	if email, err := runGit(ctx, dir, "config", "user.email"); err == nil && email != "" {
+		return name + " <" + email + ">"
+	}
+	return name
+}
+
+// adrAuthor the author of a new ADR: the --author flag, then the configuration, then git
+func adrAuthor(ctx context.Context, c *cli.Context, repo *adr.Repository) string {
+	if c.IsSet("author") {
+		return c.String("author")
+	}
+	if author := repo.Settings().Author; author != "" {
+		return author
+	}
+	return gitAuthor(ctx, repo.Dir)
+}
+
+// gitRepositoryRoot finds the root of the git repository containing dir,
+// asking git first and walking up to a .git entry when git is not installed
+func gitRepositoryRoot(ctx context.Context, dir string) (string, error) {
+	if root, err := runGit(ctx, dir, "rev-parse", "--show-toplevel"); err == nil {
+		return root, nil
+	}
+	for current := dir; ; current = filepath.Dir(current) {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current, nil
+		}
+		if filepath.Dir(current) == current {
+			return "", errors.New(dir + " is not inside a git repository")
+		}
+	}
+}
+
+// defaultBaseDir <repo-root>/docs/adr when run inside a git repository, ~/adr otherwise
+func defaultBaseDir(ctx context.Context, paths adrPaths) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return paths.DefaultBaseDir()
+	}
+	root, err := gitRepositoryRoot(ctx, cwd)
+	if err != nil {
+		return paths.DefaultBaseDir()
+	}
+	return filepath.Join(root, "docs", "adr")
+}
+
+// adrBranchName the branch to draft an ADR on, e.g. "adr/0042-use-postgres"
+func adrBranchName(record adr.Record) string {
+	return "adr/" + record.ID() + "-" + adr.Slugify(record.Title)
+}
+
+// createBranch creates a branch in the repository holding dir and switches to it,
+// uncommitted changes such as a freshly written ADR follow along
+func createBranch(ctx context.Context, dir string, branch string) error {
+	_, err := runGit(ctx, dir, "checkout", "-b", branch)
+	return err
+}
+
+// changedFiles lists the absolute paths of the files under dir added or modified in a git diff range such as origin/main...HEAD
+func changedFiles(ctx context.Context, dir string, diffRange string) ([]string, error) {
+	root, err := gitRepositoryRoot(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	out, err := runGit(ctx, dir, "diff", "--name-only", "--diff-filter=d", diffRange, "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, name := range strings.Split(out, "\n") {
+		if name != "" {
+			files = append(files, filepath.Join(root, name))
+		}
+	}
+	return files, nil
+}
+
+// lastCommit returns the author and date of the last commit that touched a file
+func lastCommit(ctx context.Context, path string) (string, string, error) {
+	out, err := runGit(ctx, filepath.Dir(path), "log", "-1", "--date=short", "--format=%an%x1f%ad", "--", filepath.Base(path))
+	if err != nil {
+		return "", "", err
+	}
+	fields := strings.Split(out, "\x1f")
+	if len(fields) < 2 {
+		return "", "", errors.New(path + " has not been committed yet")
+	}
+	return fields[0], fields[1], nil
+}
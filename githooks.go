@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/marouni/adr/pkg/lint"
+)
+
+// gitHookMarker identifies the git hooks written by adr, so they can be safely replaced
+const gitHookMarker = "# installed by adr hooks install"
+
+// supportedGitHooks the git hooks adr knows how to install
+var supportedGitHooks = []string{"pre-commit", "pre-push", "post-merge"}
+
+// installGitHook writes a git hook running "adr hooks run <hook>" in the repository holding baseDir.
+// Hooks that were not written by adr are only replaced with force.
+func installGitHook(ctx context.Context, baseDir string, hook string, force bool) (string, error) {
+	if !isSupportedGitHook(hook) {
+		return "", errors.New("unsupported git hook " + hook + ", supported hooks are " + strings.Join(supportedGitHooks, ", "))
+	}
+	hooksDir, err := runGit(ctx, baseDir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(baseDir, hooksDir)
+	}
+	hookPath := filepath.Join(hooksDir, hook)
+	if existing, err := ioutil.ReadFile(hookPath); err == nil && !strings.Contains(string(existing), gitHookMarker) && !force {
+		return "", errors.New(hookPath + " already exists, use --force to replace it")
+	}
+
+	script := "#!/bin/sh\n" + gitHookMarker + "\nexec adr hooks run " + hook + "\n"
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return "", err
+	}
+	return hookPath, ioutil.WriteFile(hookPath, []byte(script), 0755)
+}
+
+func isSupportedGitHook(hook string) bool {
+	for _, supported := range supportedGitHooks {
+		if hook == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// runGitHook runs the checks of a git hook, the lint rules numbering and index ones included, returning an error when
+// the hook must block git. The pre-commit hook first brings the table of contents up to date in the commit, the
+// post-merge hook finalizes the draft ADRs merged into the main branch instead.
+func runGitHook(ctx context.Context, repo *adr.Repository, out *reporter, hook string) error {
+	if hook == "post-merge" {
+		if !isMainBranch(ctx, repo.Dir) {
+			return nil
+		}
+		return runFinalize(ctx, repo, out, "hooks run post-merge", false)
+	}
+	if hook == "pre-commit" {
+		if err := stageIndex(ctx, repo, out); err != nil {
+			return err
+		}
+	}
+	records, err := readScopedAdrs(ctx, repo)
+	if err != nil {
+		return err
+	}
+	findings := lint.Run(repo.FS, records, lint.Rules(), lintOptions(ctx, repo)...)
+	if problems := reportFindings(out, findings); problems > 0 {
+		return errors.New("adr " + hook + " hook failed with " + pluralize(problems, "problem"))
+	}
+	return nil
+}
+
+// stageIndex regenerates the table of contents of repo when it is out of date and adds it to the commit being made.
+// A table of contents written by hand is left as it is.
+func stageIndex(ctx context.Context, repo *adr.Repository, out *reporter) error {
+	path := indexPath(repo)
+	if path == "" {
+		return nil
+	}
+	current, err := repo.FS.ReadFile(path)
+	if err == nil && !strings.HasPrefix(string(current), adr.TocMarker) {
+		return nil
+	}
+	toc, err := repo.RenderToc(ctx)
+	if err != nil || string(toc) == string(current) {
+		return err
+	}
+	if _, err := repo.WriteToc(ctx, filepath.Base(path), false); err != nil {
+		return err
+	}
+	if _, err := runGit(ctx, repo.Dir, "add", "--", path); err != nil {
+		return err
+	}
+	out.Info("The table of contents " + path + " was updated and added to the commit")
+	return nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+var (
+	// githubShortRefRegexp an issue or discussion written owner/repo#123
+	githubShortRefRegexp = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)#(\d+)$`)
+	// githubURLRegexp the page of an issue, pull request or discussion, on github.com or GitHub Enterprise
+	githubURLRegexp = regexp.MustCompile(`^https?://[^/]+/([\w.-]+)/([\w.-]+)/(issues|pull|discussions)/(\d+)`)
+)
+
+// Kinds of GitHub references
+const (
+	githubIssue      = "issue"
+	githubDiscussion = "discussion"
+)
+
+// githubRef an issue or a discussion of a GitHub repository, its kind is empty when a short reference does not tell
+type githubRef struct {
+	Owner  string
+	Repo   string
+	Number int
+	Kind   string
+}
+
+func (r githubRef) String() string {
+	return r.Owner + "/" + r.Repo + "#" + strconv.Itoa(r.Number)
+}
+
+// parseGitHubRef parses owner/repo#123 or the URL of an issue, a pull request or a discussion
+func parseGitHubRef(arg string) (githubRef, error) {
+	if m := githubShortRefRegexp.FindStringSubmatch(arg); m != nil {
+		number, _ := strconv.Atoi(m[3])
+		return githubRef{Owner: m[1], Repo: m[2], Number: number}, nil
+	}
+	if m := githubURLRegexp.FindStringSubmatch(arg); m != nil {
+		number, _ := strconv.Atoi(m[4])
+		kind := githubIssue
+		if m[3] == "discussions" {
+			kind = githubDiscussion
+		}
+		return githubRef{Owner: m[1], Repo: m[2], Number: number, Kind: kind}, nil
+	}
+	return githubRef{}, errors.New("invalid GitHub reference '" + arg + "', expected owner/repo#123 or the URL of an issue or discussion")
+}
+
+// githubClient calls the REST and GraphQL APIs of GitHub, or of the GitHub Enterprise server of GITHUB_API_URL,
+// with the token of GITHUB_TOKEN or GH_TOKEN
+type githubClient struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+func newGitHubClient() *githubClient {
+	apiURL := strings.TrimSuffix(os.Getenv("GITHUB_API_URL"), "/")
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	return &githubClient{apiURL: apiURL, token: token, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// githubError an error answer of the GitHub API
+type githubError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *githubError) Error() string {
+	return fmt.Sprintf("GitHub answered %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// rest sends a request with body encoded to JSON to the REST API and decodes the JSON answer into result
+func (g *githubClient) rest(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	return g.do(ctx, method, g.apiURL+path, body, result)
+}
+
+// graphQL runs query with variables and decodes its data into data, the errors of the query failing it
+func (g *githubClient) graphQL(ctx context.Context, query string, variables map[string]interface{}, data interface{}) error {
+	if g.token == "" {
+		return errors.New("the GitHub GraphQL API needs a token, set GITHUB_TOKEN or GH_TOKEN")
+	}
+	// GitHub Enterprise serves REST at /api/v3 and GraphQL at /api/graphql
+	endpoint := strings.TrimSuffix(g.apiURL, "/v3") + "/graphql"
+	response := struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}{}
+	err := g.do(ctx, http.MethodPost, endpoint, map[string]interface{}{"query": query, "variables": variables}, &response)
+	if err != nil {
+		return err
+	}
+	if len(response.Errors) > 0 {
+		messages := []string{}
+		for _, e := range response.Errors {
+			messages = append(messages, e.Message)
+		}
+		return errors.New("GitHub GraphQL API: " + strings.Join(messages, ", "))
+	}
+	return json.Unmarshal(response.Data, data)
+}
+
+func (g *githubClient) do(ctx context.Context, method string, url string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	request.Header.Set("Accept", "application/vnd.github+json")
+	if g.token != "" {
+		request.Header.Set("Authorization", "Bearer "+g.token)
+	}
+	response, err := g.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode >= 300 {
+		answer := struct {
+			Message string `json:"message"`
+		}{}
+		if json.Unmarshal(content, &answer) != nil || answer.Message == "" {
+			answer.Message = strings.TrimSpace(string(content))
+		}
+		return &githubError{StatusCode: response.StatusCode, Message: answer.Message}
+	}
+	if result == nil || len(content) == 0 {
+		return nil
+	}
+	return json.Unmarshal(content, result)
+}
+
+// githubThread what an ADR is scaffolded from: an issue, a pull request or a discussion
+type githubThread struct {
+	Title  string
+	Body   string
+	URL    string
+	Author string
+}
+
+// Thread fetches the issue or discussion of ref, a short reference being looked up as an issue, then as a discussion
+func (g *githubClient) Thread(ctx context.Context, ref githubRef) (githubThread, error) {
+	if ref.Kind != githubDiscussion {
+		issue := struct {
+			Title   string `json:"title"`
+			Body    string `json:"body"`
+			HTMLURL string `json:"html_url"`
+			User    struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		}{}
+		err := g.rest(ctx, http.MethodGet, "/repos/"+ref.Owner+"/"+ref.Repo+"/issues/"+strconv.Itoa(ref.Number), nil, &issue)
+		var apiErr *githubError
+		notFound := errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+		if notFound && g.token == "" {
+			err = fmt.Errorf("%w, set GITHUB_TOKEN or GH_TOKEN to read private repositories and discussions", err)
+		}
+		if err == nil || ref.Kind == githubIssue || g.token == "" || !notFound {
+			return githubThread{Title: issue.Title, Body: issue.Body, URL: issue.HTMLURL, Author: issue.User.Login}, err
+		}
+	}
+	data := struct {
+		Repository struct {
+			Discussion *struct {
+				Title  string `json:"title"`
+				Body   string `json:"body"`
+				URL    string `json:"url"`
+				Author struct {
+					Login string `json:"login"`
+				} `json:"author"`
+			} `json:"discussion"`
+		} `json:"repository"`
+	}{}
+	query := `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) { discussion(number: $number) { title body url author { login } } }
+}`
+	if err := g.graphQL(ctx, query, map[string]interface{}{"owner": ref.Owner, "name": ref.Repo, "number": ref.Number}, &data); err != nil {
+		return githubThread{}, err
+	}
+	discussion := data.Repository.Discussion
+	if discussion == nil {
+		return githubThread{}, errors.New("no issue nor discussion " + ref.String() + " found")
+	}
+	return githubThread{Title: discussion.Title, Body: discussion.Body, URL: discussion.URL, Author: discussion.Author.Login}, nil
+}
+
+// threadContext the context of an ADR scaffolded from thread: its body, quoted, and where it comes from
+func threadContext(ref githubRef, thread githubThread) string {
+	text := "From [" + ref.String() + "](" + thread.URL + ")"
+	if thread.Author != "" {
+		text += " by @" + thread.Author
+	}
+	text += ":\n"
+	body := strings.TrimSpace(strings.Replace(thread.Body, "\r\n", "\n", -1))
+	if body == "" {
+		return text
+	}
+	// quoted, the headings of the body do not end the section
+	for _, line := range strings.Split(body, "\n") {
+		text += "\n" + strings.TrimRight("> "+line, " ")
+	}
+	return text
+}
+
+// contextSection the title of the section of content holding the context of the decision, e.g.
+// "Context and Problem Statement" in MADR, "Context" when the template has none
+func contextSection(content []byte) string {
+	for _, section := range adr.Sections(content) {
+		if strings.HasPrefix(strings.ToLower(section), "context") {
+			return section
+		}
+	}
+	return "Context"
+}
+
+// scaffoldFromThread fills the context of a new ADR with thread and links it back to thread
+func scaffoldFromThread(ctx context.Context, repo *adr.Repository, record adr.Record, ref githubRef, thread githubThread) (adr.Record, error) {
+	return repo.Rewrite(ctx, record, func(content []byte) ([]byte, error) {
+		content = adr.SetSection(content, contextSection(content), threadContext(ref, thread))
+		return adr.AddLinkContent(content, adr.IssueLink, ref.String(), thread.URL)
+	})
+}
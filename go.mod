@@ -0,0 +1,18 @@
+module github.com/marouni/adr
+
+go 1.23.0
+
+require (
+	github.com/fatih/color v1.18.0
+	github.com/russross/blackfriday/v2 v2.1.0
+	github.com/urfave/cli v1.22.17
+	golang.org/x/term v0.24.0
+	golang.org/x/text v0.23.0
+)
+
+require (
+	github.com/cpuguy83/go-md2man/v2 v2.0.7 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	golang.org/x/sys v0.25.0 // indirect
+)
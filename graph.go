@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// graphFormats the formats adr graph writes
+var graphFormats = []string{"dot", "svg"}
+
+// renderSVG renders the DOT graph with Graphviz, failing with a hint when it is not installed
+func renderSVG(ctx context.Context, graph []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "dot", "-Tsvg")
+	cmd.Stdin = bytes.NewReader(graph)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, errors.New("dot is not installed, install Graphviz to render the graph to SVG, or write the DOT with --format dot")
+		}
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return nil, errors.New("dot: " + message)
+	}
+	return stdout.Bytes(), nil
+}
+
+// graphFormat the format of adr graph: the one given, else the extension of the output file when it is one of
+// graphFormats, else dot
+func graphFormat(format string, output string) (string, error) {
+	if format == "" {
+		format = "dot"
+		for _, known := range graphFormats {
+			if strings.HasSuffix(strings.ToLower(output), "."+known) {
+				format = known
+			}
+		}
+	}
+	for _, known := range graphFormats {
+		if format == known {
+			return format, nil
+		}
+	}
+	return "", errors.New("unknown graph format " + format + ", expected one of " + strings.Join(graphFormats, ", "))
+}
@@ -0,0 +1,801 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A small GraphQL engine for the queries of adr serve: it parses query documents with arguments, variables,
+// aliases, fragments and the @include and @skip directives, then resolves them against gqlObject values.
+// Mutations, subscriptions and introspection are not supported.
+
+// gqlArgs the arguments of a field, with the variables substituted
+type gqlArgs map[string]interface{}
+
+// gqlResolver computes the value of a field: a scalar, a gqlObject, a slice of either, or nil
+type gqlResolver func(args gqlArgs) (interface{}, error)
+
+// gqlObject the fields of an object, only the selected fields are resolved
+type gqlObject struct {
+	typeName string
+	fields   map[string]gqlResolver
+}
+
+// gqlField a field of a selection set
+type gqlField struct {
+	alias      string
+	name       string
+	args       map[string]gqlValue
+	directives []gqlDirective
+	selection  []gqlSelection
+}
+
+// gqlSelection a field, a fragment spread (spread set) or an inline fragment (selection set)
+type gqlSelection struct {
+	field      *gqlField
+	spread     string
+	inline     []gqlSelection
+	directives []gqlDirective
+}
+
+type gqlDirective struct {
+	name string
+	args map[string]gqlValue
+}
+
+// gqlValue a literal of the query, variables are gqlVariable values
+type gqlValue interface{}
+
+type gqlVariable string
+
+type gqlEnum string
+
+// gqlOperation an operation of the document
+type gqlOperation struct {
+	kind      string
+	name      string
+	defaults  map[string]gqlValue
+	selection []gqlSelection
+}
+
+// gqlDocument a parsed query document
+type gqlDocument struct {
+	operations []gqlOperation
+	fragments  map[string][]gqlSelection
+}
+
+// gqlError an error of the response, in the GraphQL format
+type gqlError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// gqlResponse the response to a GraphQL request
+type gqlResponse struct {
+	Data   *gqlOrdered `json:"data,omitempty"`
+	Errors []gqlError  `json:"errors,omitempty"`
+}
+
+// gqlOrdered a JSON object keeping the order of its keys, as GraphQL responses follow the order of the query
+type gqlOrdered struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrdered() *gqlOrdered {
+	return &gqlOrdered{values: map[string]interface{}{}}
+}
+
+func (o *gqlOrdered) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// MarshalJSON implements json.Marshaler
+func (o *gqlOrdered) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		value, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(name)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// gqlParser a recursive descent parser of query documents
+type gqlParser struct {
+	source []rune
+	pos    int
+}
+
+// parseGraphQL parses a query document
+func parseGraphQL(query string) (*gqlDocument, error) {
+	p := &gqlParser{source: []rune(query)}
+	document := &gqlDocument{fragments: map[string][]gqlSelection{}}
+	for {
+		p.skipIgnored()
+		if p.pos >= len(p.source) {
+			break
+		}
+		if p.peek() == '{' {
+			selection, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			document.operations = append(document.operations, gqlOperation{kind: "query", selection: selection})
+			continue
+		}
+		keyword, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		switch keyword {
+		case "query", "mutation", "subscription":
+			operation, err := p.operation(keyword)
+			if err != nil {
+				return nil, err
+			}
+			document.operations = append(document.operations, operation)
+		case "fragment":
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if on, err := p.name(); err != nil || on != "on" {
+				return nil, p.errorf("expected 'on' after fragment %s", name)
+			}
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+			if _, defined := document.fragments[name]; defined {
+				return nil, fmt.Errorf("there are several fragments named %s", name)
+			}
+			if document.fragments[name], err = p.selectionSet(); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.errorf("unexpected %q", keyword)
+		}
+	}
+	if len(document.operations) == 0 {
+		return nil, errors.New("the document has no operation")
+	}
+	return document, document.validate()
+}
+
+// validate checks what the executor relies on: an anonymous operation is the only one of the document, the
+// operations have distinct names, and the fragments spread exist and do not spread themselves, directly or not
+func (d *gqlDocument) validate() error {
+	names := map[string]bool{}
+	checked := map[string]bool{}
+	for _, operation := range d.operations {
+		if operation.name == "" && len(d.operations) > 1 {
+			return errors.New("an anonymous operation must be the only operation of the document")
+		}
+		if names[operation.name] {
+			return fmt.Errorf("there are several operations named %s", operation.name)
+		}
+		names[operation.name] = true
+		if err := d.checkSpreads(operation.selection, checked); err != nil {
+			return err
+		}
+	}
+	fragments := []string{}
+	for name := range d.fragments {
+		fragments = append(fragments, name)
+	}
+	sort.Strings(fragments)
+	for _, name := range fragments {
+		if err := d.checkSpreads([]gqlSelection{{spread: name}}, checked); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSpreads checks the fragments spread by selection and by the fragments they spread. checked holds the fragments
+// checked already, true, and those being checked, false, that a fragment spreading itself spreads again.
+func (d *gqlDocument) checkSpreads(selection []gqlSelection, checked map[string]bool) error {
+	for _, s := range selection {
+		var err error
+		switch {
+		case s.field != nil:
+			err = d.checkSpreads(s.field.selection, checked)
+		case s.spread != "":
+			done, seen := checked[s.spread]
+			if seen && !done {
+				return fmt.Errorf("fragment %s spreads itself", s.spread)
+			}
+			if seen {
+				continue
+			}
+			fragment, ok := d.fragments[s.spread]
+			if !ok {
+				return fmt.Errorf("unknown fragment %s", s.spread)
+			}
+			checked[s.spread] = false
+			if err = d.checkSpreads(fragment, checked); err == nil {
+				checked[s.spread] = true
+			}
+		default:
+			err = d.checkSpreads(s.inline, checked)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *gqlParser) errorf(format string, args ...interface{}) error {
+	line, column := 1, 1
+	for _, r := range p.source[:min(p.pos, len(p.source))] {
+		if r == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+	}
+	return fmt.Errorf("syntax error at %d:%d: %s", line, column, fmt.Sprintf(format, args...))
+}
+
+// skipIgnored skips white space, commas and comments
+func (p *gqlParser) skipIgnored() {
+	for p.pos < len(p.source) {
+		r := p.source[p.pos]
+		switch {
+		case r == '#':
+			for p.pos < len(p.source) && p.source[p.pos] != '\n' {
+				p.pos++
+			}
+		case unicode.IsSpace(r) || r == ',' || r == '\ufeff':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *gqlParser) peek() rune {
+	p.skipIgnored()
+	if p.pos >= len(p.source) {
+		return 0
+	}
+	return p.source[p.pos]
+}
+
+func (p *gqlParser) expect(r rune) error {
+	if p.peek() != r {
+		return p.errorf("expected %q", r)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *gqlParser) name() (string, error) {
+	p.skipIgnored()
+	start := p.pos
+	for p.pos < len(p.source) {
+		r := p.source[p.pos]
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (p.pos > start && r >= '0' && r <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if start == p.pos {
+		return "", p.errorf("expected a name")
+	}
+	return string(p.source[start:p.pos]), nil
+}
+
+func (p *gqlParser) operation(kind string) (gqlOperation, error) {
+	operation := gqlOperation{kind: kind, defaults: map[string]gqlValue{}}
+	if r := p.peek(); r != '{' && r != '(' && r != '@' {
+		name, err := p.name()
+		if err != nil {
+			return operation, err
+		}
+		operation.name = name
+	}
+	if p.peek() == '(' {
+		p.pos++
+		for p.peek() != ')' {
+			if err := p.expect('$'); err != nil {
+				return operation, err
+			}
+			name, err := p.name()
+			if err != nil {
+				return operation, err
+			}
+			if err := p.expect(':'); err != nil {
+				return operation, err
+			}
+			if err := p.skipType(); err != nil {
+				return operation, err
+			}
+			if p.peek() == '=' {
+				p.pos++
+				value, err := p.value()
+				if err != nil {
+					return operation, err
+				}
+				operation.defaults[name] = value
+			}
+		}
+		p.pos++
+	}
+	if _, err := p.directives(); err != nil {
+		return operation, err
+	}
+	selection, err := p.selectionSet()
+	operation.selection = selection
+	return operation, err
+}
+
+// skipType skips a type reference such as [String!]!, variables are not type checked
+func (p *gqlParser) skipType() error {
+	if p.peek() == '[' {
+		p.pos++
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if err := p.expect(']'); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.peek() == '!' {
+		p.pos++
+	}
+	return nil
+}
+
+func (p *gqlParser) selectionSet() ([]gqlSelection, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	selection := []gqlSelection{}
+	for p.peek() != '}' {
+		if p.peek() == 0 {
+			return nil, p.errorf("unterminated selection set")
+		}
+		if p.peek() == '.' {
+			if string(p.source[p.pos:min(p.pos+3, len(p.source))]) != "..." {
+				return nil, p.errorf("expected '...'")
+			}
+			p.pos += 3
+			s := gqlSelection{}
+			if p.peek() == '{' || p.peek() == '@' {
+				// inline fragment without type condition
+			} else {
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if name != "on" {
+					s.spread = name
+				} else if _, err := p.name(); err != nil {
+					return nil, err
+				}
+			}
+			var err error
+			if s.directives, err = p.directives(); err != nil {
+				return nil, err
+			}
+			if s.spread == "" {
+				if s.inline, err = p.selectionSet(); err != nil {
+					return nil, err
+				}
+			}
+			selection = append(selection, s)
+			continue
+		}
+		field, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		selection = append(selection, gqlSelection{field: field})
+	}
+	p.pos++
+	return selection, nil
+}
+
+func (p *gqlParser) field() (*gqlField, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	field := &gqlField{alias: name, name: name}
+	if p.peek() == ':' {
+		p.pos++
+		if field.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if field.args, err = p.arguments(); err != nil {
+		return nil, err
+	}
+	if field.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek() == '{' {
+		if field.selection, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+func (p *gqlParser) arguments() (map[string]gqlValue, error) {
+	args := map[string]gqlValue{}
+	if p.peek() != '(' {
+		return args, nil
+	}
+	p.pos++
+	for p.peek() != ')' {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		if args[name], err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+	p.pos++
+	return args, nil
+}
+
+func (p *gqlParser) directives() ([]gqlDirective, error) {
+	directives := []gqlDirective{}
+	for p.peek() == '@' {
+		p.pos++
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.arguments()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, gqlDirective{name: name, args: args})
+	}
+	return directives, nil
+}
+
+func (p *gqlParser) value() (gqlValue, error) {
+	switch r := p.peek(); {
+	case r == '$':
+		p.pos++
+		name, err := p.name()
+		return gqlVariable(name), err
+	case r == '"':
+		return p.stringValue()
+	case r == '[':
+		p.pos++
+		list := []interface{}{}
+		for p.peek() != ']' {
+			if p.peek() == 0 {
+				return nil, p.errorf("unterminated list")
+			}
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		p.pos++
+		return list, nil
+	case r == '{':
+		p.pos++
+		object := map[string]interface{}{}
+		for p.peek() != '}' {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(':'); err != nil {
+				return nil, err
+			}
+			if object[name], err = p.value(); err != nil {
+				return nil, err
+			}
+		}
+		p.pos++
+		return object, nil
+	case r == '-' || (r >= '0' && r <= '9'):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.source) && strings.ContainsRune("0123456789.eE+-", p.source[p.pos]) {
+			p.pos++
+		}
+		text := string(p.source[start:p.pos])
+		if n, err := strconv.Atoi(text); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %s", text)
+		}
+		return f, nil
+	}
+	name, err := p.name()
+	switch name {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return gqlEnum(name), err
+}
+
+func (p *gqlParser) stringValue() (string, error) {
+	p.pos++
+	var text strings.Builder
+	for p.pos < len(p.source) {
+		r := p.source[p.pos]
+		p.pos++
+		switch r {
+		case '"':
+			return text.String(), nil
+		case '\\':
+			if p.pos >= len(p.source) {
+				break
+			}
+			escaped := p.source[p.pos]
+			p.pos++
+			switch escaped {
+			case 'n':
+				text.WriteRune('\n')
+			case 't':
+				text.WriteRune('\t')
+			case 'r':
+				text.WriteRune('\r')
+			case 'b':
+				text.WriteRune('\b')
+			case 'f':
+				text.WriteRune('\f')
+			case 'u':
+				if p.pos+4 > len(p.source) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(string(p.source[p.pos:p.pos+4]), 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				text.WriteRune(rune(code))
+				p.pos += 4
+			default:
+				text.WriteRune(escaped)
+			}
+		case '\n':
+			return "", p.errorf("unterminated string")
+		default:
+			text.WriteRune(r)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// gqlExecutor resolves the operation of a document
+type gqlExecutor struct {
+	document  *gqlDocument
+	variables map[string]interface{}
+	errors    []gqlError
+}
+
+// executeGraphQL runs the operation named operationName, or the only operation, of a query against root
+func executeGraphQL(root gqlObject, query string, operationName string, variables map[string]interface{}) gqlResponse {
+	document, err := parseGraphQL(query)
+	if err != nil {
+		return gqlResponse{Errors: []gqlError{{Message: err.Error()}}}
+	}
+	var operation *gqlOperation
+	for i := range document.operations {
+		if document.operations[i].name == operationName || (operationName == "" && len(document.operations) == 1) {
+			operation = &document.operations[i]
+		}
+	}
+	if operation == nil {
+		return gqlResponse{Errors: []gqlError{{Message: "select the operation to run with operationName"}}}
+	}
+	if operation.kind != "query" {
+		return gqlResponse{Errors: []gqlError{{Message: operation.kind + " operations are not supported, only queries"}}}
+	}
+	e := &gqlExecutor{document: document, variables: map[string]interface{}{}}
+	for name, value := range operation.defaults {
+		e.variables[name] = value
+	}
+	for name, value := range variables {
+		e.variables[name] = value
+	}
+	data := e.selectObject(root, operation.selection, nil)
+	return gqlResponse{Data: data, Errors: e.errors}
+}
+
+// resolveValue substitutes the variables of a literal
+func (e *gqlExecutor) resolveValue(value gqlValue) interface{} {
+	switch v := value.(type) {
+	case gqlVariable:
+		return e.variables[string(v)]
+	case gqlEnum:
+		return string(v)
+	case []interface{}:
+		resolved := []interface{}{}
+		for _, item := range v {
+			resolved = append(resolved, e.resolveValue(item))
+		}
+		return resolved
+	case map[string]interface{}:
+		resolved := map[string]interface{}{}
+		for name, item := range v {
+			resolved[name] = e.resolveValue(item)
+		}
+		return resolved
+	}
+	return value
+}
+
+// included applies the @include and @skip directives
+func (e *gqlExecutor) included(directives []gqlDirective) bool {
+	for _, directive := range directives {
+		condition, _ := e.resolveValue(directive.args["if"]).(bool)
+		if (directive.name == "include" && !condition) || (directive.name == "skip" && condition) {
+			return false
+		}
+	}
+	return true
+}
+
+// fields flattens the fragments of a selection set into its fields, the document is validated already
+func (e *gqlExecutor) fields(selection []gqlSelection) []*gqlField {
+	fields := []*gqlField{}
+	for _, s := range selection {
+		switch {
+		case s.field != nil:
+			if e.included(s.field.directives) {
+				fields = append(fields, s.field)
+			}
+		case s.spread != "":
+			if e.included(s.directives) {
+				fields = append(fields, e.fields(e.document.fragments[s.spread])...)
+			}
+		default:
+			if e.included(s.directives) {
+				fields = append(fields, e.fields(s.inline)...)
+			}
+		}
+	}
+	return fields
+}
+
+func (e *gqlExecutor) fail(path []interface{}, message string) {
+	e.errors = append(e.errors, gqlError{Message: message, Path: append([]interface{}{}, path...)})
+}
+
+func (e *gqlExecutor) selectObject(object gqlObject, selection []gqlSelection, path []interface{}) *gqlOrdered {
+	result := newOrdered()
+	for _, field := range e.fields(selection) {
+		fieldPath := append(append([]interface{}{}, path...), field.alias)
+		if field.name == "__typename" {
+			result.set(field.alias, object.typeName)
+			continue
+		}
+		resolver, ok := object.fields[field.name]
+		if !ok {
+			e.fail(fieldPath, fmt.Sprintf("cannot query field %q on type %s", field.name, object.typeName))
+			continue
+		}
+		args := gqlArgs{}
+		for name, value := range field.args {
+			args[name] = e.resolveValue(value)
+		}
+		value, err := resolver(args)
+		if err != nil {
+			e.fail(fieldPath, err.Error())
+			result.set(field.alias, nil)
+			continue
+		}
+		result.set(field.alias, e.complete(value, field, fieldPath))
+	}
+	return result
+}
+
+// complete selects the fields of objects, scalars are returned as is
+func (e *gqlExecutor) complete(value interface{}, field *gqlField, path []interface{}) interface{} {
+	switch v := value.(type) {
+	case gqlObject:
+		if len(field.selection) == 0 {
+			e.fail(path, fmt.Sprintf("field %q of type %s needs a selection of subfields", field.name, v.typeName))
+			return nil
+		}
+		return e.selectObject(v, field.selection, path)
+	case *gqlObject:
+		if v == nil {
+			return nil
+		}
+		return e.complete(*v, field, path)
+	case []gqlObject:
+		list := []interface{}{}
+		for i, item := range v {
+			list = append(list, e.complete(item, field, append(append([]interface{}{}, path...), i)))
+		}
+		return list
+	}
+	if len(field.selection) > 0 {
+		e.fail(path, fmt.Sprintf("field %q is a scalar, it has no subfields", field.name))
+		return nil
+	}
+	return value
+}
+
+// Helpers reading the arguments of resolvers
+
+func (a gqlArgs) string(name string) (string, error) {
+	switch v := a[name].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	}
+	return "", fmt.Errorf("argument %q must be a string", name)
+}
+
+func (a gqlArgs) strings(name string) ([]string, error) {
+	switch v := a[name].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		list := []string{}
+		for _, item := range v {
+			text, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("argument %q must be a list of strings", name)
+			}
+			list = append(list, text)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("argument %q must be a list of strings", name)
+}
+
+func (a gqlArgs) int(name string) (int, bool, error) {
+	switch v := a[name].(type) {
+	case nil:
+		return 0, false, nil
+	case int:
+		return v, true, nil
+	case float64:
+		// numbers of JSON variables
+		if v == float64(int(v)) {
+			return int(v), true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("argument %q must be an integer", name)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseGraphQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  *gqlDocument
+	}{
+		{
+			name:  "shorthand query",
+			query: "{ adrs { number title } }",
+			want: &gqlDocument{
+				operations: []gqlOperation{{kind: "query", selection: []gqlSelection{{field: &gqlField{
+					alias: "adrs", name: "adrs", args: map[string]gqlValue{}, directives: []gqlDirective{},
+					selection: []gqlSelection{
+						{field: &gqlField{alias: "number", name: "number", args: map[string]gqlValue{}, directives: []gqlDirective{}}},
+						{field: &gqlField{alias: "title", name: "title", args: map[string]gqlValue{}, directives: []gqlDirective{}}},
+					},
+				}}}}},
+				fragments: map[string][]gqlSelection{},
+			},
+		},
+		{
+			name:  "variables, aliases and arguments",
+			query: "# accepted ADRs\nquery Accepted($status: [String!] = [\"accepted\"], $limit: Int) {\n  first: adrs(status: $status, limit: 2, order: DESC, filter: {tag: \"api\", draft: false}) { title }\n}",
+			want: &gqlDocument{
+				operations: []gqlOperation{{
+					kind:     "query",
+					name:     "Accepted",
+					defaults: map[string]gqlValue{"status": []interface{}{"accepted"}},
+					selection: []gqlSelection{{field: &gqlField{
+						alias: "first",
+						name:  "adrs",
+						args: map[string]gqlValue{
+							"status": gqlVariable("status"),
+							"limit":  2,
+							"order":  gqlEnum("DESC"),
+							"filter": map[string]interface{}{"tag": "api", "draft": false},
+						},
+						directives: []gqlDirective{},
+						selection:  []gqlSelection{{field: &gqlField{alias: "title", name: "title", args: map[string]gqlValue{}, directives: []gqlDirective{}}}},
+					}}},
+				}},
+				fragments: map[string][]gqlSelection{},
+			},
+		},
+		{
+			name:  "fragments and directives",
+			query: "query { adrs { ...Fields @skip(if: $short) ... on Adr @include(if: true) { date } } } fragment Fields on Adr { title }",
+			want: &gqlDocument{
+				operations: []gqlOperation{{kind: "query", defaults: map[string]gqlValue{}, selection: []gqlSelection{{field: &gqlField{
+					alias: "adrs", name: "adrs", args: map[string]gqlValue{}, directives: []gqlDirective{},
+					selection: []gqlSelection{
+						{spread: "Fields", directives: []gqlDirective{{name: "skip", args: map[string]gqlValue{"if": gqlVariable("short")}}}},
+						{
+							inline:     []gqlSelection{{field: &gqlField{alias: "date", name: "date", args: map[string]gqlValue{}, directives: []gqlDirective{}}}},
+							directives: []gqlDirective{{name: "include", args: map[string]gqlValue{"if": true}}},
+						},
+					},
+				}}}}},
+				fragments: map[string][]gqlSelection{
+					"Fields": {{field: &gqlField{alias: "title", name: "title", args: map[string]gqlValue{}, directives: []gqlDirective{}}}},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseGraphQL(test.query)
+			if err != nil {
+				t.Fatalf("parseGraphQL() failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseGraphQL() =\n%+v\nwant\n%+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseGraphQLSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "the document has no operation"},
+		{"{ adrs { title }", "syntax error at 1:17: unterminated selection set"},
+		{"{ adrs(status: \"accepted) { title } }", "syntax error at 1:38: unterminated string"},
+		{"query {\n  adrs(limit: 1x2) { title }\n}", "syntax error at 2:18: expected ':'"},
+		{"fragment Fields Adr { title }", "syntax error at 1:20: expected 'on' after fragment Fields"},
+		{"schema { query: Query }", "syntax error at 1:7: unexpected \"schema\""},
+		{"{ adrs { .. } }", "syntax error at 1:10: expected '...'"},
+	}
+	for _, test := range tests {
+		_, err := parseGraphQL(test.query)
+		if got := errorMessage(err); got != test.want {
+			t.Errorf("parseGraphQL(%q) failed with %q, want %q", test.query, got, test.want)
+		}
+	}
+}
+
+func TestValidateGraphQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "fragment spread twice",
+			query: "{ adrs { ...Title ...Title } } fragment Title on Adr { title }",
+		},
+		{
+			name:  "fragments sharing a fragment",
+			query: "{ adrs { ...A ...B } } fragment A on Adr { ...C } fragment B on Adr { ...C } fragment C on Adr { title }",
+		},
+		{
+			name:  "named operations",
+			query: "query Titles { adrs { title } } query Numbers { adrs { number } }",
+		},
+		{
+			name:  "unknown fragment",
+			query: "{ adrs { ...Missing } }",
+			want:  "unknown fragment Missing",
+		},
+		{
+			name:  "unknown fragment in an inline fragment",
+			query: "{ adrs { ... on Adr { ...Missing } } }",
+			want:  "unknown fragment Missing",
+		},
+		{
+			name:  "fragment spreading itself",
+			query: "{ adrs { ...A } } fragment A on Adr { title ...A }",
+			want:  "fragment A spreads itself",
+		},
+		{
+			name:  "fragment cycle",
+			query: "{ adrs { ...A } } fragment A on Adr { ...B } fragment B on Adr { supersedes { ...A } }",
+			want:  "fragment A spreads itself",
+		},
+		{
+			name:  "unused fragment cycle",
+			query: "{ adrs { title } } fragment A on Adr { ...B } fragment B on Adr { ...A }",
+			want:  "fragment A spreads itself",
+		},
+		{
+			name:  "anonymous operation among others",
+			query: "{ adrs { title } } query Numbers { adrs { number } }",
+			want:  "an anonymous operation must be the only operation of the document",
+		},
+		{
+			name:  "operations of the same name",
+			query: "query Adrs { adrs { title } } query Adrs { adrs { number } }",
+			want:  "there are several operations named Adrs",
+		},
+		{
+			name:  "fragments of the same name",
+			query: "{ adrs { ...A } } fragment A on Adr { title } fragment A on Adr { number }",
+			want:  "there are several fragments named A",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseGraphQL(test.query)
+			if got := errorMessage(err); got != test.want {
+				t.Errorf("parseGraphQL() failed with %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestExecuteInvalidGraphQL(t *testing.T) {
+	root := gqlObject{typeName: "Query", fields: map[string]gqlResolver{
+		"title": func(args gqlArgs) (interface{}, error) { return "Use Go", nil },
+	}}
+	response := executeGraphQL(root, "{ ...Missing }", "", nil)
+	got, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	if want := `{"errors":[{"message":"unknown fragment Missing"}]}`; string(got) != want {
+		t.Errorf("executeGraphQL() = %s, want %s", got, want)
+	}
+}
+
+// errorMessage the message of err, empty when there is no error
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
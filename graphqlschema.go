@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// graphQLSchema documents the schema of the GraphQL endpoint, served at /api/graphql/schema
+const graphQLSchema = `type Query {
+  "ADRs matching every filter given, like adr query"
+  records(status: [String], tags: [String], since: String, until: String, text: String, category: [String], scope: [String]): [Record!]!
+  "The ADR with this number, of the repository served or of scope, and of category when given, null when there is none"
+  record(number: Int!, scope: String, category: String): Record
+  "The scopes of the ADRs, the roots of other repositories included"
+  scopes: [String!]!
+  "The categories of the ADRs, the folders of their directories"
+  categories: [String!]!
+  "The tags of the ADRs, with the number of ADRs using each of them"
+  tags: [Tag!]!
+}
+
+type Record {
+  id: String!
+  number: Int
+  draft: String
+  title: String!
+  date: String
+  author: String
+  status: String
+  format: String!
+  path: String!
+  scope: String
+  "The folder of the ADR directory the ADR is in"
+  category: String
+  tags: [String!]!
+  "The date the decision is to be reviewed, as written in the ADR"
+  reviewBy: String
+  "The date a proposed decision is to be made by, as written in the ADR"
+  deadline: String
+  "The date the decision was accepted with adr accept, YYYY-MM-DD"
+  acceptedOn: String
+  "Who must approve the decision before it is accepted"
+  approvers: [String!]!
+  "The approvals of the decision, oldest first"
+  approvals: [Approval!]!
+  "The markdown of the ADR"
+  content: String!
+  "The ADR rendered to HTML"
+  html: String!
+  links: [Link!]!
+  "The commits that touched the ADR, newest first, with the status they left it in"
+  history: [Revision!]!
+}
+
+type Link {
+  kind: String!
+  title: String
+  target: String!
+  "The linked ADR, null when the link points outside of the ADRs"
+  record: Record
+}
+
+type Revision {
+  hash: String!
+  date: String!
+  author: String!
+  subject: String!
+  status: String
+  fromStatus: String
+  statusChanged: Boolean!
+}
+
+type Approval {
+  by: String!
+  date: String!
+}
+
+type Tag {
+  name: String!
+  count: Int!
+  records: [Record!]!
+}
+`
+
+// graphQLRequest the body of a GraphQL request
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// graphQLRoot the Query object of a request, reading the ADRs at most once
+type graphQLRoot struct {
+	ctx      context.Context
+	registry *registry
+	once     sync.Once
+	records  []adr.Record
+	err      error
+}
+
+func (g *graphQLRoot) all() ([]adr.Record, error) {
+	g.once.Do(func() {
+		g.records, g.err = g.registry.Query(g.ctx, adr.Query{})
+	})
+	return g.records, g.err
+}
+
+func (g *graphQLRoot) object() gqlObject {
+	return gqlObject{typeName: "Query", fields: map[string]gqlResolver{
+		"records": func(args gqlArgs) (interface{}, error) {
+			statuses, err := args.strings("status")
+			if err != nil {
+				return nil, err
+			}
+			tags, err := args.strings("tags")
+			if err != nil {
+				return nil, err
+			}
+			filters := map[string]string{}
+			for _, name := range []string{"since", "until", "text"} {
+				if filters[name], err = args.string(name); err != nil {
+					return nil, err
+				}
+			}
+			q, err := newQuery(statuses, tags, filters["since"], filters["until"], filters["text"], "")
+			if err != nil {
+				return nil, err
+			}
+			if q.Categories, err = args.strings("category"); err != nil {
+				return nil, err
+			}
+			all, err := g.all()
+			if err != nil {
+				return nil, err
+			}
+			records, err := q.Filter(g.registry.repo.FS, all)
+			if err != nil {
+				return nil, err
+			}
+			scopes, err := args.strings("scope")
+			return g.recordObjects(inScopes(records, scopes)), err
+		},
+		"record": func(args gqlArgs) (interface{}, error) {
+			number, ok, err := args.int("number")
+			if err != nil || !ok {
+				return nil, errors.New("argument \"number\" is required and must be an integer")
+			}
+			scope, err := args.string("scope")
+			if err != nil {
+				return nil, err
+			}
+			category, err := args.string("category")
+			if err != nil {
+				return nil, err
+			}
+			record, err := g.registry.Find(g.ctx, scope, category, number)
+			if errors.Is(err, adr.ErrAdrNotFound) {
+				return (*gqlObject)(nil), nil
+			} else if err != nil {
+				return nil, err
+			}
+			object := g.recordObject(record)
+			return &object, nil
+		},
+		"scopes": constant(g.registry.Scopes()),
+		"categories": func(gqlArgs) (interface{}, error) {
+			all, err := g.all()
+			return adr.Categories(all), err
+		},
+		"tags": func(args gqlArgs) (interface{}, error) {
+			all, err := g.all()
+			if err != nil {
+				return nil, err
+			}
+			byTag := map[string][]adr.Record{}
+			for _, record := range all {
+				for _, tag := range record.Tags {
+					byTag[strings.ToLower(tag)] = append(byTag[strings.ToLower(tag)], record)
+				}
+			}
+			names := []string{}
+			for name := range byTag {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			tags := []gqlObject{}
+			for _, name := range names {
+				name, records := name, byTag[name]
+				tags = append(tags, gqlObject{typeName: "Tag", fields: map[string]gqlResolver{
+					"name":    constant(name),
+					"count":   constant(len(records)),
+					"records": func(gqlArgs) (interface{}, error) { return g.recordObjects(records), nil },
+				}})
+			}
+			return tags, nil
+		},
+	}}
+}
+
+// constant resolves a field to a value known upfront
+func constant(value interface{}) gqlResolver {
+	return func(gqlArgs) (interface{}, error) { return value, nil }
+}
+
+// optional resolves empty strings to null
+func optional(value string) gqlResolver {
+	if value == "" {
+		return constant(nil)
+	}
+	return constant(value)
+}
+
+func (g *graphQLRoot) recordObjects(records []adr.Record) []gqlObject {
+	objects := []gqlObject{}
+	for _, record := range records {
+		objects = append(objects, g.recordObject(record))
+	}
+	return objects
+}
+
+func (g *graphQLRoot) recordObject(record adr.Record) gqlObject {
+	number := constant(record.Number)
+	if record.Number == 0 {
+		number = constant(nil)
+	}
+	tags := record.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	approvers := record.Approvers
+	if approvers == nil {
+		approvers = []string{}
+	}
+	approvals := []gqlObject{}
+	for _, approval := range record.Approvals {
+		approvals = append(approvals, gqlObject{typeName: "Approval", fields: map[string]gqlResolver{
+			"by":   constant(approval.By),
+			"date": constant(approval.Date),
+		}})
+	}
+	content := func() ([]byte, error) { return g.registry.repo.FS.ReadFile(record.Path) }
+	return gqlObject{typeName: "Record", fields: map[string]gqlResolver{
+		"id":         constant(record.ID()),
+		"number":     number,
+		"draft":      optional(record.Draft),
+		"title":      constant(record.Title),
+		"date":       optional(record.Date),
+		"author":     optional(record.Author),
+		"status":     optional(string(record.Status)),
+		"format":     constant(string(record.Format)),
+		"path":       constant(record.Path),
+		"scope":      optional(record.Scope),
+		"category":   optional(record.Category),
+		"tags":       constant(tags),
+		"reviewBy":   optional(record.ReviewBy),
+		"deadline":   optional(record.Deadline),
+		"acceptedOn": optional(record.AcceptedOn),
+		"approvers":  constant(approvers),
+		"approvals":  constant(approvals),
+		"content": func(gqlArgs) (interface{}, error) {
+			text, err := content()
+			return string(text), err
+		},
+		"html": func(gqlArgs) (interface{}, error) {
+			text, err := content()
+			return string(renderHTML(text)), err
+		},
+		"links": func(gqlArgs) (interface{}, error) {
+			links := []gqlObject{}
+			for _, link := range record.Links {
+				links = append(links, g.linkObject(record, link))
+			}
+			return links, nil
+		},
+		"history": func(gqlArgs) (interface{}, error) {
+			revisions, err := adrHistory(g.ctx, record)
+			objects := []gqlObject{}
+			for _, revision := range revisions {
+				objects = append(objects, gqlObject{typeName: "Revision", fields: map[string]gqlResolver{
+					"hash":          constant(revision.Hash),
+					"date":          constant(revision.Date),
+					"author":        constant(revision.Author),
+					"subject":       constant(revision.Subject),
+					"status":        optional(string(revision.Status)),
+					"fromStatus":    optional(string(revision.FromStatus)),
+					"statusChanged": constant(revision.StatusChanged()),
+				}})
+			}
+			return objects, err
+		},
+	}}
+}
+
+func (g *graphQLRoot) linkObject(record adr.Record, link adr.Link) gqlObject {
+	return gqlObject{typeName: "Link", fields: map[string]gqlResolver{
+		"kind":   constant(link.Kind),
+		"title":  optional(link.Title),
+		"target": constant(link.Target),
+		"record": func(gqlArgs) (interface{}, error) {
+			path, ok := record.LinkedPath(link)
+			if !ok {
+				return (*gqlObject)(nil), nil
+			}
+			all, err := g.all()
+			if err != nil {
+				return nil, err
+			}
+			for _, other := range all {
+				if filepath.Clean(other.Path) == path {
+					object := g.recordObject(other)
+					return &object, nil
+				}
+			}
+			return (*gqlObject)(nil), nil
+		},
+	}}
+}
@@ -1,137 +1,209 @@
 package main
 
 import (
-	"encoding/json"
-	"html/template"
-	"io/ioutil"
+	"context"
+	"errors"
+	"log/slog"
 	"os"
-	"os/user"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
-	"time"
 
-	"github.com/fatih/color"
+	"github.com/marouni/adr/pkg/adr"
 )
 
-// AdrConfig ADR configuration, loaded and used by each sub-command
-type AdrConfig struct {
-	BaseDir    string `json:"base_directory"`
-	CurrentAdr int    `json:"current_id"`
-}
+var adrConfigFolderName = ".adr"
 
-// Adr basic structure
-type Adr struct {
-	Number int
-	Title  string
-	Date   string
-	Status AdrStatus
+// portableGitignore the files of a .adr folder kept in a repository that are local to each checkout,
+// the configuration, templates and hooks being shared
+const portableGitignore = `# written by adr, local to this checkout
+journal.jsonl
+.lock
+index.json
+translations/
+`
+
+// adrPaths locates the adr configuration folder and the default base directory, it is built once by main
+type adrPaths struct {
+	HomeDir   string
+	ConfigDir string
+	// Portable tells whether ConfigDir is the .adr folder of a repository rather than of the home directory
+	Portable bool
 }
 
-// AdrStatus type
-type AdrStatus string
-
-// ADR status enums
-const (
-	PROPOSED   AdrStatus = "Proposed"
-	ACCEPTED   AdrStatus = "Accepted"
-	DEPRECATED AdrStatus = "Deprecated"
-	SUPERSEDED AdrStatus = "Superseded"
-)
-
-var usr, err = user.Current()
-var adrConfigFolderName = ".adr"
-var adrConfigFileName = "config.json"
-var adrConfigTemplateName = "template.md"
-var adrConfigFolderPath = filepath.Join(usr.HomeDir, adrConfigFolderName)
-var adrConfigFilePath = filepath.Join(adrConfigFolderPath, adrConfigFileName)
-var adrTemplateFilePath = filepath.Join(adrConfigFolderPath, adrConfigTemplateName)
-var adrDefaultBaseFolder = filepath.Join(usr.HomeDir, "adr")
-
-func initBaseDir(baseDir string) {
-	if _, err := os.Stat(baseDir); os.IsNotExist(err) {
-		os.Mkdir(baseDir, 0744)
-	} else {
-		color.Red(baseDir + " already exists, skipping folder creation")
+// newPaths resolves the paths of the current user, failing when the home directory cannot be found.
+// A .adr folder of the repository holding the current folder takes precedence, see findPortableConfig, and then
+// nothing is read from the home directory. On Windows the configuration folder is %APPDATA%\adr, unless an older adr
+// already configured ~\.adr.
+func newPaths() (adrPaths, error) {
+	home, err := os.UserHomeDir()
+	if cwd, cwdErr := os.Getwd(); cwdErr == nil {
+		if configDir, ok := findPortableConfig(cwd, home); ok {
+			return adrPaths{HomeDir: home, ConfigDir: configDir, Portable: true}, nil
+		}
 	}
-}
-
-func initConfig(baseDir string) {
-	if _, err := os.Stat(adrConfigFolderPath); os.IsNotExist(err) {
-		os.Mkdir(adrConfigFolderPath, 0744)
+	if err != nil {
+		return adrPaths{}, errors.New("cannot locate the home directory holding the adr configuration: " + err.Error())
+	}
+	paths := adrPaths{HomeDir: home, ConfigDir: filepath.Join(home, adrConfigFolderName)}
+	if runtime.GOOS != "windows" {
+		return paths, nil
 	}
-	config := AdrConfig{baseDir, 0}
-	bytes, err := json.MarshalIndent(config, "", " ")
+	if _, err := os.Stat(paths.ConfigFile()); err == nil {
+		return paths, nil
+	}
+	appData, err := os.UserConfigDir()
 	if err != nil {
-		panic(err)
+		return adrPaths{}, errors.New("cannot locate the %APPDATA% folder holding the adr configuration: " + err.Error())
 	}
-	ioutil.WriteFile(adrConfigFilePath, bytes, 0644)
+	paths.ConfigDir = filepath.Join(appData, "adr")
+	return paths, nil
 }
 
-func initTemplate() {
-	body := []byte(`
-# {{.Number}}. {{.Title}}
-======
-Date: {{.Date}}
-
-## Status
-======
-{{.Status}}
-
-## Context
-======
+// findPortableConfig the .adr configuration folder kept in the repository holding dir, looked for from dir up to the
+// root of its git repository. The configuration folder of the home directory is not kept in a repository.
+func findPortableConfig(dir string, home string) (string, bool) {
+	for current := filepath.Clean(dir); ; current = filepath.Dir(current) {
+		configDir := filepath.Join(current, adrConfigFolderName)
+		if _, err := os.Stat(filepath.Join(configDir, adr.ConfigFileName)); err == nil && current != filepath.Clean(home) {
+			return configDir, true
+		}
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil || filepath.Dir(current) == current {
+			return "", false
+		}
+	}
+}
 
-## Decision
-======
+// portablePaths the paths of a configuration kept in the .adr folder of the git repository holding the current
+// folder, or of the current folder outside of git
+func portablePaths(ctx context.Context, paths adrPaths) (adrPaths, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return paths, err
+	}
+	if gitRoot, err := gitRepositoryRoot(ctx, root); err == nil {
+		root = gitRoot
+	}
+	return adrPaths{HomeDir: paths.HomeDir, ConfigDir: filepath.Join(root, adrConfigFolderName), Portable: true}, nil
+}
 
-## Consequences
-======
+// portableBaseDir baseDir relative to the root of the repository holding the portable configuration, so that it is
+// the same in every checkout; it is left as is outside of the repository
+func (p adrPaths) portableBaseDir(baseDir string) string {
+	abs, err := filepath.Abs(baseDir)
+	if err != nil {
+		return baseDir
+	}
+	rel, err := filepath.Rel(filepath.Dir(p.ConfigDir), abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return baseDir
+	}
+	return filepath.ToSlash(rel)
+}
 
-`)
+// ConfigFile the path of config.json
+func (p adrPaths) ConfigFile() string {
+	return filepath.Join(p.ConfigDir, adr.ConfigFileName)
+}
 
-	ioutil.WriteFile(adrTemplateFilePath, body, 0644)
+// TemplateFile the path of the template of new ADRs
+func (p adrPaths) TemplateFile() string {
+	return filepath.Join(p.ConfigDir, adr.TemplateFileName)
 }
 
-func updateConfig(config AdrConfig) {
-	bytes, err := json.MarshalIndent(config, "", " ")
-	if err != nil {
-		panic(err)
+// DefaultBaseDir the base directory used outside of git repositories, docs/adr next to a portable configuration
+func (p adrPaths) DefaultBaseDir() string {
+	if p.Portable {
+		return filepath.Join(filepath.Dir(p.ConfigDir), "docs", "adr")
 	}
-	ioutil.WriteFile(adrConfigFilePath, bytes, 0644)
+	return filepath.Join(p.HomeDir, "adr")
 }
 
-func getConfig() AdrConfig {
-	var currentConfig AdrConfig
-
-	bytes, err := ioutil.ReadFile(adrConfigFilePath)
+// openRepository loads the ADR configuration for a sub-command, with the --scope selection applied.
+// It exits with a helpful message when adr is not initialized.
+func openRepository(ctx context.Context, paths adrPaths, out *reporter) *adr.Repository {
+	repo, err := adr.Open(paths.ConfigDir, adr.WithLogger(slog.Default()))
+	if errors.Is(err, adr.ErrNotInitialized) {
+		out.Error("No ADR configuration is found!")
+		out.Hint("Start by initializing ADR configuration, check 'adr init --help' for more help")
+		os.Exit(1)
+	}
+	if err == nil {
+		err = applyScope(ctx, repo, selectedScope)
+	}
 	if err != nil {
-		color.Red("No ADR configuration is found!")
-		color.HiGreen("Start by initializing ADR configuration, check 'adr init --help' for more help")
+		out.Error(err.Error())
 		os.Exit(1)
 	}
-
-	json.Unmarshal(bytes, &currentConfig)
-	return currentConfig
+	slog.Debug("ADR directory resolved", "dir", repo.Dir, "scope", repo.Scope)
+	notifySlack(ctx, repo)
+	return repo
 }
 
-func newAdr(config AdrConfig, adrName []string) {
-	adr := Adr{
-		Title:  strings.Join(adrName, " "),
-		Date:   time.Now().Format("02-01-2006 15:04:05"),
-		Number: config.CurrentAdr,
-		Status: PROPOSED,
+// createAdr writes a new ADR titled after adrName, or a draft ADR, running the new hooks and recording the operation in the journal
+func createAdr(ctx context.Context, repo *adr.Repository, out *reporter, command string, adrName []string, options adr.CreateOptions) (adr.Record, error) {
+	options.Title = strings.Join(adrName, " ")
+	if err := runHook(ctx, repo.ConfigDir, preNewHook, HookPayload{Title: options.Title, BaseDir: repo.Dir}); err != nil {
+		return adr.Record{}, err
 	}
-	template, err := template.ParseFiles(adrTemplateFilePath)
+	op := startOperation(repo.ConfigDir, command, adrName)
+	op.track(repo.ConfigPath())
+	record, err := repo.Create(ctx, options)
 	if err != nil {
-		panic(err)
+		return record, err
+	}
+	op.created(record.Path)
+	refreshToc(ctx, repo, out, record, op)
+	op.done()
+	if record.Draft != "" {
+		out.Success("Draft ADR " + record.Draft + " was successfully written to : " + record.Path)
+	} else {
+		out.Success("ADR number " + strconv.Itoa(record.Number) + " was successfully written to : " + record.Path)
 	}
-	adrFileName := strconv.Itoa(adr.Number) + "-" + strings.Join(strings.Split(strings.Trim(adr.Title, "\n \t"), " "), "-") + ".md"
-	adrFullPath := filepath.Join(config.BaseDir, adrFileName)
-	f, err := os.Create(adrFullPath)
+	trackProposedAdr(ctx, repo, out, record)
+	return record, runHook(ctx, repo.ConfigDir, postNewHook, hookPayload(repo, record))
+}
+
+// transitionAdr changes the status of an ADR, running the status-change hooks, regenerating the table of contents and
+// recording the operation in the journal
+func transitionAdr(ctx context.Context, repo *adr.Repository, command string, record adr.Record, status adr.Status) (adr.Record, error) {
+	if err := runHook(ctx, repo.ConfigDir, preStatusChangeHook, statusChangePayload(repo, record, status)); err != nil {
+		return record, err
+	}
+	op := startOperation(repo.ConfigDir, command, []string{strconv.Itoa(record.Number), string(status)})
+	op.track(record.Path)
+	updated, err := repo.Transition(ctx, record, status)
 	if err != nil {
-		panic(err)
+		op.done()
+		return updated, err
+	}
+	tocErr := updateToc(ctx, repo, updated, op)
+	op.done()
+	payload := statusChangePayload(repo, record, updated.Status)
+	payload.Path = updated.Path
+	if err := runHook(ctx, repo.ConfigDir, postStatusChangeHook, payload); err != nil {
+		return updated, err
+	}
+	if tocErr != nil {
+		return updated, errors.New("ADR " + updated.Ref() + " is " + string(updated.Status) + " but the table of contents was not updated: " + tocErr.Error())
+	}
+	return updated, nil
+}
+
+// pluralize formats a count with its noun, e.g. "1 problem" or "3 problems"
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(count) + " " + noun + "s"
+}
+
+// parseAdrNumber parses the ADR number argument of a command
+func parseAdrNumber(arg string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimLeft(arg, "0"))
+	if err != nil || number <= 0 {
+		return 0, errors.New("invalid ADR number '" + arg + "'")
 	}
-	template.Execute(f, adr)
-	f.Close()
-	color.Green("ADR number " + strconv.Itoa(adr.Number) + " was successfully written to : " + adrFullPath)
+	return number, nil
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// AdrRevision a commit that touched an ADR file, with the status the ADR had after it
+type AdrRevision struct {
+	Hash       string
+	Author     string
+	Date       string
+	Subject    string
+	Status     adr.Status
+	FromStatus adr.Status
+}
+
+// StatusChanged tells whether the commit changed the status of the ADR
+func (r AdrRevision) StatusChanged() bool {
+	return r.FromStatus != "" && r.FromStatus != r.Status
+}
+
+// adrHistory lists the commits of an ADR file, newest first, following renames.
+// Status transitions are detected by parsing the file as it was at each commit.
+func adrHistory(ctx context.Context, record adr.Record) ([]AdrRevision, error) {
+	dir := filepath.Dir(record.Path)
+	root, err := gitRepositoryRoot(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	out, err := runGit(ctx, dir, "log", "--follow", "--name-only", "--date=short",
+		"--format=%x1e%H%x1f%an%x1f%ad%x1f%s", "--", filepath.Base(record.Path))
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []AdrRevision{}
+	for _, record := range strings.Split(out, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		fields := strings.Split(lines[0], "\x1f")
+		if len(fields) < 4 {
+			continue
+		}
+		revision := AdrRevision{Hash: fields[0], Author: fields[1], Date: fields[2], Subject: fields[3]}
+		fileName := strings.TrimSpace(lines[len(lines)-1])
+		if content, err := runGit(ctx, root, "show", revision.Hash+":"+fileName); err == nil {
+			revision.Status = adr.Parse(filepath.Base(fileName), []byte(content)).Status
+		}
+		revisions = append(revisions, revision)
+	}
+
+	for i := len(revisions) - 2; i >= 0; i-- {
+		revisions[i].FromStatus = revisions[i+1].Status
+	}
+	return revisions, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+var adrHooksFolderName = "hooks"
+
+// Lifecycle events a hook script can be attached to, the script is named after the event
+const (
+	preNewHook           = "pre-new"
+	postNewHook          = "post-new"
+	preStatusChangeHook  = "pre-status-change"
+	postStatusChangeHook = "post-status-change"
+)
+
+// HookPayload describes the ADR concerned by a lifecycle event.
+// It is passed to hook scripts as JSON on stdin and as ADR_* environment variables.
+type HookPayload struct {
+	Event   string     `json:"event"`
+	Number  int        `json:"number,omitempty"`
+	Title   string     `json:"title"`
+	Status  adr.Status `json:"status,omitempty"`
+	Path    string     `json:"path,omitempty"`
+	BaseDir string     `json:"base_directory"`
+	// PreviousStatus the status the ADR changes from, for the status-change events
+	PreviousStatus adr.Status `json:"previous_status,omitempty"`
+}
+
+// runHook executes the hook script of an event, kept in the hooks folder of configDir, if there is one.
+// A failing pre-* hook aborts the operation, a failing post-* hook is reported as an error.
+func runHook(ctx context.Context, configDir string, event string, payload HookPayload) error {
+	hookPath := findHook(configDir, event)
+	info, err := os.Stat(hookPath)
+	if os.IsNotExist(err) || (err == nil && info.IsDir()) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("running hook", "event", event, "path", hookPath)
+	payload.Event = event
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, hookPath)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"ADR_EVENT="+event,
+		"ADR_NUMBER="+strconv.Itoa(payload.Number),
+		"ADR_TITLE="+payload.Title,
+		"ADR_STATUS="+string(payload.Status),
+		"ADR_PREVIOUS_STATUS="+string(payload.PreviousStatus),
+		"ADR_PATH="+payload.Path,
+		"ADR_BASE_DIR="+payload.BaseDir,
+	)
+	if err := cmd.Run(); err != nil {
+		return errors.New(event + " hook failed: " + err.Error())
+	}
+	return nil
+}
+
+// findHook the path of the hook script of an event. On Windows, where a script without an extension cannot be run,
+// the script may have one of the extensions of %PATHEXT%, e.g. post-new.cmd.
+func findHook(configDir string, event string) string {
+	hookPath := filepath.Join(configDir, adrHooksFolderName, event)
+	if runtime.GOOS != "windows" {
+		return hookPath
+	}
+	for _, extension := range windowsExecutableExtensions() {
+		if _, err := os.Stat(hookPath + extension); err == nil {
+			return hookPath + extension
+		}
+	}
+	return hookPath
+}
+
+// statusChangePayload builds the payload describing the change of the status of record from its status to status
+func statusChangePayload(repo *adr.Repository, record adr.Record, status adr.Status) HookPayload {
+	payload := hookPayload(repo, record)
+	payload.PreviousStatus, payload.Status = record.Status, status
+	return payload
+}
+
+// hookPayload builds the payload describing an existing ADR
+func hookPayload(repo *adr.Repository, record adr.Record) HookPayload {
+	return HookPayload{
+		Number:  record.Number,
+		Title:   record.Title,
+		Status:  record.Status,
+		Path:    record.Path,
+		BaseDir: repo.Dir,
+	}
+}
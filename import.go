@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// importedAdr an ADR imported from another tool, with where it comes from
+type importedAdr struct {
+	Source string
+	Record adr.Record
+}
+
+// importOptions how adr import writes the ADRs it imports
+type importOptions struct {
+	// Format the format of the ADRs to import, detected when empty, see adr.Importers
+	Format string
+	// KeepNumbers keeps the numbers of the ADRs, adr-tools' for instance, instead of numbering them after the ADRs of
+	// the repository
+	KeepNumbers bool
+	// Rewrite rewrites the ADRs after Template, see adr.Repository.ApplyTemplate
+	Rewrite  bool
+	Template string
+	Commit   bool
+}
+
+// importAdrs imports the ADRs importer reads at source into repo, in adr's own format. They are numbered after the
+// ADRs of repo, in the order of the importer, unless options keep their numbers, its drafts becoming drafts, and their
+// links point to their new files. The imported files are left as they are. It must be called while holding the lock.
+func importAdrs(ctx context.Context, repo *adr.Repository, importer adr.Importer, source string, options importOptions, op *operation) ([]importedAdr, error) {
+	sources, err := importer.Read(ctx, adr.OS, source)
+	if err != nil {
+		return nil, err
+	}
+	if options.KeepNumbers {
+		if err := keepNumbers(ctx, repo, sources, op); err != nil {
+			return nil, err
+		}
+	}
+	imported := []importedAdr{}
+	renamed := map[string]adr.Record{}
+	for _, item := range sources {
+		if err := ctx.Err(); err != nil {
+			return imported, err
+		}
+		record := item.Record
+		record.Format = adr.FormatNative
+		if record.Draft != "" {
+			record.Draft = adr.NewDraftID()
+			record.Path = filepath.Join(repo.Dir, adr.UniqueFileName(repo.FS, repo.Dir, record.Draft, record.Title))
+		} else {
+			if !options.KeepNumbers || record.Number == 0 {
+				op.track(repo.ConfigPath())
+				if record.Number, err = repo.ClaimNumber(ctx); err != nil {
+					return imported, err
+				}
+			}
+			record.Path = filepath.Join(repo.Dir, repo.FileName(record.Number, record.Title))
+		}
+		// written right away, the ADRs of a scope are numbered from the files on disk
+		op.created(record.Path)
+		if err := repo.FS.WriteFile(record.Path, adr.ConvertToNative(record, item.Content, nil), 0644); err != nil {
+			return imported, err
+		}
+		if strings.HasSuffix(item.Source, ".md") {
+			renamed[filepath.Base(item.Source)] = record
+		}
+		imported = append(imported, importedAdr{Source: item.Source, Record: record})
+	}
+	// once every ADR has its file, the links between them can follow
+	for i, item := range imported {
+		content := adr.ConvertToNative(item.Record, sources[i].Content, renamed)
+		if options.Rewrite {
+			if content, err = repo.ApplyTemplate(options.Template, item.Record, content); err != nil {
+				return imported, err
+			}
+		}
+		if err := repo.FS.WriteFile(item.Record.Path, content, 0644); err != nil {
+			return imported, err
+		}
+	}
+	return imported, nil
+}
+
+// keepNumbers checks that the numbers of the ADRs of sources are free in repo, neither taken by one of its ADRs,
+// archived ones included, nor by another of sources, and moves the counter of the configuration past them so that
+// the ADRs without a number, and the next ones, are numbered after them
+func keepNumbers(ctx context.Context, repo *adr.Repository, sources []adr.ImportedADR, op *operation) error {
+	records, err := repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	archived, err := repo.ListArchived(ctx)
+	if err != nil {
+		return err
+	}
+	taken := map[int]string{}
+	for _, record := range append(records, archived...) {
+		if record.Draft == "" && (record.Category == "" || !repo.Settings().PerCategory()) {
+			taken[record.Number] = record.Path
+		}
+	}
+	highest := repo.Settings().CurrentAdr
+	for _, item := range sources {
+		number := item.Record.Number
+		if item.Record.Draft != "" || number == 0 {
+			continue
+		}
+		if path, ok := taken[number]; ok {
+			return fmt.Errorf("cannot keep the number of %s, ADR %d is already %s; import without --keep-numbers to number the ADRs after the existing ones", item.Source, number, path)
+		}
+		taken[number] = item.Source
+		if number > highest {
+			highest = number
+		}
+	}
+	if highest == repo.Settings().CurrentAdr {
+		return nil
+	}
+	op.track(repo.ConfigPath())
+	return repo.SetCounter(highest)
+}
+
+// runImport imports the ADRs of source under the lock and reports their new numbers. The format of the ADRs is
+// detected unless given, see adr.Importers. With Commit, the imported ADRs are committed together.
+func runImport(ctx context.Context, repo *adr.Repository, out *reporter, source string, options importOptions) error {
+	if _, err := os.Stat(source); err != nil {
+		return err
+	}
+	if options.Rewrite {
+		if _, err := repo.Template(options.Template); err != nil {
+			return err
+		}
+	}
+	format := options.Format
+	var importer adr.Importer
+	if format == "" {
+		var ok bool
+		if format, importer, ok = adr.DetectImporter(adr.OS, source); !ok {
+			return fmt.Errorf("no ADRs recognized in %s, give their format with --format, one of %v", source, adr.Importers())
+		}
+	} else {
+		var err error
+		if importer, err = adr.LookupImporter(format); err != nil {
+			return err
+		}
+	}
+	out.Info("Importing the " + format + " ADRs of " + source)
+
+	release, err := repo.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if err := repo.Reload(); err != nil {
+		return err
+	}
+	op := startOperation(repo.ConfigDir, "import", []string{source})
+	imported, err := importAdrs(ctx, repo, importer, source, options, op)
+	if len(imported) > 0 {
+		refreshToc(ctx, repo, out, imported[0].Record, op)
+	}
+	op.done()
+	for _, item := range imported {
+		out.Success(filepath.Base(item.Source) + " is now ADR " + item.Record.ID() + " : " + item.Record.Path)
+	}
+	if err != nil || !options.Commit || len(imported) == 0 {
+		return err
+	}
+	return commitAdr(ctx, repo, "import", imported[0].Record, op.files()...)
+}
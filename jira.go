@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// jiraKeyRegexp the keys of Jira issues, e.g. ARCH-12
+var jiraKeyRegexp = regexp.MustCompile(`^[A-Z][A-Z0-9_]*-[0-9]+$`)
+
+// jiraClient calls the REST API, version 2, of the Jira site of the configuration
+type jiraClient struct {
+	*atlassianClient
+	config adr.JiraConfig
+}
+
+// newJiraClient a client of the Jira site of the configuration of repo
+func newJiraClient(repo *adr.Repository) (*jiraClient, error) {
+	config := repo.Settings().Jira
+	if config == nil || config.URL == "" {
+		return nil, errors.New("Jira is not configured, set the url of the \"jira\" object of " + repo.ConfigPath())
+	}
+	token := config.APIToken(os.Getenv)
+	if token == "" {
+		return nil, errors.New("the Jira API token is missing, set token or token_env in " + repo.ConfigPath())
+	}
+	return &jiraClient{atlassianClient: newAtlassianClient("Jira", config.URL, config.Email, token), config: *config}, nil
+}
+
+// browseURL the page of the issue keyed key
+func (j *jiraClient) browseURL(key string) string {
+	return j.baseURL + "/browse/" + key
+}
+
+// issueKeys the keys of the Jira issues an ADR links to
+func issueKeys(record adr.Record) []string {
+	keys := []string{}
+	for _, link := range record.Links {
+		if strings.EqualFold(link.Kind, adr.IssueLink) && jiraKeyRegexp.MatchString(link.Title) && strings.Contains(link.Target, "/browse/") {
+			keys = append(keys, link.Title)
+		}
+	}
+	return keys
+}
+
+// IssueStatus the name of the status of the issue keyed key
+func (j *jiraClient) IssueStatus(ctx context.Context, key string) (string, error) {
+	issue := struct {
+		Fields struct {
+			Status struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	}{}
+	err := j.do(ctx, http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields=status", nil, &issue)
+	return issue.Fields.Status.Name, err
+}
+
+// CreateIssue creates an issue in the project of the configuration, returning its key
+func (j *jiraClient) CreateIssue(ctx context.Context, summary string, description string) (string, error) {
+	if j.config.Project == "" {
+		return "", errors.New("the Jira project issues are created in is not configured, set project in the \"jira\" object")
+	}
+	issueType := j.config.IssueType
+	if issueType == "" {
+		issueType = "Task"
+	}
+	fields := map[string]interface{}{
+		"project":     map[string]string{"key": j.config.Project},
+		"issuetype":   map[string]string{"name": issueType},
+		"summary":     summary,
+		"description": description,
+	}
+	created := struct {
+		Key string `json:"key"`
+	}{}
+	err := j.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]interface{}{"fields": fields}, &created)
+	return created.Key, err
+}
+
+// MoveIssue moves the issue keyed key to the status named status, through the transition of its workflow leading there
+func (j *jiraClient) MoveIssue(ctx context.Context, key string, status string) error {
+	transitions := struct {
+		Transitions []struct {
+			ID string `json:"id"`
+			To struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}{}
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	if err := j.do(ctx, http.MethodGet, path, nil, &transitions); err != nil {
+		return err
+	}
+	for _, transition := range transitions.Transitions {
+		if strings.EqualFold(transition.To.Name, status) {
+			return j.do(ctx, http.MethodPost, path, map[string]interface{}{"transition": map[string]string{"id": transition.ID}}, nil)
+		}
+	}
+	return fmt.Errorf("the workflow of %s has no transition to %q from its current status", key, status)
+}
+
+// linkJiraIssue links the ADR numbered number to the issue keyed key
+func linkJiraIssue(ctx context.Context, repo *adr.Repository, jira *jiraClient, number int, key string) (adr.Record, error) {
+	if !jiraKeyRegexp.MatchString(key) {
+		return adr.Record{}, errors.New("invalid Jira issue key '" + key + "', keys look like ARCH-12")
+	}
+	op := startOperation(repo.ConfigDir, "jira link", []string{strconv.Itoa(number), key})
+	record, err := repo.Find(ctx, number)
+	if err != nil {
+		return record, err
+	}
+	op.track(record.Path)
+	record, err = repo.AddLink(ctx, number, adr.IssueLink, key, jira.browseURL(key))
+	op.done()
+	return record, err
+}
+
+// createJiraIssue creates the issue tracking an ADR and links the ADR to it
+func createJiraIssue(ctx context.Context, repo *adr.Repository, jira *jiraClient, record adr.Record) (string, error) {
+	description := "Architecture decision record " + record.ID() + ", " + string(record.Status) + ".\n\n" + repositoryPath(ctx, record.Path)
+	key, err := jira.CreateIssue(ctx, "ADR "+strconv.Itoa(record.Number)+": "+record.Title, description)
+	if err != nil {
+		return "", err
+	}
+	_, err = linkJiraIssue(ctx, repo, jira, record.Number, key)
+	return key, err
+}
+
+// trackProposedAdr creates the Jira issue of an ADR created as Proposed when the configuration asks to,
+// failures are only warned about as the ADR is written already
+func trackProposedAdr(ctx context.Context, repo *adr.Repository, out *reporter, record adr.Record) {
+	config := repo.Settings().Jira
+	if config == nil || !config.CreateOnPropose || record.Draft != "" || record.Status != adr.Proposed {
+		return
+	}
+	jira, err := newJiraClient(repo)
+	if err == nil {
+		var key string
+		if key, err = createJiraIssue(ctx, repo, jira, record); err == nil {
+			out.Success("Jira issue " + key + " tracks the ADR: " + jira.browseURL(key))
+			return
+		}
+	}
+	out.Warning("Jira issue not created: " + err.Error())
+	out.Hint("'adr jira create " + strconv.Itoa(record.Number) + "' creates it later")
+}
+
+// jiraSync what syncing an ADR with one of its issues changes: the ADR status, the issue status, or nothing
+type jiraSync struct {
+	record    adr.Record
+	key       string
+	adrStatus adr.Status
+	jiraFrom  string
+	jiraTo    string
+}
+
+// planJiraSync reconciles the status of an ADR with the status of its issue. The ADR status the issue status maps to
+// and the ADR status are compared, the one further along the lifecycle wins: accepting the ADR moves the issue,
+// closing the issue accepts the ADR.
+func planJiraSync(record adr.Record, key string, issueStatus string, statuses map[string]string) jiraSync {
+	plan := jiraSync{record: record, key: key, jiraFrom: issueStatus}
+	wanted := jiraStatus(statuses, record.Status)
+	if wanted != "" && strings.EqualFold(wanted, issueStatus) {
+		return plan
+	}
+	issueAdrStatus, mapped := adrStatusOf(statuses, issueStatus)
+	switch {
+	case mapped && lifecycleIndex(issueAdrStatus) > lifecycleIndex(record.Status):
+		plan.adrStatus = issueAdrStatus
+	case wanted != "":
+		plan.jiraTo = wanted
+	}
+	return plan
+}
+
+// jiraStatus the Jira status of the issues of the ADRs of status, empty when it is not mapped
+func jiraStatus(statuses map[string]string, status adr.Status) string {
+	for adrStatus, jiraStatus := range statuses {
+		if adr.NormalizeStatus(adrStatus) == status {
+			return jiraStatus
+		}
+	}
+	return ""
+}
+
+// adrStatusOf the ADR status of the issues in the Jira status issueStatus, the first in lifecycle order
+// when several ADR statuses map to it
+func adrStatusOf(statuses map[string]string, issueStatus string) (adr.Status, bool) {
+	for _, status := range adr.Statuses {
+		if wanted := jiraStatus(statuses, status); wanted != "" && strings.EqualFold(wanted, issueStatus) {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// lifecycleIndex the position of status in the lifecycle, -1 for unknown statuses
+func lifecycleIndex(status adr.Status) int {
+	for i, known := range adr.Statuses {
+		if known == status {
+			return i
+		}
+	}
+	return -1
+}
+
+// syncJira syncs the status of the ADRs linked to Jira issues with their issues, only printing the changes when dryRun
+func syncJira(ctx context.Context, repo *adr.Repository, out *reporter, dryRun bool) error {
+	jira, err := newJiraClient(repo)
+	if err != nil {
+		return err
+	}
+	if len(jira.config.Statuses) == 0 {
+		return errors.New("no statuses to sync, map ADR statuses to Jira statuses in the statuses of the \"jira\" object")
+	}
+	records, err := repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	changes, failures := 0, 0
+	for _, record := range records {
+		for _, key := range issueKeys(record) {
+			issueStatus, err := jira.IssueStatus(ctx, key)
+			if err != nil {
+				out.Error(record.ID() + ": " + err.Error())
+				failures++
+				continue
+			}
+			plan := planJiraSync(record, key, issueStatus, jira.config.Statuses)
+			if plan.adrStatus == "" && plan.jiraTo == "" {
+				continue
+			}
+			changes++
+			if err := applyJiraSync(ctx, repo, out, jira, plan, dryRun); err != nil {
+				out.Error(record.ID() + ": " + err.Error())
+				failures++
+			} else if plan.adrStatus != "" && !dryRun {
+				record.Status = plan.adrStatus
+			}
+		}
+	}
+	if failures > 0 {
+		return errors.New(pluralize(failures, "ADR issue") + " not synced")
+	}
+	if changes == 0 {
+		out.Success("The ADRs and their Jira issues are in sync")
+	}
+	return nil
+}
+
+// applyJiraSync moves the ADR or the issue of plan to its new status
+func applyJiraSync(ctx context.Context, repo *adr.Repository, out *reporter, jira *jiraClient, plan jiraSync, dryRun bool) error {
+	var message string
+	if plan.adrStatus != "" {
+		message = "ADR " + strconv.Itoa(plan.record.Number) + " from " + string(plan.record.Status) + " to " + string(plan.adrStatus) +
+			", as " + plan.key + " is " + plan.jiraFrom
+	} else {
+		message = plan.key + " from " + plan.jiraFrom + " to " + plan.jiraTo + ", as ADR " + strconv.Itoa(plan.record.Number) +
+			" is " + string(plan.record.Status)
+	}
+	if dryRun {
+		out.Info("Would move " + message)
+		return nil
+	}
+	var err error
+	if plan.adrStatus != "" {
+		_, err = transitionAdr(ctx, repo, "jira sync", plan.record, plan.adrStatus)
+	} else {
+		err = jira.MoveIssue(ctx, plan.key, plan.jiraTo)
+	}
+	if err != nil {
+		return err
+	}
+	out.Success("Moved " + message)
+	return nil
+}
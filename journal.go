@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var adrJournalFileName = "journal.jsonl"
+
+// JournalEntry one mutating operation, appended as a JSON line to the journal
+type JournalEntry struct {
+	Command   string        `json:"command"`
+	Args      []string      `json:"args"`
+	Files     []JournalFile `json:"files"`
+	Timestamp string        `json:"timestamp"`
+	User      string        `json:"user"`
+}
+
+// JournalFile a file touched by an operation, with its content hashes before and after.
+// An empty hash means the file did not exist.
+type JournalFile struct {
+	Path   string `json:"path"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// operation collects the files touched by a command until it is written to the journal
+type operation struct {
+	// configDir the adr configuration folder, holding the journal
+	configDir string
+	command   string
+	args      []string
+	before    map[string]string
+}
+
+func startOperation(configDir string, command string, args []string) *operation {
+	return &operation{configDir: configDir, command: command, args: args, before: map[string]string{}}
+}
+
+// track remembers the current hash of a file that is about to change
+func (op *operation) track(path string) {
+	if _, tracked := op.before[path]; !tracked {
+		op.before[path] = hashFile(path)
+	}
+}
+
+// created records a file that did not exist before the operation
+func (op *operation) created(path string) {
+	op.before[path] = ""
+}
+
+// files lists the files touched by the operation, outside of the adr configuration folder
+func (op *operation) files() []string {
+	files := []string{}
+	for path := range op.before {
+		if !strings.HasPrefix(absPath(path), absPath(op.configDir)+string(filepath.Separator)) {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// done appends the operation to the journal, files that did not change are left out
+func (op *operation) done() {
+	entry := JournalEntry{
+		Command:   op.command,
+		Args:      op.args,
+		Files:     []JournalFile{},
+		Timestamp: time.Now().Format(time.RFC3339),
+		User:      currentUser(),
+	}
+	for path, before := range op.before {
+		after := hashFile(path)
+		if after != before {
+			entry.Files = append(entry.Files, JournalFile{Path: path, Before: before, After: after})
+		}
+	}
+	sort.Slice(entry.Files, func(i, j int) bool { return entry.Files[i].Path < entry.Files[j].Path })
+	appendJournal(filepath.Join(op.configDir, adrJournalFileName), entry)
+}
+
+// currentUser the login of the user running adr, for the journal
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+func appendJournal(journalPath string, entry JournalEntry) {
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		panic(err)
+	}
+	slog.Debug("appending to the journal", "path", journalPath, "command", entry.Command, "files", len(entry.Files))
+	f, err := os.OpenFile(journalPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.Warn("cannot write the journal", "path", journalPath, "error", err)
+		return
+	}
+	f.Write(append(bytes, '\n'))
+	f.Close()
+}
+
+func hashFile(path string) string {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(bytes)
+	return hex.EncodeToString(sum[:])
+}
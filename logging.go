@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// logOptions the global --log-level and --log-format flags.
+// Logs are debugging details written to the standard error, separate from the messages of the reporter.
+type logOptions struct {
+	Level  string
+	Format string
+}
+
+// logger builds the logger selected by the options, writing to w
+func (o logOptions) logger(w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(o.Level)); err != nil {
+		return nil, errors.New("invalid --log-level " + o.Level + ", expected debug, info, warn or error")
+	}
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(o.Format) {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, handlerOptions)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, handlerOptions)), nil
+	default:
+		return nil, errors.New("invalid --log-format " + o.Format + ", expected text or json")
+	}
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/urfave/cli"
 )
@@ -14,11 +18,37 @@ func main() {
 	app.Usage = "Work with Architecture Decision Records (ADRs)"
 	app.Version = "0.1.0"
 
-	setFlags(app)
-	setCommands(app)
-
-	err := app.Run(os.Args)
+	paths, err := newPaths()
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	// the first Ctrl-C cancels the running command, a second one kills adr
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	out := newReporter()
+	logs := &logOptions{}
+	setFlags(app, out, logs)
+	registerExporters()
+	setCommands(ctx, app, paths, out)
+	app.Before = func(c *cli.Context) error {
+		logger, err := logs.logger(os.Stderr)
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+		slog.SetDefault(logger)
+		slog.Debug("paths resolved", "home", paths.HomeDir, "config_dir", paths.ConfigDir, "portable", paths.Portable)
+		return nil
+	}
+
+	err = app.Run(os.Args)
+	pendingNotifications.Wait()
+	if err != nil {
+		out.Error(err.Error())
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/marouni/adr/pkg/lint"
+)
+
+// metricsContentType the version of the Prometheus text format written at /metrics
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metricsLabelEscaper escapes label values for the Prometheus text format
+var metricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// serveMetrics the metrics of adr serve: gauges computed from the ADRs on each scrape,
+// and counters of the ADRs created and transitioned since the server started
+type serveMetrics struct {
+	ctx      context.Context
+	registry *registry
+	now      func() time.Time
+
+	mu            sync.Mutex
+	created       int
+	statusChanges map[adr.Status]int
+}
+
+// newServeMetrics counts the events of the repository of registry until the returned function is called
+func newServeMetrics(ctx context.Context, registry *registry) (*serveMetrics, func()) {
+	m := &serveMetrics{ctx: ctx, registry: registry, now: time.Now, statusChanges: map[adr.Status]int{}}
+	unsubscribe := registry.repo.Subscribe(m.count)
+	return m, unsubscribe
+}
+
+func (m *serveMetrics) count(event adr.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch e := event.(type) {
+	case adr.RecordCreated:
+		m.created++
+	case adr.StatusChanged:
+		m.statusChanges[e.To]++
+	}
+}
+
+// metric a family of samples, written with its help and type
+type metric struct {
+	name    string
+	help    string
+	kind    string
+	samples []sample
+}
+
+// sample a value of a metric, with at most one label
+type sample struct {
+	label string
+	value string
+	count float64
+}
+
+func (m metric) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+	for _, s := range m.samples {
+		if s.label == "" {
+			fmt.Fprintf(w, "%s %g\n", m.name, s.count)
+			continue
+		}
+		fmt.Fprintf(w, "%s{%s=\"%s\"} %g\n", m.name, s.label, metricsLabelEscaper.Replace(s.value), s.count)
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (m *serveMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	metrics, err := m.collect()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", metricsContentType)
+	for _, metric := range metrics {
+		metric.write(w)
+	}
+}
+
+// collect reads the ADRs of every scope and root and lints them
+func (m *serveMetrics) collect() ([]metric, error) {
+	records, err := m.registry.Query(m.ctx, adr.Query{})
+	if err != nil {
+		return nil, err
+	}
+	byStatus := map[adr.Status]int{}
+	drafts := 0
+	oldestProposed := time.Time{}
+	for _, record := range records {
+		if record.Number == 0 {
+			drafts++
+			continue
+		}
+		byStatus[record.Status]++
+		if record.Status != adr.Proposed {
+			continue
+		}
+		if date, err := adr.ParseDate(record.Date); err == nil && (oldestProposed.IsZero() || date.Before(oldestProposed)) {
+			oldestProposed = date
+		}
+	}
+	findings := map[string]int{}
+	for _, rule := range lint.Rules() {
+		findings[rule.Name] = 0
+	}
+	for _, finding := range lint.Run(m.registry.repo.FS, records, lint.Rules(), lintOptions(m.ctx, m.registry.repo)...) {
+		findings[finding.Rule]++
+	}
+
+	m.mu.Lock()
+	created, statusChanges := m.created, map[adr.Status]int{}
+	for status, count := range m.statusChanges {
+		statusChanges[status] = count
+	}
+	m.mu.Unlock()
+
+	metrics := []metric{
+		{name: "adr_records", help: "Number of ADRs by status, drafts excluded.", kind: "gauge", samples: statusSamples(byStatus)},
+		{name: "adr_drafts", help: "Number of drafts, ADRs not numbered yet.", kind: "gauge", samples: []sample{{count: float64(drafts)}}},
+	}
+	if !oldestProposed.IsZero() {
+		metrics = append(metrics, metric{name: "adr_oldest_proposed_age_seconds", help: "Age of the oldest Proposed ADR, from its date.", kind: "gauge",
+			samples: []sample{{count: m.now().Sub(oldestProposed).Round(time.Second).Seconds()}}})
+	}
+	metrics = append(metrics,
+		metric{name: "adr_lint_findings", help: "Number of lint findings by rule.", kind: "gauge", samples: countSamples("rule", findings)},
+		metric{name: "adr_records_created_total", help: "Number of ADRs created through this server.", kind: "counter", samples: []sample{{count: float64(created)}}},
+		metric{name: "adr_status_changes_total", help: "Number of status changes through this server, by new status.", kind: "counter", samples: statusSamples(statusChanges)},
+	)
+	return metrics, nil
+}
+
+// statusSamples one sample by status, in lifecycle order then the statuses unknown to adr
+func statusSamples(counts map[adr.Status]int) []sample {
+	byName := map[string]int{}
+	for status, count := range counts {
+		byName[string(status)] = count
+	}
+	samples := []sample{}
+	for _, status := range adr.Statuses {
+		samples = append(samples, sample{label: "status", value: string(status), count: float64(byName[string(status)])})
+		delete(byName, string(status))
+	}
+	return append(samples, countSamples("status", byName)...)
+}
+
+// countSamples one sample by label value, sorted by value
+func countSamples(label string, counts map[string]int) []sample {
+	values := []string{}
+	for value := range counts {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	samples := []sample{}
+	for _, value := range values {
+		samples = append(samples, sample{label: label, value: value, count: float64(counts[value])})
+	}
+	return samples
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// notifyWhenDone sends a desktop notification when a long-running command ends, set by the global --notify flag
+var notifyWhenDone bool
+
+// desktopNotification the command showing a desktop notification on the current operating system
+func desktopNotification(ctx context.Context, title string, message string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		script := "display notification " + appleScriptString(message) + " with title " + appleScriptString(title)
+		return exec.CommandContext(ctx, "osascript", "-e", script)
+	case "windows":
+		script := "[void][Reflection.Assembly]::LoadWithPartialName('System.Windows.Forms');" +
+			"$n = New-Object System.Windows.Forms.NotifyIcon; $n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true;" +
+			"$n.ShowBalloonTip(5000, " + powerShellString(title) + ", " + powerShellString(message) + ", 'Info'); Start-Sleep -Seconds 5; $n.Dispose()"
+		return exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", script)
+	default:
+		return exec.CommandContext(ctx, "notify-send", "--app-name=adr", title, message)
+	}
+}
+
+func appleScriptString(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
+
+func powerShellString(text string) string {
+	return "'" + strings.Replace(text, "'", "''", -1) + "'"
+}
+
+// notifyOutcome tells the desktop that a command succeeded or failed, when --notify or the notify configuration
+// asks for it. Notifications are best effort, failing to show one is only logged.
+func notifyOutcome(ctx context.Context, repo *adr.Repository, command string, err error) {
+	if !notifyWhenDone && (repo == nil || !repo.Settings().Notify) {
+		return
+	}
+	title, message := command+" succeeded", "adr "+command+" is done"
+	if err != nil {
+		title, message = command+" failed", err.Error()
+	}
+	// the notification is shown even once ctx is cancelled, it tells about the cancellation
+	if err := desktopNotification(context.WithoutCancel(ctx), "adr "+title, message).Run(); err != nil {
+		slog.Debug("desktop notification failed", "error", err)
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// Limits of the Notion API
+const (
+	notionVersion       = "2022-06-28"
+	notionBlocksPerCall = 100
+	notionTextLength    = 2000
+)
+
+var (
+	notionLinkRegexp        = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)]+)\)`)
+	notionNumberedRegexp    = regexp.MustCompile(`^\d+[.)]\s+(.*)$`)
+	notionDefaultProperties = map[string]string{"title": "Name", "status": "Status", "date": "Date", "tags": "Tags"}
+)
+
+// notionPublisher syncs ADRs to the pages of a Notion database, their status, date and tags being page properties
+type notionPublisher struct {
+	apiURL string
+	config adr.NotionConfig
+	token  string
+	client *http.Client
+}
+
+func newNotionPublisher(repo *adr.Repository) (publisher, error) {
+	config := repo.Settings().Notion
+	if config == nil || config.DatabaseID == "" {
+		return nil, errors.New("Notion is not configured, set the database_id of the \"notion\" object of " + repo.ConfigPath())
+	}
+	token := config.APIToken(os.Getenv)
+	if token == "" {
+		return nil, errors.New("the Notion token is missing, set token or token_env in " + repo.ConfigPath())
+	}
+	// NOTION_API_URL points to another server, e.g. a proxy
+	apiURL := strings.TrimSuffix(os.Getenv("NOTION_API_URL"), "/")
+	if apiURL == "" {
+		apiURL = "https://api.notion.com/v1"
+	}
+	return &notionPublisher{apiURL: apiURL, config: *config, token: token, client: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+// Wants syncs every ADR
+func (p *notionPublisher) Wants(record adr.Record) bool {
+	return true
+}
+
+// notionError an error answer of the Notion API
+type notionError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *notionError) Error() string {
+	return fmt.Sprintf("Notion answered %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Publish creates the page of record, or updates the properties of the page id and replaces its blocks.
+// A page deleted from Notion, or archived, is created again.
+func (p *notionPublisher) Publish(ctx context.Context, record adr.Record, content []byte, id string) (string, string, error) {
+	blocks := notionBlocks(string(htmlRule.ReplaceAll(adrTitleRegexp.ReplaceAll(content, nil), []byte("$1"))))
+	page := struct {
+		ID       string `json:"id"`
+		URL      string `json:"url"`
+		Archived bool   `json:"archived"`
+	}{}
+	if id != "" {
+		err := p.do(ctx, http.MethodGet, "/pages/"+id, nil, &page)
+		var apiErr *notionError
+		switch {
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+			id = ""
+		case err != nil:
+			return "", "", err
+		case page.Archived:
+			id = ""
+		}
+	}
+	if id == "" {
+		first := blocks
+		if len(first) > notionBlocksPerCall {
+			first = first[:notionBlocksPerCall]
+		}
+		body := map[string]interface{}{
+			"parent":     map[string]string{"database_id": p.config.DatabaseID},
+			"properties": p.properties(record),
+			"children":   first,
+		}
+		if err := p.do(ctx, http.MethodPost, "/pages", body, &page); err != nil {
+			return "", "", err
+		}
+		return page.ID, page.URL, p.appendBlocks(ctx, page.ID, blocks[len(first):])
+	}
+	if err := p.do(ctx, http.MethodPatch, "/pages/"+id, map[string]interface{}{"properties": p.properties(record)}, &page); err != nil {
+		return "", "", err
+	}
+	if err := p.clearBlocks(ctx, id); err != nil {
+		return "", "", err
+	}
+	return page.ID, page.URL, p.appendBlocks(ctx, id, blocks)
+}
+
+// properties the properties of the page of record, named as the configuration says
+func (p *notionPublisher) properties(record adr.Record) map[string]interface{} {
+	values := map[string]interface{}{
+		"title":  map[string]interface{}{"title": notionText(pageTitle(record))},
+		"status": map[string]interface{}{"select": map[string]string{"name": string(record.Status)}},
+	}
+	if date, err := adr.ParseDate(record.Date); err == nil {
+		values["date"] = map[string]interface{}{"date": map[string]string{"start": date.Format("2006-01-02")}}
+	}
+	tags := []map[string]string{}
+	for _, tag := range record.Tags {
+		// commas are not allowed in the options of multi-select properties
+		tags = append(tags, map[string]string{"name": strings.Replace(tag, ",", " ", -1)})
+	}
+	values["tags"] = map[string]interface{}{"multi_select": tags}
+
+	properties := map[string]interface{}{}
+	for key, value := range values {
+		name, renamed := p.config.Properties[key]
+		if !renamed {
+			name = notionDefaultProperties[key]
+		}
+		if name != "" {
+			properties[name] = value
+		}
+	}
+	return properties
+}
+
+// clearBlocks deletes the blocks of the page id
+func (p *notionPublisher) clearBlocks(ctx context.Context, id string) error {
+	for {
+		children := struct {
+			Results []struct {
+				ID string `json:"id"`
+			} `json:"results"`
+			HasMore bool `json:"has_more"`
+		}{}
+		if err := p.do(ctx, http.MethodGet, "/blocks/"+id+"/children?page_size=100", nil, &children); err != nil {
+			return err
+		}
+		for _, child := range children.Results {
+			if err := p.do(ctx, http.MethodDelete, "/blocks/"+child.ID, nil, nil); err != nil {
+				return err
+			}
+		}
+		if !children.HasMore || len(children.Results) == 0 {
+			return nil
+		}
+	}
+}
+
+// appendBlocks appends blocks to the page id, notionBlocksPerCall at a time
+func (p *notionPublisher) appendBlocks(ctx context.Context, id string, blocks []map[string]interface{}) error {
+	for len(blocks) > 0 {
+		batch := blocks
+		if len(batch) > notionBlocksPerCall {
+			batch = batch[:notionBlocksPerCall]
+		}
+		if err := p.do(ctx, http.MethodPatch, "/blocks/"+id+"/children", map[string]interface{}{"children": batch}, nil); err != nil {
+			return err
+		}
+		blocks = blocks[len(batch):]
+	}
+	return nil
+}
+
+func (p *notionPublisher) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, p.apiURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	request.Header.Set("Authorization", "Bearer "+p.token)
+	request.Header.Set("Notion-Version", notionVersion)
+	response, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode >= 300 {
+		answer := struct {
+			Message string `json:"message"`
+		}{}
+		if json.Unmarshal(content, &answer) != nil || answer.Message == "" {
+			answer.Message = strings.TrimSpace(string(content))
+		}
+		return &notionError{StatusCode: response.StatusCode, Message: answer.Message}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(content, result)
+}
+
+// notionBlocks converts markdown to Notion blocks: headings, list items, quotes, code and paragraphs.
+// Inline markup is kept as text, but for the links to web pages.
+func notionBlocks(markdown string) []map[string]interface{} {
+	blocks := []map[string]interface{}{}
+	add := func(kind string, value map[string]interface{}) {
+		blocks = append(blocks, map[string]interface{}{"object": "block", "type": kind, kind: value})
+	}
+	paragraph := []string{}
+	flush := func() {
+		if len(paragraph) > 0 {
+			add("paragraph", map[string]interface{}{"rich_text": notionText(strings.Join(paragraph, " "))})
+			paragraph = nil
+		}
+	}
+	lines := strings.Split(strings.Replace(markdown, "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "```"):
+			flush()
+			language := strings.TrimSpace(strings.TrimPrefix(line, "```"))
+			code := []string{}
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			if language == "" {
+				language = "plain text"
+			}
+			add("code", map[string]interface{}{"rich_text": notionPlainText(strings.Join(code, "\n")), "language": language})
+		case strings.HasPrefix(line, "#"):
+			flush()
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			if level > 3 {
+				level = 3
+			}
+			add(fmt.Sprintf("heading_%d", level), map[string]interface{}{"rich_text": notionText(strings.TrimSpace(strings.TrimLeft(line, "#")))})
+		case strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "+ "):
+			flush()
+			add("bulleted_list_item", map[string]interface{}{"rich_text": notionText(line[2:])})
+		case notionNumberedRegexp.MatchString(line):
+			flush()
+			add("numbered_list_item", map[string]interface{}{"rich_text": notionText(notionNumberedRegexp.FindStringSubmatch(line)[1])})
+		case strings.HasPrefix(line, ">"):
+			flush()
+			add("quote", map[string]interface{}{"rich_text": notionText(strings.TrimSpace(strings.TrimPrefix(line, ">")))})
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// notionText the rich text of a line, its links to web pages being links
+func notionText(text string) []map[string]interface{} {
+	m := notionLinkRegexp.FindStringSubmatchIndex(text)
+	if m == nil {
+		return notionPlainText(text)
+	}
+	rich := notionPlainText(text[:m[0]])
+	for _, part := range notionPlainText(text[m[2]:m[3]]) {
+		part["text"].(map[string]interface{})["link"] = map[string]string{"url": text[m[4]:m[5]]}
+		rich = append(rich, part)
+	}
+	return append(rich, notionText(text[m[1]:])...)
+}
+
+// notionPlainText text as rich text, split in parts Notion accepts
+func notionPlainText(text string) []map[string]interface{} {
+	rich := []map[string]interface{}{}
+	for runes := []rune(text); len(runes) > 0; {
+		part := runes
+		if len(part) > notionTextLength {
+			part = part[:notionTextLength]
+		}
+		rich = append(rich, map[string]interface{}{"type": "text", "text": map[string]interface{}{"content": string(part)}})
+		runes = runes[len(part):]
+	}
+	return rich
+}
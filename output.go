@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+)
+
+// Message levels, they pick the color of a message, or its level field in JSON
+const (
+	levelInfo    = "info"
+	levelSuccess = "success"
+	levelWarning = "warning"
+	levelError   = "error"
+	levelHint    = "hint"
+)
+
+var levelColors = map[string]*color.Color{
+	levelSuccess: color.New(color.FgGreen),
+	levelWarning: color.New(color.FgYellow),
+	levelError:   color.New(color.FgRed),
+	levelHint:    color.New(color.FgHiGreen),
+}
+
+// reporter writes the messages of commands, colored or as JSON lines.
+// Errors go to Err, everything else to Out.
+type reporter struct {
+	Out io.Writer
+	Err io.Writer
+	// NoColor disables colors, they are also off when the standard output is not a terminal or NO_COLOR is set
+	NoColor bool
+	// JSON writes every message as a {"level": ..., "message": ...} line
+	JSON bool
+}
+
+// newReporter a reporter writing to the standard output and error
+func newReporter() *reporter {
+	return &reporter{Out: os.Stdout, Err: os.Stderr}
+}
+
+// reportedMessage a message written in JSON mode
+type reportedMessage struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+func (r *reporter) colored() bool {
+	return !r.NoColor && !r.JSON && !color.NoColor
+}
+
+func (r *reporter) write(level string, message string) {
+	w := r.Out
+	if level == levelError {
+		w = r.Err
+	}
+	if r.JSON {
+		line, _ := json.Marshal(reportedMessage{Level: level, Message: message})
+		fmt.Fprintln(w, string(line))
+		return
+	}
+	if r.colored() {
+		w = consoleWriter(w)
+		if c, ok := levelColors[level]; ok {
+			message = c.Sprint(message)
+		}
+	}
+	fmt.Fprintln(w, message)
+}
+
+// consoleWriter the writer of the colored messages written to w: the standard output and error are replaced with
+// writers that turn the ANSI colors into console colors on the Windows consoles that do not support them
+func consoleWriter(w io.Writer) io.Writer {
+	switch w {
+	case os.Stdout:
+		return color.Output
+	case os.Stderr:
+		return color.Error
+	}
+	return w
+}
+
+// Info writes a plain message, such as a line of a listing
+func (r *reporter) Info(message string) { r.write(levelInfo, message) }
+
+// Success writes the outcome of a successful operation
+func (r *reporter) Success(message string) { r.write(levelSuccess, message) }
+
+// Warning writes a message about something that needs attention but did not fail
+func (r *reporter) Warning(message string) { r.write(levelWarning, message) }
+
+// Error writes a problem, to the error writer
+func (r *reporter) Error(message string) { r.write(levelError, message) }
+
+// Hint writes a suggestion on what to do next
+func (r *reporter) Hint(message string) { r.write(levelHint, message) }
+
+// Document writes a versioned JSON document, such as adr.ListingJSON, on a single line of Out
+func (r *reporter) Document(document interface{}) error {
+	line, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(r.Out, string(line))
+	return err
+}
+
+// Highlight colors part of an Info line, it is left as is when colors are off
+func (r *reporter) Highlight(attribute color.Attribute, format string, args ...interface{}) string {
+	text := fmt.Sprintf(format, args...)
+	if !r.colored() {
+		return text
+	}
+	return color.New(attribute).Sprint(text)
+}
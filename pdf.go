@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// defaultPDFProgram the HTML to PDF converter when the pdf configuration names none
+const defaultPDFProgram = "wkhtmltopdf"
+
+// pdfStyle completes the stylesheet of the web UI for printing: each ADR starts a page
+const pdfStyle = `
+main {
+  max-width: none;
+}
+
+.adr {
+  page-break-before: always;
+}
+
+#toc li {
+  margin-bottom: 0.25rem;
+}
+`
+
+// pdfDocument the HTML printed to PDF: the ADRs, after their table of contents when there are several
+var pdfDocument = template.Must(template.New("pdf").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{if .Toc}}Architecture Decision Records{{else}}{{range .Rows}}{{.Ref}}. {{.Title}}{{end}}{{end}}</title>
+  <style>{{.Style}}</style>
+</head>
+<body>
+  <main>
+    {{- if .Toc}}
+    <h1>Architecture Decision Records</h1>
+    <nav id="toc">
+      <h2>Contents</h2>
+      <ol>
+        {{- range .Rows}}
+        <li><a href="#{{.Page}}">{{.Ref}}. {{.Title}}</a> <span class="status status-{{.Status}}">{{.Status}}</span></li>
+        {{- end}}
+      </ol>
+    </nav>
+    {{- end}}
+    {{- range .Rows}}
+    <section{{if $.Toc}} class="adr"{{end}} id="{{.Page}}">
+      <p id="adr-meta"><span class="status badge status-{{.Status}}">{{.Status}}</span>{{.Date}}{{range .Tags}} <span class="tag">{{.}}</span>{{end}}</p>
+      <article id="adr-content">{{.Content}}</article>
+    </section>
+    {{- end}}
+  </main>
+</body>
+</html>
+`))
+
+// pdfExporter prints the ADRs, rendered to HTML, to PDF with a converter program, see adr.PDFConfig
+type pdfExporter struct {
+	ctx     context.Context
+	program string
+	args    []string
+}
+
+// configureExporters gives the exporters running programs the context of the command and the configuration of repo
+func configureExporters(ctx context.Context, repo *adr.Repository) {
+	adr.RegisterExporter("pdf", newPDFExporter(ctx, repo))
+}
+
+func newPDFExporter(ctx context.Context, repo *adr.Repository) pdfExporter {
+	exporter := pdfExporter{ctx: ctx, program: defaultPDFProgram}
+	if config := repo.Settings().PDF; config != nil {
+		if config.Program != "" {
+			exporter.program = config.Program
+		}
+		exporter.args = config.Args
+	}
+	return exporter
+}
+
+// Export writes a single PDF of records, their table of contents first and each ADR on its own pages, the links
+// between ADRs leading to their pages. Drafts are left out.
+func (p pdfExporter) Export(w io.Writer, records []adr.Record) error {
+	exported := []adr.Record{}
+	anchors := map[string]string{}
+	for _, record := range records {
+		if record.Draft == "" {
+			exported = append(exported, record)
+			anchors[filepath.Clean(record.Path)] = pdfAnchor(record)
+		}
+	}
+	rows := []siteRow{}
+	for _, record := range exported {
+		row, err := pdfRow(record, anchors[filepath.Clean(record.Path)], func(linked string) string {
+			if anchors[linked] == "" {
+				return ""
+			}
+			return "#" + anchors[linked]
+		})
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+	}
+	document, err := p.print(rows, true)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(document)
+	return err
+}
+
+// Split writes a PDF per ADR into dir, in a folder per category, the links between ADRs leading to their files.
+// Drafts are left out.
+func (p pdfExporter) Split(fsys adr.FileSystem, dir string, records []adr.Record) error {
+	files := map[string]string{}
+	scopes := map[string]bool{}
+	exported := []adr.Record{}
+	for _, record := range records {
+		if record.Draft == "" {
+			exported = append(exported, record)
+			scopes[record.Scope] = true
+		}
+	}
+	for _, record := range exported {
+		files[filepath.Clean(record.Path)] = sitePagePath(record, len(scopes) > 1, ".pdf")
+	}
+	for _, record := range exported {
+		file := files[filepath.Clean(record.Path)]
+		row, err := pdfRow(record, "", func(linked string) string {
+			if files[linked] == "" {
+				return ""
+			}
+			return relativePage(file, files[linked])
+		})
+		if err != nil {
+			return err
+		}
+		document, err := p.print([]siteRow{row}, false)
+		if err != nil {
+			return errors.New(record.Path + ": " + err.Error())
+		}
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := fsys.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := fsys.WriteFile(path, document, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pdfAnchor the id of the section of record in a single PDF, e.g. "adr-platform-3"
+func pdfAnchor(record adr.Record) string {
+	ref := record.Ref()
+	if record.Scope != "" {
+		ref = record.Scope + "/" + ref
+	}
+	return "adr-" + strings.NewReplacer("/", "-", " ", "-").Replace(ref)
+}
+
+// pdfRow record as the PDF shows it, with the id anchor and its links to other ADRs pointing where href says
+func pdfRow(record adr.Record, anchor string, href func(linked string) string) (siteRow, error) {
+	content, err := os.ReadFile(record.Path)
+	if err != nil {
+		return siteRow{}, err
+	}
+	return siteRow{
+		Ref: record.Ref(), Title: record.Title, Status: record.Status, Date: record.Date, Tags: record.Tags, Page: anchor,
+		Content: template.HTML(renderHTML(siteLinks(record, content, href))),
+	}, nil
+}
+
+// print renders rows to HTML, with their table of contents when toc is set, and converts the page to PDF
+func (p pdfExporter) print(rows []siteRow, toc bool) ([]byte, error) {
+	style, err := fs.ReadFile(webAssets, "web/style.css")
+	if err != nil {
+		return nil, err
+	}
+	var page bytes.Buffer
+	err = pdfDocument.Execute(&page, struct {
+		Toc   bool
+		Style template.CSS
+		Rows  []siteRow
+	}{toc, template.CSS(string(style) + siteStyle + pdfStyle), rows})
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "adr-pdf-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	input, output := filepath.Join(tmp, "adrs.html"), filepath.Join(tmp, "adrs.pdf")
+	if err := os.WriteFile(input, page.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+	slog.Debug("converting to PDF", "program", p.program, "args", p.args)
+	cmd := exec.CommandContext(p.ctx, p.program, append(append([]string{}, p.args...), input, output)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, errors.New(p.program + " is not installed, the pdf format converts HTML to PDF with it, or with the program of the pdf configuration")
+		}
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return nil, errors.New(p.program + ": " + message)
+	}
+	return os.ReadFile(output)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/urfave/cli"
+)
+
+// pickerPageSize the number of ADRs the built-in picker shows at once
+const pickerPageSize = 10
+
+// fuzzyScore matches pattern against text like fzf does: the letters of pattern must appear in text in order,
+// ignoring case. Consecutive letters and letters starting a word score higher.
+func fuzzyScore(pattern string, text string) (int, bool) {
+	pattern = strings.ToLower(strings.Join(strings.Fields(pattern), ""))
+	text = strings.ToLower(text)
+	score := 0
+	last := -2
+	p := []rune(pattern)
+	i := 0
+	previous := ' '
+	for position, r := range []rune(text) {
+		if i < len(p) && r == p[i] {
+			score++
+			if position == last+1 {
+				score += 2
+			}
+			if !unicode.IsLetter(previous) && !unicode.IsDigit(previous) {
+				score += 3
+			}
+			last = position
+			i++
+		}
+		previous = r
+	}
+	return score, i == len(p)
+}
+
+// pickerLine describes an ADR in the picker
+func pickerLine(record adr.Record) string {
+	return fmt.Sprintf("%s. %s [%s]", record.ID(), record.Title, record.Status)
+}
+
+// fuzzyFilter the records matching pattern, best matches first
+func fuzzyFilter(records []adr.Record, pattern string) []adr.Record {
+	type match struct {
+		record adr.Record
+		score  int
+	}
+	matches := []match{}
+	for _, record := range records {
+		if score, ok := fuzzyScore(pattern, pickerLine(record)); ok {
+			matches = append(matches, match{record, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	filtered := []adr.Record{}
+	for _, m := range matches {
+		filtered = append(filtered, m.record)
+	}
+	return filtered
+}
+
+// pickAdr lets the user choose an ADR on the terminal, with fzf when it is installed and a built-in picker otherwise
+func pickAdr(ctx context.Context, repo *adr.Repository) (adr.Record, error) {
+	records, err := repo.List(ctx)
+	if err != nil {
+		return adr.Record{}, err
+	}
+	if len(records) == 0 {
+		return adr.Record{}, errors.New("there is no ADR in " + repo.Dir + " yet")
+	}
+	if _, err := exec.LookPath("fzf"); err == nil {
+		return pickWithFzf(ctx, records)
+	}
+	return pickWithPrompts(newPrompter(), records)
+}
+
+// pickWithFzf runs fzf over the ADRs, fzf draws on the terminal itself
+func pickWithFzf(ctx context.Context, records []adr.Record) (adr.Record, error) {
+	lines := []string{}
+	for _, record := range records {
+		lines = append(lines, pickerLine(record))
+	}
+	cmd := exec.CommandContext(ctx, "fzf", "--prompt", "ADR> ", "--no-sort", "--tac")
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n"))
+	cmd.Stderr = os.Stderr
+	selected, err := cmd.Output()
+	if err != nil {
+		return adr.Record{}, errors.New("no ADR selected")
+	}
+	for i, line := range lines {
+		if line == strings.TrimSpace(string(selected)) {
+			return records[i], nil
+		}
+	}
+	return adr.Record{}, errors.New("no ADR selected")
+}
+
+// pickWithPrompts narrows the ADRs down with search prompts until one is picked by its position in the list
+func pickWithPrompts(p *prompter, records []adr.Record) (adr.Record, error) {
+	search := ""
+	for {
+		matches := fuzzyFilter(records, search)
+		if len(matches) == 1 && search != "" {
+			return matches[0], nil
+		}
+		if len(matches) == 0 {
+			fmt.Fprintln(p.Out, "No ADR matches '"+search+"'")
+			matches = records
+		}
+		if len(matches) > pickerPageSize {
+			matches = matches[:pickerPageSize]
+		}
+		for i, record := range matches {
+			fmt.Fprintf(p.Out, "%3d) %s\n", i+1, pickerLine(record))
+		}
+		answer, err := p.Ask("Pick an ADR by its position, or type to search", "1")
+		if err != nil {
+			return adr.Record{}, err
+		}
+		if position, err := strconv.Atoi(answer); err == nil && position >= 1 && position <= len(matches) {
+			return matches[position-1], nil
+		}
+		search = answer
+	}
+}
+
+// targetAdr finds the ADR a command is about: the ADR numbered after its first argument,
+// or the ADR picked on the terminal when the argument is missing
+func targetAdr(ctx context.Context, c *cli.Context, repo *adr.Repository) (adr.Record, error) {
+	if c.Args().First() == "" {
+		if !interactive() {
+			return adr.Record{}, errors.New("the ADR number is missing, 'adr list' shows the numbers of the ADRs")
+		}
+		return pickAdr(ctx, repo)
+	}
+	return findAdr(ctx, repo, c.Args().First())
+}
@@ -0,0 +1,32 @@
+package adr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// AcceptedField the header field Accept stamps the acceptance date of an ADR in, e.g. "Accepted on: 2026-10-16"
+const AcceptedField = "Accepted on"
+
+// Accept accepts the ADR numbered number, stamping the date, as ReviewDateFormat, in its AcceptedField. An accepted ADR
+// is left as it is. The deprecated and superseded ADRs are only accepted again with force, it fails with ErrNotInForce
+// otherwise, and like any transition to Accepted it fails with ErrQuorumNotMet until the approvers approved the ADR.
+func (r *Repository) Accept(ctx context.Context, number int, force bool) (Record, error) {
+	record, err := r.Find(ctx, number)
+	if err != nil {
+		return record, err
+	}
+	switch {
+	case record.Status == Accepted:
+		return record, nil
+	case (record.Status == Deprecated || record.Status == Superseded) && !force:
+		return record, fmt.Errorf("%w: ADR %d is %s", ErrNotInForce, number, strings.ToLower(string(record.Status)))
+	}
+	if record, err = r.Transition(ctx, record, Accepted); err != nil {
+		return record, err
+	}
+	return r.Rewrite(ctx, record, func(content []byte) ([]byte, error) {
+		return SetField(content, AcceptedField, r.clock().Format(ReviewDateFormat))
+	})
+}
@@ -0,0 +1,135 @@
+package adr
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ApprovalsSection the section of an ADR logging its approvals, one "* Approved by <name> on <date>" line each
+const ApprovalsSection = "Approvals"
+
+// approvalRegexp a line of the approvals section
+var approvalRegexp = regexp.MustCompile(`^[*-]\s+Approved by (.+?) on (.+)$`)
+
+// Approval the approval of an ADR by one of its approvers
+type Approval struct {
+	By   string `json:"by"`
+	Date string `json:"date"`
+}
+
+// ApprovalConfig who approves the ADRs before they are accepted
+type ApprovalConfig struct {
+	// Approvers the approvers of the new ADRs, unless they name their own
+	Approvers []string `json:"approvers,omitempty"`
+	// Quorum how many approvers must approve an ADR before it is accepted, all of them when 0
+	Quorum int `json:"quorum,omitempty"`
+}
+
+// Quorum the approvals an ADR needs before it is accepted
+type Quorum struct {
+	Approvers []string
+	// Required how many of the approvers must approve the ADR
+	Required int
+	// Approved the approvers who approved the ADR
+	Approved []string
+}
+
+// Met tells whether enough approvers approved the ADR, always true for the ADRs without approvers
+func (q Quorum) Met() bool {
+	return len(q.Approved) >= q.Required
+}
+
+// Missing the approvers who did not approve the ADR yet
+func (q Quorum) Missing() []string {
+	missing := []string{}
+	for _, approver := range q.Approvers {
+		if indexFold(q.Approved, approver) < 0 {
+			missing = append(missing, approver)
+		}
+	}
+	return missing
+}
+
+func (q Quorum) String() string {
+	return fmt.Sprintf("%d of %d required approvals", len(q.Approved), q.Required)
+}
+
+// Quorum the approvals record needs before it is accepted: its Approvers field names its approvers, those of the
+// configuration approving the ADRs without one
+func (r *Repository) Quorum(record Record) Quorum {
+	config := r.Settings().Approvals
+	approvers := record.Approvers
+	if len(approvers) == 0 && config != nil {
+		approvers = config.Approvers
+	}
+	q := Quorum{Approvers: approvers, Required: len(approvers), Approved: []string{}}
+	if config != nil && config.Quorum > 0 && config.Quorum < q.Required {
+		q.Required = config.Quorum
+	}
+	for _, approval := range record.Approvals {
+		if at := indexFold(approvers, approval.By); at >= 0 && indexFold(q.Approved, approval.By) < 0 {
+			q.Approved = append(q.Approved, approvers[at])
+		}
+	}
+	return q
+}
+
+// AddApproval logs approval at the end of the approvals section of ADR content, the section being added when missing
+func AddApproval(content []byte, approval Approval) []byte {
+	record := Parse("", content)
+	lines := []string{}
+	for _, logged := range append(record.Approvals, approval) {
+		lines = append(lines, "* Approved by "+logged.By+" on "+logged.Date)
+	}
+	if !hasSection(content, ApprovalsSection) && record.Format == FormatNative {
+		// the sections of adr are underlined
+		content = []byte(strings.TrimRight(string(content), "\n") + "\n\n## " + ApprovalsSection + "\n======\n")
+	}
+	return SetSection(content, ApprovalsSection, strings.Join(lines, "\n"))
+}
+
+// Approve logs the approval of the proposed ADR numbered number by approver, one of the approvers of its Quorum
+func (r *Repository) Approve(ctx context.Context, number int, approver string) (Record, Quorum, error) {
+	record, err := r.Find(ctx, number)
+	if err != nil {
+		return Record{}, Quorum{}, err
+	}
+	q := r.Quorum(record)
+	at := indexFold(q.Approvers, approver)
+	switch {
+	case record.Status != Proposed:
+		return record, q, fmt.Errorf("ADR %d is %s, only proposed ADRs are approved", number, strings.ToLower(string(record.Status)))
+	case len(q.Approvers) == 0:
+		return record, q, fmt.Errorf("ADR %d has no approvers", number)
+	case at < 0:
+		return record, q, fmt.Errorf("%s is not an approver of ADR %d, expected one of %s", approver, number, strings.Join(q.Approvers, ", "))
+	case indexFold(q.Approved, approver) >= 0:
+		return record, q, fmt.Errorf("%s already approved ADR %d", q.Approvers[at], number)
+	}
+	approval := Approval{By: q.Approvers[at], Date: r.clock().Format(DateFormat)}
+	record, err = r.Rewrite(ctx, record, func(content []byte) ([]byte, error) {
+		return AddApproval(content, approval), nil
+	})
+	if err != nil {
+		return record, q, err
+	}
+	return record, r.Quorum(record), nil
+}
+
+// hasSection tells whether content has a "## " section titled title
+func hasSection(content []byte, title string) bool {
+	_, _, ok := sectionBody(strings.Split(string(content), "\n"), title)
+	return ok
+}
+
+// indexFold the index of the first of names equal to name, compared case-insensitively, -1 when there is none
+func indexFold(names []string, name string) int {
+	for i, n := range names {
+		if strings.EqualFold(strings.TrimSpace(n), strings.TrimSpace(name)) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,81 @@
+package adr
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ArchiveDir the folder, next to the ADRs, of the ADRs archived with adr archive. It is not a category: the archived
+// ADRs are left out of the listings, keep their numbers and are read with ReadArchive.
+const ArchiveDir = "archive"
+
+// ArchivePath the path record takes once archived, in the ArchiveDir folder of its own folder
+func ArchivePath(record Record) string {
+	return filepath.Join(filepath.Dir(record.Path), ArchiveDir, filepath.Base(record.Path))
+}
+
+// ReadArchive reads the archived ADRs of dir and of its categories, see ArchiveDir, sorted like ReadDir sorts them
+func ReadArchive(ctx context.Context, fsys FileSystem, dir string) ([]Record, error) {
+	paths, err := fsys.Glob(filepath.Join(dir, ArchiveDir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	categorized, err := fsys.Glob(filepath.Join(dir, "*", ArchiveDir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range categorized {
+		if ValidateCategory(categoryOf(dir, filepath.Dir(path))) == nil {
+			paths = append(paths, path)
+		}
+	}
+	records := []Record{}
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		record, err := ParseFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		if record.Title == "" {
+			continue
+		}
+		record.Category = categoryOf(dir, filepath.Dir(path))
+		record.Archived = true
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Number != records[j].Number {
+			return records[i].Number < records[j].Number
+		}
+		return records[i].Path < records[j].Path
+	})
+	return records, nil
+}
+
+// ListArchived returns the archived ADRs of the repository, see ArchiveDir
+func (r *Repository) ListArchived(ctx context.Context) ([]Record, error) {
+	records, err := ReadArchive(ctx, r.FS, r.Dir)
+	for i := range records {
+		records[i].Scope = r.Scope
+	}
+	return records, err
+}
+
+// ArchiveLinks rewrites the relative links of ADR content moving into the ArchiveDir folder of its folder so that they
+// point to the same files: the links to the other archived ADRs lose their archive/ prefix, the others go one folder up
+func ArchiveLinks(content []byte) []byte {
+	return markdownTargetRegexp.ReplaceAllFunc(content, func(target []byte) []byte {
+		link := string(target[2 : len(target)-1])
+		if link == "" || strings.HasPrefix(link, "#") || strings.HasPrefix(link, "/") || strings.Contains(link, ":") {
+			return target
+		}
+		if archived, ok := strings.CutPrefix(link, ArchiveDir+"/"); ok {
+			return []byte("](" + archived + ")")
+		}
+		return []byte("](../" + link + ")")
+	})
+}
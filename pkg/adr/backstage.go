@@ -0,0 +1,213 @@
+package adr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Files and annotations of the backstage export
+const (
+	BackstageCatalogFile = "catalog-info.yaml"
+	BackstageMkDocsFile  = "mkdocs.yml"
+	// BackstageADRDir the directory of the ADR pages, relative to the export directory
+	BackstageADRDir = "docs/adrs"
+)
+
+// BackstageAnnotations the annotations of the catalog entity pointing TechDocs and the ADR plugin to the export
+var BackstageAnnotations = [][2]string{
+	{"backstage.io/techdocs-ref", "dir:."},
+	{"backstage.io/adr-location", BackstageADRDir},
+}
+
+// markdownTargetRegexp the target of a markdown link, with the end of its text
+var markdownTargetRegexp = regexp.MustCompile(`\]\([^)\s]+\)`)
+
+// backstageFileRegexp the file names the Backstage ADR plugin lists, MADR style
+var backstageFileRegexp = regexp.MustCompile(`^\d{4}-.+\.md$`)
+
+// backstageExporter writes the ADRs as the TechDocs site and the ADR directory Backstage expects, see ExportDir
+type backstageExporter struct{}
+
+// Export fails, the backstage format writes a directory
+func (backstageExporter) Export(w io.Writer, records []Record) error {
+	return errors.New("the backstage format writes a directory, give it as the output")
+}
+
+// ExportDir writes into dir, typically the root of the repository of a service:
+//   - the ADRs as docs/adrs/NNNN-title.md, the file names the Backstage ADR plugin lists, with their status and date
+//     in a front matter, and the log of the ADRs as docs/adrs/index.md
+//   - mkdocs.yml and docs/index.md for TechDocs, unless they exist
+//   - the annotations of BackstageAnnotations in catalog-info.yaml, which is created when missing
+//
+// Drafts are left out, and the pages of ADRs that no longer exist are removed.
+func (backstageExporter) ExportDir(fsys FileSystem, dir string, records []Record) error {
+	adrDir := filepath.Join(dir, filepath.FromSlash(BackstageADRDir))
+	if err := fsys.MkdirAll(adrDir, 0755); err != nil {
+		return err
+	}
+	exported := []Record{}
+	renamed := map[string]string{}
+	for _, record := range records {
+		if record.Draft == "" && record.Number > 0 {
+			exported = append(exported, record)
+			renamed[filepath.Clean(record.Path)] = backstageFileName(record)
+		}
+	}
+	for _, record := range exported {
+		content, err := fsys.ReadFile(record.Path)
+		if err != nil {
+			return err
+		}
+		page := backstagePage(record, content, renamed)
+		if err := fsys.WriteFile(filepath.Join(adrDir, backstageFileName(record)), page, 0644); err != nil {
+			return err
+		}
+	}
+	existing, err := fsys.Glob(filepath.Join(adrDir, "*.md"))
+	if err != nil {
+		return err
+	}
+	kept := map[string]bool{}
+	for _, name := range renamed {
+		kept[name] = true
+	}
+	for _, path := range existing {
+		if name := filepath.Base(path); backstageFileRegexp.MatchString(name) && !kept[name] {
+			if err := fsys.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	if err := fsys.WriteFile(filepath.Join(adrDir, "index.md"), backstageLog(exported), 0644); err != nil {
+		return err
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(abs)
+	created := map[string][]byte{
+		filepath.Join(dir, BackstageMkDocsFile): []byte("site_name: " + name + "\n" +
+			"nav:\n  - Home: index.md\n  - Architecture decisions: adrs/index.md\n" +
+			"plugins:\n  - techdocs-core\n"),
+		filepath.Join(dir, "docs", "index.md"): []byte("# " + name + "\n\nSee the [architecture decisions](adrs/index.md).\n"),
+	}
+	for path, content := range created {
+		if _, err := fsys.Stat(path); err == nil {
+			continue
+		}
+		if err := fsys.WriteFile(path, content, 0644); err != nil {
+			return err
+		}
+	}
+	return annotateCatalog(fsys, filepath.Join(dir, BackstageCatalogFile), Slugify(name))
+}
+
+// backstageFileName the name of the page of an ADR, its number padded to 4 digits then its category, if any, and title
+func backstageFileName(record Record) string {
+	if record.Category != "" {
+		return fmt.Sprintf("%04d-%s-%s.md", record.Number, Slugify(record.Category), Slugify(record.Title))
+	}
+	return fmt.Sprintf("%04d-%s.md", record.Number, Slugify(record.Title))
+}
+
+// backstagePage the content of an ADR for TechDocs and the ADR plugin: with a front matter giving its status and date
+// unless it has one, without the rules under the headings of adr's own format, and its links to other ADRs renamed,
+// renamed giving the new names of the ADRs by path
+func backstagePage(record Record, content []byte, renamed map[string]string) []byte {
+	lines := strings.Split(strings.Replace(string(content), "\r\n", "\n", -1), "\n")
+	kept := []string{}
+	for i, line := range lines {
+		if i > 0 && underlineRegexp.MatchString(strings.TrimSpace(line)) && strings.HasPrefix(strings.TrimSpace(lines[i-1]), "#") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	page := strings.TrimLeft(strings.Join(kept, "\n"), "\n")
+	page = markdownTargetRegexp.ReplaceAllStringFunc(page, func(target string) string {
+		link := target[2 : len(target)-1]
+		path, ok := record.LinkedPath(Link{Target: link})
+		if !ok || renamed[path] == "" {
+			return target
+		}
+		if at := strings.Index(link, "#"); at >= 0 {
+			return "](" + renamed[path] + link[at:] + ")"
+		}
+		return "](" + renamed[path] + ")"
+	})
+	if strings.HasPrefix(page, "---\n") {
+		return []byte(page)
+	}
+	frontMatter := "---\nstatus: " + strings.ToLower(string(record.Status)) + "\n"
+	if date, err := ParseDate(record.Date); err == nil {
+		frontMatter += "date: " + date.Format("2006-01-02") + "\n"
+	}
+	if len(record.Tags) > 0 {
+		frontMatter += "tags: [" + strings.Join(record.Tags, ", ") + "]\n"
+	}
+	return []byte(frontMatter + "---\n\n" + page)
+}
+
+// backstageLog the index of the ADR pages, a table of their number, title, status and date
+func backstageLog(records []Record) []byte {
+	log := "# Architecture decisions\n\n| Number | Title | Status | Date |\n| --- | --- | --- | --- |\n"
+	for _, record := range records {
+		title := strings.Replace(record.Title, "|", `\|`, -1)
+		log += fmt.Sprintf("| %s | [%s](%s) | %s | %s |\n", record.Ref(), title, backstageFileName(record), record.Status, record.Date)
+	}
+	return []byte(log)
+}
+
+// annotateCatalog adds the annotations of BackstageAnnotations missing from the first entity of the catalog file at
+// path, or writes a component named name with them when the file does not exist
+func annotateCatalog(fsys FileSystem, path string, name string) error {
+	content, err := fsys.ReadFile(path)
+	if err != nil {
+		if _, statErr := fsys.Stat(path); statErr == nil {
+			return err
+		}
+		catalog := "apiVersion: backstage.io/v1alpha1\nkind: Component\nmetadata:\n  name: " + name + "\n  annotations:\n"
+		for _, annotation := range BackstageAnnotations {
+			catalog += "    " + annotation[0] + ": " + annotation[1] + "\n"
+		}
+		catalog += "spec:\n  type: service\n  lifecycle: production\n  owner: unknown\n"
+		return fsys.WriteFile(path, []byte(catalog), 0644)
+	}
+	lines := strings.Split(string(content), "\n")
+	metadata, annotations := -1, -1
+	for i, line := range lines {
+		if line == "---" && metadata >= 0 {
+			break
+		}
+		switch {
+		case strings.TrimRight(line, " ") == "metadata:":
+			metadata = i
+		case metadata >= 0 && strings.TrimRight(line, " ") == "  annotations:":
+			annotations = i
+		}
+	}
+	if metadata < 0 {
+		return errors.New(path + ": no metadata found to annotate")
+	}
+	missing := []string{}
+	for _, annotation := range BackstageAnnotations {
+		if !strings.Contains(string(content), annotation[0]+":") {
+			missing = append(missing, "    "+annotation[0]+": "+annotation[1])
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	at := annotations
+	if at < 0 {
+		at = metadata
+		missing = append([]string{"  annotations:"}, missing...)
+	}
+	lines = append(lines[:at+1], append(missing, lines[at+1:]...)...)
+	return fsys.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+}
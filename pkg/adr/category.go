@@ -0,0 +1,100 @@
+package adr
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Numberings of the categories, see Config.CategoryNumbering
+const (
+	// GlobalNumbering numbers the ADRs of every category in a single sequence, the default
+	GlobalNumbering = "global"
+	// PerCategoryNumbering numbers the ADRs of each category from 1, an ADR being referred to as category/number
+	PerCategoryNumbering = "category"
+)
+
+// ValidateCategory checks that category names a folder of the ADR directory: a single, visible, folder name
+func ValidateCategory(category string) error {
+	switch {
+	case strings.TrimSpace(category) == "":
+		return errors.New("the category has no name")
+	case strings.ContainsAny(category, `/\`) || category == "." || category == "..":
+		return errors.New("invalid category '" + category + "', expected a folder name such as platform")
+	case strings.HasPrefix(category, "."):
+		return errors.New("invalid category '" + category + "', hidden folders are not categories")
+	case category == ArchiveDir:
+		return errors.New("invalid category '" + category + "', the " + ArchiveDir + " folder keeps the archived ADRs")
+	}
+	if err := ValidateFileName(category); err != nil {
+		return errors.New("invalid category: " + err.Error())
+	}
+	return nil
+}
+
+// PerCategory tells whether the ADRs of each category are numbered on their own, see PerCategoryNumbering
+func (c Config) PerCategory() bool {
+	return c.CategoryNumbering == PerCategoryNumbering
+}
+
+// Categories the categories of records, sorted, the ADRs outside of any category being left out
+func Categories(records []Record) []string {
+	seen := map[string]bool{}
+	categories := []string{}
+	for _, record := range records {
+		if record.Category != "" && !seen[record.Category] {
+			seen[record.Category] = true
+			categories = append(categories, record.Category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// Ref refers to record in commands: its number, prefixed with its category when it has one, e.g. "platform/3"
+func (r Record) Ref() string {
+	ref := r.Draft
+	if ref == "" {
+		ref = strconv.Itoa(r.Number)
+	}
+	if r.Category != "" {
+		return r.Category + "/" + ref
+	}
+	return ref
+}
+
+// markdownFiles the markdown files of an ADR directory and of its categories, the folders right under it, but for
+// their tables of contents
+func markdownFiles(fsys FileSystem, dir string) ([]string, error) {
+	found, err := fsys.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	categorized, err := fsys.Glob(filepath.Join(dir, "*", "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range categorized {
+		if ValidateCategory(categoryOf(dir, path)) == nil {
+			found = append(found, path)
+		}
+	}
+	paths := []string{}
+	for _, path := range found {
+		if !IsTocFileName(filepath.Base(path)) {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// categoryOf the category of the ADR at path in dir, empty when it is right in dir
+func categoryOf(dir string, path string) string {
+	parent := filepath.Dir(filepath.Clean(path))
+	if parent == filepath.Clean(dir) {
+		return ""
+	}
+	return filepath.Base(parent)
+}
@@ -0,0 +1,306 @@
+package adr
+
+// Config the ADR configuration, stored as config.json in the configuration folder
+type Config struct {
+	BaseDir       string            `json:"base_directory"`
+	CurrentAdr    int               `json:"current_id"`
+	AutoCommit    bool              `json:"auto_commit,omitempty"`
+	Author        string            `json:"author,omitempty"`
+	CommitMessage string            `json:"commit_message,omitempty"`
+	Scopes        map[string]string `json:"scopes,omitempty"`
+	DraftBranches bool              `json:"draft_on_branches,omitempty"`
+	// CopyOnNew what adr new copies to the clipboard: "path", "reference" or nothing when empty
+	CopyOnNew string `json:"copy_on_new,omitempty"`
+	// Notify sends a desktop notification when long-running commands end
+	Notify bool `json:"notify,omitempty"`
+	// Aliases command lines run by custom command names, e.g. "ls": "query --status proposed"
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// Webhooks the URLs adr serve posts the lifecycle events of ADRs to
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+	// Credentials who may use adr serve, anyone can when there is none
+	Credentials []Credential `json:"serve_credentials,omitempty"`
+	// Slack the Slack incoming webhooks told when ADRs are created or change status
+	Slack []SlackHook `json:"slack,omitempty"`
+	// Roots the ADR directories of other repositories adr serve shows next to its own, read-only, by name
+	Roots map[string]string `json:"serve_roots,omitempty"`
+	// Jira the Jira site ADRs are tracked in, see adr jira
+	Jira *JiraConfig `json:"jira,omitempty"`
+	// Confluence the Confluence space adr publish writes the ADRs to
+	Confluence *ConfluenceConfig `json:"confluence,omitempty"`
+	// Discussions the GitHub Discussions category adr publish mirrors the decisions to
+	Discussions *DiscussionsConfig `json:"github_discussions,omitempty"`
+	// Notion the Notion database adr publish syncs the ADRs to
+	Notion *NotionConfig `json:"notion,omitempty"`
+	// Digest the SMTP server and the recipients of adr digest
+	Digest *DigestConfig `json:"digest,omitempty"`
+	// Translation the machine translation provider of adr export --lang
+	Translation *TranslationConfig `json:"translation,omitempty"`
+	// Approvals who approves the ADRs before they are accepted, see adr approve
+	Approvals *ApprovalConfig `json:"approvals,omitempty"`
+	// Diagrams the renderer of the diagrams of adr export --render-diagrams
+	Diagrams *DiagramConfig `json:"diagrams,omitempty"`
+	// PDF the HTML to PDF converter of adr export --format pdf
+	PDF *PDFConfig `json:"pdf,omitempty"`
+	// Signing the GPG key of adr sign and the signers adr verify trusts
+	Signing *SigningConfig `json:"signing,omitempty"`
+	// Toc the table of contents of adr toc
+	Toc *TocConfig `json:"toc,omitempty"`
+	// Lint the sections adr lint requires of the ADRs
+	Lint *LintConfig `json:"lint,omitempty"`
+	// Tags the taxonomy of the tags of the ADRs, any tag being allowed when it is empty
+	Tags Taxonomy `json:"tags,omitempty"`
+	// CategoryNumbering how the ADRs of the categories, the folders of the ADR directory, are numbered:
+	// GlobalNumbering when empty, or PerCategoryNumbering
+	CategoryNumbering string `json:"category_numbering,omitempty"`
+	// DefaultTemplate the template of new ADRs when none is selected, DefaultTemplateName when empty
+	DefaultTemplate string `json:"default_template,omitempty"`
+	// DateFormat the Go layout of the dates of new ADRs, DateFormat when empty, see DateLayout
+	DateFormat string `json:"date_format,omitempty"`
+	// ReviewInterval how long after their creation new ADRs are to be reviewed, e.g. 6m or 1y, see AddInterval
+	ReviewInterval string `json:"review_interval,omitempty"`
+}
+
+// Access levels of Credential
+const (
+	ReadAccess  = "read"
+	WriteAccess = "write"
+)
+
+// Credential lets a client of adr serve in, with a bearer token or a user name and password for basic authentication.
+// Secrets can be kept in environment variables, named by the *_env fields, to keep them out of the configuration.
+type Credential struct {
+	Token       string `json:"token,omitempty"`
+	TokenEnv    string `json:"token_env,omitempty"`
+	Username    string `json:"username,omitempty"`
+	Password    string `json:"password,omitempty"`
+	PasswordEnv string `json:"password_env,omitempty"`
+	// Access ReadAccess to read ADRs only, WriteAccess to create them and change their status as well
+	Access string `json:"access"`
+}
+
+// Secrets the token, user name and password of the credential, read with getenv when kept in the environment
+func (c Credential) Secrets(getenv func(string) string) (string, string, string) {
+	token, password := c.Token, c.Password
+	if c.TokenEnv != "" {
+		token = getenv(c.TokenEnv)
+	}
+	if c.PasswordEnv != "" {
+		password = getenv(c.PasswordEnv)
+	}
+	return token, c.Username, password
+}
+
+// Webhook a URL the lifecycle events of ADRs are posted to
+type Webhook struct {
+	URL string `json:"url"`
+	// Events the names of the events posted, e.g. "status_changed", every lifecycle event when empty
+	Events []string `json:"events,omitempty"`
+	// Secret signs the payloads, SecretEnv names an environment variable holding it instead,
+	// which keeps it out of the configuration
+	Secret    string `json:"secret,omitempty"`
+	SecretEnv string `json:"secret_env,omitempty"`
+}
+
+// Wants tells whether the webhook receives the event named event
+func (w Webhook) Wants(event string) bool {
+	return wants(w.Events, event)
+}
+
+// wants tells whether event is one of events, every event being wanted when there is none
+func wants(events []string, event string) bool {
+	for _, name := range events {
+		if name == event {
+			return true
+		}
+	}
+	return len(events) == 0
+}
+
+// SigningSecret the secret signing the payloads of the webhook, read with getenv when it is kept in the environment
+func (w Webhook) SigningSecret(getenv func(string) string) string {
+	if w.SecretEnv != "" {
+		return getenv(w.SecretEnv)
+	}
+	return w.Secret
+}
+
+// DefaultTemplate the template of new ADRs written by Init
+const DefaultTemplate = `
+# {{.Number}}. {{.Title}}
+======
+Date: {{.Date}}
+{{if .Author}}Author: {{.Author}}
+{{end}}{{if .Tags}}Tags: {{join .Tags ", "}}
+{{end}}{{if .ReviewBy}}Review by: {{.ReviewBy}}
+{{end}}{{if .Approvers}}Approvers: {{join .Approvers ", "}}
+{{end}}
+## Status
+======
+{{.Status}}
+
+## Context
+======
+
+## Decision
+======
+{{if .Summary}}{{.Summary}}
+{{end}}
+## Consequences
+======
+
+`
+
+// SlackHook a Slack incoming webhook, posted a message when an ADR is created or changes status
+type SlackHook struct {
+	// WebhookURL the URL of the incoming webhook, WebhookURLEnv names an environment variable holding it instead
+	WebhookURL    string `json:"webhook_url,omitempty"`
+	WebhookURLEnv string `json:"webhook_url_env,omitempty"`
+	// Events the names of the events posted, "record_created" or "status_changed", both when empty
+	Events []string `json:"events,omitempty"`
+	// Link the URL of an ADR in the messages, where {number}, {id} and {path} are replaced,
+	// e.g. "https://github.com/org/repo/blob/main/{path}"; messages link to nothing when it is empty
+	Link string `json:"link,omitempty"`
+}
+
+// Wants tells whether the event named event is posted to the webhook
+func (h SlackHook) Wants(event string) bool {
+	return wants(h.Events, event)
+}
+
+// URL the URL of the webhook, read with getenv when it is kept in the environment
+func (h SlackHook) URL(getenv func(string) string) string {
+	if h.WebhookURLEnv != "" {
+		return getenv(h.WebhookURLEnv)
+	}
+	return h.WebhookURL
+}
+
+// JiraConfig a Jira site the ADRs are linked to, their status being kept in sync with the status of their issues
+type JiraConfig struct {
+	// URL the address of the site, e.g. "https://example.atlassian.net"
+	URL string `json:"url"`
+	// Email the account of the API token on Jira Cloud; the token is sent as a personal access token
+	// to Jira Data Center when it is empty
+	Email string `json:"email,omitempty"`
+	// Token the API token, TokenEnv names an environment variable holding it instead
+	Token    string `json:"token,omitempty"`
+	TokenEnv string `json:"token_env,omitempty"`
+	// Project the key of the project the issues of new ADRs are created in, e.g. "ARCH"
+	Project string `json:"project,omitempty"`
+	// IssueType the type of the issues created, "Task" when empty
+	IssueType string `json:"issue_type,omitempty"`
+	// CreateOnPropose creates an issue in Project for each ADR created as Proposed
+	CreateOnPropose bool `json:"create_on_propose,omitempty"`
+	// Statuses the Jira status of the issues of the ADRs of each status, e.g. "Accepted": "Done";
+	// the statuses missing are not synced
+	Statuses map[string]string `json:"statuses,omitempty"`
+}
+
+// APIToken the token of the site, read with getenv when it is kept in the environment
+func (j JiraConfig) APIToken(getenv func(string) string) string {
+	if j.TokenEnv != "" {
+		return getenv(j.TokenEnv)
+	}
+	return j.Token
+}
+
+// ConfluenceConfig the Confluence space the ADRs are published to, one page each
+type ConfluenceConfig struct {
+	// URL the address of the site, with its context path, e.g. "https://example.atlassian.net/wiki"
+	URL string `json:"url"`
+	// Email the account of the API token on Confluence Cloud; the token is sent as a personal access token
+	// to Confluence Data Center when it is empty
+	Email string `json:"email,omitempty"`
+	// Token the API token, TokenEnv names an environment variable holding it instead
+	Token    string `json:"token,omitempty"`
+	TokenEnv string `json:"token_env,omitempty"`
+	// Space the key of the space of the pages, e.g. "ARCH"
+	Space string `json:"space"`
+	// ParentID the ID of the page the pages are created under, the home page of the space when empty
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+// APIToken the token of the site, read with getenv when it is kept in the environment
+func (c ConfluenceConfig) APIToken(getenv func(string) string) string {
+	if c.TokenEnv != "" {
+		return getenv(c.TokenEnv)
+	}
+	return c.Token
+}
+
+// DiscussionsConfig the GitHub Discussions category the decisions are mirrored to, one discussion each
+type DiscussionsConfig struct {
+	// Repository the repository of the discussions, e.g. "octo-org/architecture"
+	Repository string `json:"repository"`
+	// Category the name of the category of the discussions, e.g. "Decisions"
+	Category string `json:"category"`
+	// Statuses the statuses of the ADRs mirrored, Accepted when empty; ADRs mirrored once keep being updated
+	Statuses []string `json:"statuses,omitempty"`
+}
+
+// NotionConfig the Notion database the ADRs are synced to, one page each
+type NotionConfig struct {
+	// Token the secret of the Notion integration the database is shared with, TokenEnv names an environment
+	// variable holding it instead
+	Token    string `json:"token,omitempty"`
+	TokenEnv string `json:"token_env,omitempty"`
+	// DatabaseID the ID of the database, found in its URL
+	DatabaseID string `json:"database_id"`
+	// Properties renames the properties of the pages: "title", "status", "date" and "tags" are
+	// "Name", "Status", "Date" and "Tags" by default, a property renamed to "" is not set
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// APIToken the token of the integration, read with getenv when it is kept in the environment
+func (n NotionConfig) APIToken(getenv func(string) string) string {
+	if n.TokenEnv != "" {
+		return getenv(n.TokenEnv)
+	}
+	return n.Token
+}
+
+// DigestConfig how adr digest emails the activity of the ADRs
+type DigestConfig struct {
+	// SMTP the host:port of the SMTP server, port 465 meaning implicit TLS
+	SMTP string `json:"smtp"`
+	// Username and Password authenticate to the server when set, PasswordEnv names an environment variable
+	// holding the password instead
+	Username    string `json:"username,omitempty"`
+	Password    string `json:"password,omitempty"`
+	PasswordEnv string `json:"password_env,omitempty"`
+	// From the sender of the digest, To its recipients
+	From string   `json:"from"`
+	To   []string `json:"to"`
+	// Link the URL of an ADR in the digest, where {number}, {id} and {path} are replaced, as in SlackHook
+	Link string `json:"link,omitempty"`
+}
+
+// SMTPPassword the password of the SMTP server, read with getenv when it is kept in the environment
+func (d DigestConfig) SMTPPassword(getenv func(string) string) string {
+	if d.PasswordEnv != "" {
+		return getenv(d.PasswordEnv)
+	}
+	return d.Password
+}
+
+// TranslationConfig the machine translation provider translating the ADRs exported with adr export --lang
+type TranslationConfig struct {
+	// Provider "deepl" or "libretranslate"
+	Provider string `json:"provider"`
+	// URL the URL of the API: the free DeepL API by default, https://api.deepl.com for DeepL Pro,
+	// or the LibreTranslate server
+	URL string `json:"url,omitempty"`
+	// APIKey the key of the API, APIKeyEnv names an environment variable holding it instead
+	APIKey    string `json:"api_key,omitempty"`
+	APIKeyEnv string `json:"api_key_env,omitempty"`
+	// Source the language the ADRs are written in, detected by the provider when empty
+	Source string `json:"source,omitempty"`
+}
+
+// Key the key of the API, read with getenv when it is kept in the environment
+func (t TranslationConfig) Key(getenv func(string) string) string {
+	if t.APIKeyEnv != "" {
+		return getenv(t.APIKeyEnv)
+	}
+	return t.APIKey
+}
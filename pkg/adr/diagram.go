@@ -0,0 +1,143 @@
+package adr
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Diagram languages, named like the info string of their fenced code blocks
+const (
+	Mermaid  = "mermaid"
+	PlantUML = "plantuml"
+)
+
+// DiagramAssetsDir the folder, next to the ADRs, of the diagram files they link to
+const DiagramAssetsDir = "assets"
+
+// DiagramTypes the diagram languages adr scaffolds and renders
+var DiagramTypes = []string{Mermaid, PlantUML}
+
+// diagramExtensions the languages of the diagram files ADRs link to, by extension
+var diagramExtensions = map[string]string{".mmd": Mermaid, ".mermaid": Mermaid, ".puml": PlantUML, ".plantuml": PlantUML}
+
+// diagramLinkRegexp a line made of a link to a diagram file, e.g. [Deployment](assets/3-deployment.puml)
+var diagramLinkRegexp = regexp.MustCompile(`^!?\[([^\]]*)\]\(([^)\s]+\.(?:mmd|mermaid|puml|plantuml))\)$`)
+
+// DiagramConfig how adr export renders the diagrams of the ADRs to images
+type DiagramConfig struct {
+	// RendererURL the Kroki server rendering the diagrams, https://kroki.io when empty
+	RendererURL string `json:"renderer_url,omitempty"`
+	// Format the format of the images, "svg" when empty or "png"
+	Format string `json:"format,omitempty"`
+}
+
+// Diagram a diagram of an ADR: a fenced code block, or a line linking to a diagram file
+type Diagram struct {
+	// Type the language of the diagram, see DiagramTypes
+	Type string
+	// Source the text of the diagram, empty for a diagram file
+	Source string
+	// File the target of the link to the diagram file, as written in the ADR, empty for a code block
+	File string
+	// Title the text of the link to the diagram file
+	Title string
+	// Start and End the lines of the diagram, End excluded
+	Start int
+	End   int
+}
+
+// ParseDiagramType the diagram language named name, "puml" being PlantUML
+func ParseDiagramType(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case Mermaid:
+		return Mermaid, nil
+	case PlantUML, "puml":
+		return PlantUML, nil
+	}
+	return "", fmt.Errorf("unknown diagram type %q, expected one of %v", name, DiagramTypes)
+}
+
+// DiagramScaffold a starting point for a diagram of type kind, to be completed in the ADR
+func DiagramScaffold(kind string) string {
+	if kind == PlantUML {
+		return "@startuml\nactor User\nUser -> Service : request\nService --> User : response\n@enduml"
+	}
+	return "flowchart LR\n    User --> Service\n    Service --> Database"
+}
+
+// DiagramBlock the fenced code block of a diagram of type kind
+func DiagramBlock(kind string, source string) string {
+	return "```" + kind + "\n" + strings.TrimRight(source, "\n") + "\n```"
+}
+
+// DiagramFileName the name of a new diagram file of record, in DiagramAssetsDir, e.g. "0003-use-kubernetes.puml"
+func DiagramFileName(record Record, kind string) string {
+	extension := ".mmd"
+	if kind == PlantUML {
+		extension = ".puml"
+	}
+	return record.ID() + "-" + Slugify(record.Title) + extension
+}
+
+// Diagrams the diagrams of ADR content, in the order of the file
+func Diagrams(content []byte) []Diagram {
+	lines := strings.Split(string(content), "\n")
+	diagrams := []Diagram{}
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if m := diagramLinkRegexp.FindStringSubmatch(line); m != nil {
+			diagrams = append(diagrams, Diagram{
+				Type: diagramExtensions[strings.ToLower(filepath.Ext(m[2]))], File: m[2], Title: m[1], Start: i, End: i + 1,
+			})
+			continue
+		}
+		if !strings.HasPrefix(line, "```") {
+			continue
+		}
+		kind, err := ParseDiagramType(strings.TrimPrefix(line, "```"))
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "```" {
+			end++
+		}
+		if err == nil && end < len(lines) {
+			source := strings.Replace(strings.Join(lines[i+1:end], "\n"), "\r", "", -1)
+			diagrams = append(diagrams, Diagram{Type: kind, Source: source, Start: i, End: end + 1})
+		}
+		// the other code blocks are skipped whole, they may quote diagrams
+		i = end
+	}
+	return diagrams
+}
+
+// ReplaceDiagrams replaces each diagram of ADR content with the markdown replace makes of it, e.g. an image
+func ReplaceDiagrams(content []byte, replace func(Diagram) (string, error)) ([]byte, error) {
+	lines := strings.Split(string(content), "\n")
+	diagrams := Diagrams(content)
+	// from the last diagram, the lines of the others stay where they are
+	for i := len(diagrams) - 1; i >= 0; i-- {
+		d := diagrams[i]
+		replaced, err := replace(d)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", d.Start+1, err)
+		}
+		lines = append(lines[:d.Start], append([]string{replaced + crSuffix(lines[d.End-1])}, lines[d.End:]...)...)
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// AddDiagram appends markdown, a diagram block or a link to a diagram file, to the section of ADR content titled section
+func AddDiagram(content []byte, section string, markdown string) ([]byte, error) {
+	lines := strings.Split(string(content), "\n")
+	start, end, ok := sectionBody(lines, section)
+	if !ok {
+		return nil, errors.New("no " + section + " section, expected one of " + strings.Join(Sections(content), ", "))
+	}
+	body := strings.TrimRight(strings.Join(lines[start:end], "\n"), "\n\r\t ")
+	if strings.TrimSpace(body) != "" {
+		body += "\n\n"
+	}
+	return SetSection(content, section, strings.TrimLeft(body, "\n")+markdown), nil
+}
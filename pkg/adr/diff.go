@@ -0,0 +1,69 @@
+package adr
+
+import "strings"
+
+// The changes of a section between two ADRs, see CompareSections
+const (
+	SectionUnchanged = "unchanged"
+	SectionChanged   = "changed"
+	SectionAdded     = "added"
+	SectionRemoved   = "removed"
+)
+
+// SectionChange a "## " section of two ADRs compared, Before and After being its trimmed bodies in each
+type SectionChange struct {
+	Title  string `json:"title"`
+	Change string `json:"change"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// SectionText the trimmed body of the section titled title of content, false when content has no such section
+func SectionText(content []byte, title string) (string, bool) {
+	lines := strings.Split(string(content), "\n")
+	start, end, ok := sectionBody(lines, title)
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSpace(strings.Join(lines[start:end], "\n")), true
+}
+
+// sectionTitles the titles of the "## " sections of content, the status section included
+func sectionTitles(content []byte) []string {
+	titles := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		if m := sectionRegexp.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			titles = append(titles, strings.TrimSpace(m[1]))
+		}
+	}
+	return titles
+}
+
+// CompareSections compares the sections of two ADR contents, in the order of before then of the sections only after
+// has. Sections are matched by title, ignoring case, and their bodies compared once trimmed.
+func CompareSections(before []byte, after []byte) []SectionChange {
+	changes := []SectionChange{}
+	seen := map[string]bool{}
+	for _, title := range append(sectionTitles(before), sectionTitles(after)...) {
+		if seen[strings.ToLower(title)] {
+			continue
+		}
+		seen[strings.ToLower(title)] = true
+		change := SectionChange{Title: title}
+		old, inBefore := SectionText(before, title)
+		updated, inAfter := SectionText(after, title)
+		change.Before, change.After = old, updated
+		switch {
+		case !inAfter:
+			change.Change = SectionRemoved
+		case !inBefore:
+			change.Change = SectionAdded
+		case old == updated:
+			change.Change = SectionUnchanged
+		default:
+			change.Change = SectionChanged
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,140 @@
+package adr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// dotColors the fill colors of the ADRs in the DOT graph, by status
+var dotColors = map[Status]string{
+	Proposed:   "#fff8c5",
+	Accepted:   "#dafbe1",
+	Deprecated: "#eaeef2",
+	Superseded: "#ffebe9",
+}
+
+// dotEdgeStyles the attributes of the edges of the DOT graph, by relation, see RelationEdges
+var dotEdgeStyles = map[string]string{
+	"supersedes": `style=bold`,
+	"amends":     `style=dashed`,
+	"clarifies":  `style=dotted`,
+	"relates-to": `dir=none, color="#8c959f"`,
+}
+
+// RelationEdges the relations between records, each once however many of the two ADRs link to the other: edges
+// going the way of the forward link of the relation, e.g. from the superseding ADR to the superseded one, whose Kind is
+// the name of the relation, "supersedes" or one of Relations. The links of other kinds are left out.
+func RelationEdges(records []Record) []Edge {
+	seen := map[string]bool{}
+	edges := []Edge{}
+	for _, edge := range Edges(records) {
+		name, forward := relationOf(edge.Kind)
+		if name == "" {
+			continue
+		}
+		if !forward {
+			edge.From, edge.To = edge.To, edge.From
+		}
+		from, to := filepath.Clean(edge.From.Path), filepath.Clean(edge.To.Path)
+		if name == "relates-to" && to < from {
+			from, to = to, from
+		}
+		key := name + "\x00" + from + "\x00" + to
+		if seen[key] || from == to {
+			continue
+		}
+		seen[key] = true
+		edges = append(edges, Edge{From: edge.From, To: edge.To, Kind: name})
+	}
+	return edges
+}
+
+// relationOf the name of the relation a link of kind writes, and whether it is its forward link, "" for other links
+func relationOf(kind string) (string, bool) {
+	if NormalizeStatus(kind) == Superseded {
+		return "supersedes", false
+	}
+	if strings.HasPrefix(strings.ToLower(kind), strings.ToLower(DefaultSupersedes)) {
+		return "supersedes", true
+	}
+	for _, relation := range Relations {
+		if strings.EqualFold(kind, relation.Forward) {
+			return relation.Name, true
+		}
+		if strings.EqualFold(kind, relation.Reverse) {
+			return relation.Name, false
+		}
+	}
+	return "", false
+}
+
+// exportDOT writes records and the relations between them, see RelationEdges, as a Graphviz DOT graph: a box per ADR,
+// filled after its status, in a cluster per category. Drafts are left out.
+func exportDOT(w io.Writer, records []Record) error {
+	ids := map[string]string{}
+	clusters := map[string][]Record{}
+	scopes := map[string]bool{}
+	graphed := []Record{}
+	for _, record := range records {
+		if record.Draft != "" {
+			continue
+		}
+		graphed = append(graphed, record)
+		scopes[record.Scope] = true
+		ids[filepath.Clean(record.Path)] = fmt.Sprintf("adr%d", len(ids)+1)
+		clusters[record.Category] = append(clusters[record.Category], record)
+	}
+	scoped := len(scopes) > 1
+	buffered := bufio.NewWriter(w)
+	fmt.Fprintln(buffered, "digraph adrs {")
+	fmt.Fprintln(buffered, `  rankdir=LR;`)
+	fmt.Fprintln(buffered, `  node [shape=box, style="rounded,filled", fontname="Helvetica", fillcolor="#ffffff"];`)
+	fmt.Fprintln(buffered, `  edge [fontname="Helvetica", fontsize=10];`)
+	categories := []string{}
+	for category := range clusters {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for i, category := range categories {
+		indent := "  "
+		if category != "" {
+			fmt.Fprintf(buffered, "  subgraph cluster_%d {\n    label=%s;\n", i, dotString(category))
+			indent = "    "
+		}
+		for _, record := range clusters[category] {
+			ref := record.Ref()
+			if scoped && record.Scope != "" {
+				ref = record.Scope + "/" + ref
+			}
+			attributes := "label=" + dotString(ref+". "+record.Title+"\n"+string(record.Status))
+			if color, ok := dotColors[record.Status]; ok {
+				attributes += ", fillcolor=" + dotString(color)
+			}
+			if record.Archived {
+				attributes += `, style="rounded,filled,dashed"`
+			}
+			fmt.Fprintf(buffered, "%s%s [%s];\n", indent, ids[filepath.Clean(record.Path)], attributes)
+		}
+		if category != "" {
+			fmt.Fprintln(buffered, "  }")
+		}
+	}
+	for _, edge := range RelationEdges(graphed) {
+		attributes := "label=" + dotString(strings.Replace(edge.Kind, "-", " ", -1))
+		if style := dotEdgeStyles[edge.Kind]; style != "" {
+			attributes += ", " + style
+		}
+		fmt.Fprintf(buffered, "  %s -> %s [%s];\n", ids[filepath.Clean(edge.From.Path)], ids[filepath.Clean(edge.To.Path)], attributes)
+	}
+	fmt.Fprintln(buffered, "}")
+	return buffered.Flush()
+}
+
+// dotString text as a quoted DOT string
+func dotString(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(text) + `"`
+}
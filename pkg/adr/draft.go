@@ -0,0 +1,30 @@
+package adr
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+)
+
+// DraftPrefix starts the identifier of draft ADRs, e.g. DRAFT-3f9c2a1b
+const DraftPrefix = "DRAFT-"
+
+var headingNumberRegexp = regexp.MustCompile(`(?m)^(#\s+)(\d+|DRAFT-[0-9a-f]+)\.`)
+
+// NewDraftID a random draft identifier, unlike numbers it never collides between branches
+func NewDraftID() string {
+	id := make([]byte, 4)
+	rand.Read(id)
+	return DraftPrefix + hex.EncodeToString(id)
+}
+
+// SetHeadingNumber replaces the number, or draft identifier, of the ADR heading
+func SetHeadingNumber(content []byte, number string) []byte {
+	loc := headingNumberRegexp.FindSubmatchIndex(content)
+	if loc == nil {
+		return content
+	}
+	renumbered := append([]byte{}, content[:loc[4]]...)
+	renumbered = append(renumbered, number...)
+	return append(renumbered, content[loc[5]:]...)
+}
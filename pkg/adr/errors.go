@@ -0,0 +1,25 @@
+package adr
+
+import "errors"
+
+// Errors returned by the library, possibly wrapped with details: test them with errors.Is
+var (
+	// ErrNotInitialized the configuration folder has no configuration, adr init was never run
+	ErrNotInitialized = errors.New("adr is not initialized")
+	// ErrAdrNotFound no ADR has the requested number
+	ErrAdrNotFound = errors.New("ADR not found")
+	// ErrDuplicateNumber several ADRs share the requested number, so it does not identify a single ADR
+	ErrDuplicateNumber = errors.New("duplicate ADR number")
+	// ErrInvalidStatus a status that is not one of Statuses
+	ErrInvalidStatus = errors.New("invalid ADR status")
+	// ErrTemplateNotFound no template has the requested name
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrQuorumNotMet an ADR is accepted before enough of its approvers approved it, see Quorum
+	ErrQuorumNotMet = errors.New("approval quorum not met")
+	// ErrNotInForce a deprecated or superseded ADR is accepted again without forcing it, see Accept
+	ErrNotInForce = errors.New("ADR no longer in force")
+	// ErrUnknownTag a new ADR has a tag that is not in the taxonomy of the configuration
+	ErrUnknownTag = errors.New("unknown tag")
+	// ErrNotGenerated a file to regenerate was not written by adr, see WriteToc
+	ErrNotGenerated = errors.New("file written by hand")
+)
@@ -0,0 +1,79 @@
+package adr
+
+import "sync"
+
+// Event is published by a Repository to its subscribers: one of RecordCreated, StatusChanged or IndexRebuilt
+type Event interface {
+	// EventName names the event, e.g. "record_created"
+	EventName() string
+}
+
+// RecordCreated a new ADR, or draft ADR, was written
+type RecordCreated struct {
+	Record Record
+}
+
+// StatusChanged the status of an ADR was rewritten
+type StatusChanged struct {
+	Record Record
+	From   Status
+	To     Status
+}
+
+// IndexRebuilt the ADRs of a directory were read again from disk
+type IndexRebuilt struct {
+	Dir     string
+	Records []Record
+}
+
+// EventName implements Event
+func (RecordCreated) EventName() string { return "record_created" }
+
+// EventName implements Event
+func (StatusChanged) EventName() string { return "status_changed" }
+
+// EventName implements Event
+func (IndexRebuilt) EventName() string { return "index_rebuilt" }
+
+// subscribers the event handlers of a repository, its zero value has no handler
+type subscribers struct {
+	mu       sync.Mutex
+	next     int
+	handlers map[int]func(Event)
+}
+
+// Subscribe calls handler with every event the repository publishes, switch on the type of the event for its payload.
+// Handlers run synchronously, once the repository lock is released, in the goroutine of the operation:
+// a repository shared by goroutines calls them concurrently.
+// The returned function unsubscribes.
+func (r *Repository) Subscribe(handler func(Event)) func() {
+	s := &r.subscribers
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.handlers == nil {
+		s.handlers = map[int]func(Event){}
+	}
+	id := s.next
+	s.next++
+	s.handlers[id] = handler
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.handlers, id)
+	}
+}
+
+func (r *Repository) publish(event Event) {
+	s := &r.subscribers
+	s.mu.Lock()
+	handlers := make([]func(Event), 0, len(s.handlers))
+	for id := 0; id < s.next; id++ {
+		if handler, ok := s.handlers[id]; ok {
+			handlers = append(handlers, handler)
+		}
+	}
+	s.mu.Unlock()
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
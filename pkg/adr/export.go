@@ -0,0 +1,120 @@
+package adr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// PDFConfig the program adr export --format pdf converts the ADRs, rendered to HTML, to PDF with
+type PDFConfig struct {
+	// Program the converter, run as "program args... input.html output.pdf", wkhtmltopdf when empty
+	Program string `json:"program,omitempty"`
+	// Args the arguments of the program before the input and output files
+	Args []string `json:"args,omitempty"`
+}
+
+// Exporter writes ADRs in another format, e.g. for a documentation site or another tool
+type Exporter interface {
+	Export(w io.Writer, records []Record) error
+}
+
+// DirExporter an Exporter writing several files, e.g. a documentation site, into a directory
+type DirExporter interface {
+	Exporter
+	ExportDir(fsys FileSystem, dir string, records []Record) error
+}
+
+// ExporterFunc adapts a function to the Exporter interface
+type ExporterFunc func(w io.Writer, records []Record) error
+
+// Export implements Exporter
+func (f ExporterFunc) Export(w io.Writer, records []Record) error {
+	return f(w, records)
+}
+
+var exporters = struct {
+	sync.RWMutex
+	byName map[string]Exporter
+}{byName: map[string]Exporter{
+	"json":      ExporterFunc(exportJSON),
+	"backstage": backstageExporter{},
+	"ics":       ExporterFunc(exportICS),
+	"dot":       ExporterFunc(exportDOT),
+}}
+
+// RegisterExporter makes an exporter available under name, replacing any exporter registered with that name
+func RegisterExporter(name string, exporter Exporter) error {
+	if name == "" || exporter == nil {
+		return errors.New("an exporter needs a name and an implementation")
+	}
+	exporters.Lock()
+	defer exporters.Unlock()
+	exporters.byName[name] = exporter
+	return nil
+}
+
+// Exporters the names of the registered exporters, sorted
+func Exporters() []string {
+	exporters.RLock()
+	defer exporters.RUnlock()
+	names := []string{}
+	for name := range exporters.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Export writes records with the exporter registered under name
+func Export(name string, w io.Writer, records []Record) error {
+	exporters.RLock()
+	exporter, ok := exporters.byName[name]
+	exporters.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown export format %q, expected one of %v", name, Exporters())
+	}
+	return exporter.Export(w, records)
+}
+
+// ExportsDirectory tells whether the exporter registered under name writes a directory, see ExportDir
+func ExportsDirectory(name string) bool {
+	exporters.RLock()
+	defer exporters.RUnlock()
+	_, ok := exporters.byName[name].(DirExporter)
+	return ok
+}
+
+// ExportDir writes records into dir, read with fsys, with the DirExporter registered under name
+func ExportDir(name string, fsys FileSystem, dir string, records []Record) error {
+	exporters.RLock()
+	exporter, ok := exporters.byName[name]
+	exporters.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown export format %q, expected one of %v", name, Exporters())
+	}
+	dirExporter, ok := exporter.(DirExporter)
+	if !ok {
+		return fmt.Errorf("the export format %q writes a single file, not a directory", name)
+	}
+	return dirExporter.ExportDir(fsys, dir, records)
+}
+
+// exportJSON writes the versioned listing of the records, with their markdown as their body so that other tools
+// need not parse the files
+func exportJSON(w io.Writer, records []Record) error {
+	listing := NewListingJSON(records)
+	for i, record := range records {
+		content, err := OS.ReadFile(record.Path)
+		if err != nil {
+			return err
+		}
+		listing.Records[i].Body = string(content)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(listing)
+}
@@ -0,0 +1,58 @@
+package adr
+
+import (
+	"errors"
+	"strings"
+)
+
+// windowsInvalidChars the characters NTFS does not allow in file names, besides the path separators
+const windowsInvalidChars = `<>:"|?*`
+
+// windowsReservedNames the device names Windows does not allow as file names, with or without an extension
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// ValidateFileName checks that name can name a file, or a folder, on every platform adr runs on, Windows included
+func ValidateFileName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("the file name is empty")
+	case strings.ContainsAny(name, `/\`+windowsInvalidChars) || strings.IndexFunc(name, isControl) >= 0:
+		return errors.New("invalid file name '" + name + "', the characters " + windowsInvalidChars + " and the control characters are not allowed on Windows")
+	case strings.HasSuffix(name, ".") || strings.HasSuffix(name, " "):
+		return errors.New("invalid file name '" + name + "', Windows does not allow names ending with a dot or a space")
+	case windowsReservedNames[strings.ToUpper(strings.SplitN(name, ".", 2)[0])]:
+		return errors.New("invalid file name '" + name + "', " + strings.SplitN(name, ".", 2)[0] + " is a device name on Windows")
+	}
+	return nil
+}
+
+// SanitizeFileName makes a valid file name of name, see ValidateFileName: the characters Windows does not allow are
+// replaced with dashes, the trailing dots and spaces removed and the device names suffixed with a dash
+func SanitizeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if isControl(r) || strings.ContainsRune(`/\`+windowsInvalidChars, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	name = strings.TrimRight(name, ". ")
+	if name == "" {
+		return "untitled"
+	}
+	base, extension, _ := strings.Cut(name, ".")
+	if windowsReservedNames[strings.ToUpper(base)] {
+		name = base + "-"
+		if extension != "" {
+			name += "." + extension
+		}
+	}
+	return name
+}
+
+func isControl(r rune) bool {
+	return r < 32 || r == 127
+}
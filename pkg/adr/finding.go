@@ -0,0 +1,56 @@
+package adr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Severity how serious a Finding is: errors fail adr check, adr lint and the git hooks, warnings are only reported
+type Severity string
+
+// Finding severities
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding a problem detected in an ADR by one of the checks
+type Finding struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	// Severity of the finding, an error when empty
+	Severity Severity `json:"severity,omitempty"`
+}
+
+// IsWarning tells whether the finding is only a warning, which does not fail the checks
+func (f Finding) IsWarning() bool {
+	return f.Severity == SeverityWarning
+}
+
+// CountErrors the number of findings that are not warnings
+func CountErrors(findings []Finding) int {
+	errors := 0
+	for _, finding := range findings {
+		if !finding.IsWarning() {
+			errors++
+		}
+	}
+	return errors
+}
+
+// LintConfig what adr lint requires of the ADRs beyond its built-in rules
+type LintConfig struct {
+	// RequiredSections the sections every ADR has, the sections of its format when empty, e.g. Context, Decision
+	// and Consequences for adr's own format
+	RequiredSections []string `json:"required_sections,omitempty"`
+}
+
+func (f Finding) String() string {
+	location := f.File
+	if f.Line > 0 {
+		location += ":" + strconv.Itoa(f.Line)
+	}
+	return fmt.Sprintf("%s: %s (%s)", location, f.Message, f.Rule)
+}
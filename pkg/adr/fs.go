@@ -0,0 +1,120 @@
+package adr
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileSystem the file operations the library performs, so ADRs can live on disk, in memory or in any other backend.
+// Paths are operating system paths, as handled by the path/filepath package.
+type FileSystem interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm os.FileMode) error
+	// CreateExclusive creates a file that must not exist yet, failing with an error satisfying os.IsExist otherwise
+	CreateExclusive(name string, data []byte, perm os.FileMode) error
+	Remove(name string) error
+	Stat(name string) (os.FileInfo, error)
+	MkdirAll(path string, perm os.FileMode) error
+	Glob(pattern string) ([]string, error)
+}
+
+// OS the FileSystem of the operating system
+var OS FileSystem = osFileSystem{}
+
+type osFileSystem struct{}
+
+func (osFileSystem) ReadFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(name)
+}
+
+func (osFileSystem) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return ioutil.WriteFile(name, data, perm)
+}
+
+func (osFileSystem) CreateExclusive(name string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (osFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFileSystem) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFileSystem) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (osFileSystem) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+// ReadOnly wraps an fs.FS, such as an embed.FS, into a FileSystem ADRs can be listed and parsed from.
+// Absolute paths are resolved from the root of fsys and every write fails with fs.ErrPermission.
+func ReadOnly(fsys fs.FS) FileSystem {
+	return readOnlyFileSystem{fsys}
+}
+
+type readOnlyFileSystem struct {
+	fsys fs.FS
+}
+
+// fsName converts an operating system path to the slash separated, unrooted name fs.FS expects
+func fsName(name string) string {
+	name = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(name)), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+func (r readOnlyFileSystem) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(r.fsys, fsName(name))
+}
+
+func (r readOnlyFileSystem) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return &fs.PathError{Op: "write", Path: name, Err: fs.ErrPermission}
+}
+
+func (r readOnlyFileSystem) CreateExclusive(name string, data []byte, perm os.FileMode) error {
+	return &fs.PathError{Op: "create", Path: name, Err: fs.ErrPermission}
+}
+
+func (r readOnlyFileSystem) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrPermission}
+}
+
+func (r readOnlyFileSystem) Stat(name string) (os.FileInfo, error) {
+	return fs.Stat(r.fsys, fsName(name))
+}
+
+func (r readOnlyFileSystem) MkdirAll(path string, perm os.FileMode) error {
+	return &fs.PathError{Op: "mkdir", Path: path, Err: fs.ErrPermission}
+}
+
+func (r readOnlyFileSystem) Glob(pattern string) ([]string, error) {
+	matches, err := fs.Glob(r.fsys, fsName(pattern))
+	if err != nil {
+		return nil, err
+	}
+	for i := range matches {
+		matches[i] = filepath.FromSlash(matches[i])
+		if filepath.IsAbs(pattern) {
+			matches[i] = string(filepath.Separator) + matches[i]
+		}
+	}
+	return matches, nil
+}
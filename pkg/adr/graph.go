@@ -0,0 +1,47 @@
+package adr
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Edge a link between two ADRs of the same set of records
+type Edge struct {
+	From Record
+	To   Record
+	Kind string
+}
+
+// LinkedPath the path of the file a link of record points to, false for links outside of the file system such as URLs
+func (r Record) LinkedPath(link Link) (string, bool) {
+	target := strings.SplitN(link.Target, "#", 2)[0]
+	if target == "" || strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+		return "", false
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(r.Path), filepath.FromSlash(target))
+	}
+	return filepath.Clean(target), true
+}
+
+// Edges the links between records, in the order of records and of their links.
+// Links to files that are not among records are left out.
+func Edges(records []Record) []Edge {
+	byPath := map[string]Record{}
+	for _, record := range records {
+		byPath[filepath.Clean(record.Path)] = record
+	}
+	edges := []Edge{}
+	for _, record := range records {
+		for _, link := range record.Links {
+			path, ok := record.LinkedPath(link)
+			if !ok {
+				continue
+			}
+			if target, found := byPath[path]; found {
+				edges = append(edges, Edge{From: record, To: target, Kind: link.Kind})
+			}
+		}
+	}
+	return edges
+}
@@ -0,0 +1,109 @@
+package adr
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// icsEscaper escapes the text values of iCalendar, RFC 5545 section 3.3.11
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// icsEvent an all-day event of the calendar of the ADRs
+type icsEvent struct {
+	uid         string
+	date        time.Time
+	summary     string
+	description string
+	categories  []string
+}
+
+// icsEvents the events of record: the review of a decision in force on its review-by date, and the
+// decision of a proposed ADR on its deadline. Dates that cannot be read give no event.
+func icsEvents(record Record) []icsEvent {
+	events := []icsEvent{}
+	uid := record.ID()
+	if record.Category != "" {
+		uid = Slugify(record.Category) + "-" + uid
+	}
+	if record.Scope != "" {
+		uid = Slugify(record.Scope) + "-" + uid
+	}
+	if record.InForce() {
+		if date, err := ParseDate(record.ReviewBy); err == nil {
+			events = append(events, icsEvent{
+				uid:         uid + "-review@adr",
+				date:        date,
+				summary:     "Review ADR " + record.ID() + ". " + record.Title,
+				description: string(record.Status) + " decision to review, " + record.Path,
+				categories:  record.Tags,
+			})
+		}
+	}
+	if record.Status == Proposed {
+		if date, err := ParseDate(record.Deadline); err == nil {
+			events = append(events, icsEvent{
+				uid:         uid + "-deadline@adr",
+				date:        date,
+				summary:     "Decide on ADR " + record.ID() + ". " + record.Title,
+				description: "Proposed decision to accept or reject, " + record.Path,
+				categories:  record.Tags,
+			})
+		}
+	}
+	return events
+}
+
+// exportICS writes the review-by dates and the deadlines of records as an iCalendar feed, one all-day event each.
+// The UIDs of the events only depend on the ADR and the kind of event, so that calendars subscribed to the feed
+// move an event whose date changes rather than duplicating it.
+func exportICS(w io.Writer, records []Record) error {
+	buffered := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	writeICSLine(buffered, "BEGIN:VCALENDAR")
+	writeICSLine(buffered, "VERSION:2.0")
+	writeICSLine(buffered, "PRODID:-//marouni//adr//EN")
+	writeICSLine(buffered, "CALSCALE:GREGORIAN")
+	writeICSLine(buffered, "METHOD:PUBLISH")
+	writeICSLine(buffered, "X-WR-CALNAME:Architecture decisions")
+	for _, record := range records {
+		for _, event := range icsEvents(record) {
+			writeICSLine(buffered, "BEGIN:VEVENT")
+			writeICSLine(buffered, "UID:"+event.uid)
+			writeICSLine(buffered, "DTSTAMP:"+stamp)
+			writeICSLine(buffered, "DTSTART;VALUE=DATE:"+event.date.Format("20060102"))
+			writeICSLine(buffered, "DTEND;VALUE=DATE:"+event.date.AddDate(0, 0, 1).Format("20060102"))
+			writeICSLine(buffered, "SUMMARY:"+icsEscaper.Replace(event.summary))
+			writeICSLine(buffered, "DESCRIPTION:"+icsEscaper.Replace(event.description))
+			if len(event.categories) > 0 {
+				categories := []string{}
+				for _, category := range event.categories {
+					categories = append(categories, icsEscaper.Replace(category))
+				}
+				writeICSLine(buffered, "CATEGORIES:"+strings.Join(categories, ","))
+			}
+			writeICSLine(buffered, "TRANSP:TRANSPARENT")
+			writeICSLine(buffered, "END:VEVENT")
+		}
+	}
+	writeICSLine(buffered, "END:VCALENDAR")
+	return buffered.Flush()
+}
+
+// writeICSLine writes a content line ended by CRLF, folded so that no line is longer than 75 octets
+// and without splitting UTF-8 sequences
+func writeICSLine(w *bufio.Writer, line string) {
+	width := 75
+	for len(line) > width {
+		cut := width
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// the leading space of the continuation lines counts
+		width = 74
+	}
+	w.WriteString(line + "\r\n")
+}
@@ -0,0 +1,416 @@
+package adr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Importer reads the ADRs of another tool, to import them into a repository
+type Importer interface {
+	// Detect tells whether path holds ADRs the importer reads
+	Detect(fsys FileSystem, path string) bool
+	// Read the ADRs found at path, in the order they are to be numbered
+	Read(ctx context.Context, fsys FileSystem, path string) ([]ImportedADR, error)
+}
+
+// ImportedADR an ADR read by an Importer, not imported yet
+type ImportedADR struct {
+	// Source where the ADR comes from, for the reports; the links of the other ADRs to a markdown source follow it
+	Source string
+	// Record the title, status, date, author and tags of the ADR; its Draft is set, to any value, for the ADRs
+	// to import as drafts
+	Record Record
+	// Content the markdown of the ADR, as its tool writes it, see ConvertToNative
+	Content []byte
+}
+
+var (
+	// importLinkRegexp a markdown link to a file of the same folder
+	importLinkRegexp = regexp.MustCompile(`\[([^\]]*)\]\((?:\./)?([^)/]+\.md)\)`)
+	// importSkippedRegexp the markdown files of ADR folders which are no ADRs
+	importSkippedRegexp = regexp.MustCompile(`(?i)^(readme|index|_index|.*template)\.md$`)
+	// importFieldRegexp a "Key: value" line under the title, such as the "Technical Story:" of MADR
+	importFieldRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z ]*:\s+\S`)
+	// numberedTitleRegexp a link titled with the number and the title of an ADR, as adr-tools writes them
+	numberedTitleRegexp = regexp.MustCompile(`^\d+\.\s*`)
+)
+
+// importDirs the folders where the ADR tools keep their ADRs, relative to the root of a project
+var importDirs = []string{"docs/decisions", "docs/adr", "doc/adr", "docs/architecture/decisions", "doc/architecture/decisions", "adr", "decisions"}
+
+type namedImporter struct {
+	name     string
+	importer Importer
+}
+
+// importers the importers in the order they are tried, the markdown folders last as any ADR folder is one
+var importers = struct {
+	sync.RWMutex
+	list []namedImporter
+}{list: []namedImporter{
+	{"log4brains", log4brainsImporter{}},
+	{"adr-tools", numberedImporter{format: FormatADRTools}},
+	{"madr", numberedImporter{format: FormatMADR}},
+	{"json", jsonImporter{}},
+	{"markdown", markdownImporter{}},
+}}
+
+// RegisterImporter makes an importer available under name, replacing any importer registered with that name.
+// The importers registered are detected before the built-in ones.
+func RegisterImporter(name string, importer Importer) error {
+	if name == "" || importer == nil {
+		return errors.New("an importer needs a name and an implementation")
+	}
+	importers.Lock()
+	defer importers.Unlock()
+	for i, named := range importers.list {
+		if named.name == name {
+			importers.list = append(importers.list[:i], importers.list[i+1:]...)
+			break
+		}
+	}
+	importers.list = append([]namedImporter{{name, importer}}, importers.list...)
+	return nil
+}
+
+// Importers the names of the registered importers, in the order they are detected
+func Importers() []string {
+	importers.RLock()
+	defer importers.RUnlock()
+	names := []string{}
+	for _, named := range importers.list {
+		names = append(names, named.name)
+	}
+	return names
+}
+
+// LookupImporter the importer registered under name, failing when there is none
+func LookupImporter(name string) (Importer, error) {
+	importers.RLock()
+	defer importers.RUnlock()
+	for _, named := range importers.list {
+		if named.name == name {
+			return named.importer, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown import format %q, expected one of %v", name, Importers())
+}
+
+// DetectImporter the first importer recognizing the ADRs at path, and its name
+func DetectImporter(fsys FileSystem, path string) (string, Importer, bool) {
+	importers.RLock()
+	defer importers.RUnlock()
+	for _, named := range importers.list {
+		if named.importer.Detect(fsys, path) {
+			return named.name, named.importer, true
+		}
+	}
+	return "", nil, false
+}
+
+// findImportDir the records of the folder of path, or of the first of the usual ADR folders under it, see
+// importDirs, holding a record for which match is true
+func findImportDir(ctx context.Context, fsys FileSystem, path string, match func(record Record) bool) ([]Record, bool) {
+	dirs := []string{path}
+	// adr-tools keeps its folder in the .adr-dir file of the project
+	if content, err := fsys.ReadFile(filepath.Join(path, ".adr-dir")); err == nil {
+		dirs = append(dirs, filepath.Join(path, filepath.FromSlash(strings.TrimSpace(string(content)))))
+	}
+	for _, dir := range importDirs {
+		dirs = append(dirs, filepath.Join(path, filepath.FromSlash(dir)))
+	}
+	for _, dir := range dirs {
+		if info, err := fsys.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		records, err := ReadDir(ctx, fsys, dir)
+		if err != nil {
+			continue
+		}
+		adrs := []Record{}
+		for _, record := range records {
+			if !importSkippedRegexp.MatchString(filepath.Base(record.Path)) {
+				adrs = append(adrs, record)
+			}
+		}
+		for _, record := range adrs {
+			if match(record) {
+				return adrs, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// importedFromFiles the ADRs of records with the content of their files. The ADRs without a date are dated
+// by their files, and the statuses adr does not know, such as rejected, are written like the others.
+func importedFromFiles(ctx context.Context, fsys FileSystem, records []Record) ([]ImportedADR, error) {
+	imported := []ImportedADR{}
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		content, err := fsys.ReadFile(record.Path)
+		if err != nil {
+			return nil, err
+		}
+		if record.Date == "" {
+			if info, err := fsys.Stat(record.Path); err == nil {
+				record.Date = info.ModTime().Format(DateFormat)
+			}
+		}
+		if record.Status == "" {
+			record.Status = Proposed
+		}
+		record.Status = Status(capitalize(string(record.Status)))
+		imported = append(imported, ImportedADR{Source: record.Path, Record: record, Content: content})
+	}
+	return imported, nil
+}
+
+// numberedImporter reads the numbered ADRs of adr-tools or MADR, NNNN-title.md files, in the order of their numbers
+type numberedImporter struct {
+	format Format
+}
+
+func (n numberedImporter) match(record Record) bool {
+	name := filepath.Base(record.Path)
+	return numberedFileRegexp.MatchString(name) && !datedFileRegexp.MatchString(name) && record.Format == n.format
+}
+
+// Detect implements Importer
+func (n numberedImporter) Detect(fsys FileSystem, path string) bool {
+	_, ok := findImportDir(context.Background(), fsys, path, n.match)
+	return ok
+}
+
+// Read implements Importer
+func (n numberedImporter) Read(ctx context.Context, fsys FileSystem, path string) ([]ImportedADR, error) {
+	records, ok := findImportDir(ctx, fsys, path, n.match)
+	if !ok {
+		return nil, errors.New("no " + string(n.format) + " ADRs found in " + path + ", expected NNNN-title.md files")
+	}
+	numbered := []Record{}
+	for _, record := range records {
+		if name := filepath.Base(record.Path); numberedFileRegexp.MatchString(name) && !datedFileRegexp.MatchString(name) {
+			numbered = append(numbered, record)
+		}
+	}
+	// ReadDir sorts them by number
+	return importedFromFiles(ctx, fsys, numbered)
+}
+
+// markdownImporter reads the markdown files of a folder, each titled by its first heading, in the order of their names
+type markdownImporter struct{}
+
+// Detect implements Importer
+func (markdownImporter) Detect(fsys FileSystem, path string) bool {
+	_, ok := findImportDir(context.Background(), fsys, path, func(Record) bool { return true })
+	return ok
+}
+
+// Read implements Importer
+func (markdownImporter) Read(ctx context.Context, fsys FileSystem, path string) ([]ImportedADR, error) {
+	records, ok := findImportDir(ctx, fsys, path, func(Record) bool { return true })
+	if !ok {
+		return nil, errors.New("no markdown ADRs found in " + path + ", expected markdown files starting with a # title")
+	}
+	sort.SliceStable(records, func(i, j int) bool { return filepath.Base(records[i].Path) < filepath.Base(records[j].Path) })
+	return importedFromFiles(ctx, fsys, records)
+}
+
+// ConvertToNative rewrites the content of an ADR written by another tool in adr's own format. record is the ADR as
+// imported, with its number or draft identifier, and renamed maps the file names of the imported ADRs to the ADRs
+// they were imported as, for the links between them. The metadata adr does not know, from the front matter or
+// under the title, is kept as fields under the date.
+func ConvertToNative(record Record, content []byte, renamed map[string]Record) []byte {
+	lines := strings.Split(strings.Replace(string(content), "\r\n", "\n", -1), "\n")
+	fields, body := []string{}, []string{}
+	statusLinks := []string{}
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				fields = frontMatterFields(lines[1:i])
+				start = i + 1
+				break
+			}
+		}
+	}
+	field := func(key string, value string) {
+		switch strings.ToLower(key) {
+		case "status":
+			if link, ok := parseLink(value); ok {
+				statusLinks = append(statusLinks, capitalize(link.Kind)+" ["+link.Title+"]("+link.Target+")")
+			}
+		case "date", "tags", "deciders", "decision-makers", "author":
+		default:
+			fields = append(fields, capitalize(key)+": "+value)
+		}
+	}
+	section := ""
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if m := sectionRegexp.FindStringSubmatch(trimmed); m != nil {
+			section = strings.ToLower(strings.TrimSpace(m[1]))
+			if section == "status" {
+				continue
+			}
+			body = append(body, line)
+			if i+1 >= len(lines) || !underlineRegexp.MatchString(strings.TrimSpace(lines[i+1])) {
+				body = append(body, "======")
+			}
+			continue
+		}
+		switch section {
+		case "":
+			if trimmed == "" && len(body) > 0 {
+				body = append(body, line)
+			}
+			if headingRegexp.MatchString(trimmed) || trimmed == "" || underlineRegexp.MatchString(trimmed) {
+				continue
+			}
+			if m := plainFieldRegexp.FindStringSubmatch(trimmed); m != nil {
+				field(m[1], m[2])
+			} else if m := bulletFieldRegexp.FindStringSubmatch(trimmed); m != nil {
+				field(m[2], m[3])
+			} else if importFieldRegexp.MatchString(trimmed) {
+				fields = append(fields, trimmed)
+			} else {
+				// the text under the title comes first in the body
+				body = append(body, line)
+			}
+		case "status":
+			// the status itself comes from record, only the links of the section are kept
+			if link, ok := parseLink(trimmed); ok {
+				statusLinks = append(statusLinks, capitalize(link.Kind)+" ["+link.Title+"]("+link.Target+")")
+			}
+		default:
+			body = append(body, line)
+		}
+	}
+
+	number := record.Draft
+	if number == "" {
+		number = strconv.Itoa(record.Number)
+	}
+	header := []string{"# " + number + ". " + record.Title, "======", "Date: " + record.Date}
+	if record.Author != "" {
+		header = append(header, "Author: "+record.Author)
+	}
+	if len(record.Tags) > 0 {
+		header = append(header, "Tags: "+strings.Join(record.Tags, ", "))
+	}
+	header = append(header, fields...)
+	header = append(header, "", "## Status", "======")
+	// "Superseded by [...]" is the status itself, "Supersedes [...]" is not
+	if link, ok := parseLink(strings.Join(statusLinks, "\n")); !ok || NormalizeStatus(link.Kind) != record.Status {
+		header = append(header, string(record.Status))
+	}
+	header = append(header, statusLinks...)
+	converted := strings.Join(header, "\n") + "\n\n" + strings.TrimLeft(strings.Join(body, "\n"), "\n")
+	converted = importLinkRegexp.ReplaceAllStringFunc(converted, func(link string) string {
+		m := importLinkRegexp.FindStringSubmatch(link)
+		to, ok := renamed[m[2]]
+		if !ok {
+			return link
+		}
+		// links titled with the file name of the ADR, as log4brains writes them, or with its number and title,
+		// as adr-tools does, are titled with the new number
+		if m[1] == strings.TrimSuffix(m[2], ".md") || m[1] == to.Title || numberedTitleRegexp.ReplaceAllString(m[1], "") == to.Title {
+			return MarkdownLink(record, to)
+		}
+		return "[" + m[1] + "](" + filepath.Base(to.Path) + ")"
+	})
+	return []byte(strings.TrimRight(converted, "\n") + "\n")
+}
+
+// frontMatterFields the "Key: value" fields of the front matter lines adr does not read, lists being joined
+func frontMatterFields(lines []string) []string {
+	fields := []string{}
+	known := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if m := frontMatterFieldRegexp.FindStringSubmatch(trimmed); m != nil {
+			switch strings.ToLower(m[1]) {
+			case "status", "date", "tags", "deciders", "decision-makers", "author", "title":
+				known = true
+			default:
+				known = false
+				value := strings.Trim(strings.TrimSuffix(strings.TrimPrefix(m[2], "["), "]"), `"'`)
+				fields = append(fields, capitalize(m[1])+": "+value)
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "- ") && !known && len(fields) > 0 {
+			item := strings.Trim(strings.TrimSpace(trimmed[2:]), `"'`)
+			if last := &fields[len(fields)-1]; strings.HasSuffix(*last, ": ") {
+				*last += item
+			} else {
+				*last += ", " + item
+			}
+		}
+	}
+	kept := []string{}
+	for _, field := range fields {
+		if !strings.HasSuffix(field, ": ") {
+			kept = append(kept, field)
+		}
+	}
+	return kept
+}
+
+func capitalize(text string) string {
+	if text == "" {
+		return text
+	}
+	return strings.ToUpper(text[:1]) + text[1:]
+}
+
+// ApplyTemplate rewrites content, an ADR imported in adr's own format, see ConvertToNative, after the template named
+// name, the default template when empty: the template is executed for record, its sections taking the bodies of the
+// sections of content, the sections it lacks appended, and the fields under the date of content it lacks added
+func (r *Repository) ApplyTemplate(name string, record Record, content []byte) ([]byte, error) {
+	tmpl, err := r.parseTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	written, err := render(tmpl, record, CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if record.Draft != "" {
+		written = SetHeadingNumber(written, record.Draft)
+	}
+	lines := strings.Split(string(content), "\n")
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if sectionRegexp.MatchString(line) {
+			break
+		}
+		if !importFieldRegexp.MatchString(line) {
+			continue
+		}
+		key, value, _ := strings.Cut(line, ":")
+		if strings.EqualFold(key, "date") {
+			continue
+		}
+		if withField, err := SetField(written, key, strings.TrimSpace(value)); err == nil {
+			written = withField
+		}
+	}
+	for _, title := range append([]string{"Status"}, Sections(content)...) {
+		if start, end, ok := sectionBody(lines, title); ok {
+			written = SetSection(written, title, strings.Trim(strings.Join(lines[start:end], "\n"), "\n"))
+		}
+	}
+	return []byte(strings.TrimRight(string(written), "\n") + "\n"), nil
+}
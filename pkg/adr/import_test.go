@@ -0,0 +1,46 @@
+package adr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertToNativeLinks(t *testing.T) {
+	record := Record{Number: 12, Title: "Use Pulsar", Date: "2026-10-16", Status: Accepted, Path: "/docs/adr/12-use-pulsar.md"}
+	renamed := map[string]Record{
+		"0003-use-kafka.md":    {Number: 10, Title: "Use Kafka", Path: "/docs/adr/10-use-kafka.md"},
+		"20261001-use-rest.md": {Number: 11, Title: "Use REST", Path: "/docs/adr/11-use-rest.md"},
+	}
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"adr-tools title", "Amends [3. Use Kafka](0003-use-kafka.md)", "Amends [10. Use Kafka](10-use-kafka.md)"},
+		{"log4brains title", "See [20261001-use-rest](20261001-use-rest.md)", "See [11. Use REST](11-use-rest.md)"},
+		{"title without number", "See [Use Kafka](./0003-use-kafka.md)", "See [10. Use Kafka](10-use-kafka.md)"},
+		{"other text", "See [the Kafka decision](0003-use-kafka.md)", "See [the Kafka decision](10-use-kafka.md)"},
+		{"ADR not imported", "See [1. Use Go](0001-use-go.md)", "See [1. Use Go](0001-use-go.md)"},
+		{"address", "See [the docs](https://example.com/0003-use-kafka.md)", "See [the docs](https://example.com/0003-use-kafka.md)"},
+		{"several links", "[3. Use Kafka](0003-use-kafka.md) and [Use REST](20261001-use-rest.md)", "[10. Use Kafka](10-use-kafka.md) and [11. Use REST](11-use-rest.md)"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content := "# Use Pulsar\n\n## Status\n\nAccepted\n\n## Context\n\n" + test.line + "\n"
+			got := string(ConvertToNative(record, []byte(content), renamed))
+			if !strings.Contains(got, "\n"+test.want+"\n") {
+				t.Errorf("ConvertToNative() =\n%s\nwant the line %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestConvertToNativeStatusLinks(t *testing.T) {
+	record := Record{Number: 10, Title: "Use Kafka", Date: "2026-10-16", Status: Superseded, Path: "/docs/adr/10-use-kafka.md"}
+	renamed := map[string]Record{"0004-use-pulsar.md": {Number: 12, Title: "Use Pulsar", Path: "/docs/adr/12-use-pulsar.md"}}
+	content := "# 3. Use Kafka\n\nDate: 2026-10-16\n\n## Status\n\nSuperseded by [4. Use Pulsar](0004-use-pulsar.md)\n\n## Context\n\nEvents.\n"
+	want := "# 10. Use Kafka\n======\nDate: 2026-10-16\n\n## Status\n======\nSuperseded by [12. Use Pulsar](12-use-pulsar.md)\n\n## Context\n======\n\nEvents.\n"
+	if got := string(ConvertToNative(record, []byte(content), renamed)); got != want {
+		t.Errorf("ConvertToNative() =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,227 @@
+package adr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// jsonListKeys the keys under which the JSON exports of ADR tools, such as adr-manager, list their ADRs
+var jsonListKeys = []string{"adrs", "records", "decisions", "items"}
+
+// jsonSectionKeys the keys of the sections of an ADR, in the order they are written, as MADR names them and
+// adr-manager exports them; the other text keys are written after them, sorted
+var jsonSectionKeys = []string{
+	"context", "contextAndProblemStatement", "problem", "decisionDrivers", "drivers", "consideredOptions", "options",
+	"decision", "decisionOutcome", "outcome", "consequences", "positiveConsequences", "negativeConsequences",
+	"prosAndCons", "links", "notes",
+}
+
+// jsonMetadataKeys the keys of an ADR read as its metadata rather than written as sections
+var jsonMetadataKeys = map[string]bool{
+	"id": true, "number": true, "title": true, "name": true, "status": true, "date": true, "author": true,
+	"deciders": true, "decisionMakers": true, "decision-makers": true, "tags": true, "content": true,
+	"markdown": true, "body": true, "path": true, "file": true,
+}
+
+// jsonImporter reads the ADRs of a JSON file, such as the exports of adr-manager: a list of objects with a title,
+// and either their markdown content or their sections as text
+type jsonImporter struct{}
+
+// Detect implements Importer
+func (jsonImporter) Detect(fsys FileSystem, path string) bool {
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return false
+	}
+	items, err := readJSONItems(fsys, path)
+	return err == nil && len(items) > 0
+}
+
+// Read implements Importer, the ADRs come in the order of the file
+func (jsonImporter) Read(ctx context.Context, fsys FileSystem, path string) ([]ImportedADR, error) {
+	items, err := readJSONItems(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	date := ""
+	if info, err := fsys.Stat(path); err == nil {
+		date = info.ModTime().Format(DateFormat)
+	}
+	imported := []ImportedADR{}
+	for i, item := range items {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		source := fmt.Sprintf("%s[%d]", filepath.Base(path), i)
+		title := jsonText(item["title"])
+		if title == "" {
+			title = jsonText(item["name"])
+		}
+		content := ""
+		for _, key := range []string{"content", "markdown", "body"} {
+			if text, ok := item[key].(string); ok && text != "" {
+				content = text
+				break
+			}
+		}
+		if content == "" {
+			content = "# " + title + "\n\n" + jsonSections(item)
+		}
+		record := Parse("", []byte(content))
+		if title != "" {
+			record.Title = title
+		}
+		if record.Title == "" {
+			return nil, errors.New(source + ": the ADR has no title")
+		}
+		record.Number, record.Draft = 0, ""
+		if status := jsonText(item["status"]); status != "" {
+			record.Status = NormalizeStatus(status)
+		}
+		if record.Status == "" {
+			record.Status = Proposed
+		}
+		record.Status = Status(capitalize(string(record.Status)))
+		if d := jsonText(item["date"]); d != "" {
+			record.Date = d
+		}
+		if record.Date == "" {
+			record.Date = date
+		}
+		for _, key := range []string{"author", "deciders", "decisionMakers", "decision-makers"} {
+			if author := jsonText(item[key]); author != "" {
+				record.Author = author
+				break
+			}
+		}
+		if tags := jsonText(item["tags"]); tags != "" {
+			record.Tags = parseTags(tags)
+		}
+		imported = append(imported, ImportedADR{Source: source, Record: record, Content: []byte(content)})
+	}
+	return imported, nil
+}
+
+// readJSONItems the ADR objects of the JSON file at path, a list of objects or an object listing them
+// under one of jsonListKeys
+func readJSONItems(fsys FileSystem, path string) ([]map[string]interface{}, error) {
+	content, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var items []map[string]interface{}
+	if err := json.Unmarshal(content, &items); err == nil {
+		return items, nil
+	}
+	lists := map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &lists); err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	for _, key := range jsonListKeys {
+		if list, ok := lists[key]; ok {
+			if err := json.Unmarshal(list, &items); err != nil {
+				return nil, errors.New(path + ": " + key + ": " + err.Error())
+			}
+			return items, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: no ADRs found, expected a list of ADRs or an object listing them under one of %v", path, jsonListKeys)
+}
+
+// jsonSections the markdown sections of the text values of item, see jsonSectionKeys
+func jsonSections(item map[string]interface{}) string {
+	keys := []string{}
+	for _, key := range jsonSectionKeys {
+		if _, ok := item[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	others := []string{}
+	for key := range item {
+		known := jsonMetadataKeys[key]
+		for _, section := range jsonSectionKeys {
+			known = known || key == section
+		}
+		if !known {
+			others = append(others, key)
+		}
+	}
+	sort.Strings(others)
+	sections := []string{}
+	for _, key := range append(keys, others...) {
+		text := jsonMarkdown(item[key])
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		sections = append(sections, "## "+jsonHeading(key)+"\n\n"+strings.TrimSpace(text))
+	}
+	return strings.Join(sections, "\n\n") + "\n"
+}
+
+// jsonMarkdown writes a JSON value as markdown: texts as they are, lists as bullets, objects by their title
+// and description
+func jsonMarkdown(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		bullets := []string{}
+		for _, item := range v {
+			if text := strings.TrimSpace(jsonMarkdown(item)); text != "" {
+				bullets = append(bullets, "* "+strings.Replace(text, "\n", "\n  ", -1))
+			}
+		}
+		return strings.Join(bullets, "\n")
+	case map[string]interface{}:
+		title := jsonText(v["title"])
+		if title == "" {
+			title = jsonText(v["name"])
+		}
+		description := jsonText(v["description"])
+		if title != "" && description != "" {
+			return title + ": " + description
+		}
+		return title + description
+	}
+	return ""
+}
+
+// jsonText a JSON value as a single line of text, lists being joined with commas
+func jsonText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return fmt.Sprint(v)
+	case []interface{}:
+		texts := []string{}
+		for _, item := range v {
+			if text := jsonText(item); text != "" {
+				texts = append(texts, text)
+			}
+		}
+		return strings.Join(texts, ", ")
+	}
+	return ""
+}
+
+// jsonHeading the heading of the section of a JSON key: "contextAndProblemStatement" is "Context and problem statement"
+func jsonHeading(key string) string {
+	words := []rune{}
+	for i, r := range key {
+		switch {
+		case r == '_' || r == '-':
+			words = append(words, ' ')
+		case unicode.IsUpper(r) && i > 0:
+			words = append(words, ' ', unicode.ToLower(r))
+		default:
+			words = append(words, r)
+		}
+	}
+	return capitalize(string(words))
+}
@@ -0,0 +1,94 @@
+package adr
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// IndexFileName the file of the configuration folder caching the parsed ADRs
+const IndexFileName = "index.json"
+
+// index the ADRs parsed from each directory, with the size and modification time of their files when parsed
+type index struct {
+	SchemaVersion int                              `json:"schema_version"`
+	Dirs          map[string]map[string]indexEntry `json:"dirs"`
+}
+
+type indexEntry struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Record  Record    `json:"record"`
+}
+
+// IndexPath the path of the index cache
+func (r *Repository) IndexPath() string {
+	return filepath.Join(r.ConfigDir, IndexFileName)
+}
+
+// CachedList is List for frequent callers such as shell prompts: only the files that changed since
+// the previous call are parsed again, the others come from the index cache of the configuration folder.
+// The cache is best effort, it is rebuilt when unreadable and not saving it is not an error.
+func (r *Repository) CachedList(ctx context.Context) ([]Record, error) {
+	cache := index{}
+	if content, err := r.FS.ReadFile(r.IndexPath()); err == nil {
+		json.Unmarshal(content, &cache)
+	}
+	if cache.SchemaVersion != SchemaVersion || cache.Dirs == nil {
+		cache = index{SchemaVersion: SchemaVersion, Dirs: map[string]map[string]indexEntry{}}
+	}
+	cached := cache.Dirs[r.Dir]
+
+	paths, err := markdownFiles(r.FS, r.Dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := map[string]indexEntry{}
+	changed := len(cached) != len(paths)
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		info, err := r.FS.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		entry, ok := cached[path]
+		if !ok || entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+			record, err := ParseFile(r.FS, path)
+			if err != nil {
+				return nil, err
+			}
+			record.Category = categoryOf(r.Dir, path)
+			entry = indexEntry{ModTime: info.ModTime(), Size: info.Size(), Record: record}
+			changed = true
+		}
+		entries[path] = entry
+	}
+
+	records := []Record{}
+	for _, entry := range entries {
+		if entry.Record.Title != "" {
+			entry.Record.Scope = r.Scope
+			records = append(records, entry.Record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Number != records[j].Number {
+			return records[i].Number < records[j].Number
+		}
+		return records[i].Path < records[j].Path
+	})
+
+	if changed {
+		cache.Dirs[r.Dir] = entries
+		if content, err := json.Marshal(cache); err == nil {
+			if err := r.FS.WriteFile(r.IndexPath(), content, 0644); err != nil {
+				r.log().Debug("index cache not saved", "path", r.IndexPath(), "error", err)
+			}
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,47 @@
+package adr
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// IssueLink the kind of the links from an ADR to the issue tracking it, e.g. "Issue [ARCH-12](https://...)"
+const IssueLink = "Issue"
+
+// AddLinkContent writes a link of kind to target, titled title, under the status of ADR content.
+// The content is returned unchanged when it already has this link.
+func AddLinkContent(content []byte, kind string, title string, target string) ([]byte, error) {
+	for _, link := range Parse("", content).Links {
+		if strings.EqualFold(link.Kind, kind) && link.Target == target {
+			return content, nil
+		}
+	}
+	lines := strings.Split(string(content), "\n")
+	location, ok := locateStatus(lines)
+	if !ok {
+		return nil, errors.New("no status found")
+	}
+	return []byte(strings.Join(insertLink(lines, location, kind, "["+title+"]("+target+")"), "\n")), nil
+}
+
+// insertLink inserts the line linking to link right after the status, a paragraph of its own in a status section
+func insertLink(lines []string, location statusLocation, kind string, link string) []string {
+	inserted := []string{location.field(kind, link)}
+	if location.style == sectionStatus {
+		inserted = append([]string{""}, inserted...)
+	}
+	at := location.line + 1
+	return append(lines[:at], append(inserted, lines[at:]...)...)
+}
+
+// AddLink links the ADR numbered number to target with a link of kind titled title, see AddLinkContent
+func (r *Repository) AddLink(ctx context.Context, number int, kind string, title string, target string) (Record, error) {
+	record, err := r.Find(ctx, number)
+	if err != nil {
+		return Record{}, err
+	}
+	return r.Rewrite(ctx, record, func(content []byte) ([]byte, error) {
+		return AddLinkContent(content, kind, title, target)
+	})
+}
@@ -0,0 +1,119 @@
+package adr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// LockTimeout is how long Lock waits for another process to release the lock
+var LockTimeout = 10 * time.Second
+
+// staleLockAge after which a lock left behind by a crashed process is broken
+var staleLockAge = 2 * time.Minute
+
+// Lock creates the lock file of the repository exclusively, waiting for concurrent processes, and the other
+// goroutines of this process, to release it until LockTimeout or the cancellation of ctx.
+// The returned function releases the lock.
+func (r *Repository) Lock(ctx context.Context) (func(), error) {
+	lockPath := r.LockPath()
+	deadline := time.Now().Add(LockTimeout)
+	for !r.writer.TryLock() {
+		if time.Now().After(deadline) {
+			return nil, errors.New("could not acquire " + lockPath + ", another goroutine holds it")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	release, err := r.lockFile(ctx, lockPath, deadline)
+	if err != nil {
+		r.writer.Unlock()
+		return nil, err
+	}
+	return func() {
+		release()
+		r.writer.Unlock()
+	}, nil
+}
+
+// lockFile creates the lock file, waiting for concurrent processes until deadline
+func (r *Repository) lockFile(ctx context.Context, lockPath string, deadline time.Time) (func(), error) {
+	for {
+		hostname, _ := os.Hostname()
+		owner := fmt.Sprintf("%d@%s\n", os.Getpid(), hostname)
+		err := r.FS.CreateExclusive(lockPath, []byte(owner), 0644)
+		if err == nil {
+			r.log().Debug("lock acquired", "path", lockPath)
+			return func() {
+				r.FS.Remove(lockPath)
+				r.log().Debug("lock released", "path", lockPath)
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, statErr := r.FS.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			r.log().Warn("breaking stale lock", "path", lockPath, "modified", info.ModTime())
+			r.FS.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("could not acquire " + lockPath + ", another adr command seems to be running")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// ClaimNumber reserves the next ADR number, it must be called while holding the lock.
+// The number is computed from both the config counter and the ADRs already on disk,
+// so a stale counter can never hand out a number that is already taken.
+// Scopes are numbered independently, from the ADRs on disk only, and so are the categories with PerCategoryNumbering,
+// the counter numbering the ADRs outside of any category then.
+func (r *Repository) ClaimNumber(ctx context.Context) (int, error) {
+	if r.Scope == AllScopes {
+		return 0, errors.New("select the scope of the new ADR, the " + AllScopes + " scope only applies to reading")
+	}
+	settings := r.Settings()
+	current := settings.CurrentAdr
+	counted := r.Scope == "" && (r.Category == "" || !settings.PerCategory())
+	next := 1
+	if counted {
+		next = current + 1
+	}
+	records, err := ReadDir(ctx, r.FS, r.Dir)
+	if err != nil {
+		return 0, err
+	}
+	// the archived ADRs keep their numbers
+	archived, err := ReadArchive(ctx, r.FS, r.Dir)
+	if err != nil {
+		return 0, err
+	}
+	for _, record := range append(records, archived...) {
+		if settings.PerCategory() && record.Category != r.Category {
+			continue
+		}
+		if record.Number >= next {
+			next = record.Number + 1
+		}
+	}
+	r.log().Debug("ADR number claimed", "number", next, "current_id", current, "scope", r.Scope, "category", r.Category)
+	if counted {
+		r.configMu.Lock()
+		r.Config.CurrentAdr = next
+		r.configMu.Unlock()
+		if err := r.Save(); err != nil {
+			return 0, err
+		}
+	}
+	return next, nil
+}
@@ -0,0 +1,154 @@
+package adr
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Log4brainsConfigFile the configuration of a log4brains project, at its root
+const Log4brainsConfigFile = ".log4brains.yml"
+
+// Log4brainsDraft the status of the log4brains ADRs that are not proposed yet, they are imported as drafts
+const Log4brainsDraft Status = "Draft"
+
+var (
+	// log4brainsFileRegexp the file names of log4brains ADRs: their date, then their slug
+	log4brainsFileRegexp = regexp.MustCompile(`^(\d{4})(\d{2})(\d{2})-.+\.md$`)
+	// yamlFieldRegexp a "key: value" line of the configuration, with its indentation
+	yamlFieldRegexp = regexp.MustCompile(`^(\s*)(- )?([A-Za-z_]+)\s*:\s*(.*?)\s*$`)
+)
+
+// Log4brainsFolder a folder of log4brains ADRs, Package naming its package in a multi-package project
+type Log4brainsFolder struct {
+	Package string
+	Dir     string
+}
+
+// Log4brainsFolders the ADR folders of the log4brains project at root, as its .log4brains.yml lists them:
+// the folder of the project, then the folders of its packages
+func Log4brainsFolders(fsys FileSystem, root string) ([]Log4brainsFolder, error) {
+	content, err := fsys.ReadFile(filepath.Join(root, Log4brainsConfigFile))
+	if err != nil {
+		return nil, err
+	}
+	folders := []Log4brainsFolder{}
+	// the packages are listed under the packages key, more indented than it
+	packagesIndent, pkg := -1, ""
+	for _, line := range strings.Split(strings.Replace(string(content), "\r\n", "\n", -1), "\n") {
+		m := yamlFieldRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		indent, key, value := len(m[1])+len(m[2]), m[3], strings.Trim(m[4], `"'`)
+		if packagesIndent >= 0 && indent <= packagesIndent {
+			packagesIndent = -1
+		}
+		switch {
+		case key == "packages":
+			packagesIndent = indent
+		case packagesIndent >= 0 && key == "name":
+			pkg = value
+		case packagesIndent >= 0 && key == "adrFolder" && value != "":
+			folders = append(folders, Log4brainsFolder{Package: pkg, Dir: filepath.Join(root, filepath.FromSlash(value))})
+		case key == "adrFolder" && value != "":
+			// the folder of the project comes first
+			folders = append([]Log4brainsFolder{{Dir: filepath.Join(root, filepath.FromSlash(value))}}, folders...)
+		}
+	}
+	return folders, nil
+}
+
+// ReadLog4brains the ADRs of a log4brains folder, oldest first, its template and index being left out.
+// The tags of log4brains are separated by spaces or commas, and an ADR without a date is dated by its file name.
+func ReadLog4brains(ctx context.Context, fsys FileSystem, dir string) ([]Record, error) {
+	records, err := ReadDir(ctx, fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	adrs := []Record{}
+	for _, record := range records {
+		m := log4brainsFileRegexp.FindStringSubmatch(filepath.Base(record.Path))
+		if m == nil {
+			continue
+		}
+		if record.Date == "" {
+			record.Date = m[1] + "-" + m[2] + "-" + m[3]
+		}
+		tags := []string{}
+		for _, tag := range record.Tags {
+			tags = append(tags, strings.Fields(tag)...)
+		}
+		record.Tags = tags
+		// the statuses adr does not know, such as rejected, are written like the others
+		record.Status = Status(capitalize(string(record.Status)))
+		// log4brains numbers nothing, the number read from the date of the file name is meaningless
+		record.Number = 0
+		adrs = append(adrs, record)
+	}
+	sort.SliceStable(adrs, func(i, j int) bool { return filepath.Base(adrs[i].Path) < filepath.Base(adrs[j].Path) })
+	return adrs, nil
+}
+
+// log4brainsImporter reads the ADRs of a log4brains project, from the folder holding its .log4brains.yml,
+// or of a folder of log4brains ADRs
+type log4brainsImporter struct{}
+
+// Detect implements Importer
+func (log4brainsImporter) Detect(fsys FileSystem, path string) bool {
+	if _, err := fsys.Stat(filepath.Join(path, Log4brainsConfigFile)); err == nil {
+		return true
+	}
+	records, err := ReadLog4brains(context.Background(), fsys, path)
+	return err == nil && len(records) > 0
+}
+
+// Read implements Importer, the ADRs of the packages of a project being tagged with their package and merged
+// chronologically. The log4brains drafts are imported as drafts.
+func (log4brainsImporter) Read(ctx context.Context, fsys FileSystem, path string) ([]ImportedADR, error) {
+	folders := []Log4brainsFolder{{Dir: path}}
+	if _, err := fsys.Stat(filepath.Join(path, Log4brainsConfigFile)); err == nil {
+		if folders, err = Log4brainsFolders(fsys, path); err != nil {
+			return nil, err
+		}
+	}
+	imported := []ImportedADR{}
+	for _, folder := range folders {
+		records, err := ReadLog4brains(ctx, fsys, folder.Dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if folder.Package != "" && !hasTag(record, folder.Package) {
+				record.Tags = append(record.Tags, folder.Package)
+			}
+			if strings.EqualFold(string(record.Status), string(Log4brainsDraft)) {
+				record.Draft, record.Status = string(Log4brainsDraft), Proposed
+			}
+			content, err := fsys.ReadFile(record.Path)
+			if err != nil {
+				return nil, err
+			}
+			imported = append(imported, ImportedADR{Source: record.Path, Record: record, Content: content})
+		}
+	}
+	if len(imported) == 0 {
+		return nil, errors.New("no log4brains ADRs found in " + path + ", expected " + Log4brainsConfigFile + " or YYYYMMDD-title.md files")
+	}
+	// the file names start with the date of the ADRs
+	sort.SliceStable(imported, func(i, j int) bool { return filepath.Base(imported[i].Source) < filepath.Base(imported[j].Source) })
+	return imported, nil
+}
+
+// hasTag tells whether record is tagged with tag, compared case-insensitively
+func hasTag(record Record, tag string) bool {
+	for _, t := range record.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
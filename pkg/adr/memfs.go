@@ -0,0 +1,157 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemFS an in-memory FileSystem, for tests and for tools that never touch the disk.
+// Its zero value is not usable, create it with NewMemFS.
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string]*memFile
+}
+
+type memFile struct {
+	name    string
+	data    []byte
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (f *memFile) Name() string       { return filepath.Base(f.name) }
+func (f *memFile) Size() int64        { return int64(len(f.data)) }
+func (f *memFile) Mode() os.FileMode  { return f.mode }
+func (f *memFile) ModTime() time.Time { return f.modTime }
+func (f *memFile) IsDir() bool        { return f.mode.IsDir() }
+func (f *memFile) Sys() interface{}   { return nil }
+
+// NewMemFS an empty in-memory FileSystem, holding only its root directory
+func NewMemFS() *MemFS {
+	m := &MemFS{files: map[string]*memFile{}}
+	m.MkdirAll(string(filepath.Separator), 0755)
+	m.MkdirAll(".", 0755)
+	return m
+}
+
+func (m *MemFS) lookup(op string, name string) (*memFile, error) {
+	f, ok := m.files[filepath.Clean(name)]
+	if !ok {
+		return nil, &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	}
+	return f, nil
+}
+
+func (m *MemFS) write(op string, name string, data []byte, perm os.FileMode) error {
+	name = filepath.Clean(name)
+	if f, ok := m.files[name]; ok && f.IsDir() {
+		return &os.PathError{Op: op, Path: name, Err: os.ErrInvalid}
+	}
+	if parent, err := m.lookup(op, filepath.Dir(name)); err != nil || !parent.IsDir() {
+		return &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	}
+	m.files[name] = &memFile{name: name, data: append([]byte{}, data...), mode: perm, modTime: time.Now()}
+	return nil
+}
+
+// ReadFile returns a copy of the content of a file
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if f.IsDir() {
+		return nil, &os.PathError{Op: "read", Path: name, Err: os.ErrInvalid}
+	}
+	return append([]byte{}, f.data...), nil
+}
+
+// WriteFile creates or replaces a file, its directory must exist
+func (m *MemFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.write("open", name, data, perm)
+}
+
+// CreateExclusive creates a file, failing when it already exists
+func (m *MemFS) CreateExclusive(name string, data []byte, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.lookup("open", name); err == nil {
+		return &os.PathError{Op: "open", Path: name, Err: os.ErrExist}
+	}
+	return m.write("open", name, data, perm)
+}
+
+// Remove removes a file or an empty directory
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := m.lookup("remove", name)
+	if err != nil {
+		return err
+	}
+	if f.IsDir() {
+		for other := range m.files {
+			if filepath.Dir(other) == f.name && other != f.name {
+				return &os.PathError{Op: "remove", Path: name, Err: os.ErrExist}
+			}
+		}
+	}
+	delete(m.files, f.name)
+	return nil
+}
+
+// Stat describes a file or a directory
+func (m *MemFS) Stat(name string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := m.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	info := *f
+	return &info, nil
+}
+
+// MkdirAll creates a directory and its missing parents
+func (m *MemFS) MkdirAll(path string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if f, ok := m.files[dir]; ok {
+			if !f.IsDir() {
+				return &os.PathError{Op: "mkdir", Path: dir, Err: os.ErrExist}
+			}
+		} else {
+			m.files[dir] = &memFile{name: dir, mode: os.ModeDir | perm, modTime: time.Now()}
+		}
+		if filepath.Dir(dir) == dir {
+			return nil
+		}
+	}
+}
+
+// Glob returns the sorted names of the files matching pattern, with the syntax of filepath.Match
+func (m *MemFS) Glob(pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pattern = filepath.Clean(pattern)
+	matches := []string{}
+	for name := range m.files {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
@@ -0,0 +1,87 @@
+package adr
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// Option configures a Repository built by Open or Init
+type Option func(*Repository)
+
+// Numbering formats the number of an ADR in its file name
+type Numbering func(number int) string
+
+// Plain numbering, "1-use-go.md", the default
+func Plain(number int) string {
+	return strconv.Itoa(number)
+}
+
+// Padded numbering to width digits, Padded(4) names files like adr-tools does: "0001-use-go.md"
+func Padded(width int) Numbering {
+	return func(number int) string {
+		return fmt.Sprintf("%0*d", width, number)
+	}
+}
+
+// WithFS reads and writes the configuration and the ADRs in fsys instead of OS
+func WithFS(fsys FileSystem) Option {
+	return func(r *Repository) { r.FS = fsys }
+}
+
+// WithClock dates new ADRs with now instead of time.Now, e.g. to get reproducible ADRs in tests
+func WithClock(now func() time.Time) Option {
+	return func(r *Repository) { r.now = now }
+}
+
+// WithTemplate makes a template available to this repository only, taking precedence over the templates
+// with the same name. A template without a name replaces the default template.
+func WithTemplate(t Template) Option {
+	return func(r *Repository) {
+		if t.Name == "" {
+			t.Name = DefaultTemplateName
+		}
+		if r.templates == nil {
+			r.templates = map[string]Template{}
+		}
+		r.templates[t.Name] = t
+	}
+}
+
+// WithNumbering names the files of new ADRs with numbering instead of Plain
+func WithNumbering(numbering Numbering) Option {
+	return func(r *Repository) { r.numbering = numbering }
+}
+
+// WithLogger sets the Logger of the repository
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Repository) { r.Logger = logger }
+}
+
+// newRepository a repository of configDir in OS, with options applied
+func newRepository(configDir string, options []Option) *Repository {
+	r := &Repository{FS: OS, ConfigDir: configDir}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// clock the current time, as given by WithClock
+func (r *Repository) clock() time.Time {
+	if r.now == nil {
+		return time.Now()
+	}
+	return r.now()
+}
+
+// FileName builds the file name of an ADR with the numbering of the repository, that does not exist yet in the folder
+// of the selected Category
+func (r *Repository) FileName(number int, title string) string {
+	numbering := r.numbering
+	if numbering == nil {
+		numbering = Plain
+	}
+	return UniqueFileName(r.FS, r.categoryDir(), numbering(number), title)
+}
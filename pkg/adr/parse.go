@@ -0,0 +1,396 @@
+package adr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var numberedHeadingRegexp = regexp.MustCompile(`^#\s+(\d+|DRAFT-[0-9a-f]+)\.\s*(.*)$`)
+var headingRegexp = regexp.MustCompile(`^#\s+(.*)$`)
+var sectionRegexp = regexp.MustCompile(`^##\s+(.*)$`)
+var underlineRegexp = regexp.MustCompile(`^(=+|-+)\s*$`)
+var bulletFieldRegexp = regexp.MustCompile(`^([*-])\s+([A-Za-z][A-Za-z ]*?)\s*:\s*(.*)$`)
+var plainFieldRegexp = regexp.MustCompile(`^(Date|Author|Status|Tags|Review[ -]by|Deadline|Accepted[ -]on|Approvers|Supersedes|Superseded by|Amends|Amended by|Relates to|Clarifies|Clarified by|Issue)\s*:\s*(.*)$`)
+var frontMatterFieldRegexp = regexp.MustCompile(`^([A-Za-z_-][A-Za-z _-]*?)\s*:\s*(.*)$`)
+var markdownLinkRegexp = regexp.MustCompile(`^(.*?)\s*:?\s*\[([^\]]*)\]\(([^)]*)\)`)
+var numberedFileRegexp = regexp.MustCompile(`^(\d+)-`)
+var datedFileRegexp = regexp.MustCompile(`^(\d{8})-`)
+
+// ParseFile reads and parses a single ADR file
+func ParseFile(fsys FileSystem, path string) (Record, error) {
+	content, err := fsys.ReadFile(path)
+	if err != nil {
+		return Record{}, err
+	}
+	record := Parse(filepath.Base(path), content)
+	record.Path = path
+	return record, nil
+}
+
+// Parse parses ADR content written by adr, adr-tools, MADR or log4brains.
+// fileName is only used as a fallback source for the ADR number and to tell formats apart.
+func Parse(fileName string, content []byte) Record {
+	record := Record{}
+	lines := strings.Split(strings.Replace(string(content), "\r\n", "\n", -1), "\n")
+
+	start := 0
+	frontMatter := false
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				parseFrontMatter(&record, lines[1:i])
+				start = i + 1
+				frontMatter = true
+				break
+			}
+		}
+	}
+
+	bulletMarker := ""
+	underlined := false
+	section := ""
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+
+		if record.Title == "" && section == "" {
+			if m := numberedHeadingRegexp.FindStringSubmatch(line); m != nil {
+				if strings.HasPrefix(m[1], DraftPrefix) {
+					record.Draft = m[1]
+				} else {
+					record.Number, _ = strconv.Atoi(m[1])
+				}
+				record.Title = strings.TrimSpace(m[2])
+				underlined = i+1 < len(lines) && underlineRegexp.MatchString(strings.TrimSpace(lines[i+1]))
+				continue
+			}
+			if m := headingRegexp.FindStringSubmatch(line); m != nil {
+				record.Title = strings.TrimSpace(m[1])
+				continue
+			}
+		}
+
+		if m := sectionRegexp.FindStringSubmatch(line); m != nil {
+			section = strings.ToLower(strings.TrimSpace(m[1]))
+			continue
+		}
+		if underlineRegexp.MatchString(line) {
+			continue
+		}
+
+		linkCount := len(record.Links)
+		switch section {
+		case "":
+			if m := plainFieldRegexp.FindStringSubmatch(line); m != nil {
+				setField(&record, m[1], m[2])
+			} else if m := bulletFieldRegexp.FindStringSubmatch(line); m != nil {
+				bulletMarker = m[1]
+				setField(&record, m[2], m[3])
+			}
+		case "status":
+			if link, ok := parseLink(line); ok {
+				record.Links = append(record.Links, link)
+				if record.Status == "" {
+					record.Status = NormalizeStatus(link.Kind)
+				}
+			} else if record.Status == "" {
+				record.Status = NormalizeStatus(line)
+			}
+		case "approvals":
+			if m := approvalRegexp.FindStringSubmatch(line); m != nil {
+				record.Approvals = append(record.Approvals, Approval{By: strings.TrimSpace(m[1]), Date: strings.TrimSpace(m[2])})
+			}
+		}
+		for j := linkCount; j < len(record.Links); j++ {
+			record.Links[j].Line = i + 1
+		}
+	}
+
+	if record.Number == 0 {
+		if m := numberedFileRegexp.FindStringSubmatch(fileName); m != nil && !datedFileRegexp.MatchString(fileName) {
+			record.Number, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	switch {
+	case frontMatter:
+		record.Format = FormatMADR
+	case bulletMarker == "-" || datedFileRegexp.MatchString(fileName):
+		record.Format = FormatLog4brains
+	case bulletMarker == "*":
+		record.Format = FormatMADR
+	case underlined:
+		record.Format = FormatNative
+	default:
+		record.Format = FormatADRTools
+	}
+	return record
+}
+
+// parseFrontMatter parses the YAML front matter lines found between the leading "---" markers
+func parseFrontMatter(record *Record, lines []string) {
+	key := ""
+	for i, line := range lines {
+		linkCount := len(record.Links)
+		line = strings.TrimSpace(line)
+		if m := frontMatterFieldRegexp.FindStringSubmatch(line); m != nil {
+			key = strings.ToLower(m[1])
+			setField(record, m[1], strings.Trim(m[2], `"'`))
+		} else if strings.HasPrefix(line, "- ") && key == "tags" {
+			// block list of tags, one "- tag" line each
+			record.Tags = append(record.Tags, parseTags(line[2:])...)
+		}
+		for j := linkCount; j < len(record.Links); j++ {
+			// lines start after the opening "---" marker
+			record.Links[j].Line = i + 2
+		}
+	}
+}
+
+// setField applies a "Key: value" metadata line found in MADR, log4brains or native headers
+func setField(record *Record, key string, value string) {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case "status":
+		if link, ok := parseLink(value); ok {
+			record.Links = append(record.Links, link)
+			record.Status = NormalizeStatus(link.Kind)
+		} else {
+			record.Status = NormalizeStatus(value)
+		}
+	case "date":
+		record.Date = value
+	case "author", "deciders", "decision-makers":
+		record.Author = value
+	case "tags":
+		record.Tags = append(record.Tags, parseTags(value)...)
+	case "review by", "review-by", "review_by":
+		record.ReviewBy = value
+	case "deadline", "decide by", "decide-by", "decide_by":
+		record.Deadline = value
+	case "accepted on", "accepted-on", "accepted_on":
+		record.AcceptedOn = value
+	case "approvers":
+		record.Approvers = append(record.Approvers, parseTags(value)...)
+	case "supersedes", "superseded by", "amends", "amended by", "relates to", "related to", "clarifies", "clarified by", "issue":
+		if link, ok := parseLink(key + " " + value); ok {
+			record.Links = append(record.Links, link)
+		}
+	}
+}
+
+// parseTags splits a comma separated list of tags, also written as a YAML flow list: "[security, api]"
+func parseTags(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "["), "]")
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.Trim(strings.TrimSpace(tag), `"'`); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// parseLink recognizes lines such as "Superseded by [3. Use X](0003-use-x.md)"
+func parseLink(line string) (Link, bool) {
+	m := markdownLinkRegexp.FindStringSubmatch(line)
+	if m == nil || strings.TrimSpace(m[1]) == "" {
+		return Link{}, false
+	}
+	return Link{
+		Kind:   strings.TrimSpace(m[1]),
+		Title:  strings.TrimSpace(m[2]),
+		Target: strings.TrimSpace(m[3]),
+	}, true
+}
+
+// NormalizeStatus maps the many spellings of a status ("accepted", "Superseded by ...") to the known statuses,
+// unknown statuses are returned as is
+func NormalizeStatus(status string) Status {
+	status = strings.TrimSpace(status)
+	lower := strings.ToLower(status)
+	for _, known := range Statuses {
+		if strings.HasPrefix(lower, strings.ToLower(string(known))) {
+			return known
+		}
+	}
+	return Status(status)
+}
+
+// ParseStatus is NormalizeStatus restricted to the known statuses, other statuses fail with ErrInvalidStatus
+func ParseStatus(status string) (Status, error) {
+	normalized := NormalizeStatus(status)
+	for _, known := range Statuses {
+		if normalized == known {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q, expected one of %v", ErrInvalidStatus, status, Statuses)
+}
+
+// ReadDir parses every markdown file of an ADR directory and of its categories, sorted by number
+func ReadDir(ctx context.Context, fsys FileSystem, dir string) ([]Record, error) {
+	paths, err := markdownFiles(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	records := []Record{}
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		record, err := ParseFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		if record.Title == "" {
+			continue
+		}
+		record.Category = categoryOf(dir, path)
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Number != records[j].Number {
+			return records[i].Number < records[j].Number
+		}
+		return records[i].Path < records[j].Path
+	})
+	return records, nil
+}
+
+// statusStyle how an ADR format writes its status
+type statusStyle int
+
+const (
+	frontMatterStatus statusStyle = iota
+	sectionStatus
+	bulletStatus
+	plainStatus
+)
+
+// statusLocation where ADR content keeps its status: the index of the status line, or of the heading
+// of an empty status section, and how the status is written there
+type statusLocation struct {
+	line   int
+	style  statusStyle
+	key    string
+	marker string
+	empty  bool
+}
+
+// format writes text on the status line, as the format of the location expects it
+func (l statusLocation) format(text string) string {
+	switch l.style {
+	case frontMatterStatus:
+		return l.key + ": " + text
+	case bulletStatus:
+		return l.marker + " " + l.key + ": " + text
+	case plainStatus:
+		return "Status: " + text
+	}
+	return text
+}
+
+// field writes a "key: value" metadata line next to the status, as the format of the location expects it
+func (l statusLocation) field(key string, value string) string {
+	switch l.style {
+	case frontMatterStatus:
+		return strings.ToLower(key) + ": " + value
+	case bulletStatus:
+		return l.marker + " " + key + ": " + value
+	case plainStatus:
+		return key + ": " + value
+	}
+	return key + " " + value
+}
+
+// statusText a status as the format of the location writes it, lower case in front matter and bullet headers
+func (l statusLocation) statusText(status Status) string {
+	if l.style == frontMatterStatus || l.style == bulletStatus {
+		return strings.ToLower(string(status))
+	}
+	return string(status)
+}
+
+// locateStatus finds the status of ADR content: in the front matter, the "## Status" section, a "* Status:"
+// or a "Status:" header line
+func locateStatus(lines []string) (statusLocation, bool) {
+	inFrontMatter := len(lines) > 0 && strings.TrimSpace(lines[0]) == "---"
+	statusSection := -1
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if inFrontMatter {
+			if i > 0 && line == "---" {
+				inFrontMatter = false
+			} else if m := frontMatterFieldRegexp.FindStringSubmatch(line); m != nil && strings.ToLower(m[1]) == "status" {
+				return statusLocation{line: i, style: frontMatterStatus, key: m[1]}, true
+			}
+			continue
+		}
+		if m := sectionRegexp.FindStringSubmatch(line); m != nil {
+			if statusSection >= 0 {
+				break
+			}
+			if strings.ToLower(strings.TrimSpace(m[1])) == "status" {
+				statusSection = i
+			}
+			continue
+		}
+		if statusSection >= 0 {
+			if line != "" && !underlineRegexp.MatchString(line) {
+				return statusLocation{line: i, style: sectionStatus}, true
+			}
+			continue
+		}
+		if m := bulletFieldRegexp.FindStringSubmatch(line); m != nil && strings.ToLower(m[2]) == "status" {
+			return statusLocation{line: i, style: bulletStatus, key: m[2], marker: m[1]}, true
+		}
+		if m := plainFieldRegexp.FindStringSubmatch(line); m != nil && m[1] == "Status" {
+			return statusLocation{line: i, style: plainStatus, key: m[1]}, true
+		}
+	}
+	if statusSection >= 0 {
+		// empty status section, the status goes right after its heading and underline
+		at := statusSection
+		if at+1 < len(lines) && underlineRegexp.MatchString(strings.TrimSpace(lines[at+1])) {
+			at++
+		}
+		return statusLocation{line: at, style: sectionStatus, empty: true}, true
+	}
+	return statusLocation{}, false
+}
+
+// setStatusLine writes text as the status of ADR content, text being formatted for the status location
+func setStatusLine(content []byte, text func(statusLocation) string) ([]byte, error) {
+	lines := strings.Split(string(content), "\n")
+	location, ok := locateStatus(lines)
+	if !ok {
+		return nil, errors.New("no status found")
+	}
+	line := location.format(text(location))
+	if location.empty {
+		lines = append(lines[:location.line+1], append([]string{line}, lines[location.line+1:]...)...)
+		return []byte(strings.Join(lines, "\n")), nil
+	}
+	if strings.HasSuffix(lines[location.line], "\r") {
+		line += "\r"
+	}
+	lines[location.line] = line
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// SetStatus rewrites the status of ADR content in place, wherever its format keeps it:
+// the "## Status" section, a "* Status:" header line or the front matter
+func SetStatus(content []byte, status Status) ([]byte, error) {
+	return setStatusLine(content, func(location statusLocation) string {
+		return location.statusText(status)
+	})
+}
@@ -0,0 +1,249 @@
+package adr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		want     Record
+	}{
+		{
+			name:     "adr",
+			fileName: "3-use-postgres.md",
+			content: `# 3. Use Postgres
+======
+Date: 16-10-2026 10:00:00
+Author: Ann
+Tags: data, storage
+
+## Status
+======
+Superseded by [5. Use Kafka](5-use-kafka.md)
+
+## Context
+======
+`,
+			want: Record{
+				Number: 3,
+				Title:  "Use Postgres",
+				Date:   "16-10-2026 10:00:00",
+				Author: "Ann",
+				Status: Superseded,
+				Format: FormatNative,
+				Links:  []Link{{Kind: "Superseded by", Title: "5. Use Kafka", Target: "5-use-kafka.md", Line: 9}},
+				Tags:   []string{"data", "storage"},
+			},
+		},
+		{
+			name:     "adr draft",
+			fileName: "DRAFT-1a2b-try-things.md",
+			content:  "# DRAFT-1a2b. Try things\n======\nDate: 16-10-2026 10:00:00\n\n## Status\n======\nProposed\n",
+			want:     Record{Draft: "DRAFT-1a2b", Title: "Try things", Date: "16-10-2026 10:00:00", Status: Proposed, Format: FormatNative},
+		},
+		{
+			name:     "adr-tools",
+			fileName: "0002-record-architecture-decisions.md",
+			content:  "# 2. Record architecture decisions\n\nDate: 2026-10-16\n\n## Status\n\nAccepted\n\n## Context\n\nWe need to record decisions.\n",
+			want:     Record{Number: 2, Title: "Record architecture decisions", Date: "2026-10-16", Status: Accepted, Format: FormatADRTools},
+		},
+		{
+			name:     "adr-tools numbered by its file name",
+			fileName: "0007-use-go.md",
+			content:  "# Use Go\n\n## Status\n\nDeprecated\n",
+			want:     Record{Number: 7, Title: "Use Go", Status: Deprecated, Format: FormatADRTools},
+		},
+		{
+			name:     "MADR front matter",
+			fileName: "0004-use-rest.md",
+			content: `---
+status: accepted
+date: 2026-10-16
+deciders: Ann, Bob
+tags: [api, security]
+---
+# Use REST
+
+## Context and Problem Statement
+`,
+			want: Record{
+				Number: 4,
+				Title:  "Use REST",
+				Date:   "2026-10-16",
+				Author: "Ann, Bob",
+				Status: Accepted,
+				Format: FormatMADR,
+				Tags:   []string{"api", "security"},
+			},
+		},
+		{
+			name:     "MADR front matter tags list",
+			fileName: "0005-use-grpc.md",
+			content:  "---\nstatus: proposed\ntags:\n  - api\n  - rpc\n---\n# Use gRPC\n",
+			want:     Record{Number: 5, Title: "Use gRPC", Status: Proposed, Format: FormatMADR, Tags: []string{"api", "rpc"}},
+		},
+		{
+			name:     "MADR bullets",
+			fileName: "0006-use-graphql.md",
+			content:  "# Use GraphQL\n\n* Status: proposed\n* Deciders: Ann\n* Date: 2026-10-16\n\n## Context and Problem Statement\n",
+			want:     Record{Number: 6, Title: "Use GraphQL", Date: "2026-10-16", Author: "Ann", Status: Proposed, Format: FormatMADR},
+		},
+		{
+			name:     "log4brains",
+			fileName: "20261016-use-kafka.md",
+			content: `# Use Kafka
+
+- Status: superseded by [20261020-use-pulsar](20261020-use-pulsar.md)
+- Date: 2026-10-16
+- Tags: events
+
+## Context and Problem Statement
+`,
+			want: Record{
+				Title:  "Use Kafka",
+				Date:   "2026-10-16",
+				Status: Superseded,
+				Format: FormatLog4brains,
+				Links:  []Link{{Kind: "superseded by", Title: "20261020-use-pulsar", Target: "20261020-use-pulsar.md", Line: 3}},
+				Tags:   []string{"events"},
+			},
+		},
+		{
+			name:     "windows line endings",
+			fileName: "0008-use-crlf.md",
+			content:  "# 8. Use CRLF\r\n\r\nDate: 2026-10-16\r\n\r\n## Status\r\n\r\nProposed\r\n",
+			want:     Record{Number: 8, Title: "Use CRLF", Date: "2026-10-16", Status: Proposed, Format: FormatADRTools},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Parse(test.fileName, []byte(test.content))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Parse(%q) =\n%+v\nwant\n%+v", test.fileName, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLocateStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    statusLocation
+		found   bool
+	}{
+		{
+			name:    "front matter",
+			content: "---\ndate: 2026-10-16\nStatus: accepted\n---\n# Use REST\n",
+			want:    statusLocation{line: 2, style: frontMatterStatus, key: "Status"},
+			found:   true,
+		},
+		{
+			name:    "section",
+			content: "# 1. Use Go\n======\n\n## Status\n======\nProposed\n",
+			want:    statusLocation{line: 5, style: sectionStatus},
+			found:   true,
+		},
+		{
+			name:    "empty section",
+			content: "# 1. Use Go\n======\n\n## Status\n======\n\n## Context\n",
+			want:    statusLocation{line: 4, style: sectionStatus, empty: true},
+			found:   true,
+		},
+		{
+			name:    "bullet",
+			content: "# Use Kafka\n\n- Status: draft\n- Date: 2026-10-16\n",
+			want:    statusLocation{line: 2, style: bulletStatus, key: "Status", marker: "-"},
+			found:   true,
+		},
+		{
+			name:    "plain",
+			content: "# Use Go\n\nStatus: Accepted\nDate: 2026-10-16\n",
+			want:    statusLocation{line: 2, style: plainStatus, key: "Status"},
+			found:   true,
+		},
+		{
+			name:    "status of another section",
+			content: "# Use Go\n\n## Context\n\nStatus quo.\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, found := locateStatus(strings.Split(test.content, "\n"))
+			if found != test.found || got != test.want {
+				t.Errorf("locateStatus() = %+v, %v, want %+v, %v", got, found, test.want, test.found)
+			}
+		})
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		status  Status
+		want    string
+	}{
+		{
+			name:    "adr",
+			content: "# 1. Use Go\n======\n\n## Status\n======\nProposed\n\n## Context\n",
+			status:  Accepted,
+			want:    "# 1. Use Go\n======\n\n## Status\n======\nAccepted\n\n## Context\n",
+		},
+		{
+			name:    "adr-tools with an empty status section",
+			content: "# 1. Use Go\n\n## Status\n\n## Context\n",
+			status:  Proposed,
+			want:    "# 1. Use Go\n\n## Status\nProposed\n\n## Context\n",
+		},
+		{
+			name:    "MADR front matter",
+			content: "---\nstatus: proposed\ndate: 2026-10-16\n---\n# Use REST\n",
+			status:  Deprecated,
+			want:    "---\nstatus: deprecated\ndate: 2026-10-16\n---\n# Use REST\n",
+		},
+		{
+			name:    "MADR bullets",
+			content: "# Use GraphQL\n\n* Status: proposed\n* Date: 2026-10-16\n",
+			status:  Accepted,
+			want:    "# Use GraphQL\n\n* Status: accepted\n* Date: 2026-10-16\n",
+		},
+		{
+			name:    "log4brains",
+			content: "# Use Kafka\n\n- Status: draft\n- Date: 2026-10-16\n",
+			status:  Superseded,
+			want:    "# Use Kafka\n\n- Status: superseded\n- Date: 2026-10-16\n",
+		},
+		{
+			name:    "windows line endings",
+			content: "# 1. Use Go\r\n\r\n## Status\r\n\r\nProposed\r\n",
+			status:  Accepted,
+			want:    "# 1. Use Go\r\n\r\n## Status\r\n\r\nAccepted\r\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := SetStatus([]byte(test.content), test.status)
+			if err != nil {
+				t.Fatalf("SetStatus() failed: %v", err)
+			}
+			if string(got) != test.want {
+				t.Errorf("SetStatus() =\n%q\nwant\n%q", got, test.want)
+			}
+			if status := Parse("1-use-go.md", got).Status; status != test.status {
+				t.Errorf("the status parsed once set is %q, want %q", status, test.status)
+			}
+		})
+	}
+}
+
+func TestSetStatusWithoutStatus(t *testing.T) {
+	if _, err := SetStatus([]byte("# Use Go\n\nSome notes.\n"), Accepted); err == nil {
+		t.Error("SetStatus() of content without a status succeeded, want an error")
+	}
+}
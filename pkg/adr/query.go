@@ -0,0 +1,109 @@
+package adr
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// dateLayouts the date formats found in ADRs: adr's own, the ISO dates of adr-tools, MADR and log4brains, and RFC 3339
+var dateLayouts = []string{DateFormat, "2006-01-02", time.RFC3339}
+
+// ParseDate parses the date of an ADR, whatever tool wrote it
+func ParseDate(date string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(date)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unknown date format " + date)
+}
+
+// Query selects ADRs, a record matches when it passes every filter that is set.
+// The zero Query matches every record.
+type Query struct {
+	// Statuses the record has one of these statuses
+	Statuses []Status
+	// Tags the record has all of these tags, compared case-insensitively
+	Tags []string
+	// Since and Until bound the date of the record, inclusively. Records without a readable date never match a bound.
+	Since time.Time
+	Until time.Time
+	// Text appears in the title or the content of the record, compared case-insensitively
+	Text string
+	// Categories the record is in one of these categories
+	Categories []string
+}
+
+// Match tells whether a record passes the filters of the query, content is the content of its file
+// and is only looked at for Text
+func (q Query) Match(record Record, content []byte) bool {
+	if len(q.Statuses) > 0 {
+		found := false
+		for _, status := range q.Statuses {
+			found = found || record.Status == status
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(q.Categories) > 0 {
+		found := false
+		for _, category := range q.Categories {
+			found = found || record.Category == category
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, tag := range q.Tags {
+		found := false
+		for _, recordTag := range record.Tags {
+			found = found || strings.EqualFold(tag, recordTag)
+		}
+		if !found {
+			return false
+		}
+	}
+	if !q.Since.IsZero() || !q.Until.IsZero() {
+		date, err := ParseDate(record.Date)
+		if err != nil || (!q.Since.IsZero() && date.Before(q.Since)) || (!q.Until.IsZero() && date.After(q.Until)) {
+			return false
+		}
+	}
+	if q.Text != "" {
+		text := strings.ToLower(q.Text)
+		if !strings.Contains(strings.ToLower(record.Title), text) && !strings.Contains(strings.ToLower(string(content)), text) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter keeps the records matching the query, reading their files from fsys only when the query has a Text
+func (q Query) Filter(fsys FileSystem, records []Record) ([]Record, error) {
+	matches := []Record{}
+	for _, record := range records {
+		var content []byte
+		if q.Text != "" {
+			var err error
+			if content, err = fsys.ReadFile(record.Path); err != nil {
+				return nil, err
+			}
+		}
+		if q.Match(record, content) {
+			matches = append(matches, record)
+		}
+	}
+	return matches, nil
+}
+
+// Query lists the ADRs of the repository matching q, sorted by number
+func (r *Repository) Query(ctx context.Context, q Query) ([]Record, error) {
+	records, err := r.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return q.Filter(r.FS, records)
+}
@@ -0,0 +1,77 @@
+// Package adr reads, creates and updates Architecture Decision Records (ADRs).
+//
+// It is the library behind the adr command line tool: a Repository holds the ADR configuration
+// and the directory of the ADRs, which are parsed into Records whatever tool wrote them
+// (adr itself, adr-tools, MADR or log4brains).
+package adr
+
+import "fmt"
+
+// Record an ADR, as parsed from its markdown file
+type Record struct {
+	Number int
+	Title  string
+	Date   string
+	Author string
+	Status Status
+	Draft  string
+	Path   string
+	Scope  string
+	// Category the folder of the ADR directory the ADR is in, empty when it is right in the directory
+	Category string
+	Format   Format
+	Links    []Link
+	Tags     []string
+	// ReviewBy the date the decision is to be reviewed, Deadline the date a proposed decision is to be made by,
+	// both as written in the ADR
+	ReviewBy string
+	Deadline string
+	// AcceptedOn the date the decision was accepted, as written in its AcceptedField
+	AcceptedOn string
+	// Approvers who must approve the decision before it is accepted, Approvals who did, see Quorum
+	Approvers []string
+	Approvals []Approval
+	// Archived the ADR is in the ArchiveDir folder of its folder, see ReadArchive
+	Archived bool
+}
+
+// ID identifies a record in messages: its zero padded number, e.g. "0042", or its draft identifier
+func (r Record) ID() string {
+	if r.Draft != "" {
+		return r.Draft
+	}
+	return fmt.Sprintf("%04d", r.Number)
+}
+
+// Status the lifecycle status of an ADR
+type Status string
+
+// ADR statuses
+const (
+	Proposed   Status = "Proposed"
+	Accepted   Status = "Accepted"
+	Deprecated Status = "Deprecated"
+	Superseded Status = "Superseded"
+)
+
+// Statuses the statuses known to adr, in lifecycle order
+var Statuses = []Status{Proposed, Accepted, Deprecated, Superseded}
+
+// Format identifies the tool an ADR file was written with
+type Format string
+
+// Known ADR formats
+const (
+	FormatNative     Format = "adr"
+	FormatADRTools   Format = "adr-tools"
+	FormatMADR       Format = "madr"
+	FormatLog4brains Format = "log4brains"
+)
+
+// Link a relation from one ADR to another, e.g. "Supersedes" or "Amended by"
+type Link struct {
+	Kind   string
+	Title  string
+	Target string
+	Line   int
+}
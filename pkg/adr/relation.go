@@ -0,0 +1,62 @@
+package adr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Relation a typed relation between two ADRs, written as reciprocal links under their statuses
+type Relation struct {
+	// Name the type of the relation, e.g. "amends"
+	Name string
+	// Forward the kind of the link in the ADR the relation goes from, e.g. "Amends"
+	Forward string
+	// Reverse the kind of the link back in the related ADR, e.g. "Amended by"
+	Reverse string
+}
+
+// Relations the types of relations between ADRs, supersede aside, see Repository.Supersede
+var Relations = []Relation{
+	{Name: "relates-to", Forward: "Relates to", Reverse: "Relates to"},
+	{Name: "amends", Forward: "Amends", Reverse: "Amended by"},
+	{Name: "clarifies", Forward: "Clarifies", Reverse: "Clarified by"},
+}
+
+// ParseRelation the relation named name, or written as the kind of one of its links, e.g. "Amended by"
+func ParseRelation(name string) (Relation, error) {
+	normalized := strings.ToLower(strings.Join(strings.FieldsFunc(name, func(r rune) bool { return r == ' ' || r == '-' || r == '_' }), "-"))
+	names := []string{}
+	for _, relation := range Relations {
+		for _, kind := range []string{relation.Name, relation.Forward, relation.Reverse} {
+			if strings.ToLower(strings.Replace(kind, " ", "-", -1)) == normalized {
+				return relation, nil
+			}
+		}
+		names = append(names, relation.Name)
+	}
+	return Relation{}, fmt.Errorf("unknown relation %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// Relate links from to to with the forward link of relation, and to back to from with its reverse link.
+// The links an ADR already has are not written again.
+func (r *Repository) Relate(ctx context.Context, from Record, to Record, relation Relation) (Record, Record, error) {
+	if filepath.Clean(from.Path) == filepath.Clean(to.Path) {
+		return from, to, fmt.Errorf("ADR %s cannot be related to itself", from.Ref())
+	}
+	if relation.Forward == "" || relation.Reverse == "" {
+		return from, to, errors.New("the relation " + relation.Name + " has no link kinds")
+	}
+	from, err := r.Rewrite(ctx, from, func(content []byte) ([]byte, error) {
+		return AddLinkContent(content, relation.Forward, linkTitle(to), linkTarget(from, to))
+	})
+	if err != nil {
+		return from, to, err
+	}
+	to, err = r.Rewrite(ctx, to, func(content []byte) ([]byte, error) {
+		return AddLinkContent(content, relation.Reverse, linkTitle(from), linkTarget(to, from))
+	})
+	return from, to, err
+}
@@ -0,0 +1,112 @@
+package adr
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// leadingNumberRegexp the number starting the file name of an ADR, e.g. "0003" of "0003-use-postgres.md"
+var leadingNumberRegexp = regexp.MustCompile(`^\d+`)
+
+// inlineLinkRegexp a markdown link anywhere in a line, with its text and its target
+var inlineLinkRegexp = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)\)`)
+
+// Renumbering the new number of an ADR, and the path it takes with it
+type Renumbering struct {
+	Record Record
+	Number int
+	Path   string
+}
+
+// PlanRenumbering numbers records from 1, without gaps nor duplicates, in the order of their numbers then of their
+// dates, the categories apart with perCategory. The drafts keep their identifiers. Only the records whose number
+// changes are returned, in their new order.
+func PlanRenumbering(records []Record, perCategory bool) []Renumbering {
+	groups := map[string][]Record{}
+	for _, record := range records {
+		if record.Draft != "" {
+			continue
+		}
+		group := ""
+		if perCategory {
+			group = record.Category
+		}
+		groups[group] = append(groups[group], record)
+	}
+	names := []string{}
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	plan := []Renumbering{}
+	for _, name := range names {
+		group := groups[name]
+		sort.SliceStable(group, func(i, j int) bool {
+			if group[i].Number != group[j].Number {
+				return group[i].Number < group[j].Number
+			}
+			if group[i].Date != group[j].Date {
+				return group[i].Date < group[j].Date
+			}
+			return group[i].Path < group[j].Path
+		})
+		for i, record := range group {
+			if record.Number == i+1 {
+				continue
+			}
+			name := RenumberedFileName(filepath.Base(record.Path), i+1)
+			plan = append(plan, Renumbering{Record: record, Number: i + 1, Path: filepath.Join(filepath.Dir(record.Path), name)})
+		}
+	}
+	return plan
+}
+
+// RenumberedFileName the file name name takes with number: its leading number replaced, keeping its width, e.g.
+// "0007-use-kafka.md" for "0012-use-kafka.md" and 7. A name starting with no number is kept.
+func RenumberedFileName(name string, number int) string {
+	width := len(leadingNumberRegexp.FindString(name))
+	if width == 0 {
+		return name
+	}
+	return fmt.Sprintf("%0*d", width, number) + name[width:]
+}
+
+// RenumberLinks points the links of the ADR content of record to the files of plan to their new paths, the texts of
+// the links starting with the old number, such as "3. Use Postgres", taking the new one
+func RenumberLinks(record Record, content []byte, plan []Renumbering) []byte {
+	byPath := map[string]Renumbering{}
+	for _, renumbering := range plan {
+		byPath[filepath.Clean(renumbering.Record.Path)] = renumbering
+	}
+	return inlineLinkRegexp.ReplaceAllFunc(content, func(link []byte) []byte {
+		m := inlineLinkRegexp.FindSubmatch(link)
+		text, target := string(m[1]), string(m[2])
+		linked, ok := record.LinkedPath(Link{Target: target})
+		renumbering, found := byPath[linked]
+		if !ok || !found {
+			return link
+		}
+		anchor := ""
+		if at := strings.Index(target, "#"); at >= 0 {
+			target, anchor = target[:at], target[at:]
+		}
+		target = strings.TrimSuffix(target, path.Base(target)) + filepath.Base(renumbering.Path)
+		if rest, ok := strings.CutPrefix(text, strconv.Itoa(renumbering.Record.Number)+". "); ok {
+			text = strconv.Itoa(renumbering.Number) + ". " + rest
+		}
+		return []byte("[" + text + "](" + target + anchor + ")")
+	})
+}
+
+// SetCounter sets the counter of the ADR numbers of the configuration, see ClaimNumber, and saves it
+func (r *Repository) SetCounter(number int) error {
+	r.configMu.Lock()
+	r.Config.CurrentAdr = number
+	r.configMu.Unlock()
+	return r.Save()
+}
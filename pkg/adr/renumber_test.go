@@ -0,0 +1,106 @@
+package adr
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPlanRenumbering(t *testing.T) {
+	record := func(number int, date string, path string) Record {
+		return Record{Number: number, Date: date, Path: filepath.FromSlash(path), Category: filepath.Base(filepath.Dir(path))}
+	}
+	tests := []struct {
+		name        string
+		records     []Record
+		perCategory bool
+		want        []Renumbering
+	}{
+		{
+			name:    "numbered already",
+			records: []Record{record(1, "", "docs/1-a.md"), record(2, "", "docs/2-b.md")},
+			want:    []Renumbering{},
+		},
+		{
+			name:    "gap",
+			records: []Record{record(1, "", "docs/0001-a.md"), record(4, "", "docs/0004-b.md"), record(7, "", "docs/0007-c.md")},
+			want: []Renumbering{
+				{Record: record(4, "", "docs/0004-b.md"), Number: 2, Path: filepath.FromSlash("docs/0002-b.md")},
+				{Record: record(7, "", "docs/0007-c.md"), Number: 3, Path: filepath.FromSlash("docs/0003-c.md")},
+			},
+		},
+		{
+			name:    "duplicates in the order of their dates",
+			records: []Record{record(2, "2026-10-02", "docs/2-later.md"), record(2, "2026-10-01", "docs/2-sooner.md"), record(1, "", "docs/1-a.md")},
+			want: []Renumbering{
+				{Record: record(2, "2026-10-02", "docs/2-later.md"), Number: 3, Path: filepath.FromSlash("docs/3-later.md")},
+			},
+		},
+		{
+			name:    "drafts keep their identifiers",
+			records: []Record{{Draft: "DRAFT-1a2b", Path: "DRAFT-1a2b-a.md"}, record(3, "", "docs/3-b.md")},
+			want: []Renumbering{
+				{Record: record(3, "", "docs/3-b.md"), Number: 1, Path: filepath.FromSlash("docs/1-b.md")},
+			},
+		},
+		{
+			name:        "categories apart",
+			records:     []Record{record(1, "", "docs/api/1-a.md"), record(2, "", "docs/web/2-b.md")},
+			perCategory: true,
+			want: []Renumbering{
+				{Record: record(2, "", "docs/web/2-b.md"), Number: 1, Path: filepath.FromSlash("docs/web/1-b.md")},
+			},
+		},
+		{
+			name:    "categories together",
+			records: []Record{record(1, "", "docs/api/1-a.md"), record(3, "", "docs/web/3-b.md")},
+			want: []Renumbering{
+				{Record: record(3, "", "docs/web/3-b.md"), Number: 2, Path: filepath.FromSlash("docs/web/2-b.md")},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := PlanRenumbering(test.records, test.perCategory); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("PlanRenumbering() =\n%+v\nwant\n%+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenumberedFileName(t *testing.T) {
+	tests := []struct {
+		name   string
+		number int
+		want   string
+	}{
+		{"0012-use-kafka.md", 7, "0007-use-kafka.md"},
+		{"12-use-kafka.md", 7, "07-use-kafka.md"},
+		{"9-use-kafka.md", 10, "10-use-kafka.md"},
+		{"use-kafka.md", 7, "use-kafka.md"},
+	}
+	for _, test := range tests {
+		if got := RenumberedFileName(test.name, test.number); got != test.want {
+			t.Errorf("RenumberedFileName(%q, %d) = %q, want %q", test.name, test.number, got, test.want)
+		}
+	}
+}
+
+func TestRenumberLinks(t *testing.T) {
+	record := Record{Number: 5, Path: filepath.FromSlash("docs/5-use-pulsar.md")}
+	plan := []Renumbering{
+		{Record: Record{Number: 4, Path: filepath.FromSlash("docs/4-use-kafka.md")}, Number: 3, Path: filepath.FromSlash("docs/3-use-kafka.md")},
+		{Record: Record{Number: 7, Path: filepath.FromSlash("docs/api/7-use-rest.md")}, Number: 6, Path: filepath.FromSlash("docs/api/6-use-rest.md")},
+	}
+	content := `Supersedes [4. Use Kafka](4-use-kafka.md)
+Relates to [the REST decision](api/7-use-rest.md#decision)
+See [1. Use Go](1-use-go.md) and [the docs](https://example.com/4-use-kafka.md)
+`
+	want := `Supersedes [3. Use Kafka](3-use-kafka.md)
+Relates to [the REST decision](api/6-use-rest.md#decision)
+See [1. Use Go](1-use-go.md) and [the docs](https://example.com/4-use-kafka.md)
+`
+	if got := RenumberLinks(record, []byte(content), plan); string(got) != want {
+		t.Errorf("RenumberLinks() =\n%s\nwant\n%s", got, want)
+	}
+}
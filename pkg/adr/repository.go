@@ -0,0 +1,553 @@
+package adr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// AllScopes the scope reading the base directory and every scope at once
+const AllScopes = "all"
+
+// Names of the files kept in the configuration folder
+const (
+	ConfigFileName   = "config.json"
+	TemplateFileName = "template.md"
+	LockFileName     = ".lock"
+)
+
+// DateFormat the format of the date of new ADRs, unless the configuration has a date_format
+const DateFormat = "02-01-2006 15:04:05"
+
+// Repository an ADR configuration folder and the directory of ADRs it points to.
+// Its methods are safe for concurrent use, e.g. from the handlers of a server, provided Dir and Scope are not changed
+// once it is shared and the configuration is read through Settings.
+type Repository struct {
+	// FS the file system holding both the configuration folder and the ADRs
+	FS FileSystem
+	// ConfigDir the folder holding config.json, template.md and the lock
+	ConfigDir string
+	Config    Config
+
+	// Dir the directory ADRs are read from and written to, the base directory unless a scope is selected
+	Dir string
+	// Scope the selected scope, empty for the base directory
+	Scope string
+	// Category the selected category, a folder of Dir: new ADRs are written in it, and Find only finds its ADRs
+	// when the categories are numbered on their own. Like Dir and Scope, it is set before the repository is shared.
+	Category string
+
+	// Logger receives debug logs of the files read and written, nothing is logged when it is nil
+	Logger *slog.Logger
+
+	subscribers subscribers
+	// writer serializes the writers of this process, the lock file serializes processes
+	writer sync.Mutex
+	// configMu guards Config against the reloads of concurrent writers
+	configMu  sync.RWMutex
+	now       func() time.Time
+	numbering Numbering
+	templates map[string]Template
+}
+
+// discardLogger drops every record, for repositories without a Logger
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.Level(math.MaxInt32)}))
+
+func (r *Repository) log() *slog.Logger {
+	if r.Logger == nil {
+		return discardLogger
+	}
+	return r.Logger
+}
+
+// Init creates the configuration folder, its config and template, and the base directory,
+// on the disk unless WithFS is given. A relative baseDir is kept relative, see BaseDir.
+func Init(configDir string, baseDir string, options ...Option) (*Repository, error) {
+	r := newRepository(configDir, options)
+	r.Config = Config{BaseDir: baseDir}
+	r.Dir = r.BaseDir()
+	if err := r.FS.MkdirAll(r.Dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := r.FS.MkdirAll(configDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := r.Save(); err != nil {
+		return nil, err
+	}
+	if err := r.FS.WriteFile(r.TemplatePath(), []byte(DefaultTemplate), 0644); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Open loads the repository configured in configDir, on the disk unless WithFS is given.
+// It fails with ErrNotInitialized when there is no configuration.
+func Open(configDir string, options ...Option) (*Repository, error) {
+	r := newRepository(configDir, options)
+	if err := r.Reload(); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s does not exist", ErrNotInitialized, r.ConfigPath())
+	} else if err != nil {
+		return nil, err
+	}
+	r.Dir = r.BaseDir()
+	return r, nil
+}
+
+// BaseDir the base directory of the configuration. A relative base_directory is relative to the folder holding the
+// configuration folder, e.g. the root of the repository for a configuration kept in a .adr folder of the repository.
+func (r *Repository) BaseDir() string {
+	r.configMu.RLock()
+	baseDir := r.Config.BaseDir
+	r.configMu.RUnlock()
+	if baseDir == "" || filepath.IsAbs(baseDir) {
+		return baseDir
+	}
+	return filepath.Join(filepath.Dir(filepath.Clean(r.ConfigDir)), filepath.FromSlash(baseDir))
+}
+
+// ConfigPath the path of config.json
+func (r *Repository) ConfigPath() string {
+	return filepath.Join(r.ConfigDir, ConfigFileName)
+}
+
+// TemplatePath the path of the template of new ADRs
+func (r *Repository) TemplatePath() string {
+	return filepath.Join(r.ConfigDir, TemplateFileName)
+}
+
+// LockPath the path of the lock file
+func (r *Repository) LockPath() string {
+	return filepath.Join(r.ConfigDir, LockFileName)
+}
+
+// Reload reads the configuration again, e.g. once the lock is held and concurrent processes are done with it
+func (r *Repository) Reload() error {
+	content, err := r.FS.ReadFile(r.ConfigPath())
+	if err != nil {
+		return err
+	}
+	config := Config{}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return errors.New("invalid " + r.ConfigPath() + ": " + err.Error())
+	}
+	r.configMu.Lock()
+	r.Config = config
+	r.configMu.Unlock()
+	r.log().Debug("configuration loaded", "path", r.ConfigPath(), "base_directory", config.BaseDir, "current_id", config.CurrentAdr)
+	return nil
+}
+
+// Save writes the configuration
+func (r *Repository) Save() error {
+	config := r.Settings()
+	content, err := json.MarshalIndent(config, "", " ")
+	if err != nil {
+		return err
+	}
+	r.log().Debug("writing configuration", "path", r.ConfigPath(), "current_id", config.CurrentAdr)
+	return r.FS.WriteFile(r.ConfigPath(), content, 0644)
+}
+
+// Settings a copy of the configuration, consistent even while another goroutine reloads it
+func (r *Repository) Settings() Config {
+	r.configMu.RLock()
+	defer r.configMu.RUnlock()
+	config := r.Config
+	config.Scopes = copyMap(r.Config.Scopes)
+	config.Aliases = copyMap(r.Config.Aliases)
+	config.Roots = copyMap(r.Config.Roots)
+	config.Webhooks = append([]Webhook(nil), r.Config.Webhooks...)
+	config.Slack = append([]SlackHook(nil), r.Config.Slack...)
+	config.Credentials = append([]Credential(nil), r.Config.Credentials...)
+	if r.Config.Jira != nil {
+		jira := *r.Config.Jira
+		jira.Statuses = copyMap(r.Config.Jira.Statuses)
+		config.Jira = &jira
+	}
+	if r.Config.Confluence != nil {
+		confluence := *r.Config.Confluence
+		config.Confluence = &confluence
+	}
+	if r.Config.Discussions != nil {
+		discussions := *r.Config.Discussions
+		discussions.Statuses = append([]string(nil), r.Config.Discussions.Statuses...)
+		config.Discussions = &discussions
+	}
+	if r.Config.Notion != nil {
+		notion := *r.Config.Notion
+		notion.Properties = copyMap(r.Config.Notion.Properties)
+		config.Notion = &notion
+	}
+	if r.Config.Digest != nil {
+		digest := *r.Config.Digest
+		digest.To = append([]string(nil), r.Config.Digest.To...)
+		config.Digest = &digest
+	}
+	if r.Config.Translation != nil {
+		translation := *r.Config.Translation
+		config.Translation = &translation
+	}
+	if r.Config.Diagrams != nil {
+		diagrams := *r.Config.Diagrams
+		config.Diagrams = &diagrams
+	}
+	if r.Config.PDF != nil {
+		pdf := *r.Config.PDF
+		pdf.Args = append([]string(nil), r.Config.PDF.Args...)
+		config.PDF = &pdf
+	}
+	config.Tags = append(Taxonomy(nil), r.Config.Tags...)
+	if r.Config.Approvals != nil {
+		approvals := *r.Config.Approvals
+		approvals.Approvers = append([]string(nil), r.Config.Approvals.Approvers...)
+		config.Approvals = &approvals
+	}
+	if r.Config.Signing != nil {
+		signing := *r.Config.Signing
+		signing.Signers = append([]string(nil), r.Config.Signing.Signers...)
+		config.Signing = &signing
+	}
+	if r.Config.Toc != nil {
+		toc := *r.Config.Toc
+		config.Toc = &toc
+	}
+	if r.Config.Lint != nil {
+		lint := *r.Config.Lint
+		lint.RequiredSections = append([]string(nil), r.Config.Lint.RequiredSections...)
+		config.Lint = &lint
+	}
+	return config
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
+
+// CreateOptions describes the ADR written by Create
+type CreateOptions struct {
+	Title  string
+	Author string
+	// Draft writes a draft ADR, that only gets its number once finalized
+	Draft bool
+	// Template the name of the template to use, the default template when empty
+	Template string
+	// Status the initial status, Proposed when empty
+	Status Status
+	Tags   []string
+	// Summary a one-line summary of the decision, available to templates as .Summary
+	Summary string
+	// ReviewBy the date the decision is to be reviewed, or the interval after its creation, see ReviewByDate.
+	// It defaults to the ReviewInterval of the configuration.
+	ReviewBy string
+	// Approvers who must approve the decision before it is accepted, defaults to the approvers of the configuration
+	Approvers []string
+	// AllowNewTags allows the tags that are not in the taxonomy of the configuration, rejected with ErrUnknownTag otherwise
+	AllowNewTags bool
+}
+
+// templateData the data templates are executed with: the Record being created and the extra CreateOptions
+type templateData struct {
+	Record
+	Summary string
+}
+
+// Create writes a new ADR with the next number, or a draft ADR, then publishes RecordCreated
+func (r *Repository) Create(ctx context.Context, options CreateOptions) (Record, error) {
+	record, err := r.create(ctx, options)
+	if err != nil {
+		return record, err
+	}
+	r.publish(RecordCreated{Record: record})
+	return record, nil
+}
+
+// Render executes the template of options for an ADR numbered number, as Create would write it, without writing anything
+func (r *Repository) Render(options CreateOptions, number int) ([]byte, error) {
+	record, err := r.newRecord(options)
+	if err != nil {
+		return nil, err
+	}
+	record.Number = number
+	tmpl, err := r.parseTemplate(options.Template)
+	if err != nil {
+		return nil, err
+	}
+	return render(tmpl, record, options)
+}
+
+// newRecord the record of a new ADR, yet without number
+func (r *Repository) newRecord(options CreateOptions) (Record, error) {
+	status := Proposed
+	if options.Status != "" {
+		var err error
+		if status, err = ParseStatus(string(options.Status)); err != nil {
+			return Record{}, err
+		}
+	}
+	settings := r.Settings()
+	if unknown := settings.Tags.Unknown(options.Tags); len(unknown) > 0 && !options.AllowNewTags {
+		return Record{}, fmt.Errorf("%w %s, expected tags of the taxonomy of %s", ErrUnknownTag, strings.Join(unknown, ", "), r.ConfigPath())
+	}
+	// the tags of the taxonomy are written as it spells them
+	var tags []string
+	for _, tag := range options.Tags {
+		if definition, ok := settings.Tags.Lookup(tag); ok {
+			tag = definition.Name
+		}
+		tags = append(tags, tag)
+	}
+	reviewBy := options.ReviewBy
+	if reviewBy == "" {
+		reviewBy = settings.ReviewInterval
+	}
+	approvers := options.Approvers
+	if len(approvers) == 0 && settings.Approvals != nil {
+		approvers = settings.Approvals.Approvers
+	}
+	if reviewBy != "" {
+		var err error
+		if reviewBy, err = ReviewByDate(reviewBy, r.clock()); err != nil {
+			return Record{}, err
+		}
+	}
+	return Record{
+		Title:     strings.TrimSpace(options.Title),
+		Date:      r.clock().Format(settings.DateLayout()),
+		Author:    options.Author,
+		Status:    status,
+		Tags:      tags,
+		ReviewBy:  reviewBy,
+		Approvers: approvers,
+	}, nil
+}
+
+// parseTemplate parses the template named name, the default template when empty
+func (r *Repository) parseTemplate(name string) (*template.Template, error) {
+	t, err := r.Template(name)
+	if err != nil {
+		return nil, err
+	}
+	return template.New(t.Name).Funcs(templateFuncs).Parse(t.Content)
+}
+
+// render executes tmpl for record
+func render(tmpl *template.Template, record Record, options CreateOptions) ([]byte, error) {
+	var content bytes.Buffer
+	if err := tmpl.Execute(&content, templateData{Record: record, Summary: strings.TrimSpace(options.Summary)}); err != nil {
+		return nil, err
+	}
+	// the templates written before these fields get them next to their date, those without a date go without
+	rendered := Parse("", content.Bytes())
+	written := content.Bytes()
+	if record.ReviewBy != "" && rendered.ReviewBy == "" {
+		if withField, err := SetField(written, "Review by", record.ReviewBy); err == nil {
+			written = withField
+		}
+	}
+	if len(record.Approvers) > 0 && len(rendered.Approvers) == 0 {
+		if withField, err := SetField(written, "Approvers", strings.Join(record.Approvers, ", ")); err == nil {
+			written = withField
+		}
+	}
+	return written, nil
+}
+
+// create writes a new ADR under the lock, subscribers are only notified once it is released
+func (r *Repository) create(ctx context.Context, options CreateOptions) (Record, error) {
+	record, err := r.newRecord(options)
+	if err != nil {
+		return Record{}, err
+	}
+	tmpl, err := r.parseTemplate(options.Template)
+	if err != nil {
+		return Record{}, err
+	}
+
+	release, err := r.Lock(ctx)
+	if err != nil {
+		return Record{}, err
+	}
+	defer release()
+	if err := r.Reload(); err != nil {
+		return Record{}, err
+	}
+
+	if options.Draft {
+		record.Draft = NewDraftID()
+	} else if record.Number, err = r.ClaimNumber(ctx); err != nil {
+		return Record{}, err
+	}
+	content, err := render(tmpl, record, options)
+	if err != nil {
+		return Record{}, err
+	}
+
+	dir := r.categoryDir()
+	if err := r.FS.MkdirAll(dir, 0755); err != nil {
+		return Record{}, err
+	}
+	record.Category = r.Category
+	if options.Draft {
+		record.Path = filepath.Join(dir, UniqueFileName(r.FS, dir, record.Draft, record.Title))
+		r.log().Debug("writing draft ADR", "path", record.Path, "draft", record.Draft)
+		return record, r.FS.WriteFile(record.Path, SetHeadingNumber(content, record.Draft), 0644)
+	}
+	record.Path = filepath.Join(dir, r.FileName(record.Number, record.Title))
+	r.log().Debug("writing ADR", "path", record.Path, "number", record.Number)
+	return record, r.FS.WriteFile(record.Path, content, 0644)
+}
+
+// List parses the ADRs of the repository directory, sorted by number, then publishes IndexRebuilt
+func (r *Repository) List(ctx context.Context) ([]Record, error) {
+	records, err := r.list(ctx)
+	if err == nil {
+		r.publish(IndexRebuilt{Dir: r.Dir, Records: records})
+	}
+	return records, err
+}
+
+func (r *Repository) list(ctx context.Context) ([]Record, error) {
+	records, err := ReadDir(ctx, r.FS, r.Dir)
+	for i := range records {
+		records[i].Scope = r.Scope
+	}
+	return records, err
+}
+
+// Find returns the ADR with the given number, in the selected Category with PerCategoryNumbering, outside of any
+// category when none is selected, failing with ErrAdrNotFound when there is none and with ErrDuplicateNumber when
+// several ADRs share it
+func (r *Repository) Find(ctx context.Context, number int) (Record, error) {
+	records, err := r.list(ctx)
+	if err != nil {
+		return Record{}, err
+	}
+	perCategory := r.Settings().PerCategory()
+	found := []Record{}
+	for _, record := range records {
+		if record.Number == number && (!perCategory || record.Category == r.Category) {
+			found = append(found, record)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Record{}, fmt.Errorf("%w: no ADR number %d in %s", ErrAdrNotFound, number, r.categoryDir())
+	case 1:
+		return found[0], nil
+	default:
+		return Record{}, fmt.Errorf("%w: ADR number %d is used by %s and %s", ErrDuplicateNumber, number, r.relativePath(found[0].Path), r.relativePath(found[1].Path))
+	}
+}
+
+// categoryDir the folder of the selected category, Dir when none is selected
+func (r *Repository) categoryDir() string {
+	return filepath.Join(r.Dir, r.Category)
+}
+
+// relativePath the path of an ADR file relative to Dir, e.g. "platform/3-use-go.md", for messages
+func (r *Repository) relativePath(path string) string {
+	if rel, err := filepath.Rel(r.Dir, path); err == nil {
+		return rel
+	}
+	return filepath.Base(path)
+}
+
+// Transition changes the status of the ADR record, as found by Find, rewriting its file in place, then publishes
+// StatusChanged. The status must be one of Statuses, it fails with ErrInvalidStatus otherwise.
+func (r *Repository) Transition(ctx context.Context, record Record, status Status) (Record, error) {
+	status, err := ParseStatus(string(status))
+	if err != nil {
+		return Record{}, err
+	}
+	from, record, err := r.transition(ctx, record, status)
+	if err != nil {
+		return record, err
+	}
+	if from != record.Status {
+		r.publish(StatusChanged{Record: record, From: from, To: record.Status})
+	}
+	return record, nil
+}
+
+// Rewrite replaces the content of the file of record, a draft as well, with what rewrite makes of it, under the lock
+func (r *Repository) Rewrite(ctx context.Context, record Record, rewrite func([]byte) ([]byte, error)) (Record, error) {
+	release, err := r.Lock(ctx)
+	if err != nil {
+		return Record{}, err
+	}
+	defer release()
+
+	content, err := r.FS.ReadFile(record.Path)
+	if err != nil {
+		return Record{}, err
+	}
+	updated, err := rewrite(content)
+	if err != nil {
+		return Record{}, errors.New(record.Path + ": " + err.Error())
+	}
+	r.log().Debug("rewriting ADR", "path", record.Path)
+	if err := r.FS.WriteFile(record.Path, updated, 0644); err != nil {
+		return Record{}, err
+	}
+	updatedRecord, err := ParseFile(r.FS, record.Path)
+	updatedRecord.Scope, updatedRecord.Category = r.Scope, record.Category
+	return updatedRecord, err
+}
+
+// transition rewrites the status of the ADR found as found under the lock, returning its previous status. The ADR
+// is read again once the lock is held, concurrent writers may have changed it.
+func (r *Repository) transition(ctx context.Context, found Record, status Status) (Status, Record, error) {
+	release, err := r.Lock(ctx)
+	if err != nil {
+		return "", Record{}, err
+	}
+	defer release()
+
+	record, err := ParseFile(r.FS, found.Path)
+	if os.IsNotExist(err) {
+		return "", Record{}, fmt.Errorf("%w: %s does not exist", ErrAdrNotFound, r.relativePath(found.Path))
+	}
+	if err != nil {
+		return "", Record{}, err
+	}
+	record.Scope, record.Category = r.Scope, found.Category
+	if status == Accepted && record.Status != Accepted {
+		if q := r.Quorum(record); !q.Met() {
+			return "", record, fmt.Errorf("%w for ADR %s: %s, waiting for %s", ErrQuorumNotMet, record.Ref(), q, strings.Join(q.Missing(), ", "))
+		}
+	}
+	content, err := r.FS.ReadFile(record.Path)
+	if err != nil {
+		return "", Record{}, err
+	}
+	updated, err := SetStatus(content, status)
+	if err != nil {
+		return "", Record{}, errors.New(record.Path + ": " + err.Error())
+	}
+	r.log().Debug("writing ADR status", "path", record.Path, "from", record.Status, "to", status)
+	if err := r.FS.WriteFile(record.Path, updated, 0644); err != nil {
+		return "", Record{}, err
+	}
+	updatedRecord, err := ParseFile(r.FS, record.Path)
+	updatedRecord.Scope, updatedRecord.Category = r.Scope, record.Category
+	return record.Status, updatedRecord, err
+}
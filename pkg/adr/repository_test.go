@@ -0,0 +1,158 @@
+package adr
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testRepository an initialized repository in memory, dating its ADRs with a fixed clock
+func testRepository(t *testing.T) *Repository {
+	t.Helper()
+	now := func() time.Time { return time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC) }
+	repo, err := Init("/home/.adr", "/docs/adr", WithFS(NewMemFS()), WithClock(now))
+	if err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	return repo
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	repo := testRepository(t)
+
+	first, err := repo.Create(ctx, CreateOptions{Title: "Use Postgres", Author: "Ann", Tags: []string{"data"}})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	second, err := repo.Create(ctx, CreateOptions{Title: "Use Kafka"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if first.Path != "/docs/adr/1-use-postgres.md" || second.Path != "/docs/adr/2-use-kafka.md" {
+		t.Errorf("Create() wrote %s and %s, want /docs/adr/1-use-postgres.md and /docs/adr/2-use-kafka.md", first.Path, second.Path)
+	}
+
+	record, err := repo.Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("Find() failed: %v", err)
+	}
+	want := Record{
+		Number: 1,
+		Title:  "Use Postgres",
+		Date:   "16-10-2026 10:30:00",
+		Author: "Ann",
+		Status: Proposed,
+		Path:   "/docs/adr/1-use-postgres.md",
+		Format: FormatNative,
+		Tags:   []string{"data"},
+	}
+	if !reflect.DeepEqual(record, want) {
+		t.Errorf("Find(1) =\n%+v\nwant\n%+v", record, want)
+	}
+	if repo.Settings().CurrentAdr != 2 {
+		t.Errorf("the counter is %d once 2 ADRs are written, want 2", repo.Settings().CurrentAdr)
+	}
+}
+
+func TestTransition(t *testing.T) {
+	ctx := context.Background()
+	repo := testRepository(t)
+	created, err := repo.Create(ctx, CreateOptions{Title: "Use Postgres"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	record, err := repo.Transition(ctx, created, "accepted")
+	if err != nil {
+		t.Fatalf("Transition() failed: %v", err)
+	}
+	if record.Status != Accepted {
+		t.Errorf("Transition() returned the status %q, want Accepted", record.Status)
+	}
+	if found, _ := repo.Find(ctx, 1); found.Status != Accepted {
+		t.Errorf("the ADR read again has the status %q, want Accepted", found.Status)
+	}
+	if _, err := repo.Transition(ctx, created, "rejected"); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("Transition() to an unknown status failed with %v, want ErrInvalidStatus", err)
+	}
+	missing := Record{Number: 2, Path: "/docs/adr/2-use-kafka.md"}
+	if _, err := repo.Transition(ctx, missing, Accepted); !errors.Is(err, ErrAdrNotFound) {
+		t.Errorf("Transition() of a missing ADR failed with %v, want ErrAdrNotFound", err)
+	}
+}
+
+func TestPerCategoryNumbering(t *testing.T) {
+	ctx := context.Background()
+	repo := testRepository(t)
+	repo.Config.CategoryNumbering = PerCategoryNumbering
+	if err := repo.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if _, err := repo.Create(ctx, CreateOptions{Title: "Use Postgres"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	repo.Category = "data"
+	kafka, err := repo.Create(ctx, CreateOptions{Title: "Use Kafka"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if kafka.Path != "/docs/adr/data/1-use-kafka.md" {
+		t.Fatalf("Create() wrote %s, want /docs/adr/data/1-use-kafka.md", kafka.Path)
+	}
+
+	tests := []struct {
+		category string
+		want     string
+	}{
+		{"", "/docs/adr/1-use-postgres.md"},
+		{"data", "/docs/adr/data/1-use-kafka.md"},
+	}
+	for _, test := range tests {
+		repo.Category = test.category
+		record, err := repo.Find(ctx, 1)
+		if err != nil || record.Path != test.want {
+			t.Errorf("Find(1) in the category %q = %s, %v, want %s", test.category, record.Path, err, test.want)
+		}
+	}
+
+	// the ADR found in its category changes status whatever the category selected
+	repo.Category = ""
+	record, err := repo.Transition(ctx, kafka, Accepted)
+	if err != nil {
+		t.Fatalf("Transition() failed: %v", err)
+	}
+	if record.Status != Accepted || record.Category != "data" {
+		t.Errorf("Transition() = %q in %q, want Accepted in data", record.Status, record.Category)
+	}
+	if root, _ := repo.Find(ctx, 1); root.Status != Proposed {
+		t.Errorf("the ADR 1 outside of any category is %q, want Proposed", root.Status)
+	}
+}
+
+func TestAccept(t *testing.T) {
+	ctx := context.Background()
+	repo := testRepository(t)
+	if _, err := repo.Create(ctx, CreateOptions{Title: "Use Postgres"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	record, err := repo.Accept(ctx, 1, false)
+	if err != nil {
+		t.Fatalf("Accept() failed: %v", err)
+	}
+	if record.Status != Accepted || record.AcceptedOn != "2026-10-16" {
+		t.Errorf("Accept() = %q accepted on %q, want Accepted on 2026-10-16", record.Status, record.AcceptedOn)
+	}
+	if _, err := repo.Transition(ctx, record, Deprecated); err != nil {
+		t.Fatalf("Transition() failed: %v", err)
+	}
+	if _, err := repo.Accept(ctx, 1, false); !errors.Is(err, ErrNotInForce) {
+		t.Errorf("Accept() of a deprecated ADR failed with %v, want ErrNotInForce", err)
+	}
+	if record, err := repo.Accept(ctx, 1, true); err != nil || record.Status != Accepted {
+		t.Errorf("Accept() with force = %q, %v, want Accepted", record.Status, err)
+	}
+}
@@ -0,0 +1,152 @@
+package adr
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReviewDateFormat the format of the review-by dates adr writes
+const ReviewDateFormat = "2006-01-02"
+
+var (
+	// intervalRegexp an interval written as a number of days, weeks, months or years
+	intervalRegexp = regexp.MustCompile(`^(\d+)\s*([dwmy])$`)
+	// headerFieldRegexp a "Key: value" line of the header of an ADR, plain or as a bullet
+	headerFieldRegexp = regexp.MustCompile(`^([*-]\s+)?([A-Za-z][A-Za-z _-]*?)\s*:\s*(.*)$`)
+)
+
+// AddInterval adds to t an interval written as a number of days, weeks, months or years: 90d, 2w, 6m or 1y
+func AddInterval(t time.Time, interval string) (time.Time, error) {
+	m := intervalRegexp.FindStringSubmatch(strings.ToLower(strings.TrimSpace(interval)))
+	if m == nil {
+		return time.Time{}, errors.New("invalid interval '" + interval + "', expected a number of days, weeks, months or years such as 90d, 2w, 6m or 1y")
+	}
+	n, _ := strconv.Atoi(m[1])
+	switch m[2] {
+	case "d":
+		return t.AddDate(0, 0, n), nil
+	case "w":
+		return t.AddDate(0, 0, 7*n), nil
+	case "m":
+		return t.AddDate(0, n, 0), nil
+	}
+	return t.AddDate(n, 0, 0), nil
+}
+
+// ReviewByDate the review-by date value stands for, written YYYY-MM-DD: a date, or an interval after now
+// such as 6m or 1y, see AddInterval
+func ReviewByDate(value string, now time.Time) (string, error) {
+	if date, err := ParseDate(value); err == nil {
+		return date.Format(ReviewDateFormat), nil
+	}
+	date, err := AddInterval(now, value)
+	if err != nil {
+		return "", errors.New("invalid review-by date '" + value + "', expected a date written YYYY-MM-DD or an interval such as 6m or 1y")
+	}
+	return date.Format(ReviewDateFormat), nil
+}
+
+// InForce tells whether record is a decision in force: neither a draft nor proposed, deprecated or superseded.
+// Only the decisions in force are reviewed.
+func (r Record) InForce() bool {
+	return r.Draft == "" && r.Status != Proposed && r.Status != Deprecated && r.Status != Superseded
+}
+
+// Review the review of a decision in force, due on its review-by date
+type Review struct {
+	Record Record
+	Due    time.Time
+}
+
+// Overdue tells whether the review was due before the day of now
+func (r Review) Overdue(now time.Time) bool {
+	return r.Due.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, r.Due.Location()))
+}
+
+// DueReviews the reviews of the decisions in force of records due before until, overdue ones included, soonest
+// first. The review-by dates that cannot be read are left out.
+func DueReviews(records []Record, until time.Time) []Review {
+	reviews := []Review{}
+	for _, record := range records {
+		if !record.InForce() || record.ReviewBy == "" {
+			continue
+		}
+		due, err := ParseDate(record.ReviewBy)
+		if err != nil || due.After(until) {
+			continue
+		}
+		reviews = append(reviews, Review{Record: record, Due: due})
+	}
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].Due.Before(reviews[j].Due) })
+	return reviews
+}
+
+// SetField writes a "key: value" field in the header of ADR content, as its format writes its fields:
+// the field is replaced when the header has it, and added after the date and the fields following it otherwise
+func SetField(content []byte, key string, value string) ([]byte, error) {
+	lines := strings.Split(string(content), "\n")
+	normalize := func(key string) string {
+		return strings.NewReplacer("-", " ", "_", " ").Replace(strings.ToLower(strings.TrimSpace(key)))
+	}
+	want := normalize(key)
+	// the line to insert the field after, and how the header writes its fields
+	after, format := -1, func(key string, value string) string { return key + ": " + value }
+	inFrontMatter := len(lines) > 0 && strings.TrimSpace(lines[0]) == "---"
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if inFrontMatter {
+			if i > 0 && line == "---" {
+				inFrontMatter = false
+				continue
+			}
+			m := frontMatterFieldRegexp.FindStringSubmatch(line)
+			switch {
+			case m == nil:
+			case normalize(m[1]) == want:
+				lines[i] = m[1] + ": " + value + crSuffix(raw)
+				return []byte(strings.Join(lines, "\n")), nil
+			case strings.EqualFold(m[1], "date"):
+				after = i
+				format = func(key string, value string) string {
+					return strings.Replace(strings.ToLower(key), " ", "-", -1) + ": " + value
+				}
+			}
+			continue
+		}
+		if sectionRegexp.MatchString(line) {
+			break
+		}
+		m := headerFieldRegexp.FindStringSubmatch(line)
+		switch {
+		case m == nil:
+		case normalize(m[2]) == want:
+			lines[i] = m[1] + m[2] + ": " + value + crSuffix(raw)
+			return []byte(strings.Join(lines, "\n")), nil
+		case strings.EqualFold(m[2], "date"):
+			marker := m[1]
+			after = i
+			format = func(key string, value string) string { return marker + key + ": " + value }
+		case after == i-1 && after >= 0:
+			// the fields following the date, the new field goes after them
+			after = i
+		}
+	}
+	if after < 0 {
+		return nil, errors.New("no date found to write " + key + " next to")
+	}
+	field := format(key, value) + crSuffix(lines[after])
+	lines = append(lines[:after+1], append([]string{field}, lines[after+1:]...)...)
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// crSuffix the carriage return ending line, if any, for the lines written in its place to keep the line endings
+func crSuffix(line string) string {
+	if strings.HasSuffix(line, "\r") {
+		return "\r"
+	}
+	return ""
+}
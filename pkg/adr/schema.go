@@ -0,0 +1,205 @@
+package adr
+
+import "time"
+
+// SchemaVersion the version of the JSON documents below, it changes only when a field is removed or changes meaning.
+// New fields can be added without changing it, consumers should ignore the fields they do not know.
+const SchemaVersion = 1
+
+// RecordJSON the stable JSON representation of a Record
+type RecordJSON struct {
+	ID       string     `json:"id"`
+	Number   int        `json:"number,omitempty"`
+	Draft    string     `json:"draft,omitempty"`
+	Title    string     `json:"title"`
+	Date     string     `json:"date,omitempty"`
+	Author   string     `json:"author,omitempty"`
+	Status   Status     `json:"status,omitempty"`
+	Format   Format     `json:"format"`
+	Path     string     `json:"path"`
+	Scope    string     `json:"scope,omitempty"`
+	Category string     `json:"category,omitempty"`
+	Links    []LinkJSON `json:"links"`
+	Tags     []string   `json:"tags,omitempty"`
+	ReviewBy string     `json:"review_by,omitempty"`
+	Deadline string     `json:"deadline,omitempty"`
+	// AcceptedOn the date the decision was accepted, see Repository.Accept
+	AcceptedOn string     `json:"accepted_on,omitempty"`
+	Approvers  []string   `json:"approvers,omitempty"`
+	Approvals  []Approval `json:"approvals,omitempty"`
+	Archived   bool       `json:"archived,omitempty"`
+	// Body the markdown of the ADR, only given by the json export format
+	Body string `json:"body,omitempty"`
+}
+
+// LinkJSON the stable JSON representation of a Link
+type LinkJSON struct {
+	Kind   string `json:"kind"`
+	Title  string `json:"title,omitempty"`
+	Target string `json:"target"`
+	Line   int    `json:"line,omitempty"`
+}
+
+// ListingJSON a versioned list of ADRs
+type ListingJSON struct {
+	SchemaVersion int          `json:"schema_version"`
+	Records       []RecordJSON `json:"records"`
+}
+
+// DocumentJSON the versioned JSON of an ADR with its markdown content
+type DocumentJSON struct {
+	SchemaVersion int        `json:"schema_version"`
+	Record        RecordJSON `json:"record"`
+	Content       string     `json:"content"`
+}
+
+// GraphJSON the versioned graph of the links between ADRs, edges refer to records by path
+type GraphJSON struct {
+	SchemaVersion int          `json:"schema_version"`
+	Records       []RecordJSON `json:"records"`
+	Edges         []EdgeJSON   `json:"edges"`
+}
+
+// EdgeJSON the stable JSON representation of an Edge
+type EdgeJSON struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// The events of ChangeJSON, created and status changes being named like the events of a Repository
+const (
+	ChangeCreated       = "record_created"
+	ChangeUpdated       = "record_updated"
+	ChangeStatusChanged = "status_changed"
+	ChangeRemoved       = "record_removed"
+)
+
+// ChangeJSON a change of an ADR file, From and To being set for status changes
+type ChangeJSON struct {
+	SchemaVersion int        `json:"schema_version"`
+	Event         string     `json:"event"`
+	Record        RecordJSON `json:"record"`
+	From          Status     `json:"from,omitempty"`
+	To            Status     `json:"to,omitempty"`
+}
+
+// LintReportJSON the versioned result of validating ADRs
+type LintReportJSON struct {
+	SchemaVersion int       `json:"schema_version"`
+	Ok            bool      `json:"ok"`
+	Findings      []Finding `json:"findings"`
+}
+
+// ReviewJSON the stable JSON representation of a Review, Due written YYYY-MM-DD
+type ReviewJSON struct {
+	Record  RecordJSON `json:"record"`
+	Due     string     `json:"due"`
+	Overdue bool       `json:"overdue"`
+}
+
+// ReviewReportJSON the versioned report of the reviews due, soonest first
+type ReviewReportJSON struct {
+	SchemaVersion int          `json:"schema_version"`
+	Reviews       []ReviewJSON `json:"reviews"`
+}
+
+// StatsJSON the versioned stats of ADRs
+type StatsJSON struct {
+	SchemaVersion int `json:"schema_version"`
+	Stats
+}
+
+// DiffJSON the versioned comparison of two ADRs section by section, the revisions being the git revisions they were
+// read at, if any
+type DiffJSON struct {
+	SchemaVersion int             `json:"schema_version"`
+	From          RecordJSON      `json:"from"`
+	FromRevision  string          `json:"from_revision,omitempty"`
+	To            RecordJSON      `json:"to"`
+	ToRevision    string          `json:"to_revision,omitempty"`
+	Sections      []SectionChange `json:"sections"`
+}
+
+// JSON converts a record to its stable JSON representation
+func (r Record) JSON() RecordJSON {
+	links := []LinkJSON{}
+	for _, link := range r.Links {
+		links = append(links, LinkJSON{Kind: link.Kind, Title: link.Title, Target: link.Target, Line: link.Line})
+	}
+	return RecordJSON{
+		ID:         r.ID(),
+		Number:     r.Number,
+		Draft:      r.Draft,
+		Title:      r.Title,
+		Date:       r.Date,
+		Author:     r.Author,
+		Status:     r.Status,
+		Format:     r.Format,
+		Path:       r.Path,
+		Scope:      r.Scope,
+		Category:   r.Category,
+		Links:      links,
+		Tags:       r.Tags,
+		ReviewBy:   r.ReviewBy,
+		Deadline:   r.Deadline,
+		AcceptedOn: r.AcceptedOn,
+		Approvers:  r.Approvers,
+		Approvals:  r.Approvals,
+		Archived:   r.Archived,
+	}
+}
+
+// NewListingJSON the versioned listing of records
+func NewListingJSON(records []Record) ListingJSON {
+	listing := ListingJSON{SchemaVersion: SchemaVersion, Records: []RecordJSON{}}
+	for _, record := range records {
+		listing.Records = append(listing.Records, record.JSON())
+	}
+	return listing
+}
+
+// NewDocumentJSON the versioned document of a record and the content of its file
+func NewDocumentJSON(record Record, content []byte) DocumentJSON {
+	return DocumentJSON{SchemaVersion: SchemaVersion, Record: record.JSON(), Content: string(content)}
+}
+
+// NewGraphJSON the versioned graph of records and of the links between them
+func NewGraphJSON(records []Record) GraphJSON {
+	graph := GraphJSON{SchemaVersion: SchemaVersion, Records: NewListingJSON(records).Records, Edges: []EdgeJSON{}}
+	for _, edge := range Edges(records) {
+		graph.Edges = append(graph.Edges, EdgeJSON{From: edge.From.Path, To: edge.To.Path, Kind: edge.Kind})
+	}
+	return graph
+}
+
+// NewLintReportJSON the versioned report of findings, Ok when there is no error, warnings aside
+func NewLintReportJSON(findings []Finding) LintReportJSON {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	return LintReportJSON{SchemaVersion: SchemaVersion, Ok: CountErrors(findings) == 0, Findings: findings}
+}
+
+// NewReviewReportJSON the versioned report of reviews, overdue when due before the day of now
+func NewReviewReportJSON(reviews []Review, now time.Time) ReviewReportJSON {
+	report := ReviewReportJSON{SchemaVersion: SchemaVersion, Reviews: []ReviewJSON{}}
+	for _, review := range reviews {
+		report.Reviews = append(report.Reviews, ReviewJSON{
+			Record:  review.Record.JSON(),
+			Due:     review.Due.Format(ReviewDateFormat),
+			Overdue: review.Overdue(now),
+		})
+	}
+	return report
+}
+
+// NewStatsJSON the versioned stats
+func NewStatsJSON(stats Stats) StatsJSON {
+	return StatsJSON{SchemaVersion: SchemaVersion, Stats: stats}
+}
+
+// NewDiffJSON the versioned comparison of the sections of from and to, read at the given git revisions
+func NewDiffJSON(from Record, fromRevision string, to Record, toRevision string, sections []SectionChange) DiffJSON {
+	return DiffJSON{SchemaVersion: SchemaVersion, From: from.JSON(), FromRevision: fromRevision, To: to.JSON(), ToRevision: toRevision, Sections: sections}
+}
@@ -0,0 +1,73 @@
+package adr
+
+import (
+	"sort"
+	"strings"
+)
+
+// SearchMatch a line of the content of an ADR holding terms of a search
+type SearchMatch struct {
+	// Line the number of the line, from 1
+	Line int
+	Text string
+}
+
+// SearchHit an ADR holding every term of a search, in its title or its content
+type SearchHit struct {
+	Record Record
+	// InTitle tells whether terms are in the title
+	InTitle bool
+	// Matches the lines of the content holding terms, the heading of the title aside
+	Matches []SearchMatch
+}
+
+// Search finds the records, read with fsys, holding every term in their title or content, compared case-insensitively.
+// The hits with terms in their title come first, then those with the most matching lines, then in the order of records.
+func Search(fsys FileSystem, records []Record, terms []string) ([]SearchHit, error) {
+	lowered := []string{}
+	for _, term := range terms {
+		if term = strings.ToLower(strings.TrimSpace(term)); term != "" {
+			lowered = append(lowered, term)
+		}
+	}
+	hits := []SearchHit{}
+	if len(lowered) == 0 {
+		return hits, nil
+	}
+	for _, record := range records {
+		content, err := fsys.ReadFile(record.Path)
+		if err != nil {
+			return nil, err
+		}
+		text, title := strings.ToLower(string(content)), strings.ToLower(record.Title)
+		hit := SearchHit{Record: record}
+		found := true
+		for _, term := range lowered {
+			found = found && (strings.Contains(title, term) || strings.Contains(text, term))
+			hit.InTitle = hit.InTitle || strings.Contains(title, term)
+		}
+		if !found {
+			continue
+		}
+		for i, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if headingRegexp.MatchString(line) && strings.Contains(line, record.Title) {
+				continue
+			}
+			for _, term := range lowered {
+				if strings.Contains(strings.ToLower(line), term) {
+					hit.Matches = append(hit.Matches, SearchMatch{Line: i + 1, Text: line})
+					break
+				}
+			}
+		}
+		hits = append(hits, hit)
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].InTitle != hits[j].InTitle {
+			return hits[i].InTitle
+		}
+		return len(hits[i].Matches) > len(hits[j].Matches)
+	})
+	return hits, nil
+}
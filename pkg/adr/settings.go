@@ -0,0 +1,204 @@
+package adr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownSetting a setting that is not in the configuration, see ConfigSettings
+var ErrUnknownSetting = errors.New("unknown setting")
+
+// Setting a setting of the configuration, named by the path of its JSON keys, e.g. "toc.on_new"
+type Setting struct {
+	Name string
+	Type reflect.Type
+}
+
+// ConfigSettings the settings of the configuration, sorted by name. The fields of its objects, e.g. of "toc", are settings
+// of their own besides the object.
+func ConfigSettings() []Setting {
+	settings := configSettings("", reflect.TypeOf(Config{}))
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Name < settings[j].Name })
+	return settings
+}
+
+func configSettings(prefix string, t reflect.Type) []Setting {
+	settings := []Setting{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		settings = append(settings, Setting{Name: prefix + name, Type: field.Type})
+		nested := field.Type
+		if nested.Kind() == reflect.Ptr {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct {
+			settings = append(settings, configSettings(prefix+name+".", nested)...)
+		}
+	}
+	return settings
+}
+
+// lookupSetting the setting named name, failing with ErrUnknownSetting
+func lookupSetting(name string) (Setting, error) {
+	for _, setting := range ConfigSettings() {
+		if setting.Name == name {
+			return setting, nil
+		}
+	}
+	return Setting{}, fmt.Errorf("%w %q, see adr config list", ErrUnknownSetting, name)
+}
+
+// DateLayout the layout of the dates of new ADRs, the date_format of the configuration, DateFormat by default
+func (c Config) DateLayout() string {
+	if c.DateFormat != "" {
+		return c.DateFormat
+	}
+	return DateFormat
+}
+
+// Get the value of the setting named name, nil when it is not set
+func (c Config) Get(name string) (interface{}, error) {
+	if _, err := lookupSetting(name); err != nil {
+		return nil, err
+	}
+	values, err := configValues(c)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{} = values
+	for _, key := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		value = object[key]
+	}
+	return value, nil
+}
+
+// Set the configuration with the setting named name set to value: text, a number, true or false, after the type of
+// the setting, or JSON for the lists and objects. The values the settings do not allow are an error.
+func (c Config) Set(name string, value string) (Config, error) {
+	setting, err := lookupSetting(name)
+	if err != nil {
+		return c, err
+	}
+	var parsed interface{}
+	switch setting.Type.Kind() {
+	case reflect.String:
+		parsed = value
+	case reflect.Bool:
+		if parsed, err = strconv.ParseBool(value); err != nil {
+			return c, fmt.Errorf("%s is true or false, not %q", name, value)
+		}
+	case reflect.Int:
+		if parsed, err = strconv.Atoi(value); err != nil {
+			return c, fmt.Errorf("%s is a number, not %q", name, value)
+		}
+	default:
+		if err := json.Unmarshal([]byte(value), reflect.New(setting.Type).Interface()); err != nil {
+			return c, fmt.Errorf("%s is written in JSON, e.g. %s: %v", name, jsonExample(setting.Type), err)
+		}
+		if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+			return c, err
+		}
+	}
+	values, err := configValues(c)
+	if err != nil {
+		return c, err
+	}
+	keys := strings.Split(name, ".")
+	object := values
+	for _, key := range keys[:len(keys)-1] {
+		nested, ok := object[key].(map[string]interface{})
+		if !ok {
+			nested = map[string]interface{}{}
+			object[key] = nested
+		}
+		object = nested
+	}
+	object[keys[len(keys)-1]] = parsed
+	content, err := json.Marshal(values)
+	if err != nil {
+		return c, err
+	}
+	config := Config{}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return c, err
+	}
+	return config, config.validate()
+}
+
+// configValues the configuration as JSON values, objects being maps
+func configValues(c Config) (map[string]interface{}, error) {
+	content, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	return values, json.Unmarshal(content, &values)
+}
+
+// jsonExample how a value of type t is written in JSON
+func jsonExample(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Slice:
+		return `["a", "b"]`
+	case reflect.Map:
+		return `{"name": "value"}`
+	default:
+		return `{"key": "value"}`
+	}
+}
+
+// validate checks the settings whose values are restricted
+func (c Config) validate() error {
+	if c.BaseDir == "" {
+		return errors.New("base_directory cannot be empty")
+	}
+	if c.CurrentAdr < 0 {
+		return errors.New("current_id cannot be negative")
+	}
+	if c.CategoryNumbering != "" && c.CategoryNumbering != GlobalNumbering && c.CategoryNumbering != PerCategoryNumbering {
+		return fmt.Errorf("category_numbering is %q or %q, not %q", GlobalNumbering, PerCategoryNumbering, c.CategoryNumbering)
+	}
+	if c.CopyOnNew != "" && c.CopyOnNew != "path" && c.CopyOnNew != "reference" {
+		return fmt.Errorf("copy_on_new is \"path\" or \"reference\", not %q", c.CopyOnNew)
+	}
+	if c.DateFormat != "" {
+		if _, err := ParseDate(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC).Format(c.DateFormat)); err != nil {
+			return fmt.Errorf("date_format %q writes dates adr cannot read back, use one of %s", c.DateFormat, strings.Join(dateLayouts, ", "))
+		}
+	}
+	if c.Toc != nil && c.Toc.File != "" && !IsTocFileName(c.Toc.File) {
+		return fmt.Errorf("toc.file is one of %s, not %q", strings.Join(TocFileNames, ", "), c.Toc.File)
+	}
+	if c.ReviewInterval != "" {
+		if _, err := AddInterval(time.Now(), c.ReviewInterval); err != nil {
+			return fmt.Errorf("review_interval: %v", err)
+		}
+	}
+	return c.Tags.Validate()
+}
+
+// Configure sets the setting named name to value, see Config.Set, and saves the configuration
+func (r *Repository) Configure(name string, value string) error {
+	config, err := r.Settings().Set(name, value)
+	if err != nil {
+		return err
+	}
+	r.configMu.Lock()
+	r.Config = config
+	r.configMu.Unlock()
+	return r.Save()
+}
@@ -0,0 +1,20 @@
+package adr
+
+// SignatureExtension the extension of the detached signatures of the ADRs, written next to them by adr sign
+const SignatureExtension = ".asc"
+
+// SigningConfig the GPG key adr sign signs the ADRs with, and the signers adr verify trusts
+type SigningConfig struct {
+	// Key the key signing the ADRs, a key id, fingerprint or email; the default key of gpg when empty
+	Key string `json:"key,omitempty"`
+	// Program the gpg program, "gpg" when empty
+	Program string `json:"program,omitempty"`
+	// Signers the fingerprints, key ids or emails of the keys allowed to sign ADRs, any good signature being trusted
+	// when empty
+	Signers []string `json:"signers,omitempty"`
+}
+
+// SignaturePath the path of the detached signature of record, e.g. "0003-use-kubernetes.md.asc"
+func (r Record) SignaturePath() string {
+	return r.Path + SignatureExtension
+}
@@ -0,0 +1,113 @@
+package adr
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxSlugLength keeps generated file names readable and well below file system limits
+const maxSlugLength = 60
+
+// transliterations of letters that do not decompose into an ASCII base letter
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "ae", 'œ': "oe", 'Œ': "oe", 'ø': "o", 'Ø': "o",
+	'ł': "l", 'Ł': "l", 'đ': "d", 'Đ': "d", 'ð': "d", 'Ð': "d", 'þ': "th", 'Þ': "th",
+	'ı': "i", '&': "and", '+': "plus", '@': "at",
+}
+
+// Slugify turns an ADR title into a lowercase, dash separated, file name safe string. Letters folding to ASCII once
+// their accents are dropped are folded, e.g. "é" to "e", the letters and digits of other scripts are kept as they are,
+// e.g. "Выбор базы данных" gives "выбор-базы-данных", along with the vowel signs and other marks following them, e.g.
+// "हिन्दी में निर्णय" gives "हिन्दी-में-निर्णय".
+func Slugify(title string) string {
+	var builder strings.Builder
+	dash := false
+	// kept tells whether the last rune written is a letter kept as it is, that the marks following it belong to
+	kept := false
+	for _, r := range norm.NFC.String(title) {
+		replacement, transliterated := transliterations[r]
+		if !transliterated {
+			replacement = asciiFolding(r)
+		}
+		switch {
+		case kept && unicode.In(r, unicode.Mn, unicode.Mc):
+			replacement = string(r)
+		case replacement != "":
+			kept = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			replacement = string(unicode.ToLower(r))
+			kept = unicode.IsLetter(r)
+		default:
+			dash = builder.Len() > 0
+			kept = false
+			continue
+		}
+		if dash {
+			builder.WriteRune('-')
+			dash = false
+		}
+		builder.WriteString(replacement)
+	}
+
+	runes := []rune(builder.String())
+	if len(runes) > maxSlugLength {
+		runes = runes[:maxSlugLength]
+		if cut := lastRune(runes, '-'); cut > maxSlugLength/2 {
+			runes = runes[:cut]
+		}
+	}
+	slug := strings.TrimRight(string(runes), "-")
+	if slug == "" {
+		slug = "untitled"
+	}
+	return slug
+}
+
+// asciiFolding the lowercase ASCII letters and digits r decomposes into once its accents are dropped, e.g. "e" for
+// "é" or "fi" for "ﬁ", empty when r is none of them or decomposes into other letters, e.g. "й"
+func asciiFolding(r rune) string {
+	folded := []rune{}
+	for _, d := range norm.NFKD.String(string(r)) {
+		switch {
+		case unicode.Is(unicode.Mn, d):
+		case d < unicode.MaxASCII && (unicode.IsLetter(d) || unicode.IsDigit(d)):
+			folded = append(folded, unicode.ToLower(d))
+		default:
+			return ""
+		}
+	}
+	return string(folded)
+}
+
+// lastRune the index of the last r of runes, -1 when there is none
+func lastRune(runes []rune, r rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// FileName builds a file name for a new ADR, suffixing the slug when a file with the same name already exists in dir
+func FileName(fsys FileSystem, dir string, number int, title string) string {
+	return UniqueFileName(fsys, dir, strconv.Itoa(number), title)
+}
+
+// UniqueFileName builds a "<prefix>-<slug>.md" file name that does not exist yet in dir, prefix being sanitized,
+// see SanitizeFileName
+func UniqueFileName(fsys FileSystem, dir string, prefix string, title string) string {
+	prefix = SanitizeFileName(prefix) + "-" + Slugify(title)
+	fileName := prefix + ".md"
+	for i := 2; ; i++ {
+		if _, err := fsys.Stat(filepath.Join(dir, fileName)); os.IsNotExist(err) {
+			return fileName
+		}
+		fileName = prefix + "-" + strconv.Itoa(i) + ".md"
+	}
+}
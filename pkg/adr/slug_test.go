@@ -0,0 +1,63 @@
+package adr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Use PostgreSQL", "use-postgresql"},
+		{"  Use   Kafka -- for events!  ", "use-kafka-for-events"},
+		{"Café & crème brûlée", "cafe-and-creme-brulee"},
+		{"Straße, Æther and Łódź", "strasse-aether-and-lodz"},
+		{"ﬁle ① C++", "file-1-cplusplus"},
+		{"Выбор базы данных", "выбор-базы-данных"},
+		{"Επιλογή βάσης δεδομένων", "επιλογή-βάσης-δεδομένων"},
+		{"使用数据库", "使用数据库"},
+		{"データベースの選択", "データベースの選択"},
+		{"데이터베이스 선택", "데이터베이스-선택"},
+		{"हिन्दी में निर्णय", "हिन्दी-में-निर्णय"},
+		{"தமிழ் தேர்வு", "தமிழ்-தேர்வு"},
+		{"!!!", "untitled"},
+		{"", "untitled"},
+	}
+	for _, test := range tests {
+		if got := Slugify(test.title); got != test.want {
+			t.Errorf("Slugify(%q) = %q, want %q", test.title, got, test.want)
+		}
+	}
+}
+
+func TestSlugifyLongTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{strings.Repeat("decision ", 10), "decision-decision-decision-decision-decision-decision"},
+		{strings.Repeat("решение ", 10), "решение-решение-решение-решение-решение-решение-решение"},
+		{strings.Repeat("a", 70), strings.Repeat("a", maxSlugLength)},
+	}
+	for _, test := range tests {
+		if got := Slugify(test.title); got != test.want {
+			t.Errorf("Slugify(%q) = %q, want %q", test.title, got, test.want)
+		}
+	}
+}
+
+func TestUniqueFileName(t *testing.T) {
+	fsys := NewMemFS()
+	fsys.MkdirAll("docs", 0755)
+	fsys.WriteFile("docs/1-use-go.md", []byte("# 1. Use Go\n"), 0644)
+	fsys.WriteFile("docs/1-use-go-2.md", []byte("# 1. Use Go\n"), 0644)
+
+	if got := FileName(fsys, "docs", 1, "Use Go"); got != "1-use-go-3.md" {
+		t.Errorf("FileName() = %q, want 1-use-go-3.md", got)
+	}
+	if got := FileName(fsys, "docs", 2, "Use Go"); got != "2-use-go.md" {
+		t.Errorf("FileName() = %q, want 2-use-go.md", got)
+	}
+}
@@ -0,0 +1,109 @@
+package adr
+
+import (
+	"sort"
+	"time"
+)
+
+// StatsMonthLayout the layout of the months of Stats, e.g. "2026-10"
+const StatsMonthLayout = "2006-01"
+
+// Stats figures about a set of ADRs, see ComputeStats
+type Stats struct {
+	Total int `json:"total"`
+	// ByStatus the number of numbered ADRs by status, Drafts the number of drafts
+	ByStatus map[Status]int `json:"by_status"`
+	Drafts   int            `json:"drafts"`
+	// CreatedByMonth the number of ADRs dated each month, from the first to the last, months without ADRs included
+	CreatedByMonth []MonthCount `json:"created_by_month"`
+	// DaysToAccept the average number of days from the date of the accepted ADRs to their acceptance, over Measured
+	// ADRs: those whose date of acceptance is known
+	DaysToAccept float64 `json:"average_days_to_accept"`
+	Measured     int     `json:"accepted_measured"`
+	// Authors the authors of the ADRs, most active first, counted in AuthorsFrom: AuthorsFromADRs or AuthorsFromGit
+	Authors     []AuthorCount `json:"authors"`
+	AuthorsFrom string        `json:"authors_from"`
+}
+
+// Where the authors of Stats are counted: in the ADRs they wrote, after their Author field, or in the commits they
+// made to the ADRs
+const (
+	AuthorsFromADRs = "adrs"
+	AuthorsFromGit  = "git"
+)
+
+// MonthCount a number of ADRs of a month, as StatsMonthLayout
+type MonthCount struct {
+	Month string `json:"month"`
+	Count int    `json:"count"`
+}
+
+// AuthorCount the activity of an author, in ADRs or in commits
+type AuthorCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ComputeStats the stats of records. The time to accept an ADR goes from its date to its AcceptedOn date, the authors
+// are counted in ADRs.
+func ComputeStats(records []Record) Stats {
+	stats := Stats{Total: len(records), ByStatus: map[Status]int{}, CreatedByMonth: []MonthCount{}}
+	months := map[string]int{}
+	authors := map[string]int{}
+	first, last := time.Time{}, time.Time{}
+	days := 0.0
+	for _, record := range records {
+		if record.Draft != "" {
+			stats.Drafts++
+		} else {
+			stats.ByStatus[record.Status]++
+		}
+		if record.Author != "" {
+			authors[record.Author]++
+		}
+		date, err := ParseDate(record.Date)
+		if err != nil {
+			continue
+		}
+		month := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		months[month.Format(StatsMonthLayout)]++
+		if first.IsZero() || month.Before(first) {
+			first = month
+		}
+		if month.After(last) {
+			last = month
+		}
+		if record.Status != Accepted {
+			continue
+		}
+		// acceptance dates have no time of day, the days are counted from the day of the ADR
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		if accepted, err := ParseDate(record.AcceptedOn); err == nil && !accepted.Before(day) {
+			days += accepted.Sub(day).Hours() / 24
+			stats.Measured++
+		}
+	}
+	for month := first; !first.IsZero() && !month.After(last); month = month.AddDate(0, 1, 0) {
+		stats.CreatedByMonth = append(stats.CreatedByMonth, MonthCount{Month: month.Format(StatsMonthLayout), Count: months[month.Format(StatsMonthLayout)]})
+	}
+	if stats.Measured > 0 {
+		stats.DaysToAccept = days / float64(stats.Measured)
+	}
+	stats.Authors, stats.AuthorsFrom = RankAuthors(authors), AuthorsFromADRs
+	return stats
+}
+
+// RankAuthors the authors of counts, most active first then by name
+func RankAuthors(counts map[string]int) []AuthorCount {
+	authors := []AuthorCount{}
+	for name, count := range counts {
+		authors = append(authors, AuthorCount{Name: name, Count: count})
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Count != authors[j].Count {
+			return authors[i].Count > authors[j].Count
+		}
+		return authors[i].Name < authors[j].Name
+	})
+	return authors
+}
@@ -0,0 +1,223 @@
+package adr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Default wordings of the supersede relationship
+const (
+	DefaultSupersededBy = "Superseded by"
+	DefaultSupersedes   = "Supersedes"
+)
+
+// SupersedeOptions describes how Supersede relates the replaced ADR and the ADR replacing it
+type SupersedeOptions struct {
+	// SupersededBy the wording of the relationship in the replaced ADR, DefaultSupersededBy when empty.
+	// It must still read as the Superseded status, e.g. "Superseded in part by".
+	SupersededBy string
+	// Supersedes the wording of the relationship in the replacing ADR, DefaultSupersedes when empty
+	Supersedes string
+	// Sections the sections of the replaced ADR copied forward into the replacing one, e.g. "Context"
+	Sections []string
+}
+
+func (o SupersedeOptions) wordings() (string, string, error) {
+	supersededBy, supersedes := strings.TrimSpace(o.SupersededBy), strings.TrimSpace(o.Supersedes)
+	if supersededBy == "" {
+		supersededBy = DefaultSupersededBy
+	}
+	if supersedes == "" {
+		supersedes = DefaultSupersedes
+	}
+	if NormalizeStatus(supersededBy) != Superseded {
+		return "", "", fmt.Errorf("%w: the wording %q of the replaced ADR must start with %q", ErrInvalidStatus, supersededBy, Superseded)
+	}
+	return supersededBy, supersedes, nil
+}
+
+// MarkdownLink links from one ADR to another, e.g. "[3. Use X](0003-use-x.md)"
+func MarkdownLink(from Record, to Record) string {
+	return "[" + linkTitle(to) + "](" + linkTarget(from, to) + ")"
+}
+
+// linkTitle the text of the links to an ADR, e.g. "3. Use X"
+func linkTitle(to Record) string {
+	number := to.Draft
+	if number == "" {
+		number = strconv.Itoa(to.Number)
+	}
+	return number + ". " + to.Title
+}
+
+// linkTarget the path of the file of to relative to the file of from, as markdown links write it
+func linkTarget(from Record, to Record) string {
+	target, err := filepath.Rel(filepath.Dir(from.Path), to.Path)
+	if err != nil {
+		target = to.Path
+	}
+	return filepath.ToSlash(target)
+}
+
+// Sections the titles of the "## " sections of ADR content, the status section excepted
+func Sections(content []byte) []string {
+	sections := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		if m := sectionRegexp.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			if title := strings.TrimSpace(m[1]); strings.ToLower(title) != "status" {
+				sections = append(sections, title)
+			}
+		}
+	}
+	return sections
+}
+
+// sectionBody the bounds of the body of the section titled title: from after its heading and underline to the next heading
+func sectionBody(lines []string, title string) (int, int, bool) {
+	for i, line := range lines {
+		m := sectionRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil || !strings.EqualFold(strings.TrimSpace(m[1]), title) {
+			continue
+		}
+		start := i + 1
+		if start < len(lines) && underlineRegexp.MatchString(strings.TrimSpace(lines[start])) {
+			start++
+		}
+		end := start
+		for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), "#") {
+			end++
+		}
+		return start, end, true
+	}
+	return 0, 0, false
+}
+
+// CopySection replaces the body of the section titled title in content with its body in from,
+// the section is appended to content when missing
+func CopySection(content []byte, from []byte, title string) ([]byte, error) {
+	fromLines := strings.Split(string(from), "\n")
+	start, end, ok := sectionBody(fromLines, title)
+	if !ok {
+		return nil, errors.New("no section '" + title + "' to copy")
+	}
+	body := append([]string{}, fromLines[start:end]...)
+	lines := strings.Split(string(content), "\n")
+	if start, end, ok := sectionBody(lines, title); ok {
+		lines = append(lines[:start], append(body, lines[end:]...)...)
+		return []byte(strings.Join(lines, "\n")), nil
+	}
+	heading := fromLines[start-1]
+	section := []string{"## " + title}
+	if underlineRegexp.MatchString(strings.TrimSpace(heading)) {
+		section = append(section, heading)
+	}
+	trimmed := strings.TrimRight(string(content), "\n")
+	return []byte(trimmed + "\n\n" + strings.Join(append(section, body...), "\n")), nil
+}
+
+// SetSection replaces the body of the section titled title in content with body,
+// the section is appended to content when missing
+func SetSection(content []byte, title string, body string) []byte {
+	lines := strings.Split(string(content), "\n")
+	section := []string{"", strings.TrimRight(body, "\n"), ""}
+	if start, end, ok := sectionBody(lines, title); ok {
+		lines = append(lines[:start], append(section, lines[end:]...)...)
+		return []byte(strings.Join(lines, "\n"))
+	}
+	trimmed := strings.TrimRight(string(content), "\n")
+	return []byte(trimmed + "\n\n## " + title + "\n" + strings.Join(section, "\n"))
+}
+
+// SupersedeContent rewrites the content of the replaced ADR old and of the replacing ADR by: old becomes Superseded
+// with a link to by, by links back to old and gets the sections of old listed in options
+func SupersedeContent(old Record, oldContent []byte, by Record, newContent []byte, options SupersedeOptions) ([]byte, []byte, error) {
+	supersededBy, supersedes, err := options.wordings()
+	if err != nil {
+		return nil, nil, err
+	}
+	oldUpdated, err := setStatusLine(oldContent, func(location statusLocation) string {
+		wording := supersededBy
+		if location.style == frontMatterStatus || location.style == bulletStatus {
+			wording = strings.ToLower(wording)
+		}
+		return wording + " " + MarkdownLink(old, by)
+	})
+	if err != nil {
+		return nil, nil, errors.New(old.Path + ": " + err.Error())
+	}
+
+	newUpdated := newContent
+	for _, section := range options.Sections {
+		if newUpdated, err = CopySection(newUpdated, oldContent, section); err != nil {
+			return nil, nil, errors.New(old.Path + ": " + err.Error())
+		}
+	}
+	lines := strings.Split(string(newUpdated), "\n")
+	location, ok := locateStatus(lines)
+	if !ok {
+		return nil, nil, errors.New(by.Path + ": no status found")
+	}
+	lines = insertLink(lines, location, supersedes, MarkdownLink(by, old))
+	return oldUpdated, []byte(strings.Join(lines, "\n")), nil
+}
+
+// Supersede marks the ADR old as superseded by the ADR by, both as found by Find, linking both ways, then publishes
+// StatusChanged for old
+func (r *Repository) Supersede(ctx context.Context, old Record, by Record, options SupersedeOptions) (Record, Record, error) {
+	from, oldRecord, newRecord, err := r.supersede(ctx, old, by, options)
+	if err != nil {
+		return oldRecord, newRecord, err
+	}
+	if from != oldRecord.Status {
+		r.publish(StatusChanged{Record: oldRecord, From: from, To: oldRecord.Status})
+	}
+	return oldRecord, newRecord, nil
+}
+
+// supersede rewrites both ADRs under the lock, read again once it is held, returning the previous status of old
+func (r *Repository) supersede(ctx context.Context, old Record, by Record, options SupersedeOptions) (Status, Record, Record, error) {
+	if filepath.Clean(old.Path) == filepath.Clean(by.Path) {
+		return "", Record{}, Record{}, fmt.Errorf("ADR %s cannot supersede itself", old.Ref())
+	}
+	release, err := r.Lock(ctx)
+	if err != nil {
+		return "", Record{}, Record{}, err
+	}
+	defer release()
+
+	records := [2]Record{}
+	contents := [2][]byte{}
+	for i, found := range []Record{old, by} {
+		if contents[i], err = r.FS.ReadFile(found.Path); os.IsNotExist(err) {
+			return "", Record{}, Record{}, fmt.Errorf("%w: %s does not exist", ErrAdrNotFound, r.relativePath(found.Path))
+		} else if err != nil {
+			return "", Record{}, Record{}, err
+		}
+		records[i] = Parse(filepath.Base(found.Path), contents[i])
+		records[i].Path, records[i].Scope, records[i].Category = found.Path, r.Scope, found.Category
+	}
+	oldContent, newContent, err := SupersedeContent(records[0], contents[0], records[1], contents[1], options)
+	if err != nil {
+		return "", Record{}, Record{}, err
+	}
+	r.log().Debug("writing superseded ADR", "path", records[0].Path, "by", records[1].Path)
+	if err := r.FS.WriteFile(records[0].Path, oldContent, 0644); err != nil {
+		return "", Record{}, Record{}, err
+	}
+	if err := r.FS.WriteFile(records[1].Path, newContent, 0644); err != nil {
+		return "", Record{}, Record{}, err
+	}
+	oldRecord, err := ParseFile(r.FS, records[0].Path)
+	if err != nil {
+		return "", Record{}, Record{}, err
+	}
+	newRecord, err := ParseFile(r.FS, records[1].Path)
+	oldRecord.Scope, newRecord.Scope = r.Scope, r.Scope
+	oldRecord.Category, newRecord.Category = records[0].Category, records[1].Category
+	return records[0].Status, oldRecord, newRecord, err
+}
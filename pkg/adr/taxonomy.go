@@ -0,0 +1,97 @@
+package adr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TagDefinition a tag of the taxonomy
+type TagDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Parent the broader tag this tag refines, e.g. "security" for "authentication"
+	Parent string `json:"parent,omitempty"`
+}
+
+// Taxonomy the controlled vocabulary of the tags of the ADRs, any tag being allowed when it is empty
+type Taxonomy []TagDefinition
+
+// Lookup the definition of tag, compared case-insensitively
+func (t Taxonomy) Lookup(tag string) (TagDefinition, bool) {
+	for _, definition := range t {
+		if strings.EqualFold(definition.Name, strings.TrimSpace(tag)) {
+			return definition, true
+		}
+	}
+	return TagDefinition{}, false
+}
+
+// Unknown the tags that are not in the taxonomy, none when the taxonomy is empty
+func (t Taxonomy) Unknown(tags []string) []string {
+	unknown := []string{}
+	if len(t) == 0 {
+		return unknown
+	}
+	for _, tag := range tags {
+		if _, ok := t.Lookup(tag); !ok {
+			unknown = append(unknown, tag)
+		}
+	}
+	return unknown
+}
+
+// Children the tags refining tag, in the order of the taxonomy; the top-level tags when tag is empty
+func (t Taxonomy) Children(tag string) []TagDefinition {
+	children := []TagDefinition{}
+	for _, definition := range t {
+		if strings.EqualFold(definition.Parent, tag) {
+			children = append(children, definition)
+		}
+	}
+	return children
+}
+
+// Path the tags from the top of the hierarchy down to tag, e.g. [security authentication]
+func (t Taxonomy) Path(tag string) []string {
+	path := []string{}
+	definition, ok := t.Lookup(tag)
+	// a taxonomy whose parents loop is cut after as many tags as it has
+	for i := 0; ok && i < len(t); i++ {
+		path = append([]string{definition.Name}, path...)
+		if definition.Parent == "" {
+			break
+		}
+		definition, ok = t.Lookup(definition.Parent)
+	}
+	return path
+}
+
+// Validate checks that the tags are defined once, with a name, and refine tags of the taxonomy without cycles
+func (t Taxonomy) Validate() error {
+	seen := map[string]bool{}
+	for _, definition := range t {
+		name := strings.ToLower(strings.TrimSpace(definition.Name))
+		switch {
+		case name == "":
+			return errors.New("a tag of the taxonomy has no name")
+		case seen[name]:
+			return fmt.Errorf("the tag %q is defined twice", definition.Name)
+		}
+		seen[name] = true
+	}
+	for _, definition := range t {
+		if definition.Parent == "" {
+			continue
+		}
+		if _, ok := t.Lookup(definition.Parent); !ok {
+			return fmt.Errorf("the parent %q of the tag %q is not in the taxonomy", definition.Parent, definition.Name)
+		}
+		// the path of a tag whose parents loop stops at a tag that still has a parent
+		top, _ := t.Lookup(t.Path(definition.Name)[0])
+		if top.Parent != "" {
+			return fmt.Errorf("the tag %q is its own ancestor", definition.Name)
+		}
+	}
+	return nil
+}
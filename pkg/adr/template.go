@@ -0,0 +1,162 @@
+package adr
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// DefaultTemplateName the template used when none is selected
+const DefaultTemplateName = "default"
+
+// TemplatesDirName the folder of the configuration folder holding named templates, as <name>.md files
+const TemplatesDirName = "templates"
+
+// Template a template of new ADRs, executed with the Record being created and the .Summary of CreateOptions.
+// Besides the text/template functions, join concatenates a list: {{join .Tags ", "}}
+type Template struct {
+	Name    string
+	Content string
+	// Path the file the template was read from, empty for registered templates
+	Path string
+}
+
+// templateFuncs the functions available to templates, besides the built-in ones of text/template
+var templateFuncs = template.FuncMap{"join": strings.Join}
+
+var registry = struct {
+	sync.RWMutex
+	templates map[string]string
+}{templates: map[string]string{DefaultTemplateName: DefaultTemplate}}
+
+// RegisterTemplate makes a template available to every Repository under name.
+// Templates of the configuration folder take precedence over registered ones with the same name.
+func RegisterTemplate(name string, content string) error {
+	if name == "" || ValidateFileName(name+".md") != nil {
+		return errors.New("invalid template name " + fmt.Sprintf("%q", name))
+	}
+	if _, err := template.New(name).Funcs(templateFuncs).Parse(content); err != nil {
+		return err
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	registry.templates[name] = content
+	return nil
+}
+
+// TemplatesDir the folder holding the named templates of the repository
+func (r *Repository) TemplatesDir() string {
+	return filepath.Join(r.ConfigDir, TemplatesDirName)
+}
+
+// Templates lists the templates available to the repository, sorted by name: the registered templates,
+// overridden by the templates of the configuration folder, template.md being the default template,
+// themselves overridden by the templates given WithTemplate
+func (r *Repository) Templates() ([]Template, error) {
+	byName := map[string]Template{}
+	registry.RLock()
+	for name, content := range registry.templates {
+		byName[name] = Template{Name: name, Content: content}
+	}
+	registry.RUnlock()
+	for name, t := range r.templates {
+		byName[name] = t
+	}
+
+	paths, err := r.FS.Glob(filepath.Join(r.TemplatesDir(), "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, r.TemplatePath())
+	for _, path := range paths {
+		content, err := r.FS.ReadFile(path)
+		if err != nil {
+			if path == r.TemplatePath() {
+				continue
+			}
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".md")
+		if path == r.TemplatePath() {
+			name = DefaultTemplateName
+		}
+		if _, given := r.templates[name]; !given {
+			byName[name] = Template{Name: name, Content: string(content), Path: path}
+		}
+	}
+
+	templates := []Template{}
+	for _, t := range byName {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// DefaultTemplate the name of the template of new ADRs when none is selected: the default_template of the
+// configuration, see UseTemplate, DefaultTemplateName otherwise
+func (r *Repository) DefaultTemplate() string {
+	if name := r.Settings().DefaultTemplate; name != "" {
+		return name
+	}
+	return DefaultTemplateName
+}
+
+// Template finds a template by name, the default template when name is empty, see DefaultTemplate.
+// It fails with ErrTemplateNotFound when there is no such template.
+func (r *Repository) Template(name string) (Template, error) {
+	if name == "" {
+		name = r.DefaultTemplate()
+	}
+	templates, err := r.Templates()
+	if err != nil {
+		return Template{}, err
+	}
+	for _, t := range templates {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return Template{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+}
+
+// AddTemplate writes content as the template named name of the configuration folder, the template.md of the default
+// template, and returns it. An existing template file is only overwritten with force.
+func (r *Repository) AddTemplate(name string, content string, force bool) (Template, error) {
+	if name == "" || ValidateFileName(name+".md") != nil {
+		return Template{}, errors.New("invalid template name " + fmt.Sprintf("%q", name))
+	}
+	if _, err := template.New(name).Funcs(templateFuncs).Parse(content); err != nil {
+		return Template{}, err
+	}
+	path := filepath.Join(r.TemplatesDir(), name+".md")
+	if name == DefaultTemplateName {
+		path = r.TemplatePath()
+	}
+	if _, err := r.FS.Stat(path); err == nil && !force {
+		return Template{}, errors.New("the template " + name + " already exists, " + path)
+	}
+	if err := r.FS.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Template{}, err
+	}
+	return Template{Name: name, Content: content, Path: path}, r.FS.WriteFile(path, []byte(content), 0644)
+}
+
+// UseTemplate makes the template named name the template of new ADRs when none is selected, saving it as the
+// default_template of the configuration
+func (r *Repository) UseTemplate(name string) error {
+	if _, err := r.Template(name); err != nil {
+		return err
+	}
+	if name == DefaultTemplateName {
+		name = ""
+	}
+	r.configMu.Lock()
+	r.Config.DefaultTemplate = name
+	r.configMu.Unlock()
+	return r.Save()
+}
@@ -0,0 +1,80 @@
+package adr
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// misspellings common misspellings of words found in ADR titles, and their correct spelling
+var misspellings = map[string]string{
+	"architecure":      "architecture",
+	"authentification": "authentication",
+	"definately":       "definitely",
+	"dependancy":       "dependency",
+	"dependancies":     "dependencies",
+	"enviroment":       "environment",
+	"existant":         "existent",
+	"infrastucture":    "infrastructure",
+	"managment":        "management",
+	"occured":          "occurred",
+	"persistance":      "persistence",
+	"recieve":          "receive",
+	"seperate":         "separate",
+	"seperation":       "separation",
+	"untill":           "until",
+	"wich":             "which",
+}
+
+var wordRegexp = regexp.MustCompile(`[\p{L}]+`)
+
+// NormalizeTitle suggests a cleaner ADR title: titles written in capitals are put in sentence case, trailing
+// punctuation and extra spaces are removed and common misspellings fixed. It returns the suggested title
+// and the reasons for the changes, none when the title is fine as is.
+func NormalizeTitle(title string) (string, []string) {
+	reasons := []string{}
+	normalized := strings.Join(strings.Fields(title), " ")
+	if normalized != strings.TrimSpace(title) {
+		reasons = append(reasons, "extra spaces")
+	}
+
+	if trimmed := strings.TrimRightFunc(normalized, func(r rune) bool { return strings.ContainsRune(".!?,;:", r) }); trimmed != normalized {
+		normalized = strings.TrimSpace(trimmed)
+		reasons = append(reasons, "trailing punctuation")
+	}
+
+	letters, upper := 0, 0
+	for _, r := range normalized {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	if letters > 3 && upper == letters && len(strings.Fields(normalized)) > 1 {
+		runes := []rune(strings.ToLower(normalized))
+		runes[0] = unicode.ToUpper(runes[0])
+		normalized = string(runes)
+		reasons = append(reasons, "all capitals")
+	}
+
+	misspelled := false
+	normalized = wordRegexp.ReplaceAllStringFunc(normalized, func(word string) string {
+		correct, ok := misspellings[strings.ToLower(word)]
+		if !ok {
+			return word
+		}
+		misspelled = true
+		if unicode.IsUpper([]rune(word)[0]) {
+			runes := []rune(correct)
+			runes[0] = unicode.ToUpper(runes[0])
+			return string(runes)
+		}
+		return correct
+	})
+	if misspelled {
+		reasons = append(reasons, "misspelled words")
+	}
+	return normalized, reasons
+}
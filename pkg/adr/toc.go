@@ -0,0 +1,139 @@
+package adr
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// TocFileNames the names the table of contents of the ADR directory can take, see WriteToc. The files so named are
+// never read as ADRs.
+var TocFileNames = []string{"index.md", "README.md"}
+
+// TocMarker the first line of the tables of contents written by WriteToc, which only overwrites the files starting with it
+const TocMarker = "<!-- Generated by adr toc, edit the ADRs instead of this file -->"
+
+// TocConfig the table of contents of the ADR directory
+type TocConfig struct {
+	// File the name of the table of contents, one of TocFileNames, index.md when empty
+	File string `json:"file,omitempty"`
+	// OnNew writes the table of contents as soon as adr new writes an ADR, before adr toc did. Once written, the
+	// commands changing ADRs keep it up to date either way.
+	OnNew bool `json:"on_new,omitempty"`
+}
+
+// IsTocFileName tells whether name is one of TocFileNames, ignoring case
+func IsTocFileName(name string) bool {
+	for _, toc := range TocFileNames {
+		if strings.EqualFold(name, toc) {
+			return true
+		}
+	}
+	return false
+}
+
+// TocFile the name of the table of contents of the configuration, TocFileNames[0] by default
+func (c Config) TocFile() string {
+	if c.Toc != nil && c.Toc.File != "" {
+		return c.Toc.File
+	}
+	return TocFileNames[0]
+}
+
+// Toc the markdown table of contents of the ADRs of dir: a table of their numbers, titles, dates and statuses, the
+// titles and the statuses written as links, such as Superseded by [5. Use Kafka](...), linking to the ADRs. The ADRs
+// outside of any category come first, then each category and the archived ADRs under their own heading.
+func Toc(dir string, records []Record) []byte {
+	groups := map[string][]Record{}
+	archived := []Record{}
+	for _, record := range records {
+		switch {
+		case record.Draft != "":
+		case record.Archived:
+			archived = append(archived, record)
+		default:
+			groups[record.Category] = append(groups[record.Category], record)
+		}
+	}
+	toc := TocMarker + "\n\n# Architecture decision records\n"
+	if len(groups[""]) > 0 {
+		toc += "\n" + tocTable(dir, groups[""])
+	}
+	for _, category := range Categories(records) {
+		if len(groups[category]) > 0 {
+			toc += "\n## " + category + "\n\n" + tocTable(dir, groups[category])
+		}
+	}
+	if len(archived) > 0 {
+		toc += "\n## Archived\n\n" + tocTable(dir, archived)
+	}
+	return []byte(toc)
+}
+
+// tocTable the rows of records in a table of contents of dir
+func tocTable(dir string, records []Record) string {
+	table := "| Number | Title | Date | Status |\n| --- | --- | --- | --- |\n"
+	for _, record := range records {
+		status := string(record.Status)
+		for _, link := range record.Links {
+			if record.Status == "" || NormalizeStatus(link.Kind) != record.Status {
+				continue
+			}
+			if path, ok := record.LinkedPath(link); ok {
+				status = link.Kind + " [" + tocCell(link.Title) + "](" + tocTarget(dir, path) + ")"
+				break
+			}
+		}
+		table += fmt.Sprintf("| %s | [%s](%s) | %s | %s |\n", record.Ref(), tocCell(record.Title), tocTarget(dir, record.Path), record.Date, status)
+	}
+	return table
+}
+
+// tocTarget the link of the table of contents of dir to path
+func tocTarget(dir string, path string) string {
+	target, err := filepath.Rel(dir, path)
+	if err != nil {
+		target = path
+	}
+	return filepath.ToSlash(target)
+}
+
+// tocCell text escaped for a cell of a markdown table
+func tocCell(text string) string {
+	return strings.Replace(text, "|", `\|`, -1)
+}
+
+// WriteToc writes the table of contents of the ADRs of the repository, archived ones included, see Toc, into the file
+// named name of its ADR directory, the file of the configuration when empty, see TocFile, and returns its path. A file
+// that does not start with TocMarker, a table of contents written by hand, is only overwritten with force.
+func (r *Repository) WriteToc(ctx context.Context, name string, force bool) (string, error) {
+	if name == "" {
+		name = r.Settings().TocFile()
+	}
+	if !IsTocFileName(name) {
+		return "", fmt.Errorf("invalid table of contents file %q, expected one of %s", name, strings.Join(TocFileNames, ", "))
+	}
+	path := filepath.Join(r.Dir, name)
+	if content, err := r.FS.ReadFile(path); err == nil && !force && !strings.HasPrefix(string(content), TocMarker) {
+		return path, fmt.Errorf("%w: %s", ErrNotGenerated, path)
+	}
+	toc, err := r.RenderToc(ctx)
+	if err != nil {
+		return path, err
+	}
+	return path, r.FS.WriteFile(path, toc, 0644)
+}
+
+// RenderToc the table of contents WriteToc writes, of the ADRs of the repository, archived ones included
+func (r *Repository) RenderToc(ctx context.Context) ([]byte, error) {
+	records, err := r.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := r.ListArchived(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Toc(r.Dir, append(records, archived...)), nil
+}
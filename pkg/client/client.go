@@ -0,0 +1,306 @@
+// Package client calls the HTTP API of adr serve.
+//
+// The API is described by the OpenAPI document the server returns at /api/openapi.json; the client decodes its
+// answers into the JSON types of the adr package, and maps its 404 answers to adr.ErrAdrNotFound:
+//
+//	c := client.New("http://adr.internal:8080", client.WithToken(os.Getenv("ADR_TOKEN")))
+//	listing, err := c.List(ctx, client.Filter{Statuses: []string{"proposed"}})
+//	document, err := c.Transition(ctx, 42, adr.Accepted)
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// Client calls an adr server, it is safe for concurrent use
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	authorize  func(*http.Request)
+}
+
+// Option configures a Client built by New
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with httpClient instead of http.DefaultClient, e.g. to set a timeout
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithToken authenticates the requests with a bearer token of the serve_credentials of the server
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.authorize = func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+	}
+}
+
+// WithBasicAuth authenticates the requests with a user name and password of the serve_credentials of the server
+func WithBasicAuth(username string, password string) Option {
+	return func(c *Client) {
+		c.authorize = func(r *http.Request) { r.SetBasicAuth(username, password) }
+	}
+}
+
+// New a client of the server at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, options ...Option) *Client {
+	c := &Client{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: http.DefaultClient, authorize: func(*http.Request) {}}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// Error an error answered by the server
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("adr server answered %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Unwrap maps the answers of the server to the errors of the adr package, so that errors.Is works across the API
+func (e *Error) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return adr.ErrAdrNotFound
+	}
+	return nil
+}
+
+// Filter selects ADRs, an ADR matches when it passes every filter that is set
+type Filter struct {
+	// Statuses the ADR has one of these statuses
+	Statuses []string
+	// Tags the ADR has all of these tags
+	Tags []string
+	// Since and Until bound the date of the ADR, as YYYY-MM-DD
+	Since string
+	Until string
+	// Text appears in the title or the content of the ADR
+	Text string
+	// Scopes the ADR is in one of these scopes, e.g. the roots of other repositories
+	Scopes []string
+}
+
+func (f Filter) values() url.Values {
+	values := url.Values{}
+	values["status"] = f.Statuses
+	values["tag"] = f.Tags
+	values["scope"] = f.Scopes
+	for name, value := range map[string]string{"since": f.Since, "until": f.Until, "text": f.Text} {
+		if value != "" {
+			values.Set(name, value)
+		}
+	}
+	return values
+}
+
+// CreateRequest the ADR to create, only the title is required
+type CreateRequest struct {
+	Title    string   `json:"title"`
+	Author   string   `json:"author,omitempty"`
+	Status   string   `json:"status,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Summary  string   `json:"summary,omitempty"`
+	Template string   `json:"template,omitempty"`
+	Draft    bool     `json:"draft,omitempty"`
+}
+
+// GraphQLError an error of a GraphQL query, at the path of the field that failed
+type GraphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+func (e GraphQLError) Error() string {
+	return e.Message
+}
+
+// List the ADRs matching filter
+func (c *Client) List(ctx context.Context, filter Filter) (adr.ListingJSON, error) {
+	listing := adr.ListingJSON{}
+	err := c.do(ctx, http.MethodGet, "/api/adrs?"+filter.values().Encode(), nil, &listing)
+	return listing, err
+}
+
+// Search the ADRs whose title or content contains text
+func (c *Client) Search(ctx context.Context, text string) (adr.ListingJSON, error) {
+	listing := adr.ListingJSON{}
+	err := c.do(ctx, http.MethodGet, "/api/search?"+url.Values{"q": {text}}.Encode(), nil, &listing)
+	return listing, err
+}
+
+// Get the ADR numbered number with its content, in scope or in the repository served when scope is empty
+func (c *Client) Get(ctx context.Context, number int, scope string) (adr.DocumentJSON, error) {
+	document := adr.DocumentJSON{}
+	err := c.do(ctx, http.MethodGet, adrPath(number, "", scope), nil, &document)
+	return document, err
+}
+
+// HTML the content of an ADR rendered to HTML
+func (c *Client) HTML(ctx context.Context, number int, scope string) (string, error) {
+	var html bytes.Buffer
+	err := c.do(ctx, http.MethodGet, adrPath(number, "/html", scope), nil, &html)
+	return html.String(), err
+}
+
+// Create writes a new ADR on the server
+func (c *Client) Create(ctx context.Context, request CreateRequest) (adr.DocumentJSON, error) {
+	document := adr.DocumentJSON{}
+	err := c.do(ctx, http.MethodPost, "/api/adrs", request, &document)
+	return document, err
+}
+
+// Transition changes the status of the ADR numbered number
+func (c *Client) Transition(ctx context.Context, number int, status adr.Status) (adr.DocumentJSON, error) {
+	document := adr.DocumentJSON{}
+	err := c.do(ctx, http.MethodPut, adrPath(number, "/status", ""), map[string]adr.Status{"status": status}, &document)
+	return document, err
+}
+
+// Graph the ADRs and the links between them
+func (c *Client) Graph(ctx context.Context) (adr.GraphJSON, error) {
+	graph := adr.GraphJSON{}
+	err := c.do(ctx, http.MethodGet, "/api/graph", nil, &graph)
+	return graph, err
+}
+
+// Scopes the scopes of the ADRs served
+func (c *Client) Scopes(ctx context.Context) ([]string, error) {
+	scopes := struct {
+		Scopes []string `json:"scopes"`
+	}{}
+	err := c.do(ctx, http.MethodGet, "/api/scopes", nil, &scopes)
+	return scopes.Scopes, err
+}
+
+// GraphQL runs query with variables and decodes its data into data. The errors of the query are
+// returned as GraphQLError values, joined, along with the data resolved despite them.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]interface{}, data interface{}) error {
+	response := struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []GraphQLError  `json:"errors"`
+	}{}
+	err := c.do(ctx, http.MethodPost, "/api/graphql", map[string]interface{}{"query": query, "variables": variables}, &response)
+	var apiErr *Error
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest && len(response.Errors) > 0) {
+		return err
+	}
+	if len(response.Data) > 0 && string(response.Data) != "null" {
+		if err := json.Unmarshal(response.Data, data); err != nil {
+			return err
+		}
+	}
+	errs := []error{}
+	for _, e := range response.Errors {
+		errs = append(errs, e)
+	}
+	return errors.Join(errs...)
+}
+
+// Changes calls handle with each change of the ADRs streamed by the server, until ctx is done, the stream ends
+// or handle fails. The changes made while the stream is not connected are not sent again.
+func (c *Client) Changes(ctx context.Context, handle func(adr.ChangeJSON) error) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/events", nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "text/event-stream")
+	c.authorize(request)
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		content, _ := io.ReadAll(response.Body)
+		return &Error{StatusCode: response.StatusCode, Message: strings.TrimSpace(string(content))}
+	}
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		change := adr.ChangeJSON{}
+		if err := json.Unmarshal([]byte(data), &change); err != nil {
+			return err
+		}
+		if err := handle(change); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return scanner.Err()
+}
+
+func adrPath(number int, suffix string, scope string) string {
+	path := "/api/adrs/" + strconv.Itoa(number) + suffix
+	if scope != "" {
+		path += "?" + url.Values{"scope": {scope}}.Encode()
+	}
+	return path
+}
+
+// do sends a request with body encoded to JSON, and decodes the answer into result: JSON into a value,
+// anything else into a *bytes.Buffer. Error answers are returned as *Error, once decoded into result when they are JSON.
+func (c *Client) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	request.Header.Set("Accept", "application/json")
+	c.authorize(request)
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode >= 300 {
+		apiErr := struct {
+			Error string `json:"error"`
+		}{}
+		if json.Unmarshal(content, &apiErr) != nil || apiErr.Error == "" {
+			apiErr.Error = strings.TrimSpace(string(content))
+		}
+		json.Unmarshal(content, result)
+		return &Error{StatusCode: response.StatusCode, Message: apiErr.Error}
+	}
+	if buffer, ok := result.(*bytes.Buffer); ok {
+		_, err := buffer.Write(content)
+		return err
+	}
+	return json.Unmarshal(content, result)
+}
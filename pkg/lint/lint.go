@@ -0,0 +1,155 @@
+// Package lint validates ADRs with a configurable set of rules, reporting problems as structured findings.
+//
+// It backs adr check and adr lint, and can be imported by other tools to run the same checks
+// on any directory of ADRs:
+//
+//	records, err := adr.ReadDir(ctx, adr.OS, "docs/adr")
+//	findings := lint.Run(adr.OS, records, lint.Rules())
+package lint
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// Pass the ADRs a rule checks, together with the file system holding them
+type Pass struct {
+	FS      adr.FileSystem
+	Records []adr.Record
+	// Taxonomy the tags the ADRs may have, any tag when it is empty
+	Taxonomy adr.Taxonomy
+	// PerCategory tells whether the ADRs of each category are numbered on their own
+	PerCategory bool
+	// RequiredSections the sections every ADR has, the sections of its format when empty, see MissingSection
+	RequiredSections []string
+	// IndexPath the table of contents of the ADRs and Index the content it should have, see StaleIndex
+	IndexPath string
+	Index     []byte
+	// Now the current time, time.Now when nil, see ReviewOverdue
+	Now func() time.Time
+}
+
+// now the current time, as given WithClock
+func (pass *Pass) now() time.Time {
+	if pass.Now == nil {
+		return time.Now()
+	}
+	return pass.Now()
+}
+
+// Option configures a Pass, with what the rules know of the repository besides its ADRs
+type Option func(*Pass)
+
+// WithTaxonomy checks the tags of the ADRs against taxonomy, see UnknownTag
+func WithTaxonomy(taxonomy adr.Taxonomy) Option {
+	return func(pass *Pass) { pass.Taxonomy = taxonomy }
+}
+
+// WithCategoryNumbering checks the numbers of the ADRs as config numbers the categories, see DuplicateNumber
+func WithCategoryNumbering(config adr.Config) Option {
+	return func(pass *Pass) { pass.PerCategory = config.PerCategory() }
+}
+
+// WithRequiredSections checks that the ADRs have sections, rather than the sections of their format, see MissingSection
+func WithRequiredSections(sections []string) Option {
+	return func(pass *Pass) { pass.RequiredSections = sections }
+}
+
+// WithIndex checks that the table of contents at path, written by adr toc, has content, see StaleIndex
+func WithIndex(path string, content []byte) Option {
+	return func(pass *Pass) { pass.IndexPath, pass.Index = path, content }
+}
+
+// WithClock checks the ADRs at the time now gives instead of time.Now, e.g. to get reproducible findings in tests
+func WithClock(now func() time.Time) Option {
+	return func(pass *Pass) { pass.Now = now }
+}
+
+// Rule a named check, reporting findings whose Rule is the rule name
+type Rule struct {
+	Name        string
+	Description string
+	// Severity the severity of the findings of the rule, adr.SeverityError when empty
+	Severity adr.Severity
+	Check    func(pass *Pass) []adr.Finding
+}
+
+// Rules the built-in rules, sorted by name
+func Rules() []Rule {
+	return []Rule{DanglingLink, DuplicateNumber, InvalidDate, InvalidStatus, MissingSection, NumberMismatch, ReviewOverdue, StaleIndex, UnknownTag, Unnumbered}
+}
+
+// Select the rules named in enable, or all the built-in rules when enable is empty, minus the rules named in disable.
+// Unknown rule names are an error.
+func Select(enable []string, disable []string) ([]Rule, error) {
+	byName := map[string]Rule{}
+	for _, rule := range Rules() {
+		byName[rule.Name] = rule
+	}
+	for _, name := range append(append([]string{}, enable...), disable...) {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("unknown lint rule %q", name)
+		}
+	}
+
+	disabled := map[string]bool{}
+	for _, name := range disable {
+		disabled[name] = true
+	}
+	selected := []Rule{}
+	if len(enable) == 0 {
+		for _, rule := range Rules() {
+			if !disabled[rule.Name] {
+				selected = append(selected, rule)
+			}
+		}
+		return selected, nil
+	}
+	for _, name := range enable {
+		if !disabled[name] {
+			selected = append(selected, byName[name])
+		}
+	}
+	return selected, nil
+}
+
+// Run checks records with rules, findings are sorted by file and line and have the severity of their rule
+func Run(fsys adr.FileSystem, records []adr.Record, rules []Rule, options ...Option) []adr.Finding {
+	pass := &Pass{FS: fsys, Records: records}
+	for _, option := range options {
+		option(pass)
+	}
+	findings := []adr.Finding{}
+	for _, rule := range rules {
+		severity := rule.Severity
+		if severity == "" {
+			severity = adr.SeverityError
+		}
+		for _, finding := range rule.Check(pass) {
+			if finding.Severity == "" {
+				finding.Severity = severity
+			}
+			findings = append(findings, finding)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// RunDir reads the ADRs of dir and checks them with rules
+func RunDir(ctx context.Context, fsys adr.FileSystem, dir string, rules []Rule, options ...Option) ([]adr.Finding, error) {
+	records, err := adr.ReadDir(ctx, fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return Run(fsys, records, rules, options...), nil
+}
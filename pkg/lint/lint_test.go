@@ -0,0 +1,193 @@
+package lint
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// testAdr the content of an ADR numbered number with header lines after its date and status
+func testAdr(number string, header string, status string) string {
+	if header != "" {
+		header += "\n"
+	}
+	return "# " + number + ". Use Go\n======\nDate: 2026-10-01\n" + header + "\n## Status\n======\n" + status +
+		"\n\n## Context\n======\n\n## Decision\n======\n\n## Consequences\n======\n"
+}
+
+// testClock the time the tests check the ADRs at
+func testClock() time.Time {
+	return time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC)
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		rules   []string
+		options []Option
+		want    []adr.Finding
+	}{
+		{
+			name:  "valid ADRs",
+			files: map[string]string{"1-use-go.md": testAdr("1", "Review by: 2027-01-01", "Accepted"), "2-use-go.md": testAdr("2", "", "Proposed")},
+			want:  []adr.Finding{},
+		},
+		{
+			name:  "unnumbered",
+			files: map[string]string{"use-go.md": "# Use Go\n\n## Status\n\nAccepted\n"},
+			rules: []string{"unnumbered"},
+			want:  []adr.Finding{{File: "/adr/use-go.md", Rule: "unnumbered", Message: "ADR has no number", Severity: adr.SeverityError}},
+		},
+		{
+			name:  "duplicate number",
+			files: map[string]string{"1-use-go.md": testAdr("1", "", "Accepted"), "1-use-rust.md": testAdr("1", "", "Proposed"), "2-use-go.md": testAdr("2", "", "Accepted")},
+			rules: []string{"duplicate-number"},
+			want: []adr.Finding{
+				{File: "/adr/1-use-go.md", Rule: "duplicate-number", Message: "ADR number 1 is also used by 1-use-rust.md", Severity: adr.SeverityError},
+				{File: "/adr/1-use-rust.md", Rule: "duplicate-number", Message: "ADR number 1 is also used by 1-use-go.md", Severity: adr.SeverityError},
+			},
+		},
+		{
+			name:  "dangling link",
+			files: map[string]string{"1-use-go.md": testAdr("1", "", "Superseded by [2. Use Rust](2-use-rust.md)")},
+			rules: []string{"dangling-link"},
+			want:  []adr.Finding{{File: "/adr/1-use-go.md", Line: 7, Rule: "dangling-link", Message: "Superseded by link points to missing file 2-use-rust.md", Severity: adr.SeverityError}},
+		},
+		{
+			name:  "invalid status",
+			files: map[string]string{"1-use-go.md": testAdr("1", "", "Rejected")},
+			rules: []string{"invalid-status"},
+			want:  []adr.Finding{{File: "/adr/1-use-go.md", Line: 7, Rule: "invalid-status", Message: `status "Rejected" is not one of [Proposed Accepted Deprecated Superseded]`, Severity: adr.SeverityError}},
+		},
+		{
+			name:  "missing status",
+			files: map[string]string{"1-use-go.md": "# 1. Use Go\n======\n\n## Context\n======\n"},
+			rules: []string{"invalid-status"},
+			want:  []adr.Finding{{File: "/adr/1-use-go.md", Rule: "invalid-status", Message: "ADR has no status", Severity: adr.SeverityError}},
+		},
+		{
+			name:  "invalid date",
+			files: map[string]string{"1-use-go.md": strings.Replace(testAdr("1", "", "Accepted"), "2026-10-01", "last monday", 1)},
+			rules: []string{"invalid-date"},
+			want:  []adr.Finding{{File: "/adr/1-use-go.md", Line: 3, Rule: "invalid-date", Message: `date "last monday" cannot be parsed`, Severity: adr.SeverityError}},
+		},
+		{
+			name:  "missing section",
+			files: map[string]string{"1-use-go.md": strings.Replace(testAdr("1", "", "Accepted"), "## Decision\n", "## Choice\n", 1)},
+			rules: []string{"missing-section"},
+			want:  []adr.Finding{{File: "/adr/1-use-go.md", Rule: "missing-section", Message: `ADR has no "Decision" section`, Severity: adr.SeverityError}},
+		},
+		{
+			name:    "missing required section",
+			files:   map[string]string{"1-use-go.md": testAdr("1", "", "Accepted")},
+			rules:   []string{"missing-section"},
+			options: []Option{WithRequiredSections([]string{"Context", "Alternatives"})},
+			want:    []adr.Finding{{File: "/adr/1-use-go.md", Rule: "missing-section", Message: `ADR has no "Alternatives" section`, Severity: adr.SeverityError}},
+		},
+		{
+			name:  "number mismatch",
+			files: map[string]string{"3-use-go.md": testAdr("4", "", "Accepted")},
+			rules: []string{"number-mismatch"},
+			want:  []adr.Finding{{File: "/adr/3-use-go.md", Line: 1, Rule: "number-mismatch", Message: "heading numbers the ADR 4, its file name 3", Severity: adr.SeverityError}},
+		},
+		{
+			name: "review overdue",
+			files: map[string]string{
+				"1-use-go.md": testAdr("1", "Review by: 2026-10-15", "Accepted"),
+				"2-use-go.md": testAdr("2", "Review by: 2026-10-16", "Accepted"),
+				"3-use-go.md": testAdr("3", "Review by: 2026-10-01", "Superseded"),
+			},
+			rules: []string{"review-overdue"},
+			want:  []adr.Finding{{File: "/adr/1-use-go.md", Rule: "review-overdue", Message: "Accepted decision was due for review on 2026-10-15", Severity: adr.SeverityWarning}},
+		},
+		{
+			name:    "unknown tag",
+			files:   map[string]string{"1-use-go.md": testAdr("1", "Tags: security, speed", "Accepted")},
+			rules:   []string{"unknown-tag"},
+			options: []Option{WithTaxonomy(adr.Taxonomy{{Name: "security"}})},
+			want:    []adr.Finding{{File: "/adr/1-use-go.md", Rule: "unknown-tag", Message: `tag "speed" is not in the taxonomy`, Severity: adr.SeverityError}},
+		},
+		{
+			name:    "stale index",
+			files:   map[string]string{"1-use-go.md": testAdr("1", "", "Accepted"), "index.md": adr.TocMarker + "\n\nNo ADRs yet\n"},
+			rules:   []string{"stale-index"},
+			options: []Option{WithIndex("/adr/index.md", []byte(adr.TocMarker+"\n\n| 1 | Use Go |\n"))},
+			want:    []adr.Finding{{File: "/adr/index.md", Rule: "stale-index", Message: "table of contents is out of date, run adr toc", Severity: adr.SeverityError}},
+		},
+		{
+			name:    "missing index",
+			files:   map[string]string{"1-use-go.md": testAdr("1", "", "Accepted")},
+			rules:   []string{"stale-index"},
+			options: []Option{WithIndex("/adr/index.md", []byte(adr.TocMarker+"\n"))},
+			want:    []adr.Finding{{File: "/adr/index.md", Rule: "stale-index", Message: "table of contents is missing, run adr toc", Severity: adr.SeverityError}},
+		},
+		{
+			name:    "index written by hand",
+			files:   map[string]string{"1-use-go.md": testAdr("1", "", "Accepted"), "index.md": "# Our decisions\n"},
+			rules:   []string{"stale-index"},
+			options: []Option{WithIndex("/adr/index.md", []byte(adr.TocMarker+"\n"))},
+			want:    []adr.Finding{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fsys := adr.NewMemFS()
+			fsys.MkdirAll("/adr", 0755)
+			for name, content := range test.files {
+				fsys.WriteFile("/adr/"+name, []byte(content), 0644)
+			}
+			rules, err := Select(test.rules, nil)
+			if err != nil {
+				t.Fatalf("Select() failed: %v", err)
+			}
+			options := append([]Option{WithClock(testClock)}, test.options...)
+			got, err := RunDir(context.Background(), fsys, "/adr", rules, options...)
+			if err != nil {
+				t.Fatalf("RunDir() failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("RunDir() =\n%+v\nwant\n%+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		enable  []string
+		disable []string
+		want    []string
+		fails   bool
+	}{
+		{want: names(Rules())},
+		{enable: []string{"unnumbered", "dangling-link"}, want: []string{"unnumbered", "dangling-link"}},
+		{enable: []string{"unnumbered", "dangling-link"}, disable: []string{"unnumbered"}, want: []string{"dangling-link"}},
+		{disable: []string{"review-overdue", "stale-index"}, want: []string{"dangling-link", "duplicate-number", "invalid-date", "invalid-status", "missing-section", "number-mismatch", "unknown-tag", "unnumbered"}},
+		{enable: []string{"no-such-rule"}, fails: true},
+		{disable: []string{"no-such-rule"}, fails: true},
+	}
+	for _, test := range tests {
+		rules, err := Select(test.enable, test.disable)
+		if (err != nil) != test.fails {
+			t.Errorf("Select(%v, %v) failed with %v, want failure %v", test.enable, test.disable, err, test.fails)
+			continue
+		}
+		if !test.fails && !reflect.DeepEqual(names(rules), test.want) {
+			t.Errorf("Select(%v, %v) = %v, want %v", test.enable, test.disable, names(rules), test.want)
+		}
+	}
+}
+
+// names the names of rules
+func names(rules []Rule) []string {
+	names := []string{}
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	return names
+}
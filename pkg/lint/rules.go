@@ -0,0 +1,324 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// Unnumbered reports ADRs without a number, except drafts and log4brains ADRs which are not numbered
+var Unnumbered = Rule{
+	Name:        "unnumbered",
+	Description: "Every ADR has a number",
+	Check: func(pass *Pass) []adr.Finding {
+		findings := []adr.Finding{}
+		for _, record := range pass.Records {
+			if record.Number == 0 && record.Format != adr.FormatLog4brains && record.Draft == "" {
+				findings = append(findings, adr.Finding{
+					File:    record.Path,
+					Rule:    "unnumbered",
+					Message: "ADR has no number",
+				})
+			}
+		}
+		return findings
+	},
+}
+
+// DuplicateNumber reports ADRs sharing their number with another ADR of the same scope, and of the same category
+// when the categories are numbered on their own
+var DuplicateNumber = Rule{
+	Name:        "duplicate-number",
+	Description: "No two ADRs share the same number",
+	Check: func(pass *Pass) []adr.Finding {
+		// numbers are only unique within a scope, or a category
+		type scopedNumber struct {
+			scope    string
+			category string
+			number   int
+		}
+		key := func(record adr.Record) scopedNumber {
+			if pass.PerCategory {
+				return scopedNumber{record.Scope, record.Category, record.Number}
+			}
+			return scopedNumber{record.Scope, "", record.Number}
+		}
+		byNumber := map[scopedNumber][]adr.Record{}
+		for _, record := range pass.Records {
+			if record.Number != 0 {
+				byNumber[key(record)] = append(byNumber[key(record)], record)
+			}
+		}
+		findings := []adr.Finding{}
+		for _, record := range pass.Records {
+			duplicates := byNumber[key(record)]
+			if record.Number == 0 || len(duplicates) < 2 {
+				continue
+			}
+			for _, other := range duplicates {
+				if other.Path != record.Path {
+					findings = append(findings, adr.Finding{
+						File:    record.Path,
+						Rule:    "duplicate-number",
+						Message: "ADR number " + strconv.Itoa(record.Number) + " is also used by " + filepath.Join(other.Category, filepath.Base(other.Path)),
+					})
+					break
+				}
+			}
+		}
+		return findings
+	},
+}
+
+// DanglingLink reports links to ADR files that do not exist
+var DanglingLink = Rule{
+	Name:        "dangling-link",
+	Description: "Links between ADRs point to existing files",
+	Check: func(pass *Pass) []adr.Finding {
+		findings := []adr.Finding{}
+		for _, record := range pass.Records {
+			for _, link := range record.Links {
+				target, ok := record.LinkedPath(link)
+				if !ok {
+					continue
+				}
+				if _, err := pass.FS.Stat(target); os.IsNotExist(err) {
+					findings = append(findings, adr.Finding{
+						File:    record.Path,
+						Line:    link.Line,
+						Rule:    "dangling-link",
+						Message: link.Kind + " link points to missing file " + link.Target,
+					})
+				}
+			}
+		}
+		return findings
+	},
+}
+
+// ReviewOverdue warns about the decisions in force whose review-by date has passed
+var ReviewOverdue = Rule{
+	Name:        "review-overdue",
+	Description: "Decisions in force are reviewed by their review-by date",
+	Severity:    adr.SeverityWarning,
+	Check: func(pass *Pass) []adr.Finding {
+		now := pass.now()
+		findings := []adr.Finding{}
+		for _, review := range adr.DueReviews(pass.Records, now) {
+			if review.Overdue(now) {
+				findings = append(findings, adr.Finding{
+					File:    review.Record.Path,
+					Rule:    "review-overdue",
+					Message: string(review.Record.Status) + " decision was due for review on " + review.Due.Format(adr.ReviewDateFormat),
+				})
+			}
+		}
+		return findings
+	},
+}
+
+// StaleIndex reports the table of contents given WithIndex when it is missing or lists the ADRs otherwise than adr toc
+// would now. A table of contents written by hand, without adr.TocMarker, is not checked.
+var StaleIndex = Rule{
+	Name:        "stale-index",
+	Description: "The table of contents written by adr toc is up to date",
+	Check: func(pass *Pass) []adr.Finding {
+		if pass.IndexPath == "" {
+			return nil
+		}
+		content, err := pass.FS.ReadFile(pass.IndexPath)
+		switch {
+		case os.IsNotExist(err):
+			return []adr.Finding{{File: pass.IndexPath, Rule: "stale-index", Message: "table of contents is missing, run adr toc"}}
+		case err != nil || !strings.HasPrefix(string(content), adr.TocMarker) || string(content) == string(pass.Index):
+			return nil
+		}
+		return []adr.Finding{{File: pass.IndexPath, Rule: "stale-index", Message: "table of contents is out of date, run adr toc"}}
+	},
+}
+
+// UnknownTag reports the tags that are not in the taxonomy given WithTaxonomy
+var UnknownTag = Rule{
+	Name:        "unknown-tag",
+	Description: "ADRs are tagged with the tags of the taxonomy",
+	Check: func(pass *Pass) []adr.Finding {
+		findings := []adr.Finding{}
+		for _, record := range pass.Records {
+			for _, tag := range pass.Taxonomy.Unknown(record.Tags) {
+				findings = append(findings, adr.Finding{
+					File:    record.Path,
+					Rule:    "unknown-tag",
+					Message: "tag " + strconv.Quote(tag) + " is not in the taxonomy",
+				})
+			}
+		}
+		return findings
+	},
+}
+
+// formatSections the sections the ADRs of each format have, see MissingSection
+var formatSections = map[adr.Format][]string{
+	adr.FormatNative:     {"Context", "Decision", "Consequences"},
+	adr.FormatADRTools:   {"Context", "Decision", "Consequences"},
+	adr.FormatMADR:       {"Context and Problem Statement", "Decision Outcome"},
+	adr.FormatLog4brains: {"Context and Problem Statement", "Decision Outcome"},
+}
+
+// MissingSection reports the ADRs lacking one of the sections given WithRequiredSections, or else of the sections
+// of their format
+var MissingSection = Rule{
+	Name:        "missing-section",
+	Description: "ADRs have the sections of their format",
+	Check: func(pass *Pass) []adr.Finding {
+		findings := []adr.Finding{}
+		for _, record := range pass.Records {
+			content, err := pass.FS.ReadFile(record.Path)
+			if err != nil {
+				continue
+			}
+			required := pass.RequiredSections
+			if len(required) == 0 {
+				required = formatSections[record.Format]
+			}
+			sections := adr.Sections(content)
+			for _, title := range required {
+				found := false
+				for _, section := range sections {
+					found = found || strings.EqualFold(section, title)
+				}
+				if !found {
+					findings = append(findings, adr.Finding{
+						File:    record.Path,
+						Rule:    "missing-section",
+						Message: "ADR has no " + strconv.Quote(title) + " section",
+					})
+				}
+			}
+		}
+		return findings
+	},
+}
+
+// statusLineRegexp and dateLineRegexp the status and date fields of the header or the front matter of an ADR
+var (
+	statusLineRegexp = regexp.MustCompile(`(?i)^([-*]\s*)?status\s*:`)
+	dateLineRegexp   = regexp.MustCompile(`(?i)^([-*]\s*)?date\s*:`)
+)
+
+// InvalidStatus reports the ADRs without a status, or with a status other than adr.Statuses
+var InvalidStatus = Rule{
+	Name:        "invalid-status",
+	Description: "ADRs have one of the known statuses",
+	Check: func(pass *Pass) []adr.Finding {
+		findings := []adr.Finding{}
+		for _, record := range pass.Records {
+			if _, err := adr.ParseStatus(string(record.Status)); err == nil {
+				continue
+			}
+			message := "ADR has no status"
+			if record.Status != "" {
+				message = "status " + strconv.Quote(string(record.Status)) + " is not one of " + fmt.Sprint(adr.Statuses)
+			}
+			findings = append(findings, adr.Finding{
+				File:    record.Path,
+				Line:    lineOf(pass.FS, record.Path, statusLine),
+				Rule:    "invalid-status",
+				Message: message,
+			})
+		}
+		return findings
+	},
+}
+
+// statusLine tells whether the line of an ADR, after the lines before it, writes its status
+func statusLine(before []string, line string) bool {
+	if statusLineRegexp.MatchString(line) {
+		return true
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		previous := strings.TrimSpace(before[i])
+		if strings.HasPrefix(previous, "#") {
+			return line != "" && strings.EqualFold(strings.TrimSpace(strings.TrimLeft(previous, "#")), "status") && strings.Trim(line, "=-") != ""
+		}
+		if previous != "" && strings.Trim(previous, "=-") != "" {
+			return false
+		}
+	}
+	return false
+}
+
+// InvalidDate reports the dates of ADRs which are none of the date formats of adr.ParseDate
+var InvalidDate = Rule{
+	Name:        "invalid-date",
+	Description: "The dates of the ADRs can be parsed",
+	Check: func(pass *Pass) []adr.Finding {
+		findings := []adr.Finding{}
+		for _, record := range pass.Records {
+			if record.Date == "" {
+				continue
+			}
+			if _, err := adr.ParseDate(record.Date); err != nil {
+				findings = append(findings, adr.Finding{
+					File: record.Path,
+					Line: lineOf(pass.FS, record.Path, func(before []string, line string) bool {
+						return dateLineRegexp.MatchString(line)
+					}),
+					Rule:    "invalid-date",
+					Message: "date " + strconv.Quote(record.Date) + " cannot be parsed",
+				})
+			}
+		}
+		return findings
+	},
+}
+
+// fileNumberRegexp the number starting the file name of an ADR
+var fileNumberRegexp = regexp.MustCompile(`^(\d+)-`)
+
+// NumberMismatch reports the ADRs whose heading has another number than their file name, e.g. "# 4. ..." in 0003-....md.
+// The dated files of log4brains are not numbered.
+var NumberMismatch = Rule{
+	Name:        "number-mismatch",
+	Description: "The number of the heading of an ADR is the number of its file name",
+	Check: func(pass *Pass) []adr.Finding {
+		findings := []adr.Finding{}
+		for _, record := range pass.Records {
+			m := fileNumberRegexp.FindStringSubmatch(filepath.Base(record.Path))
+			if m == nil || record.Format == adr.FormatLog4brains || record.Number == 0 {
+				continue
+			}
+			if number, _ := strconv.Atoi(m[1]); number != record.Number {
+				findings = append(findings, adr.Finding{
+					File: record.Path,
+					Line: lineOf(pass.FS, record.Path, func(before []string, line string) bool {
+						return strings.HasPrefix(line, "# ")
+					}),
+					Rule:    "number-mismatch",
+					Message: "heading numbers the ADR " + strconv.Itoa(record.Number) + ", its file name " + strconv.Itoa(number),
+				})
+			}
+		}
+		return findings
+	},
+}
+
+// lineOf the number of the first line of the file at path for which match is true, given the lines before it and
+// the line trimmed, 0 when there is none
+func lineOf(fsys adr.FileSystem, path string, match func(before []string, line string) bool) int {
+	content, err := fsys.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	lines := strings.Split(strings.Replace(string(content), "\r\n", "\n", -1), "\n")
+	for i, line := range lines {
+		if match(lines[:i], strings.TrimSpace(line)) {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// pluginPrefix starts the name of the executables adr runs as sub-commands, "adr foo" runs "adr-foo"
+const pluginPrefix = "adr-"
+
+// extension builds a sub-command compiled into adr, from the same dependencies as the built-in commands
+type extension func(ctx context.Context, paths adrPaths, out *reporter) cli.Command
+
+var extensions []extension
+
+// registerExtension adds a compiled-in sub-command, to be called from the init function of the file defining it
+func registerExtension(e extension) {
+	extensions = append(extensions, e)
+}
+
+// findPlugins lists the adr-<name> executables of the PATH by name, the first one found wins like for any command
+func findPlugins() map[string]string {
+	plugins := map[string]string{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		matches, _ := filepath.Glob(filepath.Join(dir, pluginPrefix+"*"))
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() || !executable(path, info) {
+				continue
+			}
+			name := strings.TrimPrefix(filepath.Base(path), pluginPrefix)
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+			if _, found := plugins[name]; !found {
+				plugins[name] = path
+			}
+		}
+	}
+	return plugins
+}
+
+// executable tells whether the file at path can be run: on Windows, where files have no execute permission, whether
+// its extension is one of %PATHEXT%
+func executable(path string, info os.FileInfo) bool {
+	if runtime.GOOS != "windows" {
+		return info.Mode()&0111 != 0
+	}
+	for _, extension := range windowsExecutableExtensions() {
+		if strings.EqualFold(filepath.Ext(path), extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// windowsExecutableExtensions the extensions of %PATHEXT%, those of cmd.exe by default
+func windowsExecutableExtensions() []string {
+	pathExt := os.Getenv("PATHEXT")
+	if pathExt == "" {
+		pathExt = ".com;.exe;.bat;.cmd"
+	}
+	return filepath.SplitList(pathExt)
+}
+
+// pluginNames the sorted names of the plugins
+func pluginNames(plugins map[string]string) []string {
+	names := []string{}
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runPlugin runs the adr-<name> executable with the remaining arguments.
+// It gets the adr configuration folder and the selected scope through ADR_CONFIG_DIR and ADR_SCOPE.
+func runPlugin(ctx context.Context, paths adrPaths, name string, args []string) error {
+	path, err := exec.LookPath(pluginPrefix + name)
+	if err != nil {
+		return errors.New("'" + name + "' is not an adr command, nor an " + pluginPrefix + name + " plugin of the PATH, check 'adr help'")
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"ADR_CONFIG_DIR="+paths.ConfigDir,
+		"ADR_SCOPE="+selectedScope,
+	)
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return cli.NewExitError("", exitErr.ExitCode())
+		}
+		return err
+	}
+	return nil
+}
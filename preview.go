@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// defaultPreviewAddress where adr preview --serve listens, next to the port of adr serve
+const defaultPreviewAddress = "localhost:8081"
+
+// previewPollInterval how often the previewed file is checked for changes
+var previewPollInterval = 300 * time.Millisecond
+
+// previewPage a standalone page showing an ADR, reloading its content when the file changes if live is set
+var previewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  {{if .Live}}<link rel="stylesheet" href="style.css">{{else}}<style>{{.Style}}</style>{{end}}
+</head>
+<body>
+  <main>
+    <article id="adr-content">{{.Content}}</article>
+  </main>
+  {{- if .Live}}
+  <script>
+    const events = new EventSource("events");
+    events.addEventListener("change", async () => {
+      const response = await fetch("content");
+      if (response.ok) {
+        document.getElementById("adr-content").innerHTML = await response.text();
+      }
+    });
+  </script>
+  {{- end}}
+</body>
+</html>
+`))
+
+// previewServer serves an ADR rendered to HTML, telling the browsers when its file changes
+type previewServer struct {
+	ctx    context.Context
+	repo   *adr.Repository
+	record adr.Record
+
+	mu      sync.Mutex
+	changed chan struct{}
+}
+
+// newPreviewServer previews record until ctx is done
+func newPreviewServer(ctx context.Context, repo *adr.Repository, record adr.Record) *previewServer {
+	return &previewServer{ctx: ctx, repo: repo, record: record, changed: make(chan struct{})}
+}
+
+// Handler serves the page at the root, the rendered ADR at /content and its changes as server-sent events at /events
+func (p *previewServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		var page bytes.Buffer
+		if err := p.writePage(&page, true); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page.Bytes())
+	})
+	mux.HandleFunc("/content", func(w http.ResponseWriter, r *http.Request) {
+		content, err := p.content()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(content)
+	})
+	mux.HandleFunc("/events", p.events)
+	mux.HandleFunc("/style.css", func(w http.ResponseWriter, r *http.Request) {
+		style, _ := fs.ReadFile(webAssets, "web/style.css")
+		w.Header().Set("Content-Type", "text/css; charset=utf-8")
+		w.Write(style)
+	})
+	return mux
+}
+
+func (p *previewServer) content() ([]byte, error) {
+	content, err := p.repo.FS.ReadFile(p.record.Path)
+	if err != nil {
+		return nil, err
+	}
+	return renderHTML(content), nil
+}
+
+// writePage writes the whole page, with the stylesheet inlined when it is not live
+func (p *previewServer) writePage(w io.Writer, live bool) error {
+	content, err := p.content()
+	if err != nil {
+		return err
+	}
+	style, err := fs.ReadFile(webAssets, "web/style.css")
+	if err != nil {
+		return err
+	}
+	return previewPage.Execute(w, struct {
+		Title   string
+		Live    bool
+		Style   template.CSS
+		Content template.HTML
+	}{p.record.ID() + ". " + p.record.Title, live, template.CSS(style), template.HTML(content)})
+}
+
+// events streams a "change" event each time the file of the ADR changes, until the browser goes away or the server stops
+func (p *previewServer) events(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	controller := http.NewResponseController(w)
+	io.WriteString(w, ": watching "+p.record.Path+"\n\n")
+	if err := controller.Flush(); err != nil {
+		return
+	}
+	for {
+		p.mu.Lock()
+		changed := p.changed
+		p.mu.Unlock()
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		case <-p.ctx.Done():
+			return
+		}
+		io.WriteString(w, "event: change\ndata: "+p.record.Path+"\n\n")
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// watch polls the file of the ADR until the server stops, waking up the event streams when its content changes.
+// Polling follows editors that save by replacing the file, and a file briefly missing while saved is not a change.
+func (p *previewServer) watch() {
+	last, _ := p.repo.FS.ReadFile(p.record.Path)
+	ticker := time.NewTicker(previewPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.ctx.Done():
+			return
+		}
+		content, err := p.repo.FS.ReadFile(p.record.Path)
+		if err != nil || bytes.Equal(content, last) {
+			continue
+		}
+		last = content
+		slog.Debug("previewed ADR changed", "path", p.record.Path)
+		p.mu.Lock()
+		close(p.changed)
+		p.changed = make(chan struct{})
+		p.mu.Unlock()
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/urfave/cli"
+)
+
+// assumeYes answers yes to confirmations, set by the global --yes flag
+var assumeYes bool
+
+// yesFlag lets destructive commands take --yes after their name as well
+var yesFlag = cli.BoolFlag{
+	Name:  "yes, y",
+	Usage: "Do not ask for confirmation",
+}
+
+// isTerminal tells whether f is a terminal rather than a pipe or a file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// interactive tells whether adr can ask questions: both its input and output are terminals
+func interactive() bool {
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// prompter asks questions on a terminal, writing them to Out and reading the answers from In
+type prompter struct {
+	In  *bufio.Reader
+	Out io.Writer
+}
+
+// newPrompter a prompter on the standard input, asking on the standard error to keep the output clean
+func newPrompter() *prompter {
+	return &prompter{In: bufio.NewReader(os.Stdin), Out: os.Stderr}
+}
+
+// Ask asks a question, the answer defaults to defaultValue when empty
+func (p *prompter) Ask(question string, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(p.Out, "%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(p.Out, "%s: ", question)
+	}
+	answer, err := p.In.ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		if err == io.EOF {
+			return "", errors.New("no answer to '" + question + "'")
+		}
+		return "", err
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
+
+// askNewAdr asks for the title, status, tags and summary of a new ADR, options holding the defaults.
+// The tags are asked again until they are in taxonomy, unless options allow new tags.
+func askNewAdr(p *prompter, options adr.CreateOptions, taxonomy adr.Taxonomy) (adr.CreateOptions, error) {
+	for options.Title == "" {
+		title, err := p.Ask("Title", "")
+		if err != nil {
+			return options, err
+		}
+		options.Title = title
+	}
+	for {
+		answer, err := p.Ask("Status", string(adr.Proposed))
+		if err != nil {
+			return options, err
+		}
+		if options.Status, err = adr.ParseStatus(answer); err == nil {
+			break
+		}
+		fmt.Fprintln(p.Out, err.Error())
+	}
+	given := options.Tags
+	for {
+		answer, err := p.Ask("Tags, comma separated", "")
+		if err != nil {
+			return options, err
+		}
+		options.Tags = given
+		for _, tag := range strings.Split(answer, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				options.Tags = append(options.Tags, tag)
+			}
+		}
+		unknown := taxonomy.Unknown(options.Tags)
+		if len(unknown) == 0 || options.AllowNewTags {
+			break
+		}
+		fmt.Fprintln(p.Out, "Unknown tags "+strings.Join(unknown, ", ")+", 'adr tags' lists the tags of the taxonomy")
+	}
+	var err error
+	if options.Summary, err = p.Ask("One-line summary of the decision", ""); err != nil {
+		return options, err
+	}
+	return options, nil
+}
+
+// confirm asks before a destructive operation, listing the files it affects. It fails when the user declines,
+// or when nobody can answer because adr is not run from a terminal, unless --yes was given.
+func confirm(c *cli.Context, out *reporter, action string, files []string) error {
+	out.Warning(action + ":")
+	for _, file := range files {
+		out.Info("  " + file)
+	}
+	if assumeYes || c.Bool("yes") {
+		return nil
+	}
+	if !interactive() {
+		return errors.New("confirmation needed, add --yes to " + strings.ToLower(action[:1]) + action[1:])
+	}
+	answer, err := newPrompter().Ask("Continue? y/N", "")
+	if err != nil {
+		return err
+	}
+	if answer = strings.ToLower(answer); answer != "y" && answer != "yes" {
+		return errors.New("aborted, nothing was changed")
+	}
+	return nil
+}
+
+// suggestTitle offers a normalized version of an ADR title: it is used with --fix-title, asked for on a terminal
+// and only hinted at otherwise
+func suggestTitle(c *cli.Context, out *reporter, title string) (string, error) {
+	normalized, reasons := adr.NormalizeTitle(title)
+	if len(reasons) == 0 {
+		return title, nil
+	}
+	if c.Bool("fix-title") {
+		return normalized, nil
+	}
+	suggestion := fmt.Sprintf("%q instead (fixes %s)", normalized, strings.Join(reasons, ", "))
+	if !interactive() {
+		out.Hint("Consider " + suggestion + ", --fix-title applies it")
+		return title, nil
+	}
+	answer, err := newPrompter().Ask("Use "+suggestion+"? Y/n", "")
+	if err != nil {
+		return title, err
+	}
+	if answer = strings.ToLower(answer); answer == "n" || answer == "no" {
+		return title, nil
+	}
+	return normalized, nil
+}
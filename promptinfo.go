@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// projectRoot the closest folder holding dir with a .git entry, found without running git so shell prompts stay fast.
+// It is dir itself outside of git repositories.
+func projectRoot(dir string) string {
+	dir = absPath(dir)
+	for candidate := dir; ; candidate = filepath.Dir(candidate) {
+		if _, err := os.Stat(filepath.Join(candidate, ".git")); err == nil {
+			return candidate
+		}
+		if filepath.Dir(candidate) == candidate {
+			return dir
+		}
+	}
+}
+
+// within tells whether path is dir or one of its descendants
+func within(path string, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// promptSummary the counts of ADRs by status, "3 proposed" or, with all statuses, "3 proposed, 12 accepted"
+func promptSummary(records []adr.Record, all bool) string {
+	counts := map[adr.Status]int{}
+	for _, record := range records {
+		counts[record.Status]++
+	}
+	parts := []string{}
+	for _, status := range adr.Statuses {
+		if counts[status] > 0 && (all || status == adr.Proposed) {
+			parts = append(parts, strconv.Itoa(counts[status])+" "+strings.ToLower(string(status)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// proposeAdr pushes the branch of an ADR and opens a pull request (GitHub) or merge request (GitLab) for it,
+// using the gh or glab command line tools which take care of authentication
+func proposeAdr(ctx context.Context, repo *adr.Repository, record adr.Record, remote string, branch string) error {
+	if _, err := runGit(ctx, repo.Dir, "push", "--set-upstream", remote, branch); err != nil {
+		return err
+	}
+	body, err := ioutil.ReadFile(record.Path)
+	if err != nil {
+		return err
+	}
+	title := "ADR-" + record.ID() + ": " + record.Title
+
+	remoteURL, err := runGit(ctx, repo.Dir, "remote", "get-url", remote)
+	if err != nil {
+		return err
+	}
+	var cmd *exec.Cmd
+	if strings.Contains(remoteURL, "gitlab") {
+		cmd = exec.CommandContext(ctx, "glab", "mr", "create", "--source-branch", branch, "--title", title, "--description", string(body), "--yes")
+	} else {
+		cmd = exec.CommandContext(ctx, "gh", "pr", "create", "--head", branch, "--title", title, "--body-file", "-")
+		cmd.Stdin = bytes.NewReader(body)
+	}
+	cmd.Dir = repo.Dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if execErr, ok := err.(*exec.Error); ok && execErr.Err == exec.ErrNotFound {
+			return errors.New(cmd.Args[0] + " is needed to open the pull request, the branch " + branch + " was pushed to " + remote)
+		}
+		return errors.New(cmd.Args[0] + " failed: " + err.Error())
+	}
+	return nil
+}
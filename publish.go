@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// publishManifestFileName the file, in the ADR directory, remembering the pages the ADRs were published to.
+// It is meant to be committed, so that anyone publishing again updates the same pages.
+var publishManifestFileName = ".adr-publish.json"
+
+// publisher writes ADRs to a documentation tool, one page each
+type publisher interface {
+	// Wants tells whether record gets a page, the ADRs that have one are updated whatever their status
+	Wants(record adr.Record) bool
+	// Publish writes the page of record, creating it when id is empty and updating the page id otherwise.
+	// It returns the ID and the URL of the page.
+	Publish(ctx context.Context, record adr.Record, content []byte, id string) (string, string, error)
+}
+
+// publishTargets the targets of adr publish, building their publisher from the configuration of a repository
+var publishTargets = map[string]func(repo *adr.Repository) (publisher, error){
+	"confluence":         newConfluencePublisher,
+	"github-discussions": newDiscussionsPublisher,
+	"notion":             newNotionPublisher,
+}
+
+// publishTargetNames the names of the publish targets, sorted
+func publishTargetNames() []string {
+	names := []string{}
+	for name := range publishTargets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pageTitle the title of the page of an ADR, e.g. "0012. Use PostgreSQL"
+func pageTitle(record adr.Record) string {
+	return record.ID() + ". " + record.Title
+}
+
+// publishManifest the pages the ADRs of a directory were published to
+type publishManifest struct {
+	Pages []publishedPage `json:"pages"`
+}
+
+// publishedPage the page of an ADR on a target, the hash being the one of the ADR file when it was last published
+type publishedPage struct {
+	Target string `json:"target"`
+	Number int    `json:"number"`
+	ID     string `json:"id"`
+	URL    string `json:"url,omitempty"`
+	Hash   string `json:"hash"`
+}
+
+// Outcomes of publishing one ADR
+const (
+	publishCreated  = "published"
+	publishUpdated  = "updated"
+	publishUpToDate = "up to date"
+)
+
+// publishResult what happened to one ADR during a publish
+type publishResult struct {
+	Record  adr.Record
+	URL     string
+	Outcome string
+}
+
+// publishAdrs publishes records to target, updating the pages they were published to before.
+// The ADRs that did not change since are left alone unless force is set.
+func publishAdrs(ctx context.Context, repo *adr.Repository, target string, records []adr.Record, force bool, op *operation) ([]publishResult, error) {
+	newPublisher, ok := publishTargets[target]
+	if !ok {
+		return nil, errors.New("unknown publish target '" + target + "', expected one of " + strings.Join(publishTargetNames(), ", "))
+	}
+	pub, err := newPublisher(repo)
+	if err != nil {
+		return nil, err
+	}
+	manifestPath := filepath.Join(repo.Dir, publishManifestFileName)
+	manifest := publishManifest{Pages: []publishedPage{}}
+	if bytes, err := os.ReadFile(manifestPath); err == nil {
+		if err := json.Unmarshal(bytes, &manifest); err != nil {
+			return nil, errors.New(manifestPath + ": " + err.Error())
+		}
+	}
+
+	results := []publishResult{}
+	for _, record := range records {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+			break
+		}
+		var page *publishedPage
+		for i := range manifest.Pages {
+			if manifest.Pages[i].Target == target && manifest.Pages[i].Number == record.Number {
+				page = &manifest.Pages[i]
+			}
+		}
+		if page == nil && !pub.Wants(record) {
+			continue
+		}
+		hash := hashFile(record.Path)
+		if page != nil && page.Hash == hash && !force {
+			results = append(results, publishResult{Record: record, URL: page.URL, Outcome: publishUpToDate})
+			continue
+		}
+		var content []byte
+		if content, err = os.ReadFile(record.Path); err != nil {
+			break
+		}
+		result := publishResult{Record: record, Outcome: publishUpdated}
+		if page == nil {
+			manifest.Pages = append(manifest.Pages, publishedPage{Target: target, Number: record.Number})
+			page = &manifest.Pages[len(manifest.Pages)-1]
+			result.Outcome = publishCreated
+		}
+		var id string
+		if id, result.URL, err = pub.Publish(ctx, record, content, page.ID); err != nil {
+			err = errors.New(record.Path + ": " + err.Error())
+			if page.ID == "" {
+				manifest.Pages = manifest.Pages[:len(manifest.Pages)-1]
+			}
+			break
+		}
+		page.ID, page.URL, page.Hash = id, result.URL, hash
+		results = append(results, result)
+	}
+
+	// the pages created before a failure are recorded, so that publishing again does not duplicate them
+	bytes, marshalErr := json.MarshalIndent(manifest, "", " ")
+	if marshalErr != nil {
+		return results, marshalErr
+	}
+	op.track(manifestPath)
+	if writeErr := os.WriteFile(manifestPath, bytes, 0644); writeErr != nil {
+		return results, writeErr
+	}
+	return results, err
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/urfave/cli"
+)
+
+// queryDateFormat the format of the --since and --until flags
+const queryDateFormat = "2006-01-02"
+
+// queryFlags the filters of the query command
+var queryFlags = []cli.Flag{
+	statusFilterFlag,
+	tagFilterFlag,
+	sinceFilterFlag,
+	untilFilterFlag,
+	cli.StringFlag{
+		Name:  "text",
+		Usage: "Keep the ADRs whose title or content contains this text, ignoring case",
+	},
+	categoryFilterFlag,
+}
+
+// statusFilterFlag, tagFilterFlag, sinceFilterFlag and untilFilterFlag filter the ADRs of the commands listing ADRs,
+// see parseQuery
+var (
+	statusFilterFlag = cli.StringSliceFlag{
+		Name:  "status",
+		Usage: "Keep the ADRs with this status, can be repeated to keep any of several statuses",
+	}
+	tagFilterFlag = cli.StringSliceFlag{
+		Name:  "tag",
+		Usage: "Keep the ADRs with this tag, can be repeated to require several tags",
+	}
+	sinceFilterFlag = cli.StringFlag{
+		Name:  "since",
+		Usage: "Keep the ADRs dated on or after this day, as YYYY-MM-DD",
+	}
+	untilFilterFlag = cli.StringFlag{
+		Name:  "until",
+		Usage: "Keep the ADRs dated on or before this day, as YYYY-MM-DD",
+	}
+)
+
+// categoryFilterFlag keeps the ADRs of some categories, in the commands listing ADRs
+var categoryFilterFlag = cli.StringSliceFlag{
+	Name:  "category",
+	Usage: "Keep the ADRs of this category, can be repeated to keep any of several categories",
+}
+
+// includeArchivedFlag adds the archived ADRs, see adr archive, to the ADRs a command lists
+var includeArchivedFlag = cli.BoolFlag{
+	Name:  "include-archived",
+	Usage: "Include the archived ADRs",
+}
+
+// parseQuery builds the query of the filter flags of a command
+func parseQuery(c *cli.Context) (adr.Query, error) {
+	q, err := newQuery(c.StringSlice("status"), c.StringSlice("tag"), c.String("since"), c.String("until"), c.String("text"), "--")
+	q.Categories = c.StringSlice("category")
+	return q, err
+}
+
+// newQuery builds a query from filters given as text, on the command line or in API requests.
+// Errors name the filters with prefix, e.g. "--" for flags.
+func newQuery(statuses []string, tags []string, since string, until string, text string, prefix string) (adr.Query, error) {
+	q := adr.Query{Tags: tags, Text: text}
+	for _, status := range statuses {
+		parsed, err := adr.ParseStatus(status)
+		if err != nil {
+			return adr.Query{}, err
+		}
+		q.Statuses = append(q.Statuses, parsed)
+	}
+	if since != "" {
+		t, err := time.Parse(queryDateFormat, since)
+		if err != nil {
+			return adr.Query{}, fmt.Errorf("invalid %ssince date %q, expected YYYY-MM-DD", prefix, since)
+		}
+		q.Since = t
+	}
+	if until != "" {
+		t, err := time.Parse(queryDateFormat, until)
+		if err != nil {
+			return adr.Query{}, fmt.Errorf("invalid %suntil date %q, expected YYYY-MM-DD", prefix, until)
+		}
+		// the whole day is included
+		q.Until = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return q, nil
+}
+
+// queryAdrs the ADRs of the repository matching q, in every scope when all scopes are selected
+func queryAdrs(ctx context.Context, repo *adr.Repository, q adr.Query) ([]adr.Record, error) {
+	if repo.Scope != adr.AllScopes {
+		return repo.Query(ctx, q)
+	}
+	records, err := readScopedAdrs(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return q.Filter(repo.FS, records)
+}
+
+// printRecords writes one line per ADR, with the author and date of its last commit when lastEdit is set
+func printRecords(ctx context.Context, repo *adr.Repository, out *reporter, records []adr.Record, lastEdit bool) {
+	for _, record := range records {
+		line := ""
+		if repo.Scope == adr.AllScopes {
+			line = out.Highlight(color.FgCyan, "%s ", record.Scope)
+		}
+		line += fmt.Sprintf("%s. %s [%s] (%s)", record.Ref(), record.Title, record.Status, record.Format)
+		if len(record.Tags) > 0 {
+			line += out.Highlight(color.FgMagenta, " #%s", strings.Join(record.Tags, " #"))
+		}
+		if record.Archived {
+			line += out.Highlight(color.Faint, " archived")
+		}
+		if lastEdit {
+			if author, date, err := lastCommit(ctx, record.Path); err == nil {
+				line += out.Highlight(color.FgYellow, " last edited by %s on %s", author, date)
+			} else {
+				line += out.Highlight(color.FgYellow, " not committed")
+			}
+		}
+		out.Info(line)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// errReadOnly a change asked to an ADR of another repository, answered with 409
+var errReadOnly = errors.New("read-only")
+
+// registry the ADRs adr serve shows: those of its repository, and the ADR directories of other repositories,
+// named roots, which are read-only. Serving several roots makes a registry of the decisions of a whole organization.
+type registry struct {
+	repo *adr.Repository
+	// roots the directories of the other repositories, by name
+	roots map[string]string
+}
+
+// newRegistry checks the roots: their names must not be taken by a scope and their directories must exist.
+// Relative directories of the configuration are resolved like scopes, those of the command line from the current folder.
+func newRegistry(ctx context.Context, repo *adr.Repository, configured map[string]string, given []string) (*registry, error) {
+	settings := repo.Settings()
+	roots := map[string]string{}
+	for name, dir := range configured {
+		roots[name] = scopeDir(ctx, repo.BaseDir(), dir)
+	}
+	for _, root := range given {
+		name, dir, ok := strings.Cut(root, "=")
+		if !ok || name == "" || dir == "" {
+			return nil, errors.New("invalid root " + root + ", roots are given as name=directory")
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		roots[name] = abs
+	}
+	for name, dir := range roots {
+		if _, scope := settings.Scopes[name]; scope || name == defaultScope || name == adr.AllScopes {
+			return nil, errors.New("the root " + name + " is named like a scope, rename it")
+		}
+		if info, err := repo.FS.Stat(dir); err != nil || !info.IsDir() {
+			return nil, errors.New("the directory " + dir + " of the root " + name + " is not found")
+		}
+	}
+	return &registry{repo: repo, roots: roots}, nil
+}
+
+// Names the names of the roots, sorted
+func (g *registry) Names() []string {
+	names := []string{}
+	for name := range g.roots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Scopes the scopes of the ADRs served: the scope of the repository, then the roots
+func (g *registry) Scopes() []string {
+	scope := g.repo.Scope
+	if scope == "" {
+		scope = defaultScope
+	}
+	if scope != adr.AllScopes {
+		return append([]string{scope}, g.Names()...)
+	}
+	scopes := []string{defaultScope}
+	for name := range g.repo.Settings().Scopes {
+		scopes = append(scopes, name)
+	}
+	sort.Strings(scopes[1:])
+	return append(scopes, g.Names()...)
+}
+
+// Query the ADRs of the repository then those of every root matching q. With roots, the ADRs of
+// the repository are in the default scope, and those of a root in the scope named after it.
+func (g *registry) Query(ctx context.Context, q adr.Query) ([]adr.Record, error) {
+	records, err := queryAdrs(ctx, g.repo, q)
+	if err != nil || len(g.roots) == 0 {
+		return records, err
+	}
+	for i := range records {
+		if records[i].Scope == "" {
+			records[i].Scope = defaultScope
+		}
+	}
+	for _, name := range g.Names() {
+		root, err := adr.ReadDir(ctx, g.repo.FS, g.roots[name])
+		if err != nil {
+			return nil, err
+		}
+		for i := range root {
+			root[i].Scope = name
+		}
+		if root, err = q.Filter(g.repo.FS, root); err != nil {
+			return nil, err
+		}
+		records = append(records, root...)
+	}
+	return records, nil
+}
+
+// Find the ADR numbered number in scope and category, the repository being searched when scope is empty,
+// and every category when category is empty
+func (g *registry) Find(ctx context.Context, scope string, category string, number int) (adr.Record, error) {
+	if scope == "" && category == "" {
+		return g.repo.Find(ctx, number)
+	}
+	records, err := g.Query(ctx, adr.Query{})
+	if err != nil {
+		return adr.Record{}, err
+	}
+	if scope == "" {
+		scope = defaultScope
+	}
+	for _, record := range records {
+		if record.Number == number && inScope(record, scope) && (category == "" || record.Category == category) {
+			return record, nil
+		}
+	}
+	if category != "" {
+		return adr.Record{}, fmt.Errorf("%w: no ADR number %d in the category %s of the scope %s", adr.ErrAdrNotFound, number, category, scope)
+	}
+	return adr.Record{}, fmt.Errorf("%w: no ADR number %d in the scope %s", adr.ErrAdrNotFound, number, scope)
+}
+
+// Writable fails with errReadOnly when record is not in the directory of the repository, e.g. in a root
+func (g *registry) Writable(record adr.Record) error {
+	if filepath.Clean(filepath.Dir(record.Path)) != filepath.Join(g.repo.Dir, record.Category) {
+		return fmt.Errorf("%w: %s is not in %s, change it in its own repository", errReadOnly, record.Path, g.repo.Dir)
+	}
+	return nil
+}
+
+// inScope tells whether record is in scope, records without a scope being in the default scope
+func inScope(record adr.Record, scope string) bool {
+	return record.Scope == scope || (record.Scope == "" && scope == defaultScope)
+}
+
+// inScopes the records in one of scopes, every record when there is no scope
+func inScopes(records []adr.Record, scopes []string) []adr.Record {
+	if len(scopes) == 0 {
+		return records
+	}
+	selected := []adr.Record{}
+	for _, record := range records {
+		for _, scope := range scopes {
+			if inScope(record, scope) {
+				selected = append(selected, record)
+				break
+			}
+		}
+	}
+	return selected
+}
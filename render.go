@@ -0,0 +1,180 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/russross/blackfriday/v2"
+)
+
+var (
+	mdHeadingRegexp   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	mdUnderlineRegexp = regexp.MustCompile(`^(=+|-+)\s*$`)
+	mdBulletRegexp    = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	mdTableRuleRegexp = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	mdCodeRegexp      = regexp.MustCompile("`([^`]+)`")
+	mdBoldRegexp      = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdItalicRegexp    = regexp.MustCompile(`(^|[^*\w])[*_]([^*_]+)[*_]`)
+	mdLinkRegexp      = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+)
+
+// markdownRenderer renders markdown for the terminal: styled headings, emphasis, code, quotes and aligned tables.
+// Without colors only the layout is kept.
+type markdownRenderer struct {
+	out *reporter
+}
+
+func (m markdownRenderer) style(text string, attributes ...color.Attribute) string {
+	if !m.out.colored() {
+		return text
+	}
+	return color.New(attributes...).Sprint(text)
+}
+
+// Render renders a whole markdown document
+func (m markdownRenderer) Render(content string) string {
+	lines := strings.Split(strings.Replace(content, "\r\n", "\n", -1), "\n")
+	rendered := []string{}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case i == 0 && trimmed == "---":
+			// front matter, shown dimmed
+			for ; i < len(lines); i++ {
+				rendered = append(rendered, m.style(lines[i], color.Faint))
+				if i > 0 && strings.TrimSpace(lines[i]) == "---" {
+					break
+				}
+			}
+		case strings.HasPrefix(trimmed, "```"):
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				rendered = append(rendered, "    "+m.style(lines[i], color.FgYellow))
+			}
+		case mdHeadingRegexp.MatchString(trimmed):
+			match := mdHeadingRegexp.FindStringSubmatch(trimmed)
+			rendered = append(rendered, m.heading(len(match[1]), match[2]))
+		case i+1 < len(lines) && trimmed != "" && mdUnderlineRegexp.MatchString(strings.TrimSpace(lines[i+1])) && !strings.HasPrefix(trimmed, "|"):
+			// setext heading, or a heading of adr's own format followed by its "======" rule
+			level := 2
+			if strings.HasPrefix(strings.TrimSpace(lines[i+1]), "=") {
+				level = 1
+			}
+			rendered = append(rendered, m.heading(level, trimmed))
+			i++
+		case mdUnderlineRegexp.MatchString(trimmed):
+			// rule under a heading of adr's own format
+		case strings.HasPrefix(trimmed, "|"):
+			table := []string{}
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				table = append(table, strings.TrimSpace(lines[i]))
+			}
+			i--
+			rendered = append(rendered, m.table(table)...)
+		case strings.HasPrefix(trimmed, ">"):
+			rendered = append(rendered, m.style("│ ", color.Faint)+m.inline(strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))))
+		case mdBulletRegexp.MatchString(line):
+			match := mdBulletRegexp.FindStringSubmatch(line)
+			rendered = append(rendered, match[1]+"• "+m.inline(match[2]))
+		default:
+			rendered = append(rendered, m.inline(line))
+		}
+	}
+	// the rules dropped under headings leave runs of blank lines behind
+	compact := []string{}
+	for i, line := range rendered {
+		if line != "" || i == 0 || rendered[i-1] != "" {
+			compact = append(compact, line)
+		}
+	}
+	return strings.Join(compact, "\n")
+}
+
+// heading styles a heading, level 1 being the title of the ADR
+func (m markdownRenderer) heading(level int, text string) string {
+	if level == 1 {
+		return m.style(text, color.Bold, color.FgCyan, color.Underline)
+	}
+	return m.style(text, color.Bold, color.FgCyan)
+}
+
+// inline styles the emphasis, code spans and links of a line
+func (m markdownRenderer) inline(line string) string {
+	line = mdLinkRegexp.ReplaceAllStringFunc(line, func(link string) string {
+		match := mdLinkRegexp.FindStringSubmatch(link)
+		return match[1] + " " + m.style("("+match[2]+")", color.Underline, color.Faint)
+	})
+	line = mdCodeRegexp.ReplaceAllStringFunc(line, func(code string) string {
+		return m.style(strings.Trim(code, "`"), color.FgYellow)
+	})
+	line = mdBoldRegexp.ReplaceAllStringFunc(line, func(bold string) string {
+		return m.style(bold[2:len(bold)-2], color.Bold)
+	})
+	return mdItalicRegexp.ReplaceAllStringFunc(line, func(italic string) string {
+		match := mdItalicRegexp.FindStringSubmatch(italic)
+		return match[1] + m.style(match[2], color.Italic)
+	})
+}
+
+// table aligns the cells of a markdown table and draws its rules
+func (m markdownRenderer) table(lines []string) []string {
+	rows := [][]string{}
+	widths := []int{}
+	for _, line := range lines {
+		if mdTableRuleRegexp.MatchString(line) {
+			continue
+		}
+		cells := strings.Split(strings.Trim(line, "|"), "|")
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if width := utf8.RuneCountInString(cells[i]); width > widths[i] {
+				widths[i] = width
+			}
+		}
+		rows = append(rows, cells)
+	}
+	rule := []string{}
+	for _, width := range widths {
+		rule = append(rule, strings.Repeat("─", width+2))
+	}
+	rendered := []string{m.style("┌"+strings.Join(rule, "┬")+"┐", color.Faint)}
+	for r, row := range rows {
+		cells := []string{}
+		for i, width := range widths {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			padded := " " + cell + strings.Repeat(" ", width-utf8.RuneCountInString(cell)) + " "
+			if r == 0 {
+				padded = m.style(padded, color.Bold)
+			} else {
+				padded = m.inline(padded)
+			}
+			cells = append(cells, padded)
+		}
+		separator := m.style("│", color.Faint)
+		rendered = append(rendered, separator+strings.Join(cells, separator)+separator)
+		if r == 0 && len(rows) > 1 {
+			rendered = append(rendered, m.style("├"+strings.Join(rule, "┼")+"┤", color.Faint))
+		}
+	}
+	return append(rendered, m.style("└"+strings.Join(rule, "┴")+"┘", color.Faint))
+}
+
+// htmlRule the "======" rule adr writes under its headings, a setext underline to other markdown renderers
+var htmlRule = regexp.MustCompile(`(?m)^(#+ .*)\r?\n=+[ \t]*$`)
+
+// renderHTML renders the markdown of an ADR to HTML, raw HTML of the markdown being left out
+func renderHTML(content []byte) []byte {
+	content = htmlRule.ReplaceAll(content, []byte("$1"))
+	renderer := blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+		Flags: blackfriday.CommonHTMLFlags | blackfriday.SkipHTML,
+	})
+	return blackfriday.Run(content, blackfriday.WithRenderer(renderer), blackfriday.WithExtensions(blackfriday.CommonExtensions))
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+
+	"github.com/fatih/color"
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/urfave/cli"
+)
+
+// runRenumber numbers the ADRs, archived ones included, from 1 without gaps nor duplicates, see adr.PlanRenumbering,
+// under the lock. Their files are renamed, their headings renumbered and the links of every ADR to them updated, the
+// counter of the configuration following, once the files to rename and rewrite are confirmed. With --dry-run the new
+// numbers are only printed.
+func runRenumber(ctx context.Context, c *cli.Context, repo *adr.Repository, out *reporter) error {
+	release, err := repo.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if err := repo.Reload(); err != nil {
+		return err
+	}
+	records, err := repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	archived, err := repo.ListArchived(ctx)
+	if err != nil {
+		return err
+	}
+	records = append(records, archived...)
+	plan := adr.PlanRenumbering(records, repo.Settings().PerCategory())
+	if len(plan) == 0 {
+		out.Success("The ADRs are numbered without gaps nor duplicates")
+		return nil
+	}
+	for _, renumbering := range plan {
+		record := renumbering.Record
+		renumbered := record
+		renumbered.Number = renumbering.Number
+		out.Info(record.Ref() + " -> " + renumbered.Ref() + ". " + record.Title + out.Highlight(color.FgYellow, " %s", filepath.Base(renumbering.Path)))
+	}
+	if c.Bool("dry-run") {
+		out.Hint("Dry run, nothing was changed: " + pluralize(len(plan), "ADR") + " to renumber")
+		return nil
+	}
+
+	renamed := map[string]string{}
+	for _, renumbering := range plan {
+		renamed[renumbering.Record.Path] = renumbering.Path
+	}
+	for _, renumbering := range plan {
+		if _, moving := renamed[renumbering.Path]; !moving && renumbering.Path != renumbering.Record.Path {
+			if _, err := repo.FS.Stat(renumbering.Path); err == nil {
+				return errors.New("cannot renumber " + renumbering.Record.Path + ", " + renumbering.Path + " already exists")
+			}
+		}
+	}
+	numbers := map[string]int{}
+	for _, renumbering := range plan {
+		numbers[renumbering.Record.Path] = renumbering.Number
+	}
+	// every file is read before any is written, the new path of an ADR can be the old path of another
+	contents := map[string][]byte{}
+	written := []string{}
+	for _, record := range records {
+		content, err := repo.FS.ReadFile(record.Path)
+		if err != nil {
+			return err
+		}
+		updated := adr.RenumberLinks(record, content, plan)
+		path := record.Path
+		if number, ok := numbers[record.Path]; ok {
+			updated = adr.SetHeadingNumber(updated, strconv.Itoa(number))
+			path = renamed[record.Path]
+		}
+		if path != record.Path || !bytes.Equal(content, updated) {
+			contents[path] = updated
+			written = append(written, path)
+		}
+	}
+
+	files := []string{}
+	for _, path := range written {
+		if from, ok := renamedFrom(renamed, path); ok {
+			files = append(files, from+" -> "+path)
+		} else {
+			files = append(files, path)
+		}
+	}
+	if err := confirm(c, out, "Renumber "+pluralize(len(plan), "ADR"), files); err != nil {
+		return err
+	}
+
+	op := startOperation(repo.ConfigDir, "renumber", []string{})
+	defer op.done()
+	signatures := map[string][]byte{}
+	for from, to := range renamed {
+		op.track(from)
+		if signature, err := repo.FS.ReadFile(from + adr.SignatureExtension); err == nil {
+			signatures[to+adr.SignatureExtension] = signature
+			op.track(from + adr.SignatureExtension)
+			if err := repo.FS.Remove(from + adr.SignatureExtension); err != nil {
+				return err
+			}
+		}
+		if err := repo.FS.Remove(from); err != nil {
+			return err
+		}
+	}
+	for path, signature := range signatures {
+		op.track(path)
+		if err := repo.FS.WriteFile(path, signature, 0644); err != nil {
+			return err
+		}
+	}
+	for _, path := range written {
+		op.track(path)
+		if err := repo.FS.WriteFile(path, contents[path], 0644); err != nil {
+			return err
+		}
+	}
+	if repo.Scope == "" {
+		counted := 0
+		for _, record := range records {
+			if record.Draft == "" && (record.Category == "" || !repo.Settings().PerCategory()) {
+				counted++
+			}
+		}
+		op.track(repo.ConfigPath())
+		if err := repo.SetCounter(counted); err != nil {
+			return err
+		}
+	}
+	refreshToc(ctx, repo, out, plan[0].Record, op)
+	out.Success(pluralize(len(plan), "ADR") + " renumbered")
+	if len(signatures) > 0 {
+		out.Hint("The headings of the signed ADRs changed, 'adr sign' signs them again")
+	}
+	if !shouldCommit(c, repo) {
+		return nil
+	}
+	first := plan[0].Record
+	first.Number, first.Path = plan[0].Number, plan[0].Path
+	return commitAdr(ctx, repo, "renumber", first, op.files()...)
+}
+
+// renamedFrom the path of the ADR renamed to path, if any
+func renamedFrom(renamed map[string]string, path string) (string, bool) {
+	for from, to := range renamed {
+		if to == path {
+			return from, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// defaultReviewWindow how far ahead adr review looks for upcoming reviews
+const defaultReviewWindow = "30d"
+
+// runReview reports the decisions in force whose review is overdue, then those to review within window,
+// an interval such as 30d or 2m
+func runReview(ctx context.Context, repo *adr.Repository, out *reporter, window string) error {
+	now := time.Now()
+	until, err := adr.AddInterval(now, window)
+	if err != nil {
+		return err
+	}
+	records, err := readScopedAdrs(ctx, repo)
+	if err != nil {
+		return err
+	}
+	reviews := adr.DueReviews(records, until)
+	if out.JSON {
+		return out.Document(adr.NewReviewReportJSON(reviews, now))
+	}
+	if len(reviews) == 0 {
+		out.Success("No ADR to review within " + window)
+		return nil
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for _, review := range reviews {
+		days := int(review.Due.Sub(today).Hours() / 24)
+		name := review.Record.ID() + ". " + review.Record.Title
+		due := review.Due.Format(adr.ReviewDateFormat)
+		switch {
+		case review.Overdue(now):
+			out.Warning(fmt.Sprintf("%s: review overdue since %s, %s ago", name, due, pluralize(-days, "day")))
+		case days == 0:
+			out.Warning(name + ": review due today")
+		default:
+			out.Info(fmt.Sprintf("%s. %s: review by %s, in %s", out.Highlight(color.FgYellow, "%s", review.Record.ID()), review.Record.Title, due, pluralize(days, "day")))
+		}
+	}
+	return nil
+}
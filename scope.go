@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// defaultScope names the base directory when listing ADRs of several scopes
+const defaultScope = "default"
+
+// selectedScope is set by the global --scope flag
+var selectedScope string
+
+// applyScope points the repository at the directory of the selected scope
+func applyScope(ctx context.Context, repo *adr.Repository, scope string) error {
+	if scope == "" || scope == defaultScope {
+		return nil
+	}
+	repo.Scope = scope
+	if scope == adr.AllScopes {
+		return nil
+	}
+	dir, ok := repo.Settings().Scopes[scope]
+	if !ok {
+		return errors.New("unknown scope " + scope + ", scopes are declared in the scopes configuration")
+	}
+	repo.Dir = scopeDir(ctx, repo.BaseDir(), dir)
+	return nil
+}
+
+// scopeDir resolves a scope directory, relative directories are relative to the git repository
+// holding the base directory, or to the base directory itself outside of git
+func scopeDir(ctx context.Context, baseDir string, dir string) string {
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	if root, err := gitRepositoryRoot(ctx, baseDir); err == nil {
+		return filepath.Join(root, dir)
+	}
+	return filepath.Join(baseDir, dir)
+}
+
+// readScopedAdrs reads the ADRs of the selected scope, or of the base directory and every scope with --scope all
+func readScopedAdrs(ctx context.Context, repo *adr.Repository) ([]adr.Record, error) {
+	if repo.Scope != adr.AllScopes {
+		return repo.List(ctx)
+	}
+
+	scopes := repo.Settings().Scopes
+	names := []string{}
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	all, err := adr.ReadDir(ctx, repo.FS, repo.BaseDir())
+	if err != nil {
+		return nil, err
+	}
+	for i := range all {
+		all[i].Scope = defaultScope
+	}
+	for _, name := range names {
+		records, err := adr.ReadDir(ctx, repo.FS, scopeDir(ctx, repo.BaseDir(), scopes[name]))
+		if err != nil {
+			return nil, err
+		}
+		for i := range records {
+			records[i].Scope = name
+		}
+		all = append(all, records...)
+	}
+	return all, nil
+}
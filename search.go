@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// searchSnippetWidth the number of characters of the snippets of adr search, around the first term found
+const searchSnippetWidth = 100
+
+// runSearch prints the ADRs matching q that hold every term, the archived ADRs too with includeArchived, the best hits
+// first, with a snippet of their first matching line
+func runSearch(ctx context.Context, repo *adr.Repository, out *reporter, q adr.Query, terms []string, includeArchived bool) error {
+	records, err := queryAdrs(ctx, repo, q)
+	if err != nil {
+		return err
+	}
+	if includeArchived {
+		if records, err = withArchived(ctx, repo, records, q); err != nil {
+			return err
+		}
+	}
+	hits, err := adr.Search(repo.FS, records, terms)
+	if err != nil {
+		return err
+	}
+	if out.JSON {
+		found := []adr.Record{}
+		for _, hit := range hits {
+			found = append(found, hit.Record)
+		}
+		return out.Document(adr.NewListingJSON(found))
+	}
+	if len(hits) == 0 {
+		out.Info("No ADR contains " + strings.Join(terms, " and "))
+		return nil
+	}
+	for _, hit := range hits {
+		record := hit.Record
+		line := record.Ref() + ". " + highlightTerms(out, record.Title, terms) + " [" + string(record.Status) + "]"
+		if record.Archived {
+			line += out.Highlight(color.Faint, " archived")
+		}
+		out.Info(line)
+		if len(hit.Matches) > 0 {
+			match := hit.Matches[0]
+			line := out.Highlight(color.FgYellow, "    %d: ", match.Line) + highlightTerms(out, snippet(match.Text, terms, searchSnippetWidth), terms)
+			if more := len(hit.Matches) - 1; more > 0 {
+				line += out.Highlight(color.Faint, " (+%s)", pluralize(more, "line"))
+			}
+			out.Info(line)
+		}
+	}
+	return nil
+}
+
+// snippet cuts text to about width characters around the first of terms it holds, marking the cuts with ellipses
+func snippet(text string, terms []string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	lower, at := strings.ToLower(text), -1
+	for _, term := range terms {
+		if i := strings.Index(lower, strings.ToLower(term)); i >= 0 && (at < 0 || i < at) {
+			at = i
+		}
+	}
+	// the lowercase text may not be as long as the text, e.g. for some unicode letters
+	if at < 0 || len(lower) != len(text) {
+		at = 0
+	}
+	start := utf8.RuneCountInString(text[:at]) - width/3
+	if start < 0 {
+		start = 0
+	}
+	end := start + width
+	if end > len(runes) {
+		end, start = len(runes), len(runes)-width
+	}
+	cut := string(runes[start:end])
+	if start > 0 {
+		cut = "…" + cut
+	}
+	if end < len(runes) {
+		cut += "…"
+	}
+	return cut
+}
+
+// highlightTerms colors the occurrences of terms in text, compared case-insensitively
+func highlightTerms(out *reporter, text string, terms []string) string {
+	highlighted := ""
+	lower := strings.ToLower(text)
+	for len(text) > 0 {
+		at, length := -1, 0
+		for _, term := range terms {
+			term = strings.ToLower(strings.TrimSpace(term))
+			if i := strings.Index(lower, term); term != "" && i >= 0 && (at < 0 || i < at || (i == at && len(term) > length)) {
+				at, length = i, len(term)
+			}
+		}
+		// the lowercase text may not be as long as the text, e.g. for some unicode letters
+		if at < 0 || len(lower) != len(text) {
+			return highlighted + text
+		}
+		highlighted += text[:at] + out.Highlight(color.Bold, "%s", text[at:at+length])
+		text, lower = text[at+length:], lower[at+length:]
+	}
+	return highlighted
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// defaultListenAddress where adr serve listens, only reachable from the local machine
+const defaultListenAddress = "localhost:8080"
+
+// apiPrefix the path of the REST API
+const apiPrefix = "/api/"
+
+// maxRequestSize the largest request body the API reads
+const maxRequestSize = 1 << 20
+
+// apiError the JSON body of API errors
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// createRequest the JSON body creating an ADR, only the title is required
+type createRequest struct {
+	Title    string   `json:"title"`
+	Author   string   `json:"author"`
+	Status   string   `json:"status"`
+	Tags     []string `json:"tags"`
+	Summary  string   `json:"summary"`
+	Template string   `json:"template"`
+	Draft    bool     `json:"draft"`
+}
+
+// scopesJSON the scopes of the ADRs served
+type scopesJSON struct {
+	Scopes []string `json:"scopes"`
+}
+
+// transitionRequest the JSON body changing the status of an ADR
+type transitionRequest struct {
+	Status string `json:"status"`
+}
+
+// apiServer serves the REST API of adr serve over a repository
+type apiServer struct {
+	repo        *adr.Repository
+	registry    *registry
+	out         *reporter
+	credentials []credential
+	metrics     http.Handler
+	changes     http.Handler
+}
+
+// webAssets the files of the web UI, served at the root
+//
+//go:embed web
+var webAssets embed.FS
+
+// openAPI the OpenAPI document of the API, served at /api/openapi.json
+//
+//go:embed api/openapi.json
+var openAPI []byte
+
+// Handler routes the API requests, the other paths serve the web UI. Both need credentials when some are configured.
+//
+//	GET  /api/adrs                 lists the ADRs, filtered by the status, tag, since, until, text and scope parameters
+//	POST /api/adrs                 creates an ADR
+//	GET  /api/adrs/{number}        returns an ADR with its content, of the scope and category parameters when given
+//	GET  /api/adrs/{number}/html   returns the content of an ADR rendered to HTML
+//	PUT  /api/adrs/{number}/status changes the status of an ADR
+//	GET  /api/search?q=text        lists the ADRs whose title or content contains the text
+//	GET  /api/scopes               lists the scopes of the ADRs served, roots included
+//	GET  /api/events               streams the changes of the ADRs as server-sent events
+//	GET  /api/graph                returns the ADRs and the links between them
+//	GET  /api/calendar.ics         returns the review-by dates and the deadlines of the ADRs as an iCalendar feed
+//	POST /api/graphql              runs a GraphQL query, also as GET /api/graphql?query=...
+//	GET  /api/graphql/schema       returns the GraphQL schema
+//	GET  /api/openapi.json         returns the OpenAPI document of the API
+//	GET  /metrics                  returns metrics about the ADRs in the Prometheus text format
+func (s *apiServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(apiPrefix+"adrs", s.handleAdrs)
+	mux.HandleFunc(apiPrefix+"adrs/", s.handleAdr)
+	mux.HandleFunc(apiPrefix+"search", s.handleSearch)
+	mux.HandleFunc(apiPrefix+"graph", s.handleGraph)
+	mux.HandleFunc(apiPrefix+"calendar.ics", s.handleCalendar)
+	mux.HandleFunc(apiPrefix+"scopes", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, scopesJSON{Scopes: s.registry.Scopes()})
+	})
+	mux.HandleFunc(apiPrefix+"graphql", s.handleGraphQL)
+	mux.HandleFunc(apiPrefix+"graphql/schema", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, graphQLSchema)
+	})
+	mux.HandleFunc(apiPrefix+"openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	mux.HandleFunc(apiPrefix, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, apiError{Error: "no such resource " + r.URL.Path})
+	})
+	if s.changes != nil {
+		mux.Handle(apiPrefix+"events", s.changes)
+	}
+	if s.metrics != nil {
+		mux.Handle("/metrics", s.metrics)
+	}
+	web, _ := fs.Sub(webAssets, "web")
+	mux.Handle("/", http.FileServer(http.FS(web)))
+	return logRequests(requireCredentials(s.credentials, mux))
+}
+
+// statusRecorder remembers the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController flush the streams of server-sent events
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// logRequests logs every request with the status of its response
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		slog.Debug("request served", "method", r.Method, "path", r.URL.Path, "status", recorder.status, "duration", time.Since(start))
+	})
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Debug("response not written", "error", err)
+	}
+}
+
+// writeError writes an error response, with the status matching the errors of the library
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, adr.ErrAdrNotFound), errors.Is(err, adr.ErrTemplateNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, adr.ErrInvalidStatus), errors.Is(err, adr.ErrUnknownTag), errors.Is(err, errBadRequest):
+		status = http.StatusBadRequest
+	case errors.Is(err, errUnsupportedMediaType):
+		status = http.StatusUnsupportedMediaType
+	case errors.Is(err, adr.ErrDuplicateNumber), errors.Is(err, errReadOnly), errors.Is(err, adr.ErrQuorumNotMet):
+		status = http.StatusConflict
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, apiError{Error: err.Error()})
+}
+
+// errBadRequest an invalid request, answered with 400
+var errBadRequest = errors.New("bad request")
+
+// errUnsupportedMediaType a request body of another type than the one expected, answered with 415
+var errUnsupportedMediaType = errors.New("unsupported media type")
+
+// badRequest an invalid request error with its reason
+func badRequest(reason string) error {
+	return fmt.Errorf("%w: %s", errBadRequest, reason)
+}
+
+// methodNotAllowed answers a request with a method the path does not support
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, apiError{Error: "method not allowed, use " + strings.Join(allowed, " or ")})
+}
+
+// readJSON decodes the JSON body of a request into body. The body must be sent as application/json: web pages of
+// other sites can post forms and text/plain bodies without the browser asking the server first, not JSON.
+func readJSON(r *http.Request, body interface{}) error {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return fmt.Errorf("%w: the body must be sent as application/json", errUnsupportedMediaType)
+	}
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(body); err != nil {
+		return badRequest("invalid JSON body: " + err.Error())
+	}
+	return nil
+}
+
+// handleAdrs lists and creates ADRs
+func (s *apiServer) handleAdrs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		params := r.URL.Query()
+		q, err := newQuery(params["status"], params["tag"], params.Get("since"), params.Get("until"), params.Get("text"), "")
+		if err != nil {
+			writeError(w, badRequest(err.Error()))
+			return
+		}
+		q.Categories = params["category"]
+		s.writeListing(w, r, q)
+	case http.MethodPost:
+		s.create(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleSearch lists the ADRs containing the text of the q parameter
+func (s *apiServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	text := strings.TrimSpace(r.URL.Query().Get("q"))
+	if text == "" {
+		writeError(w, badRequest("the q parameter is missing"))
+		return
+	}
+	s.writeListing(w, r, adr.Query{Text: text})
+}
+
+// writeListing writes the ADRs matching q, of the scopes of the scope parameters when there are some
+func (s *apiServer) writeListing(w http.ResponseWriter, r *http.Request, q adr.Query) {
+	records, err := s.registry.Query(r.Context(), q)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, adr.NewListingJSON(inScopes(records, r.URL.Query()["scope"])))
+}
+
+// handleGraph returns the ADRs and the links between them
+func (s *apiServer) handleGraph(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	records, err := s.registry.Query(r.Context(), adr.Query{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, adr.NewGraphJSON(records))
+}
+
+// handleCalendar serves the iCalendar feed of the ADRs, for calendars to subscribe to
+func (s *apiServer) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	records, err := s.registry.Query(r.Context(), adr.Query{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// rendered first, so that a failed export is answered with an error rather than a truncated calendar
+	var calendar bytes.Buffer
+	if err := adr.Export("ics", &calendar, records); err != nil {
+		slog.Warn("calendar not exported", "error", err)
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if _, err := calendar.WriteTo(w); err != nil {
+		slog.Debug("response not written", "error", err)
+	}
+}
+
+// handleGraphQL runs a GraphQL query, posted as JSON or given in the query parameter
+func (s *apiServer) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	request := graphQLRequest{}
+	switch r.Method {
+	case http.MethodGet:
+		params := r.URL.Query()
+		request.Query, request.OperationName = params.Get("query"), params.Get("operationName")
+		if variables := params.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				writeError(w, badRequest("invalid variables: "+err.Error()))
+				return
+			}
+		}
+	case http.MethodPost:
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql") {
+			query, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxRequestSize))
+			if err != nil {
+				writeError(w, badRequest(err.Error()))
+				return
+			}
+			request.Query = string(query)
+		} else if err := readJSON(r, &request); err != nil {
+			writeError(w, err)
+			return
+		}
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		return
+	}
+	if strings.TrimSpace(request.Query) == "" {
+		writeError(w, badRequest("the query is missing"))
+		return
+	}
+	root := &graphQLRoot{ctx: r.Context(), registry: s.registry}
+	response := executeGraphQL(root.object(), request.Query, request.OperationName, request.Variables)
+	status := http.StatusOK
+	if response.Data == nil {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, response)
+}
+
+// create writes a new ADR, running the hooks and recording it in the journal like adr new
+func (s *apiServer) create(w http.ResponseWriter, r *http.Request) {
+	request := createRequest{}
+	if err := readJSON(r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+	if strings.TrimSpace(request.Title) == "" {
+		writeError(w, badRequest("the title is missing"))
+		return
+	}
+	options := adr.CreateOptions{
+		Author:   request.Author,
+		Status:   adr.Status(request.Status),
+		Tags:     request.Tags,
+		Summary:  request.Summary,
+		Template: request.Template,
+		Draft:    request.Draft,
+	}
+	if options.Author == "" {
+		options.Author = s.repo.Settings().Author
+	}
+	record, err := createAdr(r.Context(), s.repo, s.out, "serve", []string{request.Title}, options)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if record.Draft == "" {
+		w.Header().Set("Location", apiPrefix+"adrs/"+strconv.Itoa(record.Number))
+	}
+	s.writeDocument(w, http.StatusCreated, record)
+}
+
+// handleAdr returns an ADR, or changes its status
+func (s *apiServer) handleAdr(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, apiPrefix+"adrs/"), "/"), "/")
+	number, err := strconv.Atoi(parts[0])
+	if err != nil || number <= 0 || len(parts) > 2 || (len(parts) == 2 && parts[1] != "status" && parts[1] != "html") {
+		writeJSON(w, http.StatusNotFound, apiError{Error: "no such resource " + r.URL.Path})
+		return
+	}
+	record, err := s.registry.Find(r.Context(), r.URL.Query().Get("scope"), r.URL.Query().Get("category"), number)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if len(parts) == 1 || parts[1] == "html" {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		if len(parts) == 1 {
+			s.writeDocument(w, http.StatusOK, record)
+			return
+		}
+		content, err := s.repo.FS.ReadFile(record.Path)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(renderHTML(content))
+		return
+	}
+	if r.Method != http.MethodPut {
+		methodNotAllowed(w, http.MethodPut)
+		return
+	}
+	if err := s.registry.Writable(record); err != nil {
+		writeError(w, err)
+		return
+	}
+	request := transitionRequest{}
+	if err := readJSON(r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+	if record, err = transitionAdr(r.Context(), s.repo, "serve", record, adr.Status(request.Status)); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.writeDocument(w, http.StatusOK, record)
+}
+
+// writeDocument writes an ADR with its content
+func (s *apiServer) writeDocument(w http.ResponseWriter, status int, record adr.Record) {
+	content, err := s.repo.FS.ReadFile(record.Path)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, status, adr.NewDocumentJSON(record, content))
+}
+
+// serve runs handler on address until ctx is cancelled, then lets the running requests finish
+func serve(ctx context.Context, out *reporter, address string, what string, handler http.Handler, open bool) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: sameOrigin(listener.Addr(), handler), ReadHeaderTimeout: 10 * time.Second}
+	out.Success("Serving " + what + " on http://" + listener.Addr().String() + ", Ctrl-C stops")
+	if open {
+		if err := openBrowser(ctx, browserURL(listener.Addr())); err != nil {
+			out.Warning("Could not open a browser: " + err.Error())
+		}
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(listener)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdown)
+}
+
+// browserCommands the commands opening a URL in the default browser, by operating system
+var browserCommands = map[string][]string{
+	"darwin":  {"open"},
+	"windows": {"rundll32", "url.dll,FileProtocolHandler"},
+	"linux":   {"xdg-open"},
+}
+
+// openBrowser opens url in the default browser, without waiting for it
+func openBrowser(ctx context.Context, url string) error {
+	command, ok := browserCommands[runtime.GOOS]
+	if !ok {
+		return errors.New("no browser command for " + runtime.GOOS + ", open " + url)
+	}
+	if _, err := exec.LookPath(command[0]); err != nil {
+		return errors.New(command[0] + " is not installed, open " + url)
+	}
+	return exec.CommandContext(ctx, command[0], append(command[1:], url)...).Start()
+}
+
+// browserURL the URL a browser of the local machine reaches a server listening on addr with, localhost when it
+// listens on every interface
+func browserURL(addr net.Addr) string {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return "http://" + addr.String() + "/"
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port) + "/"
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        error
+	}{
+		{name: "JSON", contentType: "application/json", body: `{"status": "accepted"}`},
+		{name: "JSON with a charset", contentType: "application/json; charset=utf-8", body: `{"status": "accepted"}`},
+		{name: "form", contentType: "application/x-www-form-urlencoded", body: "status=accepted", want: errUnsupportedMediaType},
+		{name: "plain text", contentType: "text/plain", body: `{"status": "accepted"}`, want: errUnsupportedMediaType},
+		{name: "no content type", body: `{"status": "accepted"}`, want: errUnsupportedMediaType},
+		{name: "invalid JSON", contentType: "application/json", body: `{"status": `, want: errBadRequest},
+		{name: "unknown field", contentType: "application/json", body: `{"state": "accepted"}`, want: errBadRequest},
+		{name: "too large", contentType: "application/json", body: `{"status": "` + strings.Repeat("a", maxRequestSize) + `"}`, want: errBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/api/adrs/1", strings.NewReader(test.body))
+			if test.contentType != "" {
+				r.Header.Set("Content-Type", test.contentType)
+			}
+			request := transitionRequest{}
+			err := readJSON(r, &request)
+			if (test.want == nil && err != nil) || !errors.Is(err, test.want) {
+				t.Errorf("readJSON() failed with %v, want %v", err, test.want)
+			}
+			if test.want == nil && request.Status != "accepted" {
+				t.Errorf("readJSON() read the status %q, want accepted", request.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// signature the outcome of the verification of the detached signature of an ADR
+type signature struct {
+	Record adr.Record
+	// Signer the user id of the key that made the signature, e.g. "Jane Doe <jane@example.com>"
+	Signer string
+	// KeyID the long id of the key that made the signature
+	KeyID string
+	// Fingerprint the fingerprint of the primary key of the signer, KeyFingerprint the one of the key, possibly a
+	// subkey, that made the signature
+	Fingerprint    string
+	KeyFingerprint string
+	// Trusted whether the keyring trusts the key of the signer fully or ultimately
+	Trusted bool
+	// Problem why the signature is not trusted, empty when it is
+	Problem string
+}
+
+// gpgProgram the gpg program of the signing configuration, gpg by default
+func gpgProgram(repo *adr.Repository) string {
+	if config := repo.Settings().Signing; config != nil && config.Program != "" {
+		return config.Program
+	}
+	return "gpg"
+}
+
+// runGpg runs gpg with args and returns its status lines, see --status-fd in the gpg documentation.
+// gpg failing with a status is not an error: the status tells what went wrong.
+func runGpg(ctx context.Context, repo *adr.Repository, args ...string) ([]string, error) {
+	program := gpgProgram(repo)
+	slog.Debug("running gpg", "program", program, "args", args)
+	cmd := exec.CommandContext(ctx, program, append([]string{"--batch", "--status-fd", "1"}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	status := []string{}
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		if line, ok := strings.CutPrefix(scanner.Text(), "[GNUPG:] "); ok {
+			status = append(status, line)
+		}
+	}
+	var exit *exec.ExitError
+	if err != nil && (!errors.As(err, &exit) || len(status) == 0) {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return nil, errors.New(program + ": " + message)
+	}
+	return status, nil
+}
+
+// signAdr writes the detached, ASCII-armored, signature of record next to it with key, the key of the signing
+// configuration when empty, recording the operation in the journal. It returns the path of the signature.
+func signAdr(ctx context.Context, repo *adr.Repository, record adr.Record, key string) (string, error) {
+	if config := repo.Settings().Signing; key == "" && config != nil {
+		key = config.Key
+	}
+	args := []string{"--yes", "--armor", "--detach-sign", "--output", record.SignaturePath()}
+	if key != "" {
+		args = append(args, "--local-user", key)
+	}
+	op := startOperation(repo.ConfigDir, "sign", []string{record.Ref()})
+	op.track(record.SignaturePath())
+	defer op.done()
+	status, err := runGpg(ctx, repo, append(args, record.Path)...)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range status {
+		if strings.HasPrefix(line, "SIG_CREATED ") {
+			return record.SignaturePath(), nil
+		}
+	}
+	return "", errors.New("gpg did not sign " + record.Path + ": " + strings.Join(status, ", "))
+}
+
+// verifyAdr checks the detached signature of record against its content and the signers of the signing configuration
+func verifyAdr(ctx context.Context, repo *adr.Repository, record adr.Record) (signature, error) {
+	s := signature{Record: record}
+	status, err := runGpg(ctx, repo, "--verify", record.SignaturePath(), record.Path)
+	if err != nil {
+		return s, err
+	}
+	for _, line := range status {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		keyword, args := fields[0], fields[1:]
+		switch {
+		case keyword == "GOODSIG" && len(args) > 1:
+			s.KeyID, s.Signer = args[0], strings.Join(args[1:], " ")
+		case keyword == "VALIDSIG" && len(args) > 0:
+			s.KeyFingerprint, s.Fingerprint = args[0], args[len(args)-1]
+		case keyword == "TRUST_FULLY" || keyword == "TRUST_ULTIMATE":
+			s.Trusted = true
+		case keyword == "BADSIG":
+			s.Problem = "the ADR changed since it was signed"
+		case keyword == "ERRSIG" && len(args) > 0:
+			s.Problem = "the key " + args[0] + " that signed it is not in the keyring"
+		case keyword == "EXPKEYSIG":
+			s.Problem = "the key that signed it expired"
+		case keyword == "REVKEYSIG":
+			s.Problem = "the key that signed it was revoked"
+		case keyword == "EXPSIG":
+			s.Problem = "the signature expired"
+		}
+	}
+	if s.Problem == "" && s.Signer == "" {
+		s.Problem = "gpg found no signature"
+	}
+	if s.Problem == "" && !trustedSigner(repo, s) {
+		s.Problem = s.Signer + " is not one of the signers of " + repo.ConfigPath()
+		if !s.Trusted {
+			s.Problem += ", or its key is not fully trusted"
+		}
+	}
+	return s, nil
+}
+
+// fingerprintRegexp a full fingerprint, the 40 hex digits of a v4 key
+var fingerprintRegexp = regexp.MustCompile(`^[0-9A-F]{40}$`)
+
+// trustedSigner tells whether the key of s is one of the signers of the signing configuration. A signer named by its
+// full fingerprint is matched against the fingerprints gpg validated; one named by its key id or email, which anyone
+// can forge, also needs the keyring to trust the key fully or ultimately. Any signer is trusted when the configuration
+// names none.
+func trustedSigner(repo *adr.Repository, s signature) bool {
+	config := repo.Settings().Signing
+	if config == nil || len(config.Signers) == 0 {
+		return true
+	}
+	for _, signer := range config.Signers {
+		signer = strings.TrimPrefix(strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(signer), " ", "")), "0X")
+		switch {
+		case signer == "":
+		case fingerprintRegexp.MatchString(signer):
+			if signer == strings.ToUpper(s.Fingerprint) || signer == strings.ToUpper(s.KeyFingerprint) {
+				return true
+			}
+		case !s.Trusted:
+		case strings.EqualFold(signer, s.KeyID):
+			return true
+		case strings.Contains(signer, "@") && strings.Contains(strings.ToUpper(s.Signer), "<"+signer+">"):
+			return true
+		}
+	}
+	return false
+}
+
+// runVerify verifies the signatures of the ADRs referred to by args, of all the signed ADRs when there is none,
+// and fails when one of them is missing, broken or made by a signer who is not trusted
+func runVerify(ctx context.Context, repo *adr.Repository, out *reporter, args []string) error {
+	records := []adr.Record{}
+	if len(args) == 0 {
+		all, err := repo.List(ctx)
+		if err != nil {
+			return err
+		}
+		unsigned := 0
+		for _, record := range all {
+			if _, err := os.Stat(record.SignaturePath()); err == nil {
+				records = append(records, record)
+			} else {
+				unsigned++
+			}
+		}
+		if len(records) == 0 {
+			out.Info("No signed ADRs, sign them with adr sign <number>")
+			return nil
+		}
+		if unsigned > 0 {
+			out.Hint(pluralize(unsigned, "ADR") + " not signed")
+		}
+	}
+	for _, arg := range args {
+		repo.Category = ""
+		record, err := findAdr(ctx, repo, arg)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+	failed := 0
+	for _, record := range records {
+		if _, err := os.Stat(record.SignaturePath()); err != nil {
+			out.Error("ADR " + record.Ref() + " is not signed")
+			failed++
+			continue
+		}
+		s, err := verifyAdr(ctx, repo, record)
+		if err != nil {
+			return err
+		}
+		if s.Problem != "" {
+			out.Error("ADR " + record.Ref() + " " + record.Title + ": " + s.Problem)
+			failed++
+			continue
+		}
+		out.Success("ADR " + record.Ref() + " " + record.Title + " signed by " + s.Signer + " (" + s.Fingerprint + ")")
+	}
+	if failed > 0 {
+		return errors.New(pluralize(failed, "signature") + " failed verification")
+	}
+	return nil
+}
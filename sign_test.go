@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+func TestTrustedSigner(t *testing.T) {
+	const (
+		primary = "0123456789ABCDEF0123456789ABCDEF01234567"
+		subkey  = "FEDCBA9876543210FEDCBA9876543210FEDCBA98"
+	)
+	signed := signature{
+		Signer:         "Jane Doe <jane@example.com>",
+		KeyID:          "FEDCBA9876543210",
+		Fingerprint:    primary,
+		KeyFingerprint: subkey,
+	}
+	trusted := signed
+	trusted.Trusted = true
+	tests := []struct {
+		name    string
+		signers []string
+		s       signature
+		want    bool
+	}{
+		{name: "no signers", s: signed, want: true},
+		{name: "primary fingerprint", signers: []string{primary}, s: signed, want: true},
+		{name: "subkey fingerprint", signers: []string{subkey}, s: signed, want: true},
+		{name: "fingerprint as gpg prints it", signers: []string{"0x0123 4567 89ab cdef 0123  4567 89ab cdef 0123 4567"}, s: signed, want: true},
+		{name: "other fingerprint", signers: []string{"1111111111111111111111111111111111111111"}, s: trusted, want: false},
+		{name: "key id of a trusted key", signers: []string{"fedcba9876543210"}, s: trusted, want: true},
+		{name: "key id of an untrusted key", signers: []string{"FEDCBA9876543210"}, s: signed, want: false},
+		{name: "email of a trusted key", signers: []string{"jane@example.com"}, s: trusted, want: true},
+		{name: "email of an untrusted key", signers: []string{"jane@example.com"}, s: signed, want: false},
+		{name: "part of an email", signers: []string{"e@example.com"}, s: trusted, want: false},
+		{name: "name", signers: []string{"Jane Doe"}, s: trusted, want: false},
+		{name: "empty signer", signers: []string{" "}, s: trusted, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo, err := adr.Init("/home/.adr", "/docs/adr", adr.WithFS(adr.NewMemFS()))
+			if err != nil {
+				t.Fatalf("Init() failed: %v", err)
+			}
+			repo.Config.Signing = &adr.SigningConfig{Signers: test.signers}
+			if got := trustedSigner(repo, test.s); got != test.want {
+				t.Errorf("trustedSigner(%v) = %v, want %v", test.signers, got, test.want)
+			}
+		})
+	}
+}
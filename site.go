@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// registerExporters adds the export formats that render markdown, which the adr package leaves to its users
+func registerExporters() {
+	adr.RegisterExporter("html", siteExporter{})
+	adr.RegisterExporter("pdf", pdfExporter{ctx: context.Background(), program: defaultPDFProgram})
+}
+
+// siteStyle completes the stylesheet of the web UI for the pages of the static site
+const siteStyle = `
+.badge {
+  display: inline-block;
+  padding: 0 0.5rem;
+  border: 1px solid currentColor;
+  border-radius: 1rem;
+}
+
+#adr-meta .badge {
+  margin-right: 0.5rem;
+}
+`
+
+// siteIndexPage the home page of the static site: the table of the ADRs, filtered as the search box is typed in
+var siteIndexPage = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Architecture Decision Records</title>
+  <link rel="stylesheet" href="style.css">
+</head>
+<body>
+  <header>
+    <h1>Architecture Decision Records</h1>
+  </header>
+  <main>
+    <form id="filters" onsubmit="return false">
+      <input type="search" id="search" placeholder="Search titles and content" autofocus>
+    </form>
+    <table>
+      <thead>
+        <tr><th>Number</th><th>Title</th><th>Status</th><th>Date</th><th>Tags</th></tr>
+      </thead>
+      <tbody>
+        {{- range .}}
+        <tr data-search="{{.Search}}">
+          <td>{{.Ref}}</td>
+          <td><a href="{{.Page}}">{{.Title}}</a></td>
+          <td><span class="status badge status-{{.Status}}">{{.Status}}</span></td>
+          <td>{{.Date}}</td>
+          <td>{{range .Tags}}<span class="tag">{{.}}</span>{{end}}</td>
+        </tr>
+        {{- end}}
+      </tbody>
+    </table>
+    <p id="no-match" class="legend" hidden>No ADR matches the search.</p>
+  </main>
+  <script>
+    const search = document.getElementById("search");
+    search.addEventListener("input", () => {
+      const words = search.value.toLowerCase().split(/\s+/).filter(word => word);
+      let shown = 0;
+      for (const row of document.querySelectorAll("tbody tr")) {
+        row.hidden = !words.every(word => row.dataset.search.includes(word));
+        shown += row.hidden ? 0 : 1;
+      }
+      document.getElementById("no-match").hidden = shown > 0;
+    });
+  </script>
+</body>
+</html>
+`))
+
+// sitePage the page of an ADR in the static site
+var sitePage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Ref}}. {{.Title}}</title>
+  <link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+  <header>
+    <h1><a href="{{.Root}}index.html">Architecture Decision Records</a></h1>
+  </header>
+  <main>
+    <p id="adr-meta"><span class="status badge status-{{.Status}}">{{.Status}}</span>{{.Date}}{{range .Tags}} <span class="tag">{{.}}</span>{{end}}</p>
+    <article id="adr-content">{{.Content}}</article>
+  </main>
+</body>
+</html>
+`))
+
+// siteRow an ADR of the static site, as its templates show it
+type siteRow struct {
+	Ref     string
+	Title   string
+	Status  adr.Status
+	Date    string
+	Tags    []string
+	Page    string
+	Root    string
+	Search  string
+	Content template.HTML
+}
+
+// siteLinkRegexp the target of a markdown link, with the end of its text
+var siteLinkRegexp = regexp.MustCompile(`\]\(([^)\s]+)\)`)
+
+// siteExporter writes the ADRs as a static HTML site, see ExportDir
+type siteExporter struct{}
+
+// Export fails, the html format writes a directory
+func (siteExporter) Export(w io.Writer, records []adr.Record) error {
+	return errors.New("the html format writes a directory, give it as the output")
+}
+
+// ExportDir writes into dir a static site to publish as it is: index.html, the table of the ADRs with a search box,
+// a page per ADR, in a folder per category, and their stylesheet. The links between ADRs become links between their
+// pages. Drafts are left out.
+func (siteExporter) ExportDir(fsys adr.FileSystem, dir string, records []adr.Record) error {
+	pages := map[string]string{}
+	scopes := map[string]bool{}
+	exported := []adr.Record{}
+	for _, record := range records {
+		if record.Draft == "" {
+			exported = append(exported, record)
+			scopes[record.Scope] = true
+		}
+	}
+	for _, record := range exported {
+		pages[filepath.Clean(record.Path)] = sitePagePath(record, len(scopes) > 1, ".html")
+	}
+	rows := []siteRow{}
+	for _, record := range exported {
+		content, err := fsys.ReadFile(record.Path)
+		if err != nil {
+			return err
+		}
+		page := pages[filepath.Clean(record.Path)]
+		root := strings.Repeat("../", strings.Count(page, "/"))
+		row := siteRow{
+			Ref: record.Ref(), Title: record.Title, Status: record.Status, Date: record.Date, Tags: record.Tags, Page: page, Root: root,
+			Search: strings.ToLower(strings.Join(append([]string{record.Ref(), record.Title, string(content)}, record.Tags...), " ")),
+			Content: template.HTML(renderHTML(siteLinks(record, content, func(linked string) string {
+				if pages[linked] == "" {
+					return ""
+				}
+				return relativePage(page, pages[linked])
+			}))),
+		}
+		rows = append(rows, row)
+		var html bytes.Buffer
+		if err := sitePage.Execute(&html, row); err != nil {
+			return err
+		}
+		path := filepath.Join(dir, filepath.FromSlash(page))
+		if err := fsys.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := fsys.WriteFile(path, html.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	var index bytes.Buffer
+	if err := siteIndexPage.Execute(&index, rows); err != nil {
+		return err
+	}
+	if err := fsys.WriteFile(filepath.Join(dir, "index.html"), index.Bytes(), 0644); err != nil {
+		return err
+	}
+	style, err := fs.ReadFile(webAssets, "web/style.css")
+	if err != nil {
+		return err
+	}
+	return fsys.WriteFile(filepath.Join(dir, "style.css"), append(style, siteStyle...), 0644)
+}
+
+// sitePagePath the path of the page of record in the static site, in slash form: the name of its file with extension,
+// in the folder of its category, itself in the folder of its scope when the site has several
+func sitePagePath(record adr.Record, scoped bool, extension string) string {
+	parts := []string{}
+	if scoped && record.Scope != "" {
+		parts = append(parts, adr.SanitizeFileName(record.Scope))
+	}
+	if record.Category != "" {
+		parts = append(parts, record.Category)
+	}
+	return path.Join(append(parts, strings.TrimSuffix(filepath.Base(record.Path), filepath.Ext(record.Path))+extension)...)
+}
+
+// siteLinks points the links of the ADR content of record to the pages href gives for the paths of the files they link
+// to, the links href gives no page for being left as they are
+func siteLinks(record adr.Record, content []byte, href func(linked string) string) []byte {
+	return siteLinkRegexp.ReplaceAllFunc(content, func(match []byte) []byte {
+		target := string(siteLinkRegexp.FindSubmatch(match)[1])
+		linked, ok := record.LinkedPath(adr.Link{Target: target})
+		if !ok || href(linked) == "" {
+			return match
+		}
+		anchor := ""
+		if at := strings.Index(target, "#"); at >= 0 {
+			anchor = target[at:]
+		}
+		return []byte("](" + href(linked) + anchor + ")")
+	})
+}
+
+// relativePage the link from the page at from to the page at to, both in slash form relative to the root of the site
+func relativePage(from string, to string) string {
+	return strings.Repeat("../", strings.Count(from, "/")) + to
+}
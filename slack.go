@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// pendingNotifications the notifications being sent, adr waits for them before exiting
+var pendingNotifications sync.WaitGroup
+
+// slackEscaper escapes the text of Slack messages, where <, > and & are markup
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackMessage the body of a Slack incoming webhook request
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// slackNotifier posts a message to the Slack webhooks of the configuration when an ADR is created or changes status
+type slackNotifier struct {
+	ctx    context.Context
+	client *http.Client
+	hooks  []adr.SlackHook
+}
+
+// notifySlack subscribes to the events of repo when Slack webhooks are configured, messages are sent in the background
+func notifySlack(ctx context.Context, repo *adr.Repository) {
+	hooks := repo.Settings().Slack
+	if len(hooks) == 0 {
+		return
+	}
+	notifier := &slackNotifier{ctx: ctx, client: &http.Client{Timeout: 10 * time.Second}, hooks: hooks}
+	repo.Subscribe(notifier.handle)
+}
+
+// slackText the message of an event, linking the ADR to link(record), false for the events not told to Slack
+func slackText(event adr.Event, link func(adr.Record) string) (string, bool) {
+	switch e := event.(type) {
+	case adr.RecordCreated:
+		if e.Record.Draft != "" {
+			return "", false
+		}
+		return ":memo: New ADR " + slackTitle(e.Record, link(e.Record)) + slackAuthor(e.Record) + ", " + slackEscaper.Replace(string(e.Record.Status)), true
+	case adr.StatusChanged:
+		return ":arrows_counterclockwise: ADR " + slackTitle(e.Record, link(e.Record)) + slackAuthor(e.Record) + ": " +
+			slackEscaper.Replace(string(e.From)) + " → " + slackEscaper.Replace(string(e.To)), true
+	}
+	return "", false
+}
+
+func slackTitle(record adr.Record, link string) string {
+	title := slackEscaper.Replace(strconv.Itoa(record.Number) + ". " + record.Title)
+	if link == "" {
+		return "*" + title + "*"
+	}
+	return "*<" + link + "|" + title + ">*"
+}
+
+func slackAuthor(record adr.Record) string {
+	if record.Author == "" {
+		return ""
+	}
+	return " by " + slackEscaper.Replace(record.Author)
+}
+
+// slackLink the link of hook to record
+func (s *slackNotifier) slackLink(hook adr.SlackHook, record adr.Record) string {
+	if hook.Link == "" {
+		return ""
+	}
+	return adrLink(s.ctx, hook.Link, record)
+}
+
+// adrLink the URL of record, link being a URL where {number}, {id} and {path} are replaced
+func adrLink(ctx context.Context, link string, record adr.Record) string {
+	path := repositoryPath(ctx, record.Path)
+	return strings.NewReplacer("{number}", strconv.Itoa(record.Number), "{id}", record.ID(), "{path}", path).Replace(link)
+}
+
+// repositoryPath the slash separated path of a file relative to the git repository holding it, its name outside of git
+func repositoryPath(ctx context.Context, path string) string {
+	if root, err := gitRepositoryRoot(ctx, filepath.Dir(path)); err == nil {
+		if relative, err := filepath.Rel(root, path); err == nil {
+			return filepath.ToSlash(relative)
+		}
+	}
+	return filepath.Base(path)
+}
+
+func (s *slackNotifier) handle(event adr.Event) {
+	for _, hook := range s.hooks {
+		if !hook.Wants(event.EventName()) {
+			continue
+		}
+		text, ok := slackText(event, func(record adr.Record) string { return s.slackLink(hook, record) })
+		if !ok {
+			continue
+		}
+		pendingNotifications.Add(1)
+		go func(hook adr.SlackHook) {
+			defer pendingNotifications.Done()
+			if err := s.post(hook.URL(os.Getenv), text); err != nil {
+				slog.Warn("Slack notification not sent", "event", event.EventName(), "error", err)
+			}
+		}(hook)
+	}
+}
+
+func (s *slackNotifier) post(url string, text string) error {
+	if url == "" {
+		return errors.New("the Slack webhook has no URL, check its environment variable")
+	}
+	body, err := json.Marshal(slackMessage{Text: text})
+	if err != nil {
+		return err
+	}
+	// the message is sent even when the command is interrupted right after the change
+	request, err := http.NewRequestWithContext(context.WithoutCancel(s.ctx), http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("Slack answered %s", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// statsBarWidth the width of the longest bar of the ADRs created per month
+const statsBarWidth = 30
+
+// statsAuthors the number of authors adr stats prints, its JSON lists them all
+const statsAuthors = 5
+
+// withAcceptanceDates records, the accepted ADRs without an AcceptedOn date taking the date of the last commit that
+// accepted them, when git knows it
+func withAcceptanceDates(ctx context.Context, records []adr.Record) []adr.Record {
+	dated := append([]adr.Record{}, records...)
+	for i, record := range dated {
+		if record.Status != adr.Accepted || record.AcceptedOn != "" || record.Draft != "" {
+			continue
+		}
+		revisions, err := adrHistory(ctx, record)
+		if err != nil {
+			continue
+		}
+		for _, revision := range revisions {
+			if revision.Status == adr.Accepted && revision.StatusChanged() {
+				dated[i].AcceptedOn = revision.Date
+				break
+			}
+		}
+	}
+	return dated
+}
+
+// gitAuthors the authors of the commits that touched the files of records, by number of commits
+func gitAuthors(ctx context.Context, dir string, records []adr.Record) ([]adr.AuthorCount, error) {
+	args := []string{"log", "--format=%an", "--"}
+	for _, record := range records {
+		args = append(args, record.Path)
+	}
+	log, err := runGit(ctx, dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, name := range strings.Split(log, "\n") {
+		if name = strings.TrimSpace(name); name != "" {
+			counts[name]++
+		}
+	}
+	return adr.RankAuthors(counts), nil
+}
+
+// computeStats the stats of records, their acceptance dates and authors read from git when it knows them
+func computeStats(ctx context.Context, repo *adr.Repository, records []adr.Record) adr.Stats {
+	stats := adr.ComputeStats(withAcceptanceDates(ctx, records))
+	if len(records) == 0 {
+		return stats
+	}
+	if authors, err := gitAuthors(ctx, statsDir(repo), records); err == nil && len(authors) > 0 {
+		stats.Authors, stats.AuthorsFrom = authors, adr.AuthorsFromGit
+	}
+	return stats
+}
+
+// printStats prints stats for the terminal
+func printStats(out *reporter, stats adr.Stats) {
+	counts := []string{}
+	for _, status := range adr.Statuses {
+		if stats.ByStatus[status] > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", stats.ByStatus[status], strings.ToLower(string(status))))
+		}
+	}
+	if stats.Drafts > 0 {
+		counts = append(counts, pluralize(stats.Drafts, "draft"))
+	}
+	line := pluralize(stats.Total, "ADR")
+	if len(counts) > 0 {
+		line += ": " + strings.Join(counts, ", ")
+	}
+	out.Info(line)
+	if stats.Total == 0 {
+		return
+	}
+
+	most := 0
+	for _, month := range stats.CreatedByMonth {
+		most = max(most, month.Count)
+	}
+	if most > 0 {
+		out.Info("")
+		out.Info("Created per month")
+		for _, month := range stats.CreatedByMonth {
+			bar := strings.Repeat("█", (month.Count*statsBarWidth+most-1)/most)
+			out.Info(fmt.Sprintf("  %s %s %d", month.Month, out.Highlight(color.FgCyan, "%s", bar), month.Count))
+		}
+	}
+
+	out.Info("")
+	if stats.Measured > 0 {
+		out.Info(fmt.Sprintf("Average time from proposed to accepted: %s days, over %s",
+			out.Highlight(color.FgYellow, "%.1f", stats.DaysToAccept), pluralize(stats.Measured, "accepted ADR")))
+	} else {
+		out.Info("Average time from proposed to accepted: unknown, no accepted ADR has a date of acceptance")
+	}
+
+	if len(stats.Authors) == 0 {
+		return
+	}
+	out.Info("")
+	if stats.AuthorsFrom == adr.AuthorsFromGit {
+		out.Info("Most active authors, by commits")
+	} else {
+		out.Info("Most active authors, by ADRs")
+	}
+	for i, author := range stats.Authors {
+		if i == statsAuthors {
+			break
+		}
+		out.Info(fmt.Sprintf("  %s %s", author.Name, out.Highlight(color.FgYellow, "%d", author.Count)))
+	}
+}
+
+// statsDir the folder git reads the history of the ADRs in
+func statsDir(repo *adr.Repository) string {
+	if repo.Scope == adr.AllScopes {
+		return repo.BaseDir()
+	}
+	return filepath.Clean(repo.Dir)
+}
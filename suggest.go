@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/urfave/cli"
+)
+
+// maxSuggestions the number of "did you mean" suggestions shown at most
+const maxSuggestions = 3
+
+// editDistance the number of single letter insertions, deletions, substitutions and swaps of neighbours turning a into b
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// closeTo tells whether a mistyped word is close enough to a candidate to suggest it, returning their distance:
+// a couple of typos in long words, one in short ones, or the start of the candidate
+func closeTo(typed string, candidate string) (int, bool) {
+	typed, candidate = strings.ToLower(typed), strings.ToLower(candidate)
+	distance := editDistance(typed, candidate)
+	allowed := 1
+	if len([]rune(typed)) >= 5 {
+		allowed = 2
+	}
+	if distance <= allowed {
+		return distance, true
+	}
+	if len(typed) >= 2 && strings.HasPrefix(candidate, typed) {
+		return distance, true
+	}
+	return distance, false
+}
+
+// suggestion a candidate and its distance to what was typed
+type suggestion struct {
+	text     string
+	distance int
+}
+
+// closest the texts of the best suggestions, closest first
+func closest(suggestions []suggestion) []string {
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].distance < suggestions[j].distance })
+	texts := []string{}
+	for _, s := range suggestions {
+		if len(texts) == maxSuggestions {
+			break
+		}
+		texts = append(texts, s.text)
+	}
+	return texts
+}
+
+// suggestCommands the commands close to a mistyped command name: the commands of the app, and at the top level
+// the aliases of the configuration and the plugins of the PATH
+func suggestCommands(c *cli.Context, paths adrPaths, name string) []string {
+	candidates := []string{}
+	for _, command := range c.App.Commands {
+		if !command.Hidden {
+			candidates = append(candidates, command.Names()...)
+		}
+	}
+	if c.Parent() == nil {
+		if repo, err := adr.Open(paths.ConfigDir); err == nil {
+			for alias := range repo.Settings().Aliases {
+				candidates = append(candidates, alias)
+			}
+		}
+		candidates = append(candidates, pluginNames(findPlugins())...)
+	}
+	suggestions := []suggestion{}
+	seen := map[string]bool{}
+	for _, candidate := range candidates {
+		if distance, ok := closeTo(name, candidate); ok && !seen[candidate] {
+			seen[candidate] = true
+			suggestions = append(suggestions, suggestion{"'" + c.App.Name + " " + candidate + "'", distance})
+		}
+	}
+	return closest(suggestions)
+}
+
+// suggestAdrs the ADRs close to a reference that matches none: numbers a typo away from a mistyped number,
+// titles close to words given instead of a number
+func suggestAdrs(records []adr.Record, ref string) []string {
+	ref = strings.TrimSpace(ref)
+	suggestions := []suggestion{}
+	if digits := strings.TrimLeft(ref, "0"); digits != "" && strings.Trim(digits, "0123456789") == "" {
+		for _, record := range records {
+			if distance := editDistance(digits, strconv.Itoa(record.Number)); distance <= 1 {
+				suggestions = append(suggestions, suggestion{adrReference(record), distance})
+			}
+		}
+		return closest(suggestions)
+	}
+	for _, record := range records {
+		title := strings.ToLower(record.Title)
+		if strings.Contains(title, strings.ToLower(ref)) {
+			suggestions = append(suggestions, suggestion{adrReference(record), 0})
+			continue
+		}
+		best, found := 0, false
+		for _, word := range strings.Fields(ref) {
+			if len(word) < 3 {
+				continue
+			}
+			for _, titleWord := range strings.Fields(title) {
+				if distance, ok := closeTo(word, titleWord); ok && (!found || distance < best) {
+					best, found = distance, true
+				}
+			}
+		}
+		if found {
+			suggestions = append(suggestions, suggestion{adrReference(record), best + 1})
+		}
+	}
+	return closest(suggestions)
+}
+
+// didYouMean adds the suggestions to an error, one per line
+func didYouMean(err error, suggestions []string) error {
+	if len(suggestions) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w\nDid you mean:\n  %s", err, strings.Join(suggestions, "\n  "))
+}
+
+// splitAdrRef splits a reference to an ADR, such as platform/3, into its category and its number
+func splitAdrRef(arg string) (string, string) {
+	if at := strings.LastIndex(arg, "/"); at > 0 {
+		return arg[:at], arg[at+1:]
+	}
+	return "", arg
+}
+
+// findAdr finds the ADR numbered after arg, suggesting the closest ADRs when arg matches none.
+// An ADR of a category is found as category/number, e.g. platform/3, which selects its category
+// when the categories are numbered on their own.
+func findAdr(ctx context.Context, repo *adr.Repository, arg string) (adr.Record, error) {
+	category, ref := splitAdrRef(arg)
+	if category != "" {
+		if err := adr.ValidateCategory(category); err != nil {
+			return adr.Record{}, err
+		}
+		if repo.Settings().PerCategory() {
+			repo.Category = category
+		}
+	}
+	number, err := parseAdrNumber(ref)
+	if err == nil {
+		var record adr.Record
+		record, err = repo.Find(ctx, number)
+		if err == nil && category != "" && record.Category != category {
+			err = fmt.Errorf("%w: ADR %d is not in the %s category", adr.ErrAdrNotFound, number, category)
+		}
+		if err == nil || !errors.Is(err, adr.ErrAdrNotFound) {
+			return record, err
+		}
+	}
+	records, listErr := repo.List(ctx)
+	if listErr != nil {
+		return adr.Record{}, err
+	}
+	return adr.Record{}, didYouMean(err, suggestAdrs(records, arg))
+}
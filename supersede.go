@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+	"github.com/urfave/cli"
+)
+
+// supersedeAdr marks old as superseded by the ADR numbered by, regenerating the table of contents and recording the
+// operation in the journal
+func supersedeAdr(ctx context.Context, repo *adr.Repository, out *reporter, old adr.Record, by adr.Record, options adr.SupersedeOptions) (adr.Record, adr.Record, error) {
+	op := startOperation(repo.ConfigDir, "supersede", []string{strconv.Itoa(old.Number), strconv.Itoa(by.Number)})
+	op.track(old.Path)
+	op.track(by.Path)
+	oldRecord, newRecord, err := repo.Supersede(ctx, old, by, options)
+	if err == nil {
+		refreshToc(ctx, repo, out, oldRecord, op)
+	}
+	op.done()
+	return oldRecord, newRecord, err
+}
+
+// supersedeOptions the options of adr supersede given as flags
+func supersedeOptions(c *cli.Context) adr.SupersedeOptions {
+	return adr.SupersedeOptions{
+		SupersededBy: c.String("wording"),
+		Supersedes:   c.String("reverse-wording"),
+		Sections:     c.StringSlice("copy-section"),
+	}
+}
+
+// nextAdrNumber the number the next ADR will likely get, to preview it before it is claimed
+func nextAdrNumber(repo *adr.Repository, records []adr.Record) int {
+	perCategory := repo.Settings().PerCategory()
+	next := 1
+	if repo.Scope == "" && (repo.Category == "" || !perCategory) {
+		next = repo.Settings().CurrentAdr + 1
+	}
+	for _, record := range records {
+		if perCategory && record.Category != repo.Category {
+			continue
+		}
+		if record.Number >= next {
+			next = record.Number + 1
+		}
+	}
+	return next
+}
+
+// yesOrNo asks a yes or no question, answering defaultYes when the answer is empty
+func yesOrNo(p *prompter, question string, defaultYes bool) (bool, error) {
+	choices := "y/N"
+	if defaultYes {
+		choices = "Y/n"
+	}
+	answer, err := p.Ask(question+" "+choices, "")
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	}
+	return defaultYes, nil
+}
+
+// guidedSupersede walks through superseding an ADR on the terminal: it picks the ADR to replace, the replacing ADR,
+// existing or new, the wording of the relationship and the sections to copy forward, then previews both files before writing them
+func guidedSupersede(ctx context.Context, c *cli.Context, repo *adr.Repository, out *reporter) error {
+	p := newPrompter()
+	old, err := targetAdr(ctx, c, repo)
+	if err != nil {
+		return err
+	}
+	oldContent, err := repo.FS.ReadFile(old.Path)
+	if err != nil {
+		return err
+	}
+	// a new replacing ADR is written in the category of the ADR it replaces
+	repo.Category = old.Category
+
+	// the replacing ADR: an existing ADR, or a new ADR only written once the preview is confirmed
+	var by adr.Record
+	var byContent []byte
+	create := adr.CreateOptions{Author: adrAuthor(ctx, c, repo), Template: c.String("template")}
+	answer := c.Args().Get(1)
+	for answer == "" {
+		if answer, err = p.Ask("Number of the replacing ADR, or the title of a new ADR", ""); err != nil {
+			return err
+		}
+	}
+	_, ref := splitAdrRef(answer)
+	if _, err := parseAdrNumber(ref); err == nil {
+		if by, err = findAdr(ctx, repo, answer); err != nil {
+			return err
+		}
+		if byContent, err = repo.FS.ReadFile(by.Path); err != nil {
+			return err
+		}
+	} else {
+		records, err := repo.List(ctx)
+		if err != nil {
+			return err
+		}
+		create.Title = answer
+		by = adr.Record{Number: nextAdrNumber(repo, records), Title: answer}
+		by.Path = filepath.Join(repo.Dir, repo.Category, repo.FileName(by.Number, by.Title))
+		if byContent, err = repo.Render(create, by.Number); err != nil {
+			return err
+		}
+	}
+
+	options := supersedeOptions(c)
+	if !c.IsSet("wording") {
+		if options.SupersededBy, err = p.Ask("Wording in ADR "+old.ID(), adr.DefaultSupersededBy); err != nil {
+			return err
+		}
+	}
+	if !c.IsSet("reverse-wording") {
+		if options.Supersedes, err = p.Ask("Wording in ADR "+by.ID(), adr.DefaultSupersedes); err != nil {
+			return err
+		}
+	}
+	if !c.IsSet("copy-section") {
+		for _, section := range adr.Sections(oldContent) {
+			copied, err := yesOrNo(p, "Copy the "+section+" section forward?", false)
+			if err != nil {
+				return err
+			}
+			if copied {
+				options.Sections = append(options.Sections, section)
+			}
+		}
+	}
+
+	oldUpdated, byUpdated, err := adr.SupersedeContent(old, oldContent, by, byContent, options)
+	if err != nil {
+		return err
+	}
+	printDiff(out, old.Path, string(oldContent), string(oldUpdated))
+	if create.Title != "" {
+		printDiff(out, by.Path+" (new)", "", string(byUpdated))
+	} else {
+		printDiff(out, by.Path, string(byContent), string(byUpdated))
+	}
+	if !assumeYes && !c.Bool("yes") {
+		write, err := yesOrNo(p, "Write these changes?", true)
+		if err != nil {
+			return err
+		}
+		if !write {
+			return errors.New("aborted, nothing was changed")
+		}
+	}
+
+	if err := runHook(ctx, repo.ConfigDir, preStatusChangeHook, statusChangePayload(repo, old, adr.Superseded)); err != nil {
+		return err
+	}
+	if create.Title != "" {
+		if by, err = createAdr(ctx, repo, out, "supersede", []string{create.Title}, create); err != nil {
+			return err
+		}
+	}
+	return finishSupersede(ctx, c, repo, out, old, by, create.Title != "", options)
+}
+
+// replacingAdr the ADR replacing old named by the arguments following its number: an existing ADR given by number,
+// or a new ADR given by title, written in the category of old, telling whether it was created
+func replacingAdr(ctx context.Context, c *cli.Context, repo *adr.Repository, out *reporter, old adr.Record) (adr.Record, bool, error) {
+	_, ref := splitAdrRef(c.Args().Get(1))
+	if _, err := parseAdrNumber(ref); err == nil {
+		by, err := findAdr(ctx, repo, c.Args().Get(1))
+		return by, false, err
+	}
+	repo.Category = old.Category
+	create := adr.CreateOptions{Author: adrAuthor(ctx, c, repo), Template: c.String("template")}
+	by, err := createAdr(ctx, repo, out, "supersede", c.Args().Tail(), create)
+	return by, err == nil, err
+}
+
+// finishSupersede writes the supersede relationship, running the post status-change hook of the superseded ADR,
+// then commits it when asked to. The pre status-change hook ran before the replacing ADR was created, and a replacing
+// ADR created for the purpose is removed again when the relationship cannot be written.
+func finishSupersede(ctx context.Context, c *cli.Context, repo *adr.Repository, out *reporter, old adr.Record, by adr.Record, created bool, options adr.SupersedeOptions) error {
+	previous := old.Status
+	old, superseding, err := supersedeAdr(ctx, repo, out, old, by, options)
+	if err != nil {
+		if created {
+			discardAdr(ctx, repo, out, by)
+		}
+		return err
+	}
+	by = superseding
+	out.Success(fmt.Sprintf("ADR %s is superseded by ADR %s", old.Ref(), by.Ref()))
+	payload := hookPayload(repo, old)
+	payload.PreviousStatus = previous
+	if err := runHook(ctx, repo.ConfigDir, postStatusChangeHook, payload); err != nil {
+		return err
+	}
+	if !shouldCommit(c, repo) {
+		return nil
+	}
+	// the replaced ADR, the replacing one, new or not, and the refreshed table of contents land in a
+	// single commit, so the history never shows one ADR without the other
+	return commitAdr(ctx, repo, "supersede", old, withToc(repo, old.Path, by.Path)...)
+}
+
+// discardAdr removes record, a replacing ADR created for a supersede that failed, giving its number back when no
+// other ADR took the next one
+func discardAdr(ctx context.Context, repo *adr.Repository, out *reporter, record adr.Record) {
+	op := startOperation(repo.ConfigDir, "supersede", []string{record.Ref()})
+	defer op.done()
+	if _, err := deleteAdr(repo, record, op); err != nil {
+		out.Warning("ADR " + record.Ref() + " was not removed: " + err.Error())
+		return
+	}
+	if repo.Scope == "" && (record.Category == "" || !repo.Settings().PerCategory()) && repo.Settings().CurrentAdr == record.Number {
+		op.track(repo.ConfigPath())
+		if err := repo.SetCounter(record.Number - 1); err != nil {
+			out.Warning("The ADR counter was not restored: " + err.Error())
+		}
+	}
+	refreshToc(ctx, repo, out, record, op)
+	out.Info("ADR " + record.Ref() + " was removed as it supersedes nothing")
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// syncManifestFileName the file, in the target directory, remembering where synced ADRs come from
+var syncManifestFileName = ".adr-sync.json"
+
+// syncManifest origin metadata of the ADRs copied into a directory by adr sync
+type syncManifest struct {
+	Entries []syncEntry `json:"entries"`
+}
+
+// syncEntry links a copied ADR to its origin, the hashes are those of both files right after the last sync
+type syncEntry struct {
+	Origin       string `json:"origin"`
+	OriginNumber int    `json:"origin_number"`
+	OriginHash   string `json:"origin_hash"`
+	File         string `json:"file"`
+	Hash         string `json:"hash"`
+}
+
+// Outcomes of syncing one ADR
+const (
+	syncCopied   = "copied"
+	syncUpdated  = "updated"
+	syncUpToDate = "up to date"
+	syncDiverged = "diverged"
+)
+
+// syncResult what happened to one ADR during a sync
+type syncResult struct {
+	Record  adr.Record
+	Target  string
+	Outcome string
+}
+
+// syncOrigin identifies the repository ADRs are synced from: its origin remote, or its directory
+func syncOrigin(ctx context.Context, baseDir string) string {
+	if url, err := runGit(ctx, baseDir, "remote", "get-url", "origin"); err == nil && url != "" {
+		return url
+	}
+	return absPath(baseDir)
+}
+
+// syncAdrs copies ADRs into the ADR directory of another repository, numbering them after the ADRs already there.
+// ADRs synced before are updated when they changed at the origin, unless they were also edited in the target
+// directory, they are then reported as diverged and left alone unless force is set.
+func syncAdrs(ctx context.Context, repo *adr.Repository, records []adr.Record, targetDir string, force bool, op *operation) ([]syncResult, error) {
+	manifestPath := filepath.Join(targetDir, syncManifestFileName)
+	manifest := syncManifest{Entries: []syncEntry{}}
+	if bytes, err := ioutil.ReadFile(manifestPath); err == nil {
+		if err := json.Unmarshal(bytes, &manifest); err != nil {
+			return nil, err
+		}
+	}
+	targetAdrs, err := adr.ReadDir(ctx, adr.OS, targetDir)
+	if err != nil {
+		return nil, err
+	}
+	nextNumber := 1
+	for _, record := range targetAdrs {
+		if record.Number >= nextNumber {
+			nextNumber = record.Number + 1
+		}
+	}
+
+	origin := syncOrigin(ctx, repo.Dir)
+	results := []syncResult{}
+	for _, record := range records {
+		// once cancelled, the ADRs already synced are still recorded in the manifest
+		if ctx.Err() != nil {
+			break
+		}
+		content, err := ioutil.ReadFile(record.Path)
+		if err != nil {
+			return results, err
+		}
+		originHash := hashFile(record.Path)
+
+		var entry *syncEntry
+		for i := range manifest.Entries {
+			if manifest.Entries[i].Origin == origin && manifest.Entries[i].OriginNumber == record.Number {
+				entry = &manifest.Entries[i]
+			}
+		}
+
+		result := syncResult{Record: record}
+		var number int
+		if entry == nil {
+			number = nextNumber
+			nextNumber++
+			manifest.Entries = append(manifest.Entries, syncEntry{
+				Origin:       origin,
+				OriginNumber: record.Number,
+				File:         adr.FileName(adr.OS, targetDir, number, record.Title),
+			})
+			entry = &manifest.Entries[len(manifest.Entries)-1]
+			result.Outcome = syncCopied
+		} else {
+			targetPath := filepath.Join(targetDir, entry.File)
+			targetHash := hashFile(targetPath)
+			switch {
+			case targetHash != entry.Hash && !force:
+				result.Outcome = syncDiverged
+			case originHash == entry.OriginHash && targetHash == entry.Hash:
+				result.Outcome = syncUpToDate
+			default:
+				result.Outcome = syncUpdated
+			}
+			if targetAdr, err := adr.ParseFile(adr.OS, targetPath); err == nil && targetAdr.Number > 0 {
+				number = targetAdr.Number
+			} else {
+				number = nextNumber
+				nextNumber++
+			}
+		}
+
+		result.Target = filepath.Join(targetDir, entry.File)
+		if result.Outcome == syncCopied || result.Outcome == syncUpdated {
+			op.track(result.Target)
+			if err := ioutil.WriteFile(result.Target, adr.SetHeadingNumber(content, strconv.Itoa(number)), 0644); err != nil {
+				return results, err
+			}
+			entry.OriginHash = originHash
+			entry.Hash = hashFile(result.Target)
+		}
+		results = append(results, result)
+	}
+
+	bytes, err := json.MarshalIndent(manifest, "", " ")
+	if err != nil {
+		return results, err
+	}
+	op.track(manifestPath)
+	if err := ioutil.WriteFile(manifestPath, bytes, 0644); err != nil {
+		return results, err
+	}
+	return results, ctx.Err()
+}
+
+// selectAdrs keeps the ADRs whose number is in args, or all of them when args is empty
+func selectAdrs(records []adr.Record, args []string) ([]adr.Record, error) {
+	if len(args) == 0 {
+		return records, nil
+	}
+	selected := []adr.Record{}
+	for _, arg := range args {
+		number, err := parseAdrNumber(arg)
+		if err != nil {
+			return nil, didYouMean(err, suggestAdrs(records, arg))
+		}
+		found := false
+		for _, record := range records {
+			if record.Number == number {
+				selected = append(selected, record)
+				found = true
+			}
+		}
+		if !found {
+			return nil, didYouMean(errors.New("no ADR number "+arg), suggestAdrs(records, arg))
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// runTags lists the tags of the taxonomy as a tree with the number of ADRs using them, then the tags used
+// outside of the taxonomy
+func runTags(ctx context.Context, repo *adr.Repository, out *reporter) error {
+	taxonomy := repo.Settings().Tags
+	if err := taxonomy.Validate(); err != nil {
+		return fmt.Errorf("invalid tags in %s: %w", repo.ConfigPath(), err)
+	}
+	records, err := readScopedAdrs(ctx, repo)
+	if err != nil {
+		return err
+	}
+	// counted by their name in the taxonomy, whatever their case in the ADRs
+	counts := map[string]int{}
+	for _, record := range records {
+		for _, tag := range record.Tags {
+			if definition, ok := taxonomy.Lookup(tag); ok {
+				tag = definition.Name
+			}
+			counts[tag]++
+		}
+	}
+
+	var list func(parent string, depth int)
+	list = func(parent string, depth int) {
+		for _, definition := range taxonomy.Children(parent) {
+			line := strings.Repeat("  ", depth) + out.Highlight(color.FgCyan, "%s", definition.Name) + " (" + pluralize(counts[definition.Name], "ADR") + ")"
+			if definition.Description != "" {
+				line += "  " + definition.Description
+			}
+			out.Info(line)
+			list(definition.Name, depth+1)
+		}
+	}
+	list("", 0)
+
+	unknown := []string{}
+	for tag := range counts {
+		if _, ok := taxonomy.Lookup(tag); !ok {
+			unknown = append(unknown, tag)
+		}
+	}
+	sort.Strings(unknown)
+	switch {
+	case len(taxonomy) == 0 && len(unknown) == 0:
+		out.Info("No tags yet")
+	case len(taxonomy) == 0:
+		for _, tag := range unknown {
+			out.Info(out.Highlight(color.FgCyan, "%s", tag) + " (" + pluralize(counts[tag], "ADR") + ")")
+		}
+		out.Hint("list the tags in the \"tags\" of " + repo.ConfigPath() + " to check them")
+	default:
+		for _, tag := range unknown {
+			out.Warning(tag + " (" + pluralize(counts[tag], "ADR") + ") is not in the taxonomy")
+		}
+	}
+	return nil
+}
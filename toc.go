@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// refreshToc regenerates the table of contents after adr wrote record, see updateToc. A table of contents that cannot
+// be written is only reported: the ADR is written already.
+func refreshToc(ctx context.Context, repo *adr.Repository, out *reporter, record adr.Record, op *operation) {
+	if err := updateToc(ctx, repo, record, op); err != nil {
+		out.Warning("The table of contents was not updated: " + err.Error())
+	}
+}
+
+// updateToc regenerates the table of contents after a command changed record, when adr toc wrote one or the toc
+// configuration asks for it on new ADRs, so that its numbers, titles and statuses stay those of the ADRs
+func updateToc(ctx context.Context, repo *adr.Repository, record adr.Record, op *operation) error {
+	path := refreshedToc(repo)
+	if path == "" || record.Draft != "" {
+		return nil
+	}
+	op.track(path)
+	_, err := repo.WriteToc(ctx, "", false)
+	return err
+}
+
+// refreshedToc the path of the table of contents the commands changing ADRs regenerate, empty when there is none, it
+// was written by hand, or the ADRs of all the scopes are changed, so that it is committed with the ADRs
+func refreshedToc(repo *adr.Repository) string {
+	path := indexPath(repo)
+	if path == "" {
+		return ""
+	}
+	content, err := repo.FS.ReadFile(path)
+	if err != nil {
+		if toc := repo.Settings().Toc; toc == nil || !toc.OnNew {
+			return ""
+		}
+		return path
+	}
+	if !strings.HasPrefix(string(content), adr.TocMarker) {
+		return ""
+	}
+	return path
+}
+
+// withToc files and the table of contents regenerated along with them, if any
+func withToc(repo *adr.Repository, files ...string) []string {
+	if toc := refreshedToc(repo); toc != "" {
+		if _, err := repo.FS.Stat(toc); err == nil {
+			files = append(files, toc)
+		}
+	}
+	return files
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// translationsDirName the folder of the adr configuration caching the translations, one <lang>.json file each
+const translationsDirName = "translations"
+
+// translationBatch how many lines are sent to the provider at once
+const translationBatch = 50
+
+var (
+	// translationPrefixRegexp the markdown markers kept as is at the start of a line: headings, list items, quotes
+	// and the numbers of titles
+	translationPrefixRegexp = regexp.MustCompile(`^\s*(?:(?:#{1,6}|[-*+]|\d+[.)]|>)\s+)*`)
+	// translationFieldRegexp the metadata lines left untranslated, as adr reads them
+	translationFieldRegexp = regexp.MustCompile(`^\s*[-*]?\s*(Date|Author|Status|Tags|Review[ -]by|Deadline|Issue|Supersedes|Superseded by)\s*:`)
+)
+
+// translator translates texts to a language, keeping their order
+type translator interface {
+	Translate(ctx context.Context, texts []string, lang string) ([]string, error)
+}
+
+// translationProviders the providers of the translation configuration, by name
+var translationProviders = map[string]func(config adr.TranslationConfig, key string) translator{
+	"deepl": func(config adr.TranslationConfig, key string) translator {
+		if config.URL == "" {
+			config.URL = "https://api-free.deepl.com"
+		}
+		return &deeplTranslator{config: config, key: key, client: &http.Client{Timeout: time.Minute}}
+	},
+	"libretranslate": func(config adr.TranslationConfig, key string) translator {
+		return &libreTranslator{config: config, key: key, client: &http.Client{Timeout: time.Minute}}
+	},
+}
+
+func newTranslator(repo *adr.Repository) (translator, error) {
+	config := repo.Settings().Translation
+	if config == nil || config.Provider == "" {
+		return nil, errors.New("no translation provider, set the provider of the \"translation\" object of " + repo.ConfigPath())
+	}
+	newProvider, ok := translationProviders[config.Provider]
+	if !ok {
+		return nil, errors.New("unknown translation provider '" + config.Provider + "', expected deepl or libretranslate")
+	}
+	key := config.Key(os.Getenv)
+	if key == "" && config.Provider == "deepl" {
+		return nil, errors.New("the DeepL API key is missing, set api_key or api_key_env in " + repo.ConfigPath())
+	}
+	if config.URL == "" && config.Provider == "libretranslate" {
+		return nil, errors.New("the LibreTranslate server is missing, set the url of the \"translation\" object of " + repo.ConfigPath())
+	}
+	return newProvider(*config, key), nil
+}
+
+// deeplTranslator translates with the DeepL API
+type deeplTranslator struct {
+	config adr.TranslationConfig
+	key    string
+	client *http.Client
+}
+
+// Translate implements translator
+func (d *deeplTranslator) Translate(ctx context.Context, texts []string, lang string) ([]string, error) {
+	body := map[string]interface{}{"text": texts, "target_lang": strings.ToUpper(lang)}
+	if d.config.Source != "" {
+		body["source_lang"] = strings.ToUpper(d.config.Source)
+	}
+	answer := struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}{}
+	headers := map[string]string{"Authorization": "DeepL-Auth-Key " + d.key}
+	if err := postTranslation(ctx, d.client, "DeepL", strings.TrimSuffix(d.config.URL, "/")+"/v2/translate", headers, body, &answer); err != nil {
+		return nil, err
+	}
+	translated := []string{}
+	for _, translation := range answer.Translations {
+		translated = append(translated, translation.Text)
+	}
+	return translated, nil
+}
+
+// libreTranslator translates with a LibreTranslate server
+type libreTranslator struct {
+	config adr.TranslationConfig
+	key    string
+	client *http.Client
+}
+
+// Translate implements translator
+func (l *libreTranslator) Translate(ctx context.Context, texts []string, lang string) ([]string, error) {
+	source := l.config.Source
+	if source == "" {
+		source = "auto"
+	}
+	body := map[string]interface{}{"q": texts, "source": source, "target": lang, "format": "text"}
+	if l.key != "" {
+		body["api_key"] = l.key
+	}
+	answer := struct {
+		TranslatedText []string `json:"translatedText"`
+	}{}
+	if err := postTranslation(ctx, l.client, "LibreTranslate", strings.TrimSuffix(l.config.URL, "/")+"/translate", nil, body, &answer); err != nil {
+		return nil, err
+	}
+	return answer.TranslatedText, nil
+}
+
+// postTranslation posts body as JSON to url and decodes the JSON answer into result
+func postTranslation(ctx context.Context, client *http.Client, provider string, url string, headers map[string]string, body interface{}, result interface{}) error {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode >= 300 {
+		answer := struct {
+			Message string `json:"message"`
+			Error   string `json:"error"`
+		}{}
+		json.Unmarshal(content, &answer)
+		message := answer.Message + answer.Error
+		if message == "" {
+			message = strings.TrimSpace(string(content))
+		}
+		return fmt.Errorf("%s answered %s: %s", provider, response.Status, message)
+	}
+	return json.Unmarshal(content, result)
+}
+
+// translateMarkdown translates the text of content, line by line, leaving as is the code blocks, the front matter,
+// the rules under headings, the metadata fields and the markdown markers starting the lines
+func translateMarkdown(ctx context.Context, t translator, content string, lang string) (string, error) {
+	lines := strings.Split(strings.Replace(content, "\r\n", "\n", -1), "\n")
+	texts, at := []string{}, []int{}
+	frontMatter := len(lines) > 0 && strings.TrimSpace(lines[0]) == "---"
+	code := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case frontMatter:
+			frontMatter = i == 0 || trimmed != "---"
+			continue
+		case strings.HasPrefix(trimmed, "```"):
+			code = !code
+			continue
+		case code || trimmed == "" || strings.Trim(trimmed, "=-") == "" || translationFieldRegexp.MatchString(line),
+			strings.HasPrefix(trimmed, "![") && strings.Contains(trimmed, "](data:"):
+			continue
+		}
+		if text := line[len(translationPrefixRegexp.FindString(line)):]; strings.TrimSpace(text) != "" {
+			texts, at = append(texts, text), append(at, i)
+		}
+	}
+	for start := 0; start < len(texts); start += translationBatch {
+		end := start + translationBatch
+		if end > len(texts) {
+			end = len(texts)
+		}
+		translated, err := t.Translate(ctx, texts[start:end], lang)
+		if err != nil {
+			return "", err
+		}
+		if len(translated) != end-start {
+			return "", fmt.Errorf("the translation provider returned %d texts for %d", len(translated), end-start)
+		}
+		for j, text := range translated {
+			i := at[start+j]
+			lines[i] = translationPrefixRegexp.FindString(lines[i]) + text
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// translationCache the translations of the ADRs to a language, by hash of their content
+type translationCache struct {
+	Translations map[string]string `json:"translations"`
+}
+
+// translateRecords translates records to lang into copies of their files in a temporary directory, which the
+// records returned point to, with their titles translated. The ADRs translated before, unchanged since, come from
+// the cache of the configuration folder. The returned function removes the copies.
+func translateRecords(ctx context.Context, repo *adr.Repository, records []adr.Record, lang string) ([]adr.Record, func(), error) {
+	t, err := newTranslator(repo)
+	if err != nil {
+		return nil, nil, err
+	}
+	cachePath := filepath.Join(repo.ConfigDir, translationsDirName, lang+".json")
+	cache := translationCache{}
+	if content, err := os.ReadFile(cachePath); err == nil {
+		if err := json.Unmarshal(content, &cache); err != nil {
+			slog.Debug("translation cache not read, translating again", "path", cachePath, "error", err)
+		}
+	}
+	// the translations of this export only are kept, dropping those of the ADRs that changed since
+	used := translationCache{Translations: map[string]string{}}
+
+	fresh := 0
+	translated, cleanup, err := rewriteCopies(records, "adr-"+lang+"-", func(record adr.Record, content []byte) ([]byte, error) {
+		sum := sha256.Sum256(content)
+		hash := hex.EncodeToString(sum[:])
+		text, cached := cache.Translations[hash]
+		if !cached {
+			var err error
+			if text, err = translateMarkdown(ctx, t, string(content), lang); err != nil {
+				return nil, errors.New(record.Path + ": " + err.Error())
+			}
+			fresh++
+		}
+		used.Translations[hash] = text
+		return []byte(text), nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	slog.Debug("ADRs translated", "lang", lang, "translated", fresh, "cached", len(records)-fresh)
+
+	// a cache that cannot be saved only costs translating again
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+		if content, err := json.MarshalIndent(used, "", " "); err == nil {
+			if err := os.WriteFile(cachePath, content, 0644); err != nil {
+				slog.Debug("translation cache not saved", "path", cachePath, "error", err)
+			}
+		}
+	}
+	return translated, cleanup, nil
+}
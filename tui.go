@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// Keys of the terminal UI, besides the keys it shares with the status board
+const (
+	keyPageUp   = "page-up"
+	keyPageDown = "page-down"
+	keySearch   = "search"
+	keyReload   = "reload"
+)
+
+// browserKeys maps the bytes read from the terminal to the keys of the terminal UI: arrows and jk select an ADR,
+// page up and down or b and space scroll its preview
+var browserKeys = map[string]string{
+	"\x1b[A": keyUp, "k": keyUp,
+	"\x1b[B": keyDown, "j": keyDown,
+	"\x1b[5~": keyPageUp, "b": keyPageUp,
+	"\x1b[6~": keyPageDown, " ": keyPageDown,
+	"/": keySearch,
+	"r": keyReload,
+	"q": keyQuit, "\x1b": keyQuit, "\x03": keyQuit,
+}
+
+// browserStatuses the statuses the keys of the terminal UI give the selected ADR
+var browserStatuses = map[string]adr.Status{"p": adr.Proposed, "a": adr.Accepted, "d": adr.Deprecated}
+
+// browser the ADRs of the terminal UI matching its search, with a cursor on the one previewed
+type browser struct {
+	records []adr.Record
+	shown   []adr.Record
+	query   string
+	row     int
+	// scroll the first line of the preview shown
+	scroll int
+}
+
+func newBrowser(records []adr.Record) *browser {
+	b := &browser{records: records}
+	b.filter()
+	return b
+}
+
+// filter shows the ADRs matching the query, best matches first, all of them without a query
+func (b *browser) filter() {
+	b.shown = b.records
+	if strings.TrimSpace(b.query) != "" {
+		b.shown = fuzzyFilter(b.records, b.query)
+	}
+	b.row = clamp(b.row, 0, len(b.shown)-1)
+	b.scroll = 0
+}
+
+// selected the ADR under the cursor
+func (b *browser) selected() (adr.Record, bool) {
+	if b.row >= len(b.shown) {
+		return adr.Record{}, false
+	}
+	return b.shown[b.row], true
+}
+
+// moveCursor moves the cursor by rows, the preview starting over
+func (b *browser) moveCursor(rows int) {
+	row := clamp(b.row+rows, 0, len(b.shown)-1)
+	if row != b.row {
+		b.row, b.scroll = row, 0
+	}
+}
+
+// replace updates the ADR of record, e.g. once its status changed
+func (b *browser) replace(record adr.Record) {
+	for _, records := range [][]adr.Record{b.records, b.shown} {
+		for i := range records {
+			if records[i].Path == record.Path {
+				records[i] = record
+			}
+		}
+	}
+}
+
+// render draws the list of the ADRs next to the preview of the selected one for a terminal of the given size,
+// in raw mode lines end with \r\n. The footer shows the search while typing it, searching, and the keys otherwise.
+func (b *browser) render(w io.Writer, width int, height int, preview []string, searching bool, message string) {
+	listWidth := width * 2 / 5
+	previewWidth := width - listWidth - 3
+	rows := height - 4
+	lines := []string{}
+	title := ""
+	if record, ok := b.selected(); ok {
+		title = record.Title
+	}
+	lines = append(lines, "\x1b[1m"+fit(fmt.Sprintf("ADRs (%d/%d)", len(b.shown), len(b.records)), listWidth)+" │ "+fit(title, previewWidth)+"\x1b[0m")
+	lines = append(lines, strings.Repeat("─", listWidth+1)+"┼"+strings.Repeat("─", previewWidth+1))
+	first := 0
+	if b.row >= rows {
+		first = b.row - rows + 1
+	}
+	for i := 0; i < rows; i++ {
+		cell := fit("", listWidth)
+		if row := first + i; row < len(b.shown) {
+			cell = fit(pickerLine(b.shown[row]), listWidth)
+			if row == b.row {
+				cell = "\x1b[7m" + cell + "\x1b[0m"
+			}
+		}
+		text := ""
+		if line := b.scroll + i; line < len(preview) {
+			text = preview[line]
+		}
+		lines = append(lines, cell+" │ "+fit(text, previewWidth))
+	}
+	switch {
+	case searching:
+		lines = append(lines, "/"+b.query+"\x1b[7m \x1b[0m  enter keeps the search, esc clears it")
+	case b.query != "":
+		lines = append(lines, fit("↓↑ or jk select, b space scroll, p a d propose accept deprecate, / search: "+b.query+", r reload, q quit", width-1))
+	default:
+		lines = append(lines, fit("↓↑ or jk select, b space scroll, p a d propose accept deprecate, / search, r reload, q quit", width-1))
+	}
+	lines = append(lines, fit(message, width-1))
+	fmt.Fprint(w, "\x1b[H\x1b[2J"+strings.Join(lines, "\r\n"))
+}
+
+// previewLines the content of the ADR of record, rendered for the terminal without colors and wrapped to width
+func previewLines(repo *adr.Repository, record adr.Record, width int) []string {
+	content, err := repo.FS.ReadFile(record.Path)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	rendered := markdownRenderer{&reporter{NoColor: true}}.Render(strings.TrimSpace(string(content)))
+	lines := []string{}
+	for _, line := range strings.Split(rendered, "\n") {
+		for width > 0 && utf8.RuneCountInString(line) > width {
+			runes := []rune(line)
+			lines = append(lines, string(runes[:width]))
+			line = string(runes[width:])
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// runBrowser shows the terminal UI until the user quits: the ADRs listed next to the preview of the selected one,
+// searched as with the picker, the keys of the statuses transitioning the selected ADR
+func runBrowser(ctx context.Context, repo *adr.Repository) error {
+	if !interactive() {
+		return errors.New("adr tui needs a terminal")
+	}
+	records, err := repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	restore, err := rawTerminal()
+	if err != nil {
+		return errors.New("adr tui cannot read keys: " + err.Error())
+	}
+	defer restore()
+
+	b := newBrowser(records)
+	message := ""
+	searching := false
+	buffer := make([]byte, 8)
+	for ctx.Err() == nil {
+		width, height := terminalSize()
+		preview := []string{}
+		if record, ok := b.selected(); ok {
+			preview = previewLines(repo, record, width-width*2/5-3)
+		}
+		b.render(os.Stdout, width, height, preview, searching, message)
+		message = ""
+		n, err := os.Stdin.Read(buffer)
+		if err != nil {
+			return err
+		}
+		key := string(buffer[:n])
+		if searching {
+			switch key {
+			case "\r", "\n":
+				searching = false
+			case "\x1b", "\x03":
+				searching, b.query = false, ""
+			case "\x7f", "\b":
+				if runes := []rune(b.query); len(runes) > 0 {
+					b.query = string(runes[:len(runes)-1])
+				}
+			default:
+				if utf8.ValidString(key) && !strings.ContainsAny(key, "\x1b\r\n\t") {
+					b.query += key
+				}
+			}
+			b.filter()
+			continue
+		}
+		if status, ok := browserStatuses[key]; ok {
+			record, ok := b.selected()
+			if !ok {
+				continue
+			}
+			if record.Draft != "" {
+				message = "ADR " + record.ID() + " is a draft, 'adr finalize' numbers it before its status changes"
+				continue
+			}
+			repo.Category = record.Category
+			updated, err := transitionAdr(ctx, repo, "tui", record, status)
+			if err != nil {
+				message = err.Error()
+				continue
+			}
+			b.replace(updated)
+			message = fmt.Sprintf("%s. %s is now %s", updated.ID(), updated.Title, updated.Status)
+			continue
+		}
+		switch browserKeys[key] {
+		case keyUp:
+			b.moveCursor(-1)
+		case keyDown:
+			b.moveCursor(1)
+		case keyPageUp:
+			b.scroll = clamp(b.scroll-(height-4), 0, len(preview)-1)
+		case keyPageDown:
+			b.scroll = clamp(b.scroll+(height-4), 0, len(preview)-1)
+		case keySearch:
+			searching = true
+		case keyReload:
+			if records, err = repo.List(ctx); err != nil {
+				message = err.Error()
+				continue
+			}
+			b.records = records
+			b.filter()
+			message = pluralize(len(records), "ADR") + " read again"
+		case keyQuit:
+			if b.query != "" && key == "\x1b" {
+				b.query = ""
+				b.filter()
+				continue
+			}
+			return nil
+		}
+	}
+	return ctx.Err()
+}
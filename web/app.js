@@ -0,0 +1,237 @@
+// The web UI of adr serve: a list of the ADRs with filters, the rendered ADRs and the graph of their links,
+// all read from the REST API.
+(function () {
+  "use strict";
+
+  const $ = (id) => document.getElementById(id);
+  const views = ["list-view", "adr-view", "graph-view"];
+
+  function show(view) {
+    views.forEach((v) => { $(v).hidden = v !== view; });
+    $("nav-list").classList.toggle("active", view !== "graph-view");
+    $("nav-graph").classList.toggle("active", view === "graph-view");
+    $("error").hidden = true;
+  }
+
+  function fail(error) {
+    $("error").textContent = error.message || String(error);
+    $("error").hidden = false;
+  }
+
+  async function api(path) {
+    const response = await fetch(path);
+    if (!response.ok) {
+      const body = await response.json().catch(() => ({}));
+      throw new Error(body.error || response.statusText);
+    }
+    return response.headers.get("Content-Type").startsWith("application/json") ? response.json() : response.text();
+  }
+
+  function element(tag, attributes, ...children) {
+    const e = document.createElement(tag);
+    Object.entries(attributes || {}).forEach(([name, value]) => e.setAttribute(name, value));
+    children.forEach((child) => e.append(child));
+    return e;
+  }
+
+  function statusBadge(status) {
+    return element("span", { class: "status status-" + status }, status || "");
+  }
+
+  function tags(record) {
+    return (record.tags || []).map((tag) => element("span", { class: "tag" }, tag));
+  }
+
+  // the statuses and tags of the list filter it when clicked
+  function filterOn(name, value, e) {
+    e.classList.add("filter");
+    e.dataset.filter = name;
+    e.dataset.value = value;
+    e.title = "Show the ADRs with this " + name;
+    return e;
+  }
+
+  // with several scopes, e.g. the roots of other repositories, ADRs are shown and addressed with their scope
+  let scopes = [];
+
+  // ADRs of a category are addressed with it as well, their numbers may be those of other categories
+  function scoped(path, scope, category) {
+    const params = new URLSearchParams();
+    if (scope) {
+      params.set("scope", scope);
+    }
+    if (category) {
+      params.set("category", category);
+    }
+    return params.toString() ? path + "?" + params : path;
+  }
+
+  function adrLink(record) {
+    return scoped("#/adr/" + record.number, scopes.length > 1 && record.scope, record.category);
+  }
+
+  function adrLabel(record) {
+    return (record.category ? record.category + "/" : "") + record.id;
+  }
+
+  async function loadScopes() {
+    scopes = (await api("api/scopes")).scopes;
+    if (scopes.length > 1) {
+      $("scope-filter").append(...scopes.map((scope) => element("option", {}, scope)));
+      $("scope-filter").hidden = false;
+      document.querySelectorAll(".scope-column").forEach((e) => { e.hidden = false; });
+    }
+  }
+
+  // the filters of the list, also written in the URL so that a filtered list can be shared or bookmarked
+  function filterParams() {
+    const params = new URLSearchParams();
+    for (const [name, value] of new FormData($("filters"))) {
+      if (value.trim() !== "") {
+        params.append(name, value.trim());
+      }
+    }
+    return params;
+  }
+
+  function setFilters(params) {
+    for (const field of $("filters").elements) {
+      field.value = params.get(field.name) || "";
+    }
+  }
+
+  function applyFilters() {
+    const params = filterParams();
+    history.replaceState(null, "", "#/" + (params.toString() ? "?" + params : ""));
+    return showList();
+  }
+
+  async function loadTags() {
+    const known = new Set();
+    (await api("api/adrs")).records.forEach((record) => (record.tags || []).forEach((tag) => known.add(tag)));
+    $("known-tags").replaceChildren(...[...known].sort().map((tag) => element("option", { value: tag })));
+  }
+
+  async function showList() {
+    show("list-view");
+    const listing = await api("api/adrs?" + filterParams());
+    const rows = listing.records.map((record) => element("tr", {},
+      ...(scopes.length > 1 ? [element("td", {}, record.scope || "")] : []),
+      element("td", {}, adrLabel(record)),
+      element("td", {}, record.number ? element("a", { href: adrLink(record) }, record.title) : record.title),
+      element("td", {}, filterOn("status", record.status, statusBadge(record.status))),
+      element("td", {}, record.date || ""),
+      element("td", {}, ...tags(record).map((tag) => filterOn("tag", tag.textContent, tag)))));
+    $("records").replaceChildren(...rows);
+    $("empty").hidden = rows.length > 0;
+  }
+
+  async function showAdr(number, scope, category) {
+    show("adr-view");
+    const [adrDocument, html] = await Promise.all([
+      api(scoped("api/adrs/" + number, scope, category)), api(scoped("api/adrs/" + number + "/html", scope, category))]);
+    const record = adrDocument.record;
+    $("adr-meta").replaceChildren(scope ? scope + " · " : "", category ? category + " · " : "", statusBadge(record.status), " " + (record.date || ""),
+      record.author ? " by " + record.author : "", " ", ...tags(record));
+    $("adr-content").innerHTML = html;
+    // links between ADRs point to markdown files of the same directory, they open the ADR in the UI instead
+    const byFile = {};
+    (await api(scoped("api/adrs", record.scope))).records.forEach((r) => { byFile[r.path.split(/[\\/]/).pop()] = r; });
+    $("adr-content").querySelectorAll("a[href$='.md']").forEach((a) => {
+      const target = byFile[a.getAttribute("href").split("/").pop()];
+      if (target && target.number) {
+        a.setAttribute("href", adrLink(target));
+      }
+    });
+  }
+
+  const svg = (tag, attributes, ...children) => {
+    const e = document.createElementNS("http://www.w3.org/2000/svg", tag);
+    Object.entries(attributes || {}).forEach(([name, value]) => e.setAttribute(name, value));
+    children.forEach((child) => e.append(child));
+    return e;
+  };
+
+  async function showGraph() {
+    show("graph-view");
+    const graph = await api("api/graph");
+    // ADRs are laid out on a grid in number order, links are drawn as curves between them
+    const columns = 4, width = 200, height = 36, gapX = 40, gapY = 50;
+    const position = {};
+    graph.records.forEach((record, i) => {
+      position[record.path] = {
+        x: 20 + (i % columns) * (width + gapX),
+        y: 20 + Math.floor(i / columns) * (height + gapY),
+      };
+    });
+    const rows = Math.ceil(graph.records.length / columns);
+    const root = svg("svg", { viewBox: "0 0 " + (40 + columns * (width + gapX)) + " " + (40 + rows * (height + gapY)) },
+      svg("defs", {}, svg("marker", { id: "arrow", viewBox: "0 0 10 10", refX: "10", refY: "5", markerWidth: "8", markerHeight: "8", orient: "auto" },
+        svg("path", { d: "M0,0 L10,5 L0,10 z", fill: "#656d76" }))));
+    // links usually go both ways, e.g. Supersedes and Superseded by, a single arrow is drawn for them
+    const drawn = new Set();
+    graph.edges.forEach((edge) => {
+      if (drawn.has(edge.to + "\n" + edge.from)) {
+        return;
+      }
+      drawn.add(edge.from + "\n" + edge.to);
+      const from = position[edge.from], to = position[edge.to];
+      const x1 = from.x + width / 2, y1 = from.y + height, x2 = to.x + width / 2, y2 = to.y;
+      const bend = (y2 <= y1 ? -1 : 1) * 40;
+      root.append(svg("path", { class: "edge", d: `M${x1},${y1} C${x1},${y1 + bend} ${x2},${y2 - bend} ${x2},${y2}` }));
+      root.append(svg("text", { class: "edge-label", x: (x1 + x2) / 2 + 4, y: (y1 + y2) / 2 }, edge.kind));
+    });
+    graph.records.forEach((record) => {
+      const p = position[record.path];
+      const label = (scopes.length > 1 && record.scope ? record.scope + " " : "") + adrLabel(record) + ". " + record.title;
+      const node = svg("g", { class: "node", transform: `translate(${p.x},${p.y})` },
+        svg("title", {}, label + " [" + record.status + "]"),
+        svg("rect", { width: width, height: height }),
+        svg("text", { x: 8, y: 22, class: "status-" + record.status }, label.length > 30 ? label.slice(0, 29) + "…" : label));
+      if (record.number) {
+        node.addEventListener("click", () => { location.hash = adrLink(record); });
+      }
+      root.append(node);
+    });
+    $("graph").replaceChildren(root);
+  }
+
+  function route() {
+    const hash = location.hash.replace(/^#/, "") || "/";
+    const adr = hash.match(/^\/adr\/(\d+)(?:\?(.+))?$/);
+    const list = hash.match(/^\/(?:\?(.*))?$/);
+    const params = new URLSearchParams(adr ? adr[2] : list ? list[1] : "");
+    if (list) {
+      setFilters(params);
+    }
+    const shown = adr ? showAdr(adr[1], params.get("scope"), params.get("category")) : hash === "/graph" ? showGraph() : showList();
+    shown.catch(fail);
+  }
+
+  let typing;
+  $("filters").addEventListener("input", () => {
+    clearTimeout(typing);
+    typing = setTimeout(() => applyFilters().catch(fail), 200);
+  });
+  $("records").addEventListener("click", (event) => {
+    const filter = event.target.closest("[data-filter]");
+    if (filter) {
+      $("filters").elements[filter.dataset.filter].value = filter.dataset.value;
+      applyFilters().catch(fail);
+    }
+  });
+  $("filters").addEventListener("submit", (event) => event.preventDefault());
+  window.addEventListener("hashchange", route);
+  loadScopes().catch(fail).then(route);
+  loadTags().catch(fail);
+
+  // the views follow the changes of the ADRs, whether made here, with adr or in an editor
+  let refreshing;
+  const events = new EventSource("api/events");
+  ["record_created", "record_updated", "status_changed", "record_removed"].forEach((name) => {
+    events.addEventListener(name, () => {
+      clearTimeout(refreshing);
+      refreshing = setTimeout(route, 300);
+    });
+  });
+})();
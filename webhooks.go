@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+// Headers of webhook deliveries
+const (
+	webhookEventHeader     = "X-Adr-Event"
+	webhookSignatureHeader = "X-Adr-Signature-256"
+)
+
+// webhookAttempts how many times a delivery is tried before giving up, waiting twice longer after each failure
+const webhookAttempts = 3
+
+var webhookRetryDelay = 2 * time.Second
+
+// WebhookPayload the JSON body posted to webhooks
+type WebhookPayload struct {
+	SchemaVersion int            `json:"schema_version"`
+	Event         string         `json:"event"`
+	SentAt        time.Time      `json:"sent_at"`
+	Record        adr.RecordJSON `json:"record"`
+	From          adr.Status     `json:"from,omitempty"`
+	To            adr.Status     `json:"to,omitempty"`
+}
+
+// webhookPayload the payload of a lifecycle event, false for the events not sent to webhooks
+func webhookPayload(event adr.Event, now time.Time) (WebhookPayload, bool) {
+	payload := WebhookPayload{SchemaVersion: adr.SchemaVersion, Event: event.EventName(), SentAt: now.UTC()}
+	switch e := event.(type) {
+	case adr.RecordCreated:
+		payload.Record = e.Record.JSON()
+	case adr.StatusChanged:
+		payload.Record, payload.From, payload.To = e.Record.JSON(), e.From, e.To
+	default:
+		return payload, false
+	}
+	return payload, true
+}
+
+// webhookSignature signs a body with the secret of a webhook: "sha256=" and the hex HMAC-SHA256 of the body
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookSender posts the lifecycle events of a repository to the webhooks of its configuration
+type webhookSender struct {
+	ctx     context.Context
+	client  *http.Client
+	hooks   []adr.Webhook
+	pending sync.WaitGroup
+}
+
+// sendWebhooks subscribes to the events of repo, posting them to the configured webhooks in the background.
+// The returned function unsubscribes and waits for the deliveries in flight.
+func sendWebhooks(ctx context.Context, repo *adr.Repository) func() {
+	sender := &webhookSender{ctx: ctx, client: &http.Client{Timeout: 10 * time.Second}, hooks: repo.Settings().Webhooks}
+	if len(sender.hooks) == 0 {
+		return func() {}
+	}
+	unsubscribe := repo.Subscribe(sender.handle)
+	return func() {
+		unsubscribe()
+		sender.pending.Wait()
+	}
+}
+
+func (s *webhookSender) handle(event adr.Event) {
+	payload, ok := webhookPayload(event, time.Now())
+	if !ok {
+		return
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Warn("webhook payload not encoded", "event", payload.Event, "error", err)
+		return
+	}
+	for _, hook := range s.hooks {
+		if !hook.Wants(payload.Event) {
+			continue
+		}
+		s.pending.Add(1)
+		go func(hook adr.Webhook) {
+			defer s.pending.Done()
+			s.deliver(hook, payload.Event, body)
+		}(hook)
+	}
+}
+
+// deliver posts body to a webhook, trying again after failures and server errors
+func (s *webhookSender) deliver(hook adr.Webhook, event string, body []byte) {
+	delay := webhookRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := s.post(hook, event, body)
+		if err == nil {
+			slog.Debug("webhook delivered", "url", hook.URL, "event", event)
+			return
+		}
+		if attempt == webhookAttempts {
+			slog.Warn("webhook not delivered", "url", hook.URL, "event", event, "attempts", attempt, "error", err)
+			return
+		}
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-s.ctx.Done():
+			slog.Warn("webhook not delivered", "url", hook.URL, "event", event, "error", s.ctx.Err())
+			return
+		}
+	}
+}
+
+func (s *webhookSender) post(hook adr.Webhook, event string, body []byte) error {
+	request, err := http.NewRequestWithContext(context.WithoutCancel(s.ctx), http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "adr")
+	request.Header.Set(webhookEventHeader, event)
+	if secret := hook.SigningSecret(os.Getenv); secret != "" {
+		request.Header.Set(webhookSignatureHeader, webhookSignature(secret, body))
+	}
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("%s answered %s", hook.URL, response.Status)
+	}
+	return nil
+}
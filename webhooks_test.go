@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/marouni/adr/pkg/adr"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	tests := []struct {
+		secret string
+		body   string
+		want   string
+	}{
+		{"key", "The quick brown fox jumps over the lazy dog", "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+		{"", "", "sha256=b613679a0814d9ec772f95d778c35fc5ff1697c493715653c6c712144292c5ad"},
+	}
+	for _, test := range tests {
+		if got := webhookSignature(test.secret, []byte(test.body)); got != test.want {
+			t.Errorf("webhookSignature(%q, %q) = %s, want %s", test.secret, test.body, got, test.want)
+		}
+	}
+}
+
+func TestWebhookHeaders(t *testing.T) {
+	tests := []struct {
+		name      string
+		hook      adr.Webhook
+		signature string
+	}{
+		{
+			name:      "secret",
+			hook:      adr.Webhook{Secret: "s3cret"},
+			signature: webhookSignature("s3cret", []byte(`{"event":"status_changed"}`)),
+		},
+		{
+			name: "no secret",
+			hook: adr.Webhook{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			received := make(chan *http.Request, 1)
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				received <- r
+			}))
+			defer server.Close()
+
+			sender := &webhookSender{ctx: context.Background(), client: &http.Client{Timeout: 5 * time.Second}}
+			test.hook.URL = server.URL
+			if err := sender.post(test.hook, "status_changed", []byte(`{"event":"status_changed"}`)); err != nil {
+				t.Fatalf("post() failed: %v", err)
+			}
+			r := <-received
+			if got := r.Header.Get(webhookEventHeader); got != "status_changed" {
+				t.Errorf("the %s header is %q, want status_changed", webhookEventHeader, got)
+			}
+			if got := r.Header.Get(webhookSignatureHeader); got != test.signature {
+				t.Errorf("the %s header is %q, want %q", webhookSignatureHeader, got, test.signature)
+			}
+			if test.signature != "" && webhookSignature("s3cret", body) != r.Header.Get(webhookSignatureHeader) {
+				t.Errorf("the signature does not match the body received, %s", body)
+			}
+		})
+	}
+}